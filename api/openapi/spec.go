@@ -0,0 +1,8 @@
+package openapi
+
+import _ "embed"
+
+// Spec is the raw OpenAPI document the generated types are built from.
+//
+//go:embed openapi.yml
+var Spec []byte
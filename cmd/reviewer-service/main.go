@@ -3,19 +3,34 @@ package main
 import (
 	"context"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// Working hours name IANA zones; embed them in case the image has none.
+	_ "time/tzdata"
 
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/openapi"
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	apigrpc "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/api/grpc"
 	apihttp "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/api/http"
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/config"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/integration/github"
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
 )
 
 func main() {
+	if len(os.Args) > 1 && (os.Args[1] == "import" || os.Args[1] == "export") {
+		os.Exit(runRoster(os.Args[1], os.Args[2:]))
+	}
+
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
 	logger.Info("Reviewer service started")
 
@@ -47,16 +62,53 @@ func main() {
 	teamRepo := postgres.NewTeamRepo(db)
 	userRepo := postgres.NewUserRepo(db)
 	prRepo := postgres.NewPRRepo(db)
+	prRepo.SetCapacityOverflow(domain.CapacityOverflow(cfg.CapacityOverflow()))
+	prRepo.SetFairness(cfg.FairnessWindow(), time.Now)
+	txManager := postgres.NewTxManager(db)
 
-	teamService := service.NewTeamService(teamRepo, userRepo)
-	userService := service.NewUserService(userRepo, prRepo)
+	teamService := service.NewTeamService(teamRepo, userRepo, prRepo, txManager)
+	userService := service.NewUserService(userRepo, prRepo, txManager)
 	prService := service.NewPRService(prRepo, userRepo, time.Now)
+	prService.SetTeamHierarchy(teamRepo)
+	prService.SetReviewerPreference(service.ReviewerPreference(cfg.ReviewerPreference()))
+	prService.SetCapacityOverflow(domain.CapacityOverflow(cfg.CapacityOverflow()))
+	prService.SetFairness(prRepo)
 	statsService := service.NewStatsService(prRepo)
-
-	app := service.NewApp(teamService, userService, prService, statsService)
+	rosterService := service.NewRosterService(postgres.NewRosterRepo(db), prRepo, txManager)
+	oooService := service.NewOutOfOfficeService(postgres.NewOutOfOfficeRepo(db), prRepo, txManager, time.Now)
+
+	app := service.NewApp(teamService, userService, prService, statsService, rosterService, oooService)
+
+	idempotencyService := service.NewIdempotencyService(postgres.NewIdempotencyRepo(db), time.Now, cfg.IdempotencyTTL())
+	idempotencyService.SetLockTimeout(cfg.IdempotencyLockTimeout())
+	app.WithIdempotency(idempotencyService)
+
+	if cfg.GitHubEnabled() {
+		ghClient := github.NewClient(github.Config{
+			BaseURL:      cfg.GitHub.BaseURLOrDefault(),
+			Token:        cfg.GitHub.TokenOrEnv(),
+			MaxAttempts:  cfg.GitHub.MaxAttemptsOrDefault(),
+			RetryBackoff: cfg.GitHub.RetryBackoffOrDefault(),
+			Timeout:      cfg.GitHub.TimeoutOrDefault(),
+		})
+		syncService := service.NewReviewerSyncService(ghClient, postgres.NewReviewerSyncRepo(db), userRepo, time.Now, cfg.GitHub.SyncTimeout())
+		app.WithReviewerSync(syncService)
+		logger.Info("github reviewer sync enabled", "base_url", cfg.GitHub.BaseURLOrDefault())
+	}
 
 	server := apihttp.NewServer(app, logger)
-	router := apihttp.NewRouter(server, logger)
+	if cfg.SCIMEnabled() {
+		server.EnableSCIM(cfg.SCIM.TokenOrEnv())
+		logger.Info("scim provisioning enabled")
+	}
+	validator, err := apihttp.NewOpenAPIValidator(server, openapi.Spec, apihttp.ValidatorOptions{
+		ValidateResponses: cfg.HTTPValidateResponses(),
+	})
+	if err != nil {
+		logger.Error("failed to build openapi validator", "error", err.Error())
+		return
+	}
+	router := apihttp.NewRouter(server, logger, validator)
 
 	srv := &http.Server{
 		Addr:         cfg.HTTPAddr(),
@@ -74,6 +126,29 @@ func main() {
 		}
 	}()
 
+	grpcListener, err := net.Listen("tcp", cfg.GRPCAddr())
+	if err != nil {
+		logger.Error("failed to listen for grpc", "addr", cfg.GRPCAddr(), "error", err.Error())
+		return
+	}
+
+	grpcServer := grpc.NewServer()
+	reviewerv1.RegisterReviewerServiceServer(grpcServer, apigrpc.NewServer(app, logger))
+	reflection.Register(grpcServer)
+
+	go func() {
+		logger.Info("grpc server starting", "addr", cfg.GRPCAddr())
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Error("grpc server failed", "error", err)
+			os.Exit(1)
+		}
+	}()
+
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go purgeIdempotencyKeys(jobsCtx, idempotencyService, logger)
+	go handOverOutOfOfficeReviews(jobsCtx, oooService, cfg.OutOfOfficeCheckInterval(), logger)
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 
@@ -88,4 +163,55 @@ func main() {
 	} else {
 		logger.Info("http server stopped gracefully")
 	}
+
+	grpcServer.GracefulStop()
+	logger.Info("grpc server stopped gracefully")
+
+	if app.Sync != nil {
+		app.Sync.Wait()
+	}
+}
+
+// purgeIdempotencyKeys drops expired Idempotency-Key records once per TTL.
+// Claim already ignores expired rows, this only keeps the table small.
+func purgeIdempotencyKeys(ctx context.Context, svc *service.IdempotencyService, logger *slog.Logger) {
+	ticker := time.NewTicker(svc.TTL())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := svc.PurgeExpired(ctx)
+			if err != nil {
+				logger.Error("failed to purge idempotency keys", "error", err)
+				continue
+			}
+			logger.Info("purged expired idempotency keys", "count", n)
+		}
+	}
+}
+
+// handOverOutOfOfficeReviews reassigns, once per interval, the open reviews
+// of users whose out-of-office period has started.
+func handOverOutOfOfficeReviews(ctx context.Context, svc *service.OutOfOfficeService, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			started, updated, err := svc.HandOverStarted(ctx)
+			if err != nil {
+				logger.Error("failed to hand over out-of-office reviews", "error", err)
+				continue
+			}
+			if len(started) > 0 {
+				logger.Info("handed over out-of-office reviews", "periods", len(started), "updated_pull_requests", updated)
+			}
+		}
+	}
 }
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/config"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+// runRoster runs the roster subcommands and returns the exit code:
+//
+//	reviewer-service import -config config.yaml -file roster.csv [-dry-run]
+//	reviewer-service export -config config.yaml -format csv [-file roster.csv]
+//
+// Without -file the roster is read from stdin or written to stdout. The
+// format defaults to the file extension, then to yaml.
+func runRoster(command string, args []string) int {
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to config file")
+	file := fs.String("file", "", "Roster file; stdin or stdout when empty")
+	format := fs.String("format", "", "Roster format: yaml or csv")
+	dryRun := fs.Bool("dry-run", false, "Only print the changes an import would make")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *configPath == "" {
+		fmt.Fprintln(os.Stderr, "config path is required")
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn}))
+	connectCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	db, err := postgres.NewDB(connectCtx, cfg.DB.ConnString(), logger)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to connect to db:", err)
+		return 1
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to close db:", err)
+		}
+	}()
+	if err := postgres.RunMigrations(connectCtx, db, logger); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to init db schema:", err)
+		return 1
+	}
+
+	roster := service.NewRosterService(postgres.NewRosterRepo(db), postgres.NewPRRepo(db), postgres.NewTxManager(db))
+	rosterFormat := rosterFileFormat(*format, *file)
+
+	if command == "import" {
+		err = importRoster(ctx, roster, rosterFormat, *file, *dryRun)
+	} else {
+		err = exportRoster(ctx, roster, rosterFormat, *file)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func importRoster(ctx context.Context, roster *service.RosterService, format domain.RosterFormat, file string, dryRun bool) error {
+	var (
+		data []byte
+		err  error
+	)
+	if file == "" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		// #nosec G304 -- roster file path is provided via command line flag
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return fmt.Errorf("read roster: %w", err)
+	}
+
+	result, err := roster.Import(ctx, format, data, dryRun)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range result.Changes {
+		fmt.Printf("%-15s %-20s %-20s %s\n", c.Kind, c.TeamName, c.UserID, c.Detail)
+	}
+	if dryRun {
+		fmt.Printf("dry run: %d changes not applied\n", len(result.Changes))
+	} else {
+		fmt.Printf("%d changes applied\n", len(result.Changes))
+	}
+	return nil
+}
+
+func exportRoster(ctx context.Context, roster *service.RosterService, format domain.RosterFormat, file string) error {
+	data, err := roster.Export(ctx, format)
+	if err != nil {
+		return err
+	}
+
+	if file == "" {
+		_, err = os.Stdout.Write(data)
+	} else {
+		err = os.WriteFile(file, data, 0o600)
+	}
+	if err != nil {
+		return fmt.Errorf("write roster: %w", err)
+	}
+	return nil
+}
+
+func rosterFileFormat(format, file string) domain.RosterFormat {
+	if format != "" {
+		return domain.RosterFormat(format)
+	}
+	switch filepath.Ext(file) {
+	case ".csv":
+		return domain.RosterFormatCSV
+	default:
+		return domain.RosterFormatYAML
+	}
+}
@@ -0,0 +1,28 @@
+package grpc
+
+import (
+	"context"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) ImportRoster(ctx context.Context, req *reviewerv1.ImportRosterRequest) (*reviewerv1.ImportRosterResponse, error) {
+	if len(req.GetData()) == 0 {
+		return nil, s.requiredField("data")
+	}
+
+	result, err := s.app.Roster.Import(ctx, converter.RosterFormatFromProto(req.GetFormat()), req.GetData(), req.GetDryRun())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+	return converter.RosterImportResultToProto(result), nil
+}
+
+func (s *Server) ExportRoster(ctx context.Context, req *reviewerv1.ExportRosterRequest) (*reviewerv1.ExportRosterResponse, error) {
+	data, err := s.app.Roster.Export(ctx, converter.RosterFormatFromProto(req.GetFormat()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+	return &reviewerv1.ExportRosterResponse{Data: data}, nil
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) AddOutOfOffice(ctx context.Context, req *reviewerv1.AddOutOfOfficeRequest) (*reviewerv1.OutOfOfficeResponse, error) {
+	if req.GetStartsAt() == nil {
+		return nil, s.requiredField("starts_at")
+	}
+	if req.GetEndsAt() == nil {
+		return nil, s.requiredField("ends_at")
+	}
+
+	created, err := s.app.OutOfOffice.AddPeriod(ctx, domain.OutOfOfficePeriod{
+		UserID:   req.GetUserId(),
+		StartsAt: req.GetStartsAt().GetSeconds(),
+		EndsAt:   req.GetEndsAt().GetSeconds(),
+		Reason:   req.GetReason(),
+	})
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.OutOfOfficeResponse{
+		Ooo: converter.OutOfOfficePeriodToProto(created),
+	}, nil
+}
+
+func (s *Server) ListOutOfOffice(ctx context.Context, req *reviewerv1.ListOutOfOfficeRequest) (*reviewerv1.ListOutOfOfficeResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+
+	periods, err := s.app.OutOfOffice.ListPeriods(ctx, req.GetUserId(), req.GetIncludePast())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.ListOutOfOfficeResponse{
+		UserId:  req.GetUserId(),
+		Periods: make([]*reviewerv1.OutOfOfficePeriod, 0, len(periods)),
+	}
+	for i := range periods {
+		resp.Periods = append(resp.Periods, converter.OutOfOfficePeriodToProto(&periods[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) RemoveOutOfOffice(ctx context.Context, req *reviewerv1.RemoveOutOfOfficeRequest) (*reviewerv1.OutOfOfficeResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+
+	removed, err := s.app.OutOfOffice.RemovePeriod(ctx, req.GetUserId(), req.GetOooId())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.OutOfOfficeResponse{
+		Ooo: converter.OutOfOfficePeriodToProto(removed),
+	}, nil
+}
@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) CreatePullRequest(ctx context.Context, req *reviewerv1.CreatePullRequestRequest) (*reviewerv1.PullRequestResponse, error) {
+	pr, err := s.app.PR.CreatePullRequest(ctx, req.GetPullRequestId(), req.GetPullRequestName(), req.GetAuthorId(),
+		req.GetTeamName(), req.GetLabels(),
+		domain.GitHubLink{Repo: req.GetGithubRepo(), Number: int(req.GetGithubNumber())})
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.PullRequestResponse{
+		Pr: converter.PullRequestToProto(pr),
+	}, nil
+}
+
+func (s *Server) MergePullRequest(ctx context.Context, req *reviewerv1.MergePullRequestRequest) (*reviewerv1.PullRequestResponse, error) {
+	pr, err := s.app.PR.MergePullRequest(ctx, req.GetPullRequestId(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.PullRequestResponse{
+		Pr: converter.PullRequestToProto(pr),
+	}, nil
+}
+
+func (s *Server) ReassignReviewer(ctx context.Context, req *reviewerv1.ReassignReviewerRequest) (*reviewerv1.ReassignReviewerResponse, error) {
+	updated, replacedBy, err := s.app.PR.ReassignReviewer(ctx, req.GetPullRequestId(), req.GetOldReviewerId(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.ReassignReviewerResponse{
+		Pr:         converter.PullRequestToProto(updated),
+		ReplacedBy: replacedBy,
+	}, nil
+}
+
+func (s *Server) GetPullRequest(ctx context.Context, req *reviewerv1.GetPullRequestRequest) (*reviewerv1.PullRequestResponse, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, s.requiredField("pull_request_id")
+	}
+
+	pr, err := s.app.PR.GetPullRequest(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.PullRequestResponse{
+		Pr: converter.PullRequestToProto(pr),
+	}, nil
+}
+
+func (s *Server) ListPullRequests(ctx context.Context, req *reviewerv1.ListPullRequestsRequest) (*reviewerv1.ListPullRequestsResponse, error) {
+	filter := domain.PullRequestFilter{
+		Status:      converter.PRStatusFromProto(req.GetStatus()),
+		AuthorID:    req.GetAuthorId(),
+		ReviewerID:  req.GetReviewerId(),
+		TeamName:    req.GetTeamName(),
+		CreatedFrom: req.GetCreatedFrom().GetSeconds(),
+		CreatedTo:   req.GetCreatedTo().GetSeconds(),
+		MergedFrom:  req.GetMergedFrom().GetSeconds(),
+		MergedTo:    req.GetMergedTo().GetSeconds(),
+	}
+
+	prs, next, err := s.app.PR.ListPullRequests(ctx, filter, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.ListPullRequestsResponse{
+		PullRequests:  make([]*reviewerv1.PullRequest, 0, len(prs)),
+		NextPageToken: next,
+	}
+	for i := range prs {
+		resp.PullRequests = append(resp.PullRequests, converter.PullRequestToProto(&prs[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetSyncStatus(ctx context.Context, req *reviewerv1.GetSyncStatusRequest) (*reviewerv1.ReviewerSync, error) {
+	if req.GetPullRequestId() == "" {
+		return nil, s.requiredField("pull_request_id")
+	}
+	if s.app.Sync == nil {
+		return nil, s.handleError(domain.NewDomainError(domain.ErrorCodeNotFound, "reviewer sync is disabled"))
+	}
+
+	status, err := s.app.Sync.GetSyncStatus(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.ReviewerSyncToProto(status), nil
+}
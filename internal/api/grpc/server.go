@@ -0,0 +1,108 @@
+package grpc
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+const errorDomain = "reviewer-service"
+
+type Server struct {
+	reviewerv1.UnimplementedReviewerServiceServer
+
+	app    *service.App
+	logger *slog.Logger
+}
+
+func NewServer(app *service.App, logger *slog.Logger) *Server {
+	return &Server{
+		app:    app,
+		logger: logger,
+	}
+}
+
+func (s *Server) domainStatus(c codes.Code, code domain.ErrorCode, message string, fields ...domain.FieldError) error {
+	st := status.New(c, message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: string(code),
+		Domain: errorDomain,
+	}}
+	if len(fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+		for _, f := range fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Reason,
+			})
+		}
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// handleError mirrors the HTTP Server.handleError mapping; the domain
+// error code travels in an ErrorInfo detail. TEAM_EXISTS is a 400 over
+// HTTP, so it is INVALID_ARGUMENT here rather than ALREADY_EXISTS.
+func (s *Server) handleError(err error) error {
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		c := codes.Internal
+
+		switch de.Code {
+		case domain.ErrorCodeTeamExists:
+			c = codes.InvalidArgument
+		case domain.ErrorCodePRExists,
+			domain.ErrorCodeUserExists:
+			c = codes.AlreadyExists
+		case domain.ErrorCodePRMerged,
+			domain.ErrorCodeNotAssigned,
+			domain.ErrorCodeNoCandidate,
+			domain.ErrorCodeAllAtCapacity,
+			domain.ErrorCodeHasOpenReviews,
+			domain.ErrorCodeUserInOtherTeam,
+			domain.ErrorCodeTeamArchived,
+			domain.ErrorCodeTeamHasHistory,
+			domain.ErrorCodeAlreadyReactivated,
+			domain.ErrorCodeTeamCycle,
+			domain.ErrorCodeTeamHasSubteams:
+			c = codes.FailedPrecondition
+		case domain.ErrorCodeNotFound:
+			c = codes.NotFound
+		case domain.ErrorCodeVersionConflict:
+			c = codes.Aborted
+		case domain.ErrorCodeBadRequest,
+			domain.ErrorCodeValidation:
+			c = codes.InvalidArgument
+		}
+
+		return s.domainStatus(c, de.Code, de.Message, de.Details...)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.domainStatus(codes.NotFound, domain.ErrorCodeNotFound, "resource not found")
+	}
+
+	s.logger.Error("unexpected error", "error", err)
+	return s.domainStatus(codes.Internal, domain.ErrorCodeInternal, "internal server error")
+}
+
+func (s *Server) requiredField(field string) error {
+	return s.domainStatus(codes.InvalidArgument, domain.ErrorCodeValidation, field+" is required", domain.FieldError{
+		Field:  field,
+		Reason: "required",
+	})
+}
@@ -0,0 +1,196 @@
+package grpc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func TestServer_handleError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantReason string
+		wantFields int
+	}{
+		{name: "TEAM_EXISTS", err: domain.NewDomainError(domain.ErrorCodeTeamExists, "team exists"), wantCode: codes.InvalidArgument, wantReason: "TEAM_EXISTS"},
+		{name: "PR_EXISTS", err: domain.NewDomainError(domain.ErrorCodePRExists, "pr exists"), wantCode: codes.AlreadyExists, wantReason: "PR_EXISTS"},
+		{name: "PR_MERGED", err: domain.NewDomainError(domain.ErrorCodePRMerged, "merged"), wantCode: codes.FailedPrecondition, wantReason: "PR_MERGED"},
+		{name: "NO_CANDIDATE", err: domain.NewDomainError(domain.ErrorCodeNoCandidate, "no candidate"), wantCode: codes.FailedPrecondition, wantReason: "NO_CANDIDATE"},
+		{name: "USER_IN_OTHER_TEAM", err: domain.NewDomainError(domain.ErrorCodeUserInOtherTeam, "u2 (backend)"), wantCode: codes.FailedPrecondition, wantReason: "USER_IN_OTHER_TEAM"},
+		{name: "TEAM_HAS_HISTORY", err: domain.NewDomainError(domain.ErrorCodeTeamHasHistory, "history"), wantCode: codes.FailedPrecondition, wantReason: "TEAM_HAS_HISTORY"},
+		{name: "TEAM_CYCLE", err: domain.NewDomainError(domain.ErrorCodeTeamCycle, "cycle"), wantCode: codes.FailedPrecondition, wantReason: "TEAM_CYCLE"},
+		{name: "HAS_OPEN_REVIEWS", err: domain.NewDomainError(domain.ErrorCodeHasOpenReviews, "u2 (1)"), wantCode: codes.FailedPrecondition, wantReason: "HAS_OPEN_REVIEWS"},
+		{name: "обёрнутый NOT_FOUND", err: errors.Join(errors.New("get team"), domain.NewDomainError(domain.ErrorCodeNotFound, "team not found")), wantCode: codes.NotFound, wantReason: "NOT_FOUND"},
+		{name: "sql.ErrNoRows", err: sql.ErrNoRows, wantCode: codes.NotFound, wantReason: "NOT_FOUND"},
+		{name: "VALIDATION_ERROR с полями", err: domain.NewValidationError("invalid", domain.FieldError{Field: "team_name", Reason: "required"}), wantCode: codes.InvalidArgument, wantReason: "VALIDATION_ERROR", wantFields: 1},
+		{name: "BAD_REQUEST", err: domain.NewDomainError(domain.ErrorCodeBadRequest, "bad body"), wantCode: codes.InvalidArgument, wantReason: "BAD_REQUEST"},
+		{name: "неизвестная ошибка", err: errors.New("boom"), wantCode: codes.Internal, wantReason: "INTERNAL"},
+	}
+
+	s := NewServer(nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, ok := status.FromError(s.handleError(tt.err))
+			if !ok {
+				t.Fatalf("expected grpc status error")
+			}
+			if st.Code() != tt.wantCode {
+				t.Errorf("expected code %s, got %s", tt.wantCode, st.Code())
+			}
+
+			reason := ""
+			fields := 0
+			for _, d := range st.Details() {
+				switch detail := d.(type) {
+				case *errdetails.ErrorInfo:
+					reason = detail.GetReason()
+				case *errdetails.BadRequest:
+					fields = len(detail.GetFieldViolations())
+				}
+			}
+			if reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, reason)
+			}
+			if fields != tt.wantFields {
+				t.Errorf("expected %d field violations, got %d", tt.wantFields, fields)
+			}
+		})
+	}
+}
+
+func newPRServer(prs *mocks.MockPRRepository) *Server {
+	app := service.NewApp(nil, nil, service.NewPRService(prs, &mocks.MockPRUserRepository{}, time.Now), nil, nil, nil)
+	return NewServer(app, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestServer_GetPullRequest(t *testing.T) {
+	prs := &mocks.MockPRRepository{
+		GetByIDResult:    &domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1", Status: domain.PRStatusOpen, Version: 3},
+		GetByIDReviewers: []string{"u2", "u3"},
+	}
+	s := newPRServer(prs)
+
+	resp, err := s.GetPullRequest(context.Background(), &reviewerv1.GetPullRequestRequest{PullRequestId: "pr-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetPr().GetPullRequestId() != "pr-1" || resp.GetPr().GetVersion() != 3 || len(resp.GetPr().GetAssignedReviewers()) != 2 {
+		t.Errorf("unexpected pr %+v", resp.GetPr())
+	}
+
+	if _, err := s.GetPullRequest(context.Background(), &reviewerv1.GetPullRequestRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected INVALID_ARGUMENT without pull_request_id, got %v", err)
+	}
+
+	prs.GetByIDErr = sql.ErrNoRows
+	if _, err := s.GetPullRequest(context.Background(), &reviewerv1.GetPullRequestRequest{PullRequestId: "pr-9"}); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND for an unknown PR, got %v", err)
+	}
+}
+
+func TestServer_ListPullRequests(t *testing.T) {
+	prs := &mocks.MockPRRepository{
+		ListResult: domain.PullRequestPage{
+			PullRequests: []domain.PullRequest{{ID: "pr-2"}, {ID: "pr-1"}},
+			Next:         &domain.PullRequestCursor{CreatedAtMicro: 1_700_000_000_000_000, ID: "pr-1"},
+		},
+	}
+	s := newPRServer(prs)
+
+	resp, err := s.ListPullRequests(context.Background(), &reviewerv1.ListPullRequestsRequest{
+		Status:      reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN,
+		AuthorId:    "u1",
+		ReviewerId:  "u2",
+		TeamName:    "backend",
+		CreatedFrom: timestamppb.New(time.Unix(1_700_000_000, 0)),
+		MergedTo:    timestamppb.New(time.Unix(1_700_600_000, 0)),
+		PageSize:    2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetPullRequests()) != 2 || resp.GetNextPageToken() == "" {
+		t.Errorf("expected 2 PRs and a next page token, got %+v", resp)
+	}
+	want := domain.PullRequestFilter{
+		Status:      domain.PRStatusOpen,
+		AuthorID:    "u1",
+		ReviewerID:  "u2",
+		TeamName:    "backend",
+		CreatedFrom: 1_700_000_000,
+		MergedTo:    1_700_600_000,
+	}
+	if prs.ListFilter != want {
+		t.Errorf("expected filter %+v, got %+v", want, prs.ListFilter)
+	}
+	if prs.ListLimit != 2 {
+		t.Errorf("expected limit 2, got %d", prs.ListLimit)
+	}
+
+	_, err = s.ListPullRequests(context.Background(), &reviewerv1.ListPullRequestsRequest{PageToken: resp.GetNextPageToken()})
+	if err != nil {
+		t.Fatalf("unexpected error on the next page: %v", err)
+	}
+	if prs.ListAfter == nil || prs.ListAfter.ID != "pr-1" {
+		t.Errorf("expected the next page to start after pr-1, got %+v", prs.ListAfter)
+	}
+
+	if _, err := s.ListPullRequests(context.Background(), &reviewerv1.ListPullRequestsRequest{PageSize: 101}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected INVALID_ARGUMENT for a page size over the limit, got %v", err)
+	}
+}
+
+func TestServer_GetSyncStatus(t *testing.T) {
+	s := newPRServer(&mocks.MockPRRepository{})
+	req := &reviewerv1.GetSyncStatusRequest{PullRequestId: "pr-1"}
+
+	if _, err := s.GetSyncStatus(context.Background(), req); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND while sync is disabled, got %v", err)
+	}
+
+	syncs := &mocks.MockReviewerSyncRepository{
+		GetSyncResult: &domain.ReviewerSync{
+			PullRequestID: "pr-1",
+			Status:        domain.ReviewerSyncFailed,
+			Attempts:      3,
+			LastError:     "status 502",
+			UpdatedAt:     1_700_000_000,
+		},
+	}
+	s.app.Sync = service.NewReviewerSyncService(&mocks.MockReviewerRequester{}, syncs, &mocks.MockUserRepository{}, nil, 0)
+
+	resp, err := s.GetSyncStatus(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.GetStatus() != reviewerv1.ReviewerSyncStatus_REVIEWER_SYNC_STATUS_FAILED || resp.GetAttempts() != 3 ||
+		resp.GetLastError() != "status 502" || resp.GetUpdatedAt().GetSeconds() != 1_700_000_000 {
+		t.Errorf("unexpected sync status %+v", resp)
+	}
+
+	syncs.GetSyncErr = sql.ErrNoRows
+	if _, err := s.GetSyncStatus(context.Background(), req); status.Code(err) != codes.NotFound {
+		t.Errorf("expected NOT_FOUND for a PR never synced, got %v", err)
+	}
+
+	if _, err := s.GetSyncStatus(context.Background(), &reviewerv1.GetSyncStatusRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected INVALID_ARGUMENT without pull_request_id, got %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"context"
+	"sort"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+)
+
+func (s *Server) GetAssignmentStats(ctx context.Context, _ *reviewerv1.GetAssignmentStatsRequest) (*reviewerv1.GetAssignmentStatsResponse, error) {
+	byUser, byPR, err := s.app.Stats.GetAssignmentStats(ctx)
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	userIDs := make([]string, 0, len(byUser))
+	for id := range byUser {
+		userIDs = append(userIDs, id)
+	}
+	sort.Strings(userIDs)
+
+	prIDs := make([]string, 0, len(byPR))
+	for id := range byPR {
+		prIDs = append(prIDs, id)
+	}
+	sort.Strings(prIDs)
+
+	resp := &reviewerv1.GetAssignmentStatsResponse{
+		ByUser:        make([]*reviewerv1.UserAssignmentStats, 0, len(userIDs)),
+		ByPullRequest: make([]*reviewerv1.PullRequestAssignmentStats, 0, len(prIDs)),
+	}
+
+	for _, id := range userIDs {
+		resp.ByUser = append(resp.ByUser, &reviewerv1.UserAssignmentStats{
+			UserId:      id,
+			Assignments: byUser[id],
+		})
+	}
+
+	for _, id := range prIDs {
+		resp.ByPullRequest = append(resp.ByPullRequest, &reviewerv1.PullRequestAssignmentStats{
+			PullRequestId: id,
+			Assignments:   byPR[id],
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,267 @@
+package grpc
+
+import (
+	"context"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) AddTeam(ctx context.Context, req *reviewerv1.AddTeamRequest) (*reviewerv1.AddTeamResponse, error) {
+	domainTeam := converter.TeamFromProto(req.GetTeam())
+
+	created, err := s.app.Team.CreateTeam(ctx, domainTeam.Name, domainTeam.ParentTeam, domainTeam.Members, req.GetMove())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(created),
+	}, nil
+}
+
+func (s *Server) GetTeam(ctx context.Context, req *reviewerv1.GetTeamRequest) (*reviewerv1.Team, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	team, err := s.app.Team.GetTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.TeamToProto(team), nil
+}
+
+func (s *Server) ListTeams(ctx context.Context, req *reviewerv1.ListTeamsRequest) (*reviewerv1.ListTeamsResponse, error) {
+	filter := domain.TeamFilter{
+		NamePrefix:       req.GetNamePrefix(),
+		MinActiveMembers: int(req.GetMinActiveMembers()),
+	}
+	if req.MaxActiveMembers != nil {
+		maxActive := int(req.GetMaxActiveMembers())
+		filter.MaxActiveMembers = &maxActive
+	}
+
+	teams, next, err := s.app.Team.ListTeams(ctx, filter, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.ListTeamsResponse{
+		Teams:         make([]*reviewerv1.TeamSummary, 0, len(teams)),
+		NextPageToken: next,
+	}
+	for i := range teams {
+		resp.Teams = append(resp.Teams, converter.TeamSummaryToProto(&teams[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) ListTeamSubtreeMembers(
+	ctx context.Context,
+	req *reviewerv1.ListTeamSubtreeMembersRequest,
+) (*reviewerv1.ListTeamSubtreeMembersResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	members, err := s.app.Team.ListSubtreeMembers(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.ListTeamSubtreeMembersResponse{
+		TeamName: req.GetTeamName(),
+		Members:  make([]*reviewerv1.User, 0, len(members)),
+	}
+	for i := range members {
+		resp.Members = append(resp.Members, converter.UserToProto(&members[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) SetTeamParent(ctx context.Context, req *reviewerv1.SetTeamParentRequest) (*reviewerv1.AddTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	team, err := s.app.Team.SetParentTeam(ctx, req.GetTeamName(), req.GetParentTeam())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(team),
+	}, nil
+}
+
+func (s *Server) DeactivateTeam(ctx context.Context, req *reviewerv1.DeactivateTeamRequest) (*reviewerv1.DeactivateTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	res, err := s.app.PR.DeactivateTeamAndReassignOpenPRs(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.DeactivateTeamResponse{
+		TeamName:            res.TeamName,
+		DeactivatedUsers:    int32(res.DeactivatedUsers),
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+		DeactivationId:      res.DeactivationID,
+	}, nil
+}
+
+func (s *Server) ReactivateTeam(ctx context.Context, req *reviewerv1.ReactivateTeamRequest) (*reviewerv1.ReactivateTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	res, err := s.app.Team.ReactivateTeam(ctx, req.GetTeamName(), req.GetDeactivationId(), req.GetRefillReviewers())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.ReactivateTeamResponse{
+		TeamName:            res.TeamName,
+		DeactivationId:      res.DeactivationID,
+		ReactivatedUsers:    res.ReactivatedUsers,
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+	}, nil
+}
+
+func (s *Server) AddTeamMembers(ctx context.Context, req *reviewerv1.AddTeamMembersRequest) (*reviewerv1.AddTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	domainTeam := converter.TeamFromProto(&reviewerv1.Team{TeamName: req.GetTeamName(), Members: req.GetMembers()})
+
+	team, err := s.app.Team.AddMembers(ctx, domainTeam.Name, domainTeam.Members, req.GetMove())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(team),
+	}, nil
+}
+
+func (s *Server) AddTeamMemberships(ctx context.Context, req *reviewerv1.AddTeamMembershipsRequest) (*reviewerv1.AddTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+	if len(req.GetUserIds()) == 0 {
+		return nil, s.requiredField("user_ids")
+	}
+
+	team, err := s.app.Team.AddMemberships(ctx, req.GetTeamName(), req.GetUserIds(),
+		converter.TeamRoleFromProto(req.GetRole()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(team),
+	}, nil
+}
+
+func (s *Server) SetTeamMemberRole(ctx context.Context, req *reviewerv1.SetTeamMemberRoleRequest) (*reviewerv1.AddTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+	role := converter.TeamRoleFromProto(req.GetRole())
+	if role == "" {
+		return nil, s.requiredField("role")
+	}
+
+	team, err := s.app.Team.SetMemberRole(ctx, req.GetTeamName(), req.GetUserId(), role)
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(team),
+	}, nil
+}
+
+func (s *Server) SetTeamMaxOpenReviews(ctx context.Context, req *reviewerv1.SetTeamMaxOpenReviewsRequest) (*reviewerv1.AddTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	team, err := s.app.Team.SetMaxOpenReviews(ctx, req.GetTeamName(), int(req.GetMaxOpenReviews()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.AddTeamResponse{
+		Team: converter.TeamToProto(team),
+	}, nil
+}
+
+func (s *Server) RemoveTeamMembers(ctx context.Context, req *reviewerv1.RemoveTeamMembersRequest) (*reviewerv1.TeamMembershipResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+	if len(req.GetUserIds()) == 0 {
+		return nil, s.requiredField("user_ids")
+	}
+
+	res, err := s.app.Team.RemoveMembers(ctx, req.GetTeamName(), req.GetUserIds(), req.GetReassignReviews())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.TeamMembershipToProto(res), nil
+}
+
+func (s *Server) UpdateTeam(ctx context.Context, req *reviewerv1.UpdateTeamRequest) (*reviewerv1.TeamMembershipResponse, error) {
+	if req.GetTeam().GetTeamName() == "" {
+		return nil, s.requiredField("team.team_name")
+	}
+
+	domainTeam := converter.TeamFromProto(req.GetTeam())
+
+	res, err := s.app.Team.UpdateTeam(ctx, domainTeam.Name, domainTeam.Members, req.GetReassignReviews(), req.GetMove())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.TeamMembershipToProto(res), nil
+}
+
+func (s *Server) ArchiveTeam(ctx context.Context, req *reviewerv1.ArchiveTeamRequest) (*reviewerv1.ArchiveTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	res, err := s.app.Team.ArchiveTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.TeamArchiveToProto(res), nil
+}
+
+func (s *Server) DeleteTeam(ctx context.Context, req *reviewerv1.DeleteTeamRequest) (*reviewerv1.DeleteTeamResponse, error) {
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	res, err := s.app.Team.DeleteTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.DeleteTeamResponse{
+		TeamName:     res.TeamName,
+		DeletedUsers: int32(res.DeletedUsers),
+	}, nil
+}
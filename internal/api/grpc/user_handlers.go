@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) ListUsers(ctx context.Context, req *reviewerv1.ListUsersRequest) (*reviewerv1.ListUsersResponse, error) {
+	filter := domain.UserFilter{
+		TeamName:         req.GetTeamName(),
+		IsActive:         req.IsActive,
+		UsernameContains: req.GetUsername(),
+	}
+
+	users, next, err := s.app.User.ListUsers(ctx, filter, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.ListUsersResponse{
+		Users:         make([]*reviewerv1.User, 0, len(users)),
+		NextPageToken: next,
+	}
+	for i := range users {
+		resp.Users = append(resp.Users, converter.UserToProto(&users[i]))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetUser(ctx context.Context, req *reviewerv1.GetUserRequest) (*reviewerv1.GetUserResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+
+	details, err := s.app.User.GetUser(ctx, req.GetUserId())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return converter.UserDetailsToProto(details), nil
+}
+
+func (s *Server) SetUserIsActive(ctx context.Context, req *reviewerv1.SetUserIsActiveRequest) (*reviewerv1.UserResponse, error) {
+	user, err := s.app.User.SetActive(ctx, req.GetUserId(), req.GetIsActive())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.UserResponse{
+		User: converter.UserToProto(user),
+	}, nil
+}
+
+func (s *Server) SetWorkingHours(ctx context.Context, req *reviewerv1.SetWorkingHoursRequest) (*reviewerv1.WorkingHoursResponse, error) {
+	hours, err := converter.WorkingHoursFromProto(req.GetWorkingHours())
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	user, err := s.app.User.SetWorkingHours(ctx, req.GetUserId(), hours)
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.WorkingHoursResponse{
+		UserId:       user.ID,
+		WorkingHours: converter.WorkingHoursToProto(user.WorkingHours),
+	}, nil
+}
+
+func (s *Server) SetUserMaxOpenReviews(ctx context.Context, req *reviewerv1.SetUserMaxOpenReviewsRequest) (*reviewerv1.MaxOpenReviewsResponse, error) {
+	user, err := s.app.User.SetMaxOpenReviews(ctx, req.GetUserId(), int(req.GetMaxOpenReviews()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.MaxOpenReviewsResponse{
+		UserId:         user.ID,
+		MaxOpenReviews: int32(user.MaxOpenReviews),
+		OpenReviews:    int32(user.OpenReviews),
+	}, nil
+}
+
+func (s *Server) SetUserWeeklyReviewQuota(ctx context.Context, req *reviewerv1.SetUserWeeklyReviewQuotaRequest) (*reviewerv1.WeeklyReviewQuotaResponse, error) {
+	user, err := s.app.User.SetWeeklyReviewQuota(ctx, req.GetUserId(), int(req.GetWeeklyReviewQuota()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.WeeklyReviewQuotaResponse{
+		UserId:            user.ID,
+		WeeklyReviewQuota: int32(user.WeeklyReviewQuota),
+		WeeklyReviews:     int32(user.WeeklyReviews),
+	}, nil
+}
+
+func (s *Server) MoveUserTeam(ctx context.Context, req *reviewerv1.MoveUserTeamRequest) (*reviewerv1.MoveUserTeamResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+	if req.GetTeamName() == "" {
+		return nil, s.requiredField("team_name")
+	}
+
+	res, err := s.app.Team.MoveUser(ctx, req.GetUserId(), req.GetTeamName(),
+		converter.OpenReviewsPolicyFromProto(req.GetOpenReviews()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	return &reviewerv1.MoveUserTeamResponse{
+		User:                converter.UserToProto(&res.User),
+		FromTeam:            res.FromTeam,
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+	}, nil
+}
+
+func (s *Server) GetUserReviews(ctx context.Context, req *reviewerv1.GetUserReviewsRequest) (*reviewerv1.GetUserReviewsResponse, error) {
+	if req.GetUserId() == "" {
+		return nil, s.requiredField("user_id")
+	}
+
+	filter := domain.ReviewFilter{
+		Status:           converter.PRStatusFromProto(req.GetStatus()),
+		OldestFirst:      req.GetOldestFirst(),
+		IncludeReviewers: req.GetIncludeReviewers(),
+	}
+	if req.GetSince() != nil {
+		filter.Since = req.GetSince().GetSeconds()
+	}
+
+	prs, next, err := s.app.User.ListAssignedPullRequests(ctx, req.GetUserId(), filter, req.GetPageToken(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, s.handleError(err)
+	}
+
+	resp := &reviewerv1.GetUserReviewsResponse{
+		UserId:        req.GetUserId(),
+		PullRequests:  make([]*reviewerv1.PullRequestShort, 0, len(prs)),
+		NextPageToken: next,
+	}
+	for i := range prs {
+		resp.PullRequests = append(resp.PullRequests, converter.PullRequestShortToProto(&prs[i]))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+// rosterContentTypes maps the media type of a roster to its format, both
+// for imported bodies and exports.
+var rosterContentTypes = map[string]domain.RosterFormat{
+	"application/yaml": domain.RosterFormatYAML,
+	"text/csv":         domain.RosterFormatCSV,
+}
+
+func (s *Server) HandleAdminImport(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleAdminImport", "error", err)
+		}
+	}()
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	format, ok := rosterContentTypes[mediaType]
+	if err != nil || !ok {
+		s.writeBadRequest(w, "roster must be sent as application/yaml or text/csv")
+		return
+	}
+
+	q := newQueryParams(r.URL.Query())
+	dryRun := q.bool("dry_run")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
+		return
+	}
+
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeBadRequest(w, "failed to read body")
+		return
+	}
+
+	result, err := s.app.Roster.Import(r.Context(), format, data, dryRun)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, converter.RosterImportResultToOpenAPI(result))
+}
+
+func (s *Server) HandleAdminExport(w http.ResponseWriter, r *http.Request) {
+	format := domain.RosterFormatYAML
+	if v := r.URL.Query().Get("format"); v != "" {
+		format = domain.RosterFormat(v)
+	}
+
+	data, err := s.app.Roster.Export(r.Context(), format)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	for contentType, f := range rosterContentTypes {
+		if f == format {
+			w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+		}
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="roster.`+string(format)+`"`)
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(data); err != nil {
+		s.logger.Error("failed to write roster export", "error", err)
+	}
+}
@@ -0,0 +1,39 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	etagHeader    = "ETag"
+	ifMatchHeader = "If-Match"
+)
+
+var errInvalidIfMatch = errors.New(`must be a single quoted version, e.g. "3"`)
+
+func formatETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch returns the version the client expects, or 0 when the header
+// is absent or "*" and any version is acceptable.
+func parseIfMatch(r *http.Request) (int64, error) {
+	raw := strings.TrimSpace(r.Header.Get(ifMatchHeader))
+	if raw == "" || raw == "*" {
+		return 0, nil
+	}
+
+	raw = strings.TrimPrefix(raw, "W/")
+	if len(raw) < 2 || raw[0] != '"' || raw[len(raw)-1] != '"' {
+		return 0, errInvalidIfMatch
+	}
+
+	version, err := strconv.ParseInt(raw[1:len(raw)-1], 10, 64)
+	if err != nil || version <= 0 {
+		return 0, errInvalidIfMatch
+	}
+	return version, nil
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+// replayedHeaders are the response headers stored with an idempotent
+// response and sent again on replay.
+var replayedHeaders = []string{"Content-Type", etagHeader, "Location"}
+
+// Idempotency replays the stored response for a POST that repeats an
+// Idempotency-Key. Requests without the header, and all requests when the
+// feature is disabled, go straight to the handler.
+func (s *Server) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if s.app.Idempotency == nil || r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			s.writeValidationError(w, "idempotency key is too long", domain.FieldError{
+				Field:  idempotencyKeyHeader,
+				Reason: "must be at most 255 characters",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeBadRequest(w, "failed to read request body")
+			return
+		}
+		defer func() {
+			if err := r.Body.Close(); err != nil {
+				slog.Debug("failed to close request body", "error", err)
+			}
+		}()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		endpoint := r.Method + " " + r.URL.Path
+		sum := sha256.Sum256(body)
+
+		stored, err := s.app.Idempotency.Begin(r.Context(), key, endpoint, hex.EncodeToString(sum[:]))
+		if err != nil {
+			s.handleError(w, err)
+			return
+		}
+		if stored != nil {
+			// Records stored before headers were kept carry none.
+			w.Header().Set("Content-Type", "application/json")
+			for name, value := range stored.ResponseHeaders {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(stored.StatusCode)
+			// #nosec G104 -- the client may have gone away, nothing to do about it
+			_, _ = w.Write(stored.ResponseBody)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// The outcome is stored even if the client has already hung up:
+		// that is exactly the client that is going to retry. Server errors
+		// and auth failures are not final, so the key is released instead.
+		ctx := context.WithoutCancel(r.Context())
+		if !storable(rec.status) {
+			if err := s.app.Idempotency.Release(ctx, key, endpoint); err != nil {
+				s.logger.Error("failed to release idempotency key", "key", key, "endpoint", endpoint, "error", err)
+			}
+		} else if err := s.app.Idempotency.Complete(ctx, key, endpoint, rec.status, storedHeaders(rec.header), rec.body.Bytes()); err != nil {
+			s.logger.Error("failed to store idempotent response", "key", key, "endpoint", endpoint, "error", err)
+		}
+
+		rec.flush(w)
+	})
+}
+
+// storable reports whether a response with the status is kept for replay.
+func storable(status int) bool {
+	return status < http.StatusInternalServerError &&
+		status != http.StatusUnauthorized && status != http.StatusForbidden
+}
+
+// storedHeaders picks the replayedHeaders that the handler set.
+func storedHeaders(h http.Header) map[string]string {
+	stored := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if value := h.Get(name); value != "" {
+			stored[name] = value
+		}
+	}
+	return stored
+}
@@ -0,0 +1,125 @@
+package http
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// memoryIdempotencyRepo keeps records in a map so that a replay can be
+// exercised end to end through the router.
+type memoryIdempotencyRepo struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{records: make(map[string]domain.IdempotencyRecord)}
+}
+
+func (m *memoryIdempotencyRepo) Claim(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore int64) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.records[rec.Key+rec.Endpoint]
+	if ok && existing.ExpiresAt > rec.CreatedAt && (existing.Completed || existing.CreatedAt > staleBefore) {
+		return false, nil
+	}
+	m.records[rec.Key+rec.Endpoint] = *rec
+	return true, nil
+}
+
+func (m *memoryIdempotencyRepo) Get(ctx context.Context, key, endpoint string) (*domain.IdempotencyRecord, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec, ok := m.records[key+endpoint]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &rec, nil
+}
+
+func (m *memoryIdempotencyRepo) Complete(ctx context.Context, key, endpoint string, statusCode int, headers map[string]string, body []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rec := m.records[key+endpoint]
+	rec.Completed = true
+	rec.StatusCode = statusCode
+	rec.ResponseHeaders = headers
+	rec.ResponseBody = append([]byte(nil), body...)
+	m.records[key+endpoint] = rec
+	return nil
+}
+
+func (m *memoryIdempotencyRepo) Release(ctx context.Context, key, endpoint string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, key+endpoint)
+	return nil
+}
+
+func (m *memoryIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	router := newTestRouter(t)
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/pullRequest/reassign", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	body := `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`
+
+	first := send("retry-1", body)
+	if first.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", first.Code, first.Body.String())
+	}
+	if first.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("expected first response not to be marked as replayed")
+	}
+
+	second := send("retry-1", body)
+	if second.Code != http.StatusOK {
+		t.Fatalf("expected replayed status 200, got %d", second.Code)
+	}
+	if second.Header().Get(idempotentReplayedHeader) != "true" {
+		t.Errorf("expected %s header on replay", idempotentReplayedHeader)
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %s, got %s", first.Body.String(), second.Body.String())
+	}
+	for _, h := range []string{"Content-Type", etagHeader} {
+		if got, want := second.Header().Get(h), first.Header().Get(h); want == "" || got != want {
+			t.Errorf("expected replayed %s %q, got %q", h, want, got)
+		}
+	}
+
+	mismatch := send("retry-1", `{"pull_request_id":"pr-1","old_reviewer_id":"u3"}`)
+	if mismatch.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status 422 for reused key, got %d: %s", mismatch.Code, mismatch.Body.String())
+	}
+
+	noKey := send("", body)
+	if noKey.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("expected request without key to run normally")
+	}
+
+	tooLong := send(strings.Repeat("k", maxIdempotencyKeyLength+1), body)
+	if tooLong.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for too long key, got %d", tooLong.Code)
+	}
+}
@@ -0,0 +1,99 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/openapi"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/converter"
+)
+
+func (s *Server) HandleOutOfOfficeAdd(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleOutOfOfficeAdd", "error", err)
+		}
+	}()
+
+	var req openapi.OutOfOfficeAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+
+	period := domain.OutOfOfficePeriod{
+		UserID:   req.UserId,
+		StartsAt: req.StartsAt.Unix(),
+		EndsAt:   req.EndsAt.Unix(),
+	}
+	if req.Reason != nil {
+		period.Reason = *req.Reason
+	}
+
+	created, err := s.app.OutOfOffice.AddPeriod(r.Context(), period)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusCreated, openapi.OutOfOfficeResponse{
+		Ooo: converter.OutOfOfficePeriodToOpenAPI(created),
+	})
+}
+
+func (s *Server) HandleOutOfOfficeList(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParams(r.URL.Query())
+	userID := q.str("user_id")
+	if userID == "" {
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+	includePast := q.bool("include_past")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
+		return
+	}
+
+	periods, err := s.app.OutOfOffice.ListPeriods(r.Context(), userID, includePast)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.OutOfOfficeListResponse{
+		UserId:  userID,
+		Periods: make([]openapi.OutOfOfficePeriod, 0, len(periods)),
+	}
+	for i := range periods {
+		resp.Periods = append(resp.Periods, converter.OutOfOfficePeriodToOpenAPI(&periods[i]))
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleOutOfOfficeRemove(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleOutOfOfficeRemove", "error", err)
+		}
+	}()
+
+	var req openapi.OutOfOfficeRemoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.UserId == "" {
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+
+	removed, err := s.app.OutOfOffice.RemovePeriod(r.Context(), req.UserId, req.OooId)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, openapi.OutOfOfficeResponse{
+		Ooo: converter.OutOfOfficePeriodToOpenAPI(removed),
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/openapi"
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
@@ -11,9 +12,13 @@ import (
 )
 
 type createPRRequest struct {
-	PullRequestID   string `json:"pull_request_id"`
-	PullRequestName string `json:"pull_request_name"`
-	AuthorID        string `json:"author_id"`
+	PullRequestID   string   `json:"pull_request_id"`
+	PullRequestName string   `json:"pull_request_name"`
+	AuthorID        string   `json:"author_id"`
+	TeamName        string   `json:"team_name"`
+	Labels          []string `json:"labels"`
+	GitHubRepo      string   `json:"github_repo"`
+	GitHubNumber    int      `json:"github_number"`
 }
 
 type prResponse struct {
@@ -28,10 +33,11 @@ func (s *Server) HandlePullRequestCreate(w http.ResponseWriter, r *http.Request)
 	}()
 	var req createPRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
 		return
 	}
-	pr, err := s.app.PR.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := s.app.PR.CreatePullRequest(r.Context(), req.PullRequestID, req.PullRequestName, req.AuthorID,
+		req.TeamName, req.Labels, domain.GitHubLink{Repo: req.GitHubRepo, Number: req.GitHubNumber})
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -39,6 +45,7 @@ func (s *Server) HandlePullRequestCreate(w http.ResponseWriter, r *http.Request)
 	resp := prResponse{
 		PR: converter.PullRequestToOpenAPI(pr),
 	}
+	w.Header().Set(etagHeader, formatETag(pr.Version))
 	s.writeJSON(w, http.StatusCreated, resp)
 }
 
@@ -54,11 +61,15 @@ func (s *Server) HandlePullRequestMerge(w http.ResponseWriter, r *http.Request)
 	}()
 	var req mergePRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	expectedVersion, ok := s.expectedVersion(w, r)
+	if !ok {
 		return
 	}
 
-	pr, err := s.app.PR.MergePullRequest(r.Context(), req.PullRequestID)
+	pr, err := s.app.PR.MergePullRequest(r.Context(), req.PullRequestID, expectedVersion)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -66,6 +77,7 @@ func (s *Server) HandlePullRequestMerge(w http.ResponseWriter, r *http.Request)
 	resp := prResponse{
 		PR: converter.PullRequestToOpenAPI(pr),
 	}
+	w.Header().Set(etagHeader, formatETag(pr.Version))
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
@@ -87,24 +99,127 @@ func (s *Server) HandlePullRequestReassign(w http.ResponseWriter, r *http.Reques
 	}()
 	var req reassignPRRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	expectedVersion, ok := s.expectedVersion(w, r)
+	if !ok {
 		return
 	}
-	updated, err := s.app.PR.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID)
+	updated, replacedBy, err := s.app.PR.ReassignReviewer(r.Context(), req.PullRequestID, req.OldUserID, expectedVersion)
 	if err != nil {
 		s.handleError(w, err)
 		return
 	}
-	replacedBy := ""
-	for _, id := range updated.AssignedReviewers {
-		if id != req.OldUserID {
-			replacedBy = id
-			break
-		}
-	}
 	resp := reassignPRResponse{
 		PR:         converter.PullRequestToOpenAPI(updated),
 		ReplacedBy: replacedBy,
 	}
+	w.Header().Set(etagHeader, formatETag(updated.Version))
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) expectedVersion(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	version, err := parseIfMatch(r)
+	if err != nil {
+		s.writeValidationError(w, "invalid If-Match header", domain.FieldError{
+			Field:  ifMatchHeader,
+			Reason: err.Error(),
+		})
+		return 0, false
+	}
+	return version, true
+}
+
+func (s *Server) HandlePullRequestGet(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		s.writeRequiredError(w, "pull_request_id")
+		return
+	}
+
+	pr, err := s.app.PR.GetPullRequest(r.Context(), prID)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	resp := prResponse{
+		PR: converter.PullRequestToOpenAPI(pr),
+	}
+	w.Header().Set(etagHeader, formatETag(pr.Version))
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type prListResponse struct {
+	PullRequests []openapi.PullRequest `json:"pull_requests"`
+	NextCursor   string                `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) HandlePullRequestList(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParams(r.URL.Query())
+	filter := domain.PullRequestFilter{
+		Status:      domain.PRStatus(q.str("status")),
+		AuthorID:    q.str("author_id"),
+		ReviewerID:  q.str("reviewer_id"),
+		TeamName:    q.str("team_name"),
+		CreatedFrom: q.unix("created_from"),
+		CreatedTo:   q.unix("created_to"),
+		MergedFrom:  q.unix("merged_from"),
+		MergedTo:    q.unix("merged_to"),
+	}
+	limit := q.int("limit")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
+		return
+	}
+
+	prs, next, err := s.app.PR.ListPullRequests(r.Context(), filter, q.str("cursor"), limit)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := prListResponse{
+		PullRequests: make([]openapi.PullRequest, 0, len(prs)),
+		NextCursor:   next,
+	}
+	for i := range prs {
+		resp.PullRequests = append(resp.PullRequests, converter.PullRequestToOpenAPI(&prs[i]))
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type reviewerSyncResponse struct {
+	PullRequestID string `json:"pull_request_id"`
+	Status        string `json:"status"`
+	Attempts      int    `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	UpdatedAt     string `json:"updated_at"`
+}
+
+func (s *Server) HandlePullRequestSyncStatus(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		s.writeRequiredError(w, "pull_request_id")
+		return
+	}
+	if s.app.Sync == nil {
+		s.writeDomainError(w, http.StatusNotFound, domain.ErrorCodeNotFound, "reviewer sync is disabled")
+		return
+	}
+
+	status, err := s.app.Sync.GetSyncStatus(r.Context(), prID)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := reviewerSyncResponse{
+		PullRequestID: status.PullRequestID,
+		Status:        string(status.Status),
+		Attempts:      status.Attempts,
+		LastError:     status.LastError,
+		UpdatedAt:     time.Unix(status.UpdatedAt, 0).UTC().Format(time.RFC3339),
+	}
 	s.writeJSON(w, http.StatusOK, resp)
 }
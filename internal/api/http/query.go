@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// queryParams reads optional query parameters, collecting a field error for
+// every value that does not parse instead of stopping at the first one.
+type queryParams struct {
+	values url.Values
+	errs   []domain.FieldError
+}
+
+func newQueryParams(values url.Values) *queryParams {
+	return &queryParams{values: values}
+}
+
+func (p *queryParams) str(name string) string {
+	return p.values.Get(name)
+}
+
+// unix parses an RFC 3339 date-time into unix seconds; absent means 0.
+func (p *queryParams) unix(name string) int64 {
+	v := p.values.Get(name)
+	if v == "" {
+		return 0
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		p.errs = append(p.errs, domain.FieldError{Field: name, Reason: "must be an RFC 3339 date-time"})
+		return 0
+	}
+	return t.Unix()
+}
+
+func (p *queryParams) int(name string) int {
+	v := p.values.Get(name)
+	if v == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		p.errs = append(p.errs, domain.FieldError{Field: name, Reason: "must be an integer"})
+		return 0
+	}
+	return n
+}
+
+func (p *queryParams) bool(name string) bool {
+	v := p.values.Get(name)
+	if v == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		p.errs = append(p.errs, domain.FieldError{Field: name, Reason: "must be a boolean"})
+		return false
+	}
+	return b
+}
+
+// optInt is int for parameters where absent differs from 0; absent means nil.
+func (p *queryParams) optInt(name string) *int {
+	if p.values.Get(name) == "" {
+		return nil
+	}
+	n := p.int(name)
+	return &n
+}
+
+// optBool is bool for parameters where absent differs from false; absent
+// means nil.
+func (p *queryParams) optBool(name string) *bool {
+	if p.values.Get(name) == "" {
+		return nil
+	}
+	b := p.bool(name)
+	return &b
+}
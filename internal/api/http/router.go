@@ -7,30 +7,83 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func NewRouter(server *Server, logger *slog.Logger) http.Handler {
+func NewRouter(server *Server, logger *slog.Logger, middlewares ...func(http.Handler) http.Handler) http.Handler {
 	r := chi.NewRouter()
+	r.Use(middlewares...)
 
-	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		http.Redirect(w, r, "/swagger", http.StatusTemporaryRedirect)
-	})
+	// Idempotency runs inside each group so that SCIM requests are
+	// authenticated before a stored response can be replayed.
+	r.Group(func(r chi.Router) {
+		r.Use(server.Idempotency)
+
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "/swagger", http.StatusTemporaryRedirect)
+		})
+
+		r.Get("/healthz", server.HealthCheck)
+
+		r.Post("/team/add", server.HandleTeamAdd)
+		r.Get("/team/get", server.HandleTeamGet)
+		r.Get("/teams", server.HandleTeamList)
+		r.Get("/team/members", server.HandleTeamMembers)
+		r.Post("/team/setParent", server.HandleTeamSetParent)
+		r.Post("/team/deactivate", server.HandleTeamDeactivate)
+		r.Post("/team/reactivate", server.HandleTeamReactivate)
+		r.Post("/team/members/add", server.HandleTeamMembersAdd)
+		r.Post("/team/members/remove", server.HandleTeamMembersRemove)
+		r.Post("/team/memberships/add", server.HandleTeamMembershipsAdd)
+		r.Post("/team/members/setRole", server.HandleTeamMemberSetRole)
+		r.Post("/team/setMaxOpenReviews", server.HandleTeamSetMaxOpenReviews)
+		r.Post("/team/update", server.HandleTeamUpdate)
+		r.Post("/team/archive", server.HandleTeamArchive)
+		r.Post("/team/delete", server.HandleTeamDelete)
 
-	r.Get("/healthz", server.HealthCheck)
+		r.Post("/users/setIsActive", server.HandleUserSetIsActive)
+		r.Get("/users", server.HandleUserList)
+		r.Get("/users/get", server.HandleUserGet)
+		r.Get("/users/getReview", server.HandleUserGetReview)
+		r.Post("/users/moveTeam", server.HandleUserMoveTeam)
+		r.Get("/users/ooo", server.HandleOutOfOfficeList)
+		r.Post("/users/ooo/add", server.HandleOutOfOfficeAdd)
+		r.Post("/users/ooo/remove", server.HandleOutOfOfficeRemove)
+		r.Post("/users/setWorkingHours", server.HandleUserSetWorkingHours)
+		r.Post("/users/setMaxOpenReviews", server.HandleUserSetMaxOpenReviews)
+		r.Post("/users/setWeeklyReviewQuota", server.HandleUserSetWeeklyReviewQuota)
 
-	r.Post("/team/add", server.HandleTeamAdd)
-	r.Get("/team/get", server.HandleTeamGet)
-	r.Post("/team/deactivate", server.HandleTeamDeactivate)
+		r.Post("/pullRequest/create", server.HandlePullRequestCreate)
+		r.Post("/pullRequest/merge", server.HandlePullRequestMerge)
+		r.Post("/pullRequest/reassign", server.HandlePullRequestReassign)
+		r.Get("/pullRequest/get", server.HandlePullRequestGet)
+		r.Get("/pullRequest/syncStatus", server.HandlePullRequestSyncStatus)
+		r.Get("/pullRequests", server.HandlePullRequestList)
 
-	r.Post("/users/setIsActive", server.HandleUserSetIsActive)
-	r.Get("/users/getReview", server.HandleUserGetReview)
+		r.Get("/openapi.yaml", server.ServeOpenAPISpec)
+		r.Get("/swagger", server.SwaggerUI)
 
-	r.Post("/pullRequest/create", server.HandlePullRequestCreate)
-	r.Post("/pullRequest/merge", server.HandlePullRequestMerge)
-	r.Post("/pullRequest/reassign", server.HandlePullRequestReassign)
+		r.Get("/stats/assignments", server.HandleStatsAssignments)
 
-	r.Get("/openapi.yaml", server.ServeOpenAPISpec)
-	r.Get("/swagger", server.SwaggerUI)
+		r.Post("/admin/import", server.HandleAdminImport)
+		r.Get("/admin/export", server.HandleAdminExport)
+	})
+
+	r.Route("/scim/v2", func(r chi.Router) {
+		r.Use(server.SCIMAuth)
+		r.Use(server.Idempotency)
 
-	r.Get("/stats/assignments", server.HandleStatsAssignments)
+		r.Get("/Users", server.HandleSCIMUserList)
+		r.Post("/Users", server.HandleSCIMUserCreate)
+		r.Get("/Users/{id}", server.HandleSCIMUserGet)
+		r.Put("/Users/{id}", server.HandleSCIMUserReplace)
+		r.Patch("/Users/{id}", server.HandleSCIMUserPatch)
+		r.Delete("/Users/{id}", server.HandleSCIMUserDelete)
+
+		r.Get("/Groups", server.HandleSCIMGroupList)
+		r.Post("/Groups", server.HandleSCIMGroupCreate)
+		r.Get("/Groups/{id}", server.HandleSCIMGroupGet)
+		r.Put("/Groups/{id}", server.HandleSCIMGroupReplace)
+		r.Patch("/Groups/{id}", server.HandleSCIMGroupPatch)
+		r.Delete("/Groups/{id}", server.HandleSCIMGroupDelete)
+	})
 
 	return r
 }
@@ -0,0 +1,401 @@
+package http
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// SCIM 2.0 (RFC 7643, RFC 7644) lets an identity provider provision users
+// and groups. A SCIM user is a domain.User whose id is the user ID and whose
+// userName is the username; a SCIM group is a team whose id and displayName
+// are the team name.
+const (
+	scimContentType = "application/scim+json"
+
+	scimUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimGroupSchema = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimListSchema  = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimErrorSchema = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	scimBasePath = "/scim/v2"
+)
+
+type scimMeta struct {
+	ResourceType string `json:"resourceType"`
+	Location     string `json:"location"`
+}
+
+type scimUser struct {
+	Schemas  []string  `json:"schemas"`
+	ID       string    `json:"id,omitempty"`
+	UserName string    `json:"userName"`
+	Active   *bool     `json:"active,omitempty"`
+	Meta     *scimMeta `json:"meta,omitempty"`
+}
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members,omitempty"`
+	Meta        *scimMeta    `json:"meta,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+type scimError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+type scimPatchRequest struct {
+	Schemas    []string      `json:"schemas"`
+	Operations []scimPatchOp `json:"Operations"`
+}
+
+// scimPatchOp is one PATCH operation. Identity providers differ in case
+// ("Replace" vs "replace") and in whether they send a path or an object of
+// attributes as the value, so both forms are accepted.
+type scimPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+func scimUserResource(u *domain.User) scimUser {
+	active := u.IsActive
+	return scimUser{
+		Schemas:  []string{scimUserSchema},
+		ID:       u.ID,
+		UserName: u.Username,
+		Active:   &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Location:     scimBasePath + "/Users/" + u.ID,
+		},
+	}
+}
+
+func scimGroupResource(name string, members []domain.User) scimGroup {
+	g := scimGroup{
+		Schemas:     []string{scimGroupSchema},
+		ID:          name,
+		DisplayName: name,
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     scimBasePath + "/Groups/" + name,
+		},
+	}
+	for _, m := range members {
+		g.Members = append(g.Members, scimMember{Value: m.ID, Display: m.Username})
+	}
+	return g
+}
+
+// SCIMAuth guards the SCIM endpoints with the configured bearer token. With
+// SCIM disabled they do not exist.
+func (s *Server) SCIMAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.scimToken == "" {
+			s.writeSCIMError(w, http.StatusNotFound, "", "SCIM provisioning is disabled")
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.scimToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="scim"`)
+			s.writeSCIMError(w, http.StatusUnauthorized, "", "invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) writeSCIM(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", scimContentType)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Error("failed to encode SCIM response", "error", err)
+	}
+}
+
+func (s *Server) writeSCIMError(w http.ResponseWriter, status int, scimType, detail string) {
+	s.writeSCIM(w, status, scimError{
+		Schemas:  []string{scimErrorSchema},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+// handleSCIMError is handleError in the SCIM error format.
+func (s *Server) handleSCIMError(w http.ResponseWriter, err error) {
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		detail := de.Message
+		for _, d := range de.Details {
+			detail += "; " + d.Field + ": " + d.Reason
+		}
+
+		switch de.Code {
+		case domain.ErrorCodeUserExists, domain.ErrorCodeTeamExists:
+			s.writeSCIMError(w, http.StatusConflict, "uniqueness", detail)
+		case domain.ErrorCodeNotFound:
+			s.writeSCIMError(w, http.StatusNotFound, "", detail)
+		case domain.ErrorCodeBadRequest, domain.ErrorCodeValidation:
+			s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", detail)
+		case domain.ErrorCodeInternal:
+			s.writeSCIMError(w, http.StatusInternalServerError, "", detail)
+		default:
+			s.writeSCIMError(w, http.StatusConflict, "", detail)
+		}
+		return
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		s.writeSCIMError(w, http.StatusNotFound, "", "resource not found")
+		return
+	}
+
+	s.logger.Error("unexpected error", "error", err)
+	s.writeSCIMError(w, http.StatusInternalServerError, "", "internal server error")
+}
+
+var scimFilterPattern = regexp.MustCompile(`^\s*(\w+)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseSCIMFilter supports the only filter identity providers rely on,
+// `attr eq "value"`, for the given attributes. An empty filter returns an
+// empty attribute.
+func parseSCIMFilter(filter string, attrs ...string) (attr, value string, err error) {
+	if filter == "" {
+		return "", "", nil
+	}
+	m := scimFilterPattern.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", errors.New(`only filters of the form attr eq "value" are supported`)
+	}
+	for _, a := range attrs {
+		if strings.EqualFold(m[1], a) {
+			value, err := strconv.Unquote(`"` + m[2] + `"`)
+			if err != nil {
+				return "", "", errors.New("invalid filter value")
+			}
+			return a, value, nil
+		}
+	}
+	return "", "", errors.New("filtering by " + m[1] + " is not supported")
+}
+
+// scimPage applies SCIM paging (1-based startIndex, count) to a full list.
+func scimPage[T any](r *http.Request, items []T) (scimListResponse, error) {
+	startIndex, count := 1, len(items)
+	if v := r.URL.Query().Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return scimListResponse{}, errors.New("startIndex must be an integer")
+		}
+		startIndex = max(n, 1)
+	}
+	if v := r.URL.Query().Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return scimListResponse{}, errors.New("count must be an integer")
+		}
+		count = max(n, 0)
+	}
+
+	from := min(startIndex-1, len(items))
+	to := min(from+count, len(items))
+	resp := scimListResponse{
+		Schemas:      []string{scimListSchema},
+		TotalResults: len(items),
+		StartIndex:   startIndex,
+		ItemsPerPage: to - from,
+		Resources:    make([]any, 0, to-from),
+	}
+	for _, it := range items[from:to] {
+		resp.Resources = append(resp.Resources, it)
+	}
+	return resp, nil
+}
+
+// scimBool reads a boolean that some identity providers send as a string,
+// e.g. "False".
+func scimBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return false, errors.New("active must be a boolean")
+	}
+	b, err := strconv.ParseBool(strings.ToLower(s))
+	if err != nil {
+		return false, errors.New("active must be a boolean")
+	}
+	return b, nil
+}
+
+// scimUserChange is what a PUT or PATCH asks to change; nil fields stay.
+type scimUserChange struct {
+	UserName *string
+	Active   *bool
+}
+
+// apply sets the attribute named attr (case-insensitively) from raw.
+// Attributes the service does not keep, such as name or emails, are ignored.
+func (c *scimUserChange) apply(attr string, raw json.RawMessage) error {
+	switch strings.ToLower(attr) {
+	case "username":
+		var name string
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return errors.New("userName must be a string")
+		}
+		c.UserName = &name
+	case "active":
+		active, err := scimBool(raw)
+		if err != nil {
+			return err
+		}
+		c.Active = &active
+	}
+	return nil
+}
+
+func (c *scimUserChange) applyPatch(ops []scimPatchOp) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			// userName and active cannot be removed, and other
+			// attributes are not kept.
+			continue
+		default:
+			return errors.New("unsupported patch operation " + op.Op)
+		}
+
+		if op.Path != "" {
+			if err := c.apply(op.Path, op.Value); err != nil {
+				return err
+			}
+			continue
+		}
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attrs); err != nil {
+			return errors.New("patch value without a path must be an object")
+		}
+		for attr, raw := range attrs {
+			if err := c.apply(attr, raw); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var scimMemberPathPattern = regexp.MustCompile(`^(?i:members)\[\s*(?i:value)\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+
+// applyGroupPatch applies PATCH operations to the member set of a group.
+// Renaming the group is refused: the team name is its id.
+func applyGroupPatch(name string, members map[string]bool, ops []scimPatchOp) error {
+	for _, op := range ops {
+		kind := strings.ToLower(op.Op)
+		if kind != "add" && kind != "remove" && kind != "replace" {
+			return errors.New("unsupported patch operation " + op.Op)
+		}
+
+		if m := scimMemberPathPattern.FindStringSubmatch(op.Path); m != nil {
+			if kind != "remove" {
+				return errors.New("only remove is supported for a filtered members path")
+			}
+			delete(members, m[1])
+			continue
+		}
+
+		var value json.RawMessage
+		switch strings.ToLower(op.Path) {
+		case "members":
+			value = op.Value
+		case "displayname":
+			if err := checkGroupName(name, op.Value); err != nil {
+				return err
+			}
+			continue
+		case "":
+			var attrs map[string]json.RawMessage
+			if err := json.Unmarshal(op.Value, &attrs); err != nil {
+				return errors.New("patch value without a path must be an object")
+			}
+			for attr, raw := range attrs {
+				switch strings.ToLower(attr) {
+				case "displayname":
+					if err := checkGroupName(name, raw); err != nil {
+						return err
+					}
+				case "members":
+					value = raw
+				}
+			}
+			if value == nil {
+				continue
+			}
+		default:
+			return errors.New("unsupported patch path " + op.Path)
+		}
+
+		var list []scimMember
+		if len(value) > 0 {
+			if err := json.Unmarshal(value, &list); err != nil {
+				return errors.New("members must be a list of {value}")
+			}
+		}
+		switch kind {
+		case "replace":
+			clear(members)
+			fallthrough
+		case "add":
+			for _, m := range list {
+				members[m.Value] = true
+			}
+		case "remove":
+			if len(value) == 0 {
+				clear(members)
+			}
+			for _, m := range list {
+				delete(members, m.Value)
+			}
+		}
+	}
+	return nil
+}
+
+func checkGroupName(name string, raw json.RawMessage) error {
+	var displayName string
+	if err := json.Unmarshal(raw, &displayName); err != nil || displayName != name {
+		return errSCIMGroupRename
+	}
+	return nil
+}
+
+var errSCIMGroupRename = errors.New("displayName is the group id and cannot be changed")
@@ -0,0 +1,370 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+type scimUserRequest struct {
+	UserName string          `json:"userName"`
+	Active   json.RawMessage `json:"active"`
+}
+
+type scimGroupRequest struct {
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members"`
+}
+
+// decodeSCIM reads the request body into v and reports a malformed body to
+// the client.
+func (s *Server) decodeSCIM(w http.ResponseWriter, r *http.Request, v any) bool {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing SCIM request body", "error", err)
+		}
+	}()
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidSyntax", "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func (s *Server) HandleSCIMUserList(w http.ResponseWriter, r *http.Request) {
+	_, userName, err := parseSCIMFilter(r.URL.Query().Get("filter"), "userName")
+	if err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	var users []scimUser
+	filter := domain.UserFilter{Username: userName}
+	cursor := ""
+	for {
+		page, next, err := s.app.User.ListUsers(r.Context(), filter, cursor, service.MaxPageLimit)
+		if err != nil {
+			s.handleSCIMError(w, err)
+			return
+		}
+		for i := range page {
+			users = append(users, scimUserResource(&page[i]))
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	resp, err := scimPage(r, users)
+	if err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	s.writeSCIM(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleSCIMUserGet(w http.ResponseWriter, r *http.Request) {
+	details, err := s.app.User.GetUser(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	s.writeSCIM(w, http.StatusOK, scimUserResource(&details.User))
+}
+
+// HandleSCIMUserCreate creates a user without a team; the user ID is the
+// SCIM userName. Teams are joined through groups.
+func (s *Server) HandleSCIMUserCreate(w http.ResponseWriter, r *http.Request) {
+	var req scimUserRequest
+	if !s.decodeSCIM(w, r, &req) {
+		return
+	}
+	active := true
+	if req.Active != nil {
+		var err error
+		if active, err = scimBool(req.Active); err != nil {
+			s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+
+	user, err := s.app.User.CreateUser(r.Context(), req.UserName, req.UserName, active)
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	resource := scimUserResource(user)
+	w.Header().Set("Location", resource.Meta.Location)
+	s.writeSCIM(w, http.StatusCreated, resource)
+}
+
+func (s *Server) HandleSCIMUserReplace(w http.ResponseWriter, r *http.Request) {
+	var attrs map[string]json.RawMessage
+	if !s.decodeSCIM(w, r, &attrs) {
+		return
+	}
+	var change scimUserChange
+	for attr, raw := range attrs {
+		if err := change.apply(attr, raw); err != nil {
+			s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+			return
+		}
+	}
+	s.changeSCIMUser(w, r, change)
+}
+
+func (s *Server) HandleSCIMUserPatch(w http.ResponseWriter, r *http.Request) {
+	var req scimPatchRequest
+	if !s.decodeSCIM(w, r, &req) {
+		return
+	}
+	var change scimUserChange
+	if err := change.applyPatch(req.Operations); err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	s.changeSCIMUser(w, r, change)
+}
+
+// HandleSCIMUserDelete deprovisions the user. Users are never deleted, since
+// PRs keep referring to them: the user is deactivated as by
+// /users/setIsActive.
+func (s *Server) HandleSCIMUserDelete(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.app.User.SetActive(r.Context(), chi.URLParam(r, "id"), false); err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// changeSCIMUser renames the user and sets its active flag as /users/setIsActive
+// does, touching only what differs.
+func (s *Server) changeSCIMUser(w http.ResponseWriter, r *http.Request, change scimUserChange) {
+	ctx := r.Context()
+	details, err := s.app.User.GetUser(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	user := &details.User
+
+	if change.UserName != nil && *change.UserName != user.Username {
+		if user, err = s.app.User.RenameUser(ctx, user.ID, *change.UserName); err != nil {
+			s.handleSCIMError(w, err)
+			return
+		}
+	}
+	if change.Active != nil && *change.Active != user.IsActive {
+		if user, err = s.app.User.SetActive(ctx, user.ID, *change.Active); err != nil {
+			s.handleSCIMError(w, err)
+			return
+		}
+	}
+	s.writeSCIM(w, http.StatusOK, scimUserResource(user))
+}
+
+// HandleSCIMGroupList lists the non-archived teams. Members are loaded per
+// team unless excludedAttributes=members is given.
+func (s *Server) HandleSCIMGroupList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	_, displayName, err := parseSCIMFilter(r.URL.Query().Get("filter"), "displayName")
+	if err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidFilter", err.Error())
+		return
+	}
+
+	var names []string
+	filter := domain.TeamFilter{NamePrefix: displayName}
+	cursor := ""
+	for {
+		page, next, err := s.app.Team.ListTeams(ctx, filter, cursor, service.MaxPageLimit)
+		if err != nil {
+			s.handleSCIMError(w, err)
+			return
+		}
+		for _, t := range page {
+			if displayName == "" || t.Name == displayName {
+				names = append(names, t.Name)
+			}
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	withMembers := !strings.Contains(strings.ToLower(r.URL.Query().Get("excludedAttributes")), "members")
+	groups := make([]scimGroup, 0, len(names))
+	for _, name := range names {
+		var members []domain.User
+		if withMembers {
+			team, err := s.app.Team.GetTeam(ctx, name)
+			if err != nil {
+				s.handleSCIMError(w, err)
+				return
+			}
+			members = team.Members
+		}
+		groups = append(groups, scimGroupResource(name, members))
+	}
+
+	resp, err := scimPage(r, groups)
+	if err != nil {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", err.Error())
+		return
+	}
+	s.writeSCIM(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleSCIMGroupGet(w http.ResponseWriter, r *http.Request) {
+	team, err := s.scimTeam(r.Context(), chi.URLParam(r, "id"))
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	s.writeSCIM(w, http.StatusOK, scimGroupResource(team.Name, team.Members))
+}
+
+// HandleSCIMGroupCreate creates a root team with the listed members, who must
+// already exist.
+func (s *Server) HandleSCIMGroupCreate(w http.ResponseWriter, r *http.Request) {
+	var req scimGroupRequest
+	if !s.decodeSCIM(w, r, &req) {
+		return
+	}
+	if req.DisplayName == "" {
+		s.writeSCIMError(w, http.StatusBadRequest, "invalidValue", "displayName is required")
+		return
+	}
+
+	add := make([]string, 0, len(req.Members))
+	for _, m := range req.Members {
+		add = append(add, m.Value)
+	}
+	result, err := s.app.Team.ProvisionMembers(r.Context(), req.DisplayName, add, nil, true)
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	resource := scimGroupResource(result.Team.Name, result.Team.Members)
+	w.Header().Set("Location", resource.Meta.Location)
+	s.writeSCIM(w, http.StatusCreated, resource)
+}
+
+func (s *Server) HandleSCIMGroupReplace(w http.ResponseWriter, r *http.Request) {
+	var req scimGroupRequest
+	if !s.decodeSCIM(w, r, &req) {
+		return
+	}
+	name := chi.URLParam(r, "id")
+	if req.DisplayName != "" && req.DisplayName != name {
+		s.writeSCIMError(w, http.StatusBadRequest, "mutability", errSCIMGroupRename.Error())
+		return
+	}
+
+	members := make(map[string]bool, len(req.Members))
+	for _, m := range req.Members {
+		members[m.Value] = true
+	}
+	s.changeSCIMGroup(w, r, func(string, map[string]bool) map[string]bool { return members })
+}
+
+func (s *Server) HandleSCIMGroupPatch(w http.ResponseWriter, r *http.Request) {
+	var req scimPatchRequest
+	if !s.decodeSCIM(w, r, &req) {
+		return
+	}
+
+	s.changeSCIMGroup(w, r, func(name string, members map[string]bool) map[string]bool {
+		if err := applyGroupPatch(name, members, req.Operations); err != nil {
+			s.writeSCIMError(w, http.StatusBadRequest, scimPatchErrorType(err), err.Error())
+			return nil
+		}
+		return members
+	})
+}
+
+// HandleSCIMGroupDelete takes everyone off the team, reassigning their open
+// reviews in it, and archives the team.
+func (s *Server) HandleSCIMGroupDelete(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.app.Team.DisbandTeam(r.Context(), chi.URLParam(r, "id")); err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// changeSCIMGroup loads the group and brings its members in line with the
+// set desired returns; a nil set means desired has already responded.
+func (s *Server) changeSCIMGroup(
+	w http.ResponseWriter,
+	r *http.Request,
+	desired func(name string, members map[string]bool) map[string]bool,
+) {
+	ctx := r.Context()
+	team, err := s.scimTeam(ctx, chi.URLParam(r, "id"))
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+
+	current := make(map[string]bool, len(team.Members))
+	for _, m := range team.Members {
+		current[m.ID] = true
+	}
+	want := desired(team.Name, maps.Clone(current))
+	if want == nil {
+		return
+	}
+
+	var add, remove []string
+	for id := range want {
+		if !current[id] {
+			add = append(add, id)
+		}
+	}
+	for id := range current {
+		if !want[id] {
+			remove = append(remove, id)
+		}
+	}
+	sort.Strings(add)
+	sort.Strings(remove)
+
+	result, err := s.app.Team.ProvisionMembers(ctx, team.Name, add, remove, false)
+	if err != nil {
+		s.handleSCIMError(w, err)
+		return
+	}
+	s.writeSCIM(w, http.StatusOK, scimGroupResource(result.Team.Name, result.Team.Members))
+}
+
+// scimTeam loads a team as a group; an archived team is a deleted group.
+func (s *Server) scimTeam(ctx context.Context, name string) (*domain.Team, error) {
+	team, err := s.app.Team.GetTeam(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if team.IsArchived() {
+		return nil, domain.NewDomainError(domain.ErrorCodeNotFound, "group "+name+" not found")
+	}
+	return team, nil
+}
+
+func scimPatchErrorType(err error) string {
+	if errors.Is(err, errSCIMGroupRename) {
+		return "mutability"
+	}
+	return "invalidValue"
+}
@@ -0,0 +1,353 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+const testSCIMToken = "scim-secret"
+
+type scimTestRepos struct {
+	users     *mocks.MockUserRepository
+	teams     *mocks.MockTeamRepository
+	teamUsers *mocks.MockTeamUserRepository
+	teamPRs   *mocks.MockTeamPRRepository
+	userPRs   *mocks.MockUserPRRepository
+}
+
+func newSCIMTestRouter(t *testing.T, token string) (http.Handler, *scimTestRepos) {
+	t.Helper()
+
+	backend := &domain.Team{
+		Name:    "backend",
+		Members: []domain.User{{ID: "u1", Username: "alice@example.com", TeamName: "backend", IsActive: true}},
+	}
+	repos := &scimTestRepos{
+		users: &mocks.MockUserRepository{
+			GetByIDResult:     &domain.User{ID: "u2", Username: "bob@example.com", TeamName: "backend", IsActive: true},
+			SetIsActiveResult: &domain.User{ID: "u2", Username: "bob@example.com", TeamName: "backend", IsActive: false},
+			ListResult: domain.UserPage{
+				Users: []domain.User{{ID: "u1", Username: "alice@example.com", TeamName: "backend", IsActive: true}},
+			},
+		},
+		teams: &mocks.MockTeamRepository{
+			GetWithMembersResult: backend,
+			ListResult: domain.TeamPage{
+				Teams: []domain.TeamSummary{{Name: "backend", MemberCount: 1, ActiveMemberCount: 1}},
+			},
+		},
+		teamUsers: &mocks.MockTeamUserRepository{
+			// u2 already has a primary team, u3 has none.
+			CurrentTeamsResult: map[string]string{"u2": "frontend"},
+		},
+		teamPRs: &mocks.MockTeamPRRepository{ReassignResult: mocks.ReviewerChanges(2)},
+		userPRs: &mocks.MockUserPRRepository{},
+	}
+
+	app := service.NewApp(
+		service.NewTeamService(repos.teams, repos.teamUsers, repos.teamPRs, &mocks.MockTxManager{}),
+		service.NewUserService(repos.users, repos.userPRs, &mocks.MockTxManager{}),
+		service.NewPRService(&mocks.MockPRRepository{}, &mocks.MockPRUserRepository{}, time.Now),
+		service.NewStatsService(&mocks.MockAssignmentStatsRepo{}),
+		service.NewRosterService(&mocks.MockRosterRepository{}, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{}),
+		service.NewOutOfOfficeService(&mocks.MockOutOfOfficeRepository{}, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{}, time.Now),
+	)
+	app.WithIdempotency(service.NewIdempotencyService(newMemoryIdempotencyRepo(), time.Now, time.Hour))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(app, logger)
+	if token != "" {
+		server.EnableSCIM(token)
+	}
+	return NewRouter(server, logger), repos
+}
+
+// TestSCIM replays requests recorded from identity providers (testdata/scim).
+func TestSCIM(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		payload    string
+		wantStatus int
+		check      func(t *testing.T, repos *scimTestRepos, body map[string]any)
+	}{
+		{
+			name:       "поиск пользователя по userName",
+			method:     http.MethodGet,
+			path:       `/scim/v2/Users?filter=userName%20eq%20%22alice@example.com%22&startIndex=1&count=100`,
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.users.ListFilter.Username != "alice@example.com" {
+					t.Errorf("expected username filter, got %+v", repos.users.ListFilter)
+				}
+				if body["totalResults"] != float64(1) {
+					t.Errorf("expected one result, got %v", body)
+				}
+			},
+		},
+		{
+			name:       "неподдерживаемый фильтр",
+			method:     http.MethodGet,
+			path:       `/scim/v2/Users?filter=emails%20co%20%22example%22`,
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if body["scimType"] != "invalidFilter" {
+					t.Errorf("expected invalidFilter, got %v", body)
+				}
+			},
+		},
+		{
+			name:       "создание пользователя Okta",
+			method:     http.MethodPost,
+			path:       "/scim/v2/Users",
+			payload:    "okta_create_user.json",
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				want := []domain.User{{ID: "carol@example.com", Username: "carol@example.com", IsActive: true}}
+				if !slices.Equal(repos.users.Created, want) {
+					t.Errorf("expected %+v created, got %+v", want, repos.users.Created)
+				}
+				if body["id"] != "carol@example.com" {
+					t.Errorf("expected id carol@example.com, got %v", body["id"])
+				}
+			},
+		},
+		{
+			name:       "деактивация Okta",
+			method:     http.MethodPatch,
+			path:       "/scim/v2/Users/u2",
+			payload:    "okta_deactivate_user.json",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.users.SetIsActiveCalls != 1 || body["active"] != false {
+					t.Errorf("expected the user deactivated, got %d calls and %v", repos.users.SetIsActiveCalls, body)
+				}
+			},
+		},
+		{
+			name:       "деактивация Azure строкой False",
+			method:     http.MethodPatch,
+			path:       "/scim/v2/Users/u2",
+			payload:    "azure_deactivate_user.json",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.users.SetIsActiveCalls != 1 {
+					t.Errorf("expected the user deactivated, got %d calls", repos.users.SetIsActiveCalls)
+				}
+			},
+		},
+		{
+			name:       "замена пользователя Azure меняет только имя",
+			method:     http.MethodPut,
+			path:       "/scim/v2/Users/u2",
+			payload:    "azure_replace_user.json",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if body["userName"] != "bob.brown@example.com" {
+					t.Errorf("expected the user renamed, got %v", body)
+				}
+				if repos.users.SetIsActiveCalls != 0 {
+					t.Errorf("expected active left alone, got %d calls", repos.users.SetIsActiveCalls)
+				}
+			},
+		},
+		{
+			name:       "удаление пользователя деактивирует его",
+			method:     http.MethodDelete,
+			path:       "/scim/v2/Users/u2",
+			wantStatus: http.StatusNoContent,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.users.SetIsActiveCalls != 1 {
+					t.Errorf("expected the user deactivated, got %d calls", repos.users.SetIsActiveCalls)
+				}
+				if len(repos.userPRs.HandedOver) != 1 || !slices.Equal(repos.userPRs.HandedOver[0], []string{"u2"}) {
+					t.Errorf("expected open reviews of u2 handed over, got %v", repos.userPRs.HandedOver)
+				}
+			},
+		},
+		{
+			name:       "создание группы Okta",
+			method:     http.MethodPost,
+			path:       "/scim/v2/Groups",
+			payload:    "okta_create_group.json",
+			wantStatus: http.StatusCreated,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.teamUsers.SetTeamCalls != 1 || !slices.Equal(repos.teamUsers.MembershipIDs, []string{"u2"}) {
+					t.Errorf("expected u3 given the team and u2 added as a member, got %d and %v",
+						repos.teamUsers.SetTeamCalls, repos.teamUsers.MembershipIDs)
+				}
+			},
+		},
+		{
+			name:       "добавление участников Azure",
+			method:     http.MethodPatch,
+			path:       "/scim/v2/Groups/backend",
+			payload:    "azure_add_members.json",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if repos.teamUsers.MembershipTeam != "backend" || !slices.Equal(repos.teamUsers.MembershipIDs, []string{"u2"}) {
+					t.Errorf("expected u2 added to backend, got %s %v", repos.teamUsers.MembershipTeam, repos.teamUsers.MembershipIDs)
+				}
+				if repos.teamUsers.RemovedIDs != nil {
+					t.Errorf("expected nobody removed, got %v", repos.teamUsers.RemovedIDs)
+				}
+			},
+		},
+		{
+			name:       "удаление участника Okta переназначает ревью",
+			method:     http.MethodPatch,
+			path:       "/scim/v2/Groups/backend",
+			payload:    "okta_remove_member.json",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if !slices.Equal(repos.teamUsers.RemovedIDs, []string{"u1"}) || !slices.Equal(repos.teamPRs.ReassignedIDs, []string{"u1"}) {
+					t.Errorf("expected u1 removed with reassignment, got %v and %v", repos.teamUsers.RemovedIDs, repos.teamPRs.ReassignedIDs)
+				}
+			},
+		},
+		{
+			name:       "переименование группы запрещено",
+			method:     http.MethodPatch,
+			path:       "/scim/v2/Groups/backend",
+			payload:    "okta_rename_group.json",
+			wantStatus: http.StatusBadRequest,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				if body["scimType"] != "mutability" {
+					t.Errorf("expected mutability, got %v", body)
+				}
+			},
+		},
+		{
+			name:       "список групп без участников",
+			method:     http.MethodGet,
+			path:       "/scim/v2/Groups?excludedAttributes=members",
+			wantStatus: http.StatusOK,
+			check: func(t *testing.T, repos *scimTestRepos, body map[string]any) {
+				resources, _ := body["Resources"].([]any)
+				if len(resources) != 1 {
+					t.Fatalf("expected one group, got %v", body)
+				}
+				if _, ok := resources[0].(map[string]any)["members"]; ok {
+					t.Errorf("expected members excluded, got %v", resources[0])
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, repos := newSCIMTestRouter(t, testSCIMToken)
+
+			var payload []byte
+			if tt.payload != "" {
+				var err error
+				payload, err = os.ReadFile(filepath.Join("testdata", "scim", tt.payload))
+				if err != nil {
+					t.Fatalf("read payload: %v", err)
+				}
+			}
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewReader(payload))
+			req.Header.Set("Content-Type", scimContentType)
+			req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			var body map[string]any
+			if rec.Body.Len() > 0 {
+				if ct := rec.Header().Get("Content-Type"); ct != scimContentType {
+					t.Errorf("expected content type %s, got %s", scimContentType, ct)
+				}
+				if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+					t.Fatalf("decode response: %v", err)
+				}
+			}
+			tt.check(t, repos, body)
+		})
+	}
+}
+
+func TestSCIMAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		header     string
+		wantStatus int
+	}{
+		{name: "SCIM выключен", header: "Bearer " + testSCIMToken, wantStatus: http.StatusNotFound},
+		{name: "без токена", enabled: true, wantStatus: http.StatusUnauthorized},
+		{name: "неверный токен", enabled: true, header: "Bearer wrong", wantStatus: http.StatusUnauthorized},
+		{name: "верный токен", enabled: true, header: "Bearer " + testSCIMToken, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := ""
+			if tt.enabled {
+				token = testSCIMToken
+			}
+			router, _ := newSCIMTestRouter(t, token)
+
+			req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestSCIMIdempotencyAfterAuth(t *testing.T) {
+	router, _ := newSCIMTestRouter(t, testSCIMToken)
+
+	send := func(auth string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/scim/v2/Users",
+			bytes.NewReader([]byte(`{"userName":"carol@example.com","externalId":"u3","active":true}`)))
+		req.Header.Set("Content-Type", "application/scim+json")
+		req.Header.Set(idempotencyKeyHeader, "scim-retry-1")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	unauthorized := send("")
+	if unauthorized.Code != http.StatusUnauthorized {
+		t.Fatalf("expected status 401, got %d: %s", unauthorized.Code, unauthorized.Body.String())
+	}
+
+	authorized := send("Bearer " + testSCIMToken)
+	if authorized.Code == http.StatusUnauthorized || authorized.Header().Get(idempotentReplayedHeader) != "" {
+		t.Fatalf("expected authorized request to run, got %d replayed=%q",
+			authorized.Code, authorized.Header().Get(idempotentReplayedHeader))
+	}
+
+	again := send("")
+	if again.Code != http.StatusUnauthorized || again.Header().Get(idempotentReplayedHeader) != "" {
+		t.Errorf("expected unauthenticated retry to get 401 without replay, got %d replayed=%q",
+			again.Code, again.Header().Get(idempotentReplayedHeader))
+	}
+}
@@ -14,6 +14,10 @@ import (
 type Server struct {
 	app    *service.App
 	logger *slog.Logger
+
+	// scimToken is the bearer token of the SCIM endpoints; they are
+	// disabled while it is empty.
+	scimToken string
 }
 
 func NewServer(app *service.App, logger *slog.Logger) *Server {
@@ -23,9 +27,21 @@ func NewServer(app *service.App, logger *slog.Logger) *Server {
 	}
 }
 
+// EnableSCIM turns on the /scim/v2 provisioning endpoints for clients that
+// present token.
+func (s *Server) EnableSCIM(token string) {
+	s.scimToken = token
+}
+
+type fieldErrorDTO struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
 type apiError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Details []fieldErrorDTO `json:"details,omitempty"`
 }
 
 type errorResponse struct {
@@ -55,10 +71,41 @@ func (s *Server) writeDomainError(w http.ResponseWriter, status int, code domain
 	s.writeJSON(w, status, resp)
 }
 
+func (s *Server) writeDomainErrorWithDetails(w http.ResponseWriter, status int, code domain.ErrorCode, message string, details []domain.FieldError) {
+	resp := errorResponse{
+		Error: apiError{
+			Code:    string(code),
+			Message: message,
+		},
+	}
+	for _, d := range details {
+		resp.Error.Details = append(resp.Error.Details, fieldErrorDTO{
+			Field:  d.Field,
+			Reason: d.Reason,
+		})
+	}
+	s.writeJSON(w, status, resp)
+}
+
+func (s *Server) writeBadRequest(w http.ResponseWriter, message string) {
+	s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeBadRequest, message)
+}
+
+func (s *Server) writeValidationError(w http.ResponseWriter, message string, details ...domain.FieldError) {
+	s.writeDomainErrorWithDetails(w, http.StatusBadRequest, domain.ErrorCodeValidation, message, details)
+}
+
+func (s *Server) writeRequiredError(w http.ResponseWriter, field string) {
+	s.writeValidationError(w, field+" is required", domain.FieldError{
+		Field:  field,
+		Reason: "required",
+	})
+}
+
 func (s *Server) writeUnknownError(w http.ResponseWriter, message string) {
 	resp := errorResponse{
 		Error: apiError{
-			Code:    string(domain.ErrorCodeNotFound),
+			Code:    string(domain.ErrorCodeInternal),
 			Message: message,
 		},
 	}
@@ -77,17 +124,34 @@ func (s *Server) handleError(w http.ResponseWriter, err error) {
 		switch de.Code {
 		case domain.ErrorCodeTeamExists:
 			status = http.StatusBadRequest
-		case domain.ErrorCodePRExists:
+		case domain.ErrorCodePRExists,
+			domain.ErrorCodeUserExists:
 			status = http.StatusConflict
 		case domain.ErrorCodePRMerged,
 			domain.ErrorCodeNotAssigned,
-			domain.ErrorCodeNoCandidate:
+			domain.ErrorCodeNoCandidate,
+			domain.ErrorCodeAllAtCapacity,
+			domain.ErrorCodeHasOpenReviews,
+			domain.ErrorCodeUserInOtherTeam,
+			domain.ErrorCodeTeamArchived,
+			domain.ErrorCodeTeamHasHistory,
+			domain.ErrorCodeAlreadyReactivated,
+			domain.ErrorCodeTeamCycle,
+			domain.ErrorCodeTeamHasSubteams:
 			status = http.StatusConflict
 		case domain.ErrorCodeNotFound:
 			status = http.StatusNotFound
+		case domain.ErrorCodeBadRequest,
+			domain.ErrorCodeValidation:
+			status = http.StatusBadRequest
+		case domain.ErrorCodeVersionConflict,
+			domain.ErrorCodeIdempotencyInProgress:
+			status = http.StatusConflict
+		case domain.ErrorCodeIdempotencyKeyReused:
+			status = http.StatusUnprocessableEntity
 		}
 
-		s.writeDomainError(w, status, de.Code, de.Message)
+		s.writeDomainErrorWithDetails(w, status, de.Code, de.Message, de.Details)
 		return
 	}
 
@@ -97,7 +161,7 @@ func (s *Server) handleError(w http.ResponseWriter, err error) {
 	}
 
 	s.logger.Error("unexpected error", "error", err)
-	s.writeDomainError(w, http.StatusInternalServerError, domain.ErrorCodeNotFound, "internal server error")
+	s.writeUnknownError(w, "internal server error")
 }
 
 func (s *Server) HealthCheck(w http.ResponseWriter, r *http.Request) {
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/openapi"
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
@@ -20,15 +21,16 @@ func (s *Server) HandleTeamAdd(w http.ResponseWriter, r *http.Request) {
 			slog.Debug("error closing body in HandleTeamAdd", "error", err)
 		}
 	}()
-	var req openapi.Team
+	var req openapi.TeamAddRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
 		return
 	}
 
-	domainTeam := converter.TeamFromOpenAPI(&req)
+	domainTeam := converter.TeamFromOpenAPI(&openapi.Team{TeamName: req.TeamName, Members: req.Members, ParentTeam: req.ParentTeam})
 
-	created, err := s.app.Team.CreateTeam(r.Context(), domainTeam.Name, domainTeam.Members)
+	created, err := s.app.Team.CreateTeam(r.Context(), domainTeam.Name, domainTeam.ParentTeam, domainTeam.Members,
+		req.Move != nil && *req.Move)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -43,7 +45,7 @@ func (s *Server) HandleTeamAdd(w http.ResponseWriter, r *http.Request) {
 func (s *Server) HandleTeamGet(w http.ResponseWriter, r *http.Request) {
 	teamName := r.URL.Query().Get("team_name")
 	if teamName == "" {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "team_name is required")
+		s.writeRequiredError(w, "team_name")
 		return
 	}
 
@@ -57,6 +59,97 @@ func (s *Server) HandleTeamGet(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
+type teamListResponse struct {
+	Teams      []openapi.TeamSummary `json:"teams"`
+	NextCursor string                `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) HandleTeamList(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParams(r.URL.Query())
+	filter := domain.TeamFilter{
+		NamePrefix:       q.str("name_prefix"),
+		MinActiveMembers: q.int("min_active_members"),
+		MaxActiveMembers: q.optInt("max_active_members"),
+	}
+	limit := q.int("limit")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
+		return
+	}
+
+	teams, next, err := s.app.Team.ListTeams(r.Context(), filter, q.str("cursor"), limit)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := teamListResponse{
+		Teams:      make([]openapi.TeamSummary, 0, len(teams)),
+		NextCursor: next,
+	}
+	for i := range teams {
+		resp.Teams = append(resp.Teams, converter.TeamSummaryToOpenAPI(&teams[i]))
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamMembers(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	members, err := s.app.Team.ListSubtreeMembers(r.Context(), teamName)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.TeamSubtreeMembersResponse{
+		TeamName: teamName,
+		Members:  make([]openapi.User, 0, len(members)),
+	}
+	for i := range members {
+		resp.Members = append(resp.Members, converter.UserToOpenAPI(&members[i]))
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamSetParent(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamSetParent", "error", err)
+		}
+	}()
+
+	var req openapi.TeamSetParentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	var parentTeam string
+	if req.ParentTeam != nil {
+		parentTeam = *req.ParentTeam
+	}
+
+	team, err := s.app.Team.SetParentTeam(r.Context(), req.TeamName, parentTeam)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := createTeamResponse{
+		Team: converter.TeamToOpenAPI(team),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
 type deactivateTeamRequest struct {
 	TeamName string `json:"team_name"`
 }
@@ -65,6 +158,7 @@ type deactivateTeamResponse struct {
 	TeamName            string `json:"team_name"`
 	DeactivatedUsers    int    `json:"deactivated_users"`
 	UpdatedPullRequests int    `json:"updated_pull_requests"`
+	DeactivationID      int64  `json:"deactivation_id,omitempty"`
 }
 
 func (s *Server) HandleTeamDeactivate(w http.ResponseWriter, r *http.Request) {
@@ -76,11 +170,11 @@ func (s *Server) HandleTeamDeactivate(w http.ResponseWriter, r *http.Request) {
 
 	var req deactivateTeamRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
 		return
 	}
 	if req.TeamName == "" {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "team_name is required")
+		s.writeRequiredError(w, "team_name")
 		return
 	}
 
@@ -94,7 +188,303 @@ func (s *Server) HandleTeamDeactivate(w http.ResponseWriter, r *http.Request) {
 		TeamName:            res.TeamName,
 		DeactivatedUsers:    res.DeactivatedUsers,
 		UpdatedPullRequests: res.UpdatedPullRequests,
+		DeactivationID:      res.DeactivationID,
+	}
+
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamReactivate(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamReactivate", "error", err)
+		}
+	}()
+
+	var req openapi.TeamReactivateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
 	}
 
+	var deactivationID int64
+	if req.DeactivationId != nil {
+		deactivationID = *req.DeactivationId
+	}
+
+	res, err := s.app.Team.ReactivateTeam(r.Context(), req.TeamName, deactivationID,
+		req.RefillReviewers != nil && *req.RefillReviewers)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.TeamReactivateResponse{
+		TeamName:            res.TeamName,
+		DeactivationId:      res.DeactivationID,
+		ReactivatedUsers:    res.ReactivatedUsers,
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamMembersAdd(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamMembersAdd", "error", err)
+		}
+	}()
+
+	var req openapi.TeamMembersAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	domainTeam := converter.TeamFromOpenAPI(&openapi.Team{TeamName: req.TeamName, Members: req.Members})
+
+	team, err := s.app.Team.AddMembers(r.Context(), domainTeam.Name, domainTeam.Members,
+		req.Move != nil && *req.Move)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := createTeamResponse{
+		Team: converter.TeamToOpenAPI(team),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamMembershipsAdd(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamMembershipsAdd", "error", err)
+		}
+	}()
+
+	var req openapi.TeamMembershipsAddRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+	if len(req.UserIds) == 0 {
+		s.writeRequiredError(w, "user_ids")
+		return
+	}
+
+	team, err := s.app.Team.AddMemberships(r.Context(), req.TeamName, req.UserIds, converter.TeamRoleFromOpenAPI(req.Role))
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := createTeamResponse{
+		Team: converter.TeamToOpenAPI(team),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamMemberSetRole(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamMemberSetRole", "error", err)
+		}
+	}()
+
+	var req openapi.TeamMemberSetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+	if req.UserId == "" {
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+	if req.Role == "" {
+		s.writeRequiredError(w, "role")
+		return
+	}
+
+	team, err := s.app.Team.SetMemberRole(r.Context(), req.TeamName, req.UserId, converter.TeamRoleFromOpenAPI(&req.Role))
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := createTeamResponse{
+		Team: converter.TeamToOpenAPI(team),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamSetMaxOpenReviews", "error", err)
+		}
+	}()
+
+	var req openapi.TeamSetMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+	var limit int
+	if req.MaxOpenReviews != nil {
+		limit = int(*req.MaxOpenReviews)
+	}
+
+	team, err := s.app.Team.SetMaxOpenReviews(r.Context(), req.TeamName, limit)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := createTeamResponse{
+		Team: converter.TeamToOpenAPI(team),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamMembersRemove(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamMembersRemove", "error", err)
+		}
+	}()
+
+	var req openapi.TeamMembersRemoveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+	if len(req.UserIds) == 0 {
+		s.writeRequiredError(w, "user_ids")
+		return
+	}
+
+	res, err := s.app.Team.RemoveMembers(r.Context(), req.TeamName, req.UserIds,
+		req.ReassignReviews != nil && *req.ReassignReviews)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, converter.TeamMembershipToOpenAPI(res))
+}
+
+func (s *Server) HandleTeamUpdate(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamUpdate", "error", err)
+		}
+	}()
+
+	var req openapi.TeamUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	domainTeam := converter.TeamFromOpenAPI(&openapi.Team{TeamName: req.TeamName, Members: req.Members})
+
+	res, err := s.app.Team.UpdateTeam(r.Context(), domainTeam.Name, domainTeam.Members,
+		req.ReassignReviews != nil && *req.ReassignReviews, req.Move != nil && *req.Move)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, converter.TeamMembershipToOpenAPI(res))
+}
+
+func (s *Server) HandleTeamArchive(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamArchive", "error", err)
+		}
+	}()
+
+	var req openapi.TeamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	res, err := s.app.Team.ArchiveTeam(r.Context(), req.TeamName)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.TeamArchiveResponse{
+		TeamName:            res.TeamName,
+		ArchivedAt:          time.Unix(res.ArchivedAt, 0).UTC(),
+		DeactivatedUsers:    int32(res.DeactivatedUsers),
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleTeamDelete(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleTeamDelete", "error", err)
+		}
+	}()
+
+	var req openapi.TeamNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	res, err := s.app.Team.DeleteTeam(r.Context(), req.TeamName)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.TeamDeleteResponse{
+		TeamName:     res.TeamName,
+		DeletedUsers: int32(res.DeletedUsers),
+	}
 	s.writeJSON(w, http.StatusOK, resp)
 }
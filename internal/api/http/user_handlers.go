@@ -27,7 +27,7 @@ func (s *Server) HandleUserSetIsActive(w http.ResponseWriter, r *http.Request) {
 	}()
 	var req setUserActiveRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "invalid JSON body")
+		s.writeBadRequest(w, "invalid JSON body")
 		return
 	}
 	user, err := s.app.User.SetActive(r.Context(), req.UserID, req.IsActive)
@@ -41,19 +41,202 @@ func (s *Server) HandleUserSetIsActive(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, resp)
 }
 
+func (s *Server) HandleUserSetWorkingHours(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleUserSetWorkingHours", "error", err)
+		}
+	}()
+
+	var req openapi.SetWorkingHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	hours, err := converter.WorkingHoursFromOpenAPI(req.WorkingHours)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	user, err := s.app.User.SetWorkingHours(r.Context(), req.UserId, hours)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, openapi.WorkingHoursResponse{
+		UserId:       user.ID,
+		WorkingHours: converter.WorkingHoursToOpenAPI(user.WorkingHours),
+	})
+}
+
+func (s *Server) HandleUserSetMaxOpenReviews(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleUserSetMaxOpenReviews", "error", err)
+		}
+	}()
+
+	var req openapi.SetMaxOpenReviewsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	var limit int
+	if req.MaxOpenReviews != nil {
+		limit = int(*req.MaxOpenReviews)
+	}
+
+	user, err := s.app.User.SetMaxOpenReviews(r.Context(), req.UserId, limit)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, converter.MaxOpenReviewsToOpenAPI(user))
+}
+
+func (s *Server) HandleUserSetWeeklyReviewQuota(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleUserSetWeeklyReviewQuota", "error", err)
+		}
+	}()
+
+	var req openapi.SetWeeklyReviewQuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	var quota int
+	if req.WeeklyReviewQuota != nil {
+		quota = int(*req.WeeklyReviewQuota)
+	}
+
+	user, err := s.app.User.SetWeeklyReviewQuota(r.Context(), req.UserId, quota)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+	s.writeJSON(w, http.StatusOK, converter.WeeklyReviewQuotaToOpenAPI(user))
+}
+
+func (s *Server) HandleUserMoveTeam(w http.ResponseWriter, r *http.Request) {
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			slog.Debug("error closing body in HandleUserMoveTeam", "error", err)
+		}
+	}()
+
+	var req openapi.UserMoveTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeBadRequest(w, "invalid JSON body")
+		return
+	}
+	if req.UserId == "" {
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+	if req.TeamName == "" {
+		s.writeRequiredError(w, "team_name")
+		return
+	}
+
+	var policy domain.OpenReviewsPolicy
+	if req.OpenReviews != nil {
+		policy = domain.OpenReviewsPolicy(*req.OpenReviews)
+	}
+
+	res, err := s.app.Team.MoveUser(r.Context(), req.UserId, req.TeamName, policy)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := openapi.UserMoveTeamResponse{
+		User:                converter.UserToOpenAPI(&res.User),
+		FromTeam:            res.FromTeam,
+		UpdatedPullRequests: int32(res.UpdatedPullRequests),
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+type userListResponse struct {
+	Users      []openapi.User `json:"users"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+func (s *Server) HandleUserList(w http.ResponseWriter, r *http.Request) {
+	q := newQueryParams(r.URL.Query())
+	filter := domain.UserFilter{
+		TeamName:         q.str("team_name"),
+		IsActive:         q.optBool("is_active"),
+		UsernameContains: q.str("username"),
+	}
+	limit := q.int("limit")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
+		return
+	}
+
+	users, next, err := s.app.User.ListUsers(r.Context(), filter, q.str("cursor"), limit)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	resp := userListResponse{
+		Users:      make([]openapi.User, 0, len(users)),
+		NextCursor: next,
+	}
+	for i := range users {
+		resp.Users = append(resp.Users, converter.UserToOpenAPI(&users[i]))
+	}
+	s.writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) HandleUserGet(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+
+	details, err := s.app.User.GetUser(r.Context(), userID)
+	if err != nil {
+		s.handleError(w, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, converter.UserDetailsToOpenAPI(details))
+}
+
 type userReviewsResponse struct {
 	UserID       string                     `json:"user_id"`
 	PullRequests []openapi.PullRequestShort `json:"pull_requests"`
+	NextCursor   string                     `json:"next_cursor,omitempty"`
 }
 
 func (s *Server) HandleUserGetReview(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
+	q := newQueryParams(r.URL.Query())
+	userID := q.str("user_id")
 	if userID == "" {
-		s.writeDomainError(w, http.StatusBadRequest, domain.ErrorCodeNotFound, "user_id is required")
+		s.writeRequiredError(w, "user_id")
+		return
+	}
+
+	filter := domain.ReviewFilter{
+		Status:           domain.PRStatus(q.str("status")),
+		Since:            q.unix("since"),
+		OldestFirst:      q.str("sort") == "oldest",
+		IncludeReviewers: q.bool("include_reviewers"),
+	}
+	limit := q.int("limit")
+	if len(q.errs) > 0 {
+		s.writeValidationError(w, "invalid query parameters", q.errs...)
 		return
 	}
 
-	prs, err := s.app.User.ListAssignedPullRequests(r.Context(), userID)
+	prs, next, err := s.app.User.ListAssignedPullRequests(r.Context(), userID, filter, q.str("cursor"), limit)
 	if err != nil {
 		s.handleError(w, err)
 		return
@@ -62,6 +245,7 @@ func (s *Server) HandleUserGetReview(w http.ResponseWriter, r *http.Request) {
 	resp := userReviewsResponse{
 		UserID:       userID,
 		PullRequests: make([]openapi.PullRequestShort, 0, len(prs)),
+		NextCursor:   next,
 	}
 
 	for i := range prs {
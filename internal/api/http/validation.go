@@ -0,0 +1,205 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type ValidatorOptions struct {
+	// ValidateResponses checks every response against the spec too and
+	// replaces a non-conforming one with a 500. Meant for tests.
+	ValidateResponses bool
+}
+
+type openAPIValidator struct {
+	server  *Server
+	router  routers.Router
+	opts    ValidatorOptions
+	filters *openapi3filter.Options
+}
+
+// NewOpenAPIValidator builds a chi middleware that validates requests (and
+// optionally responses) against the given OpenAPI document. Routes that are
+// not described in the spec (health check, swagger) pass through untouched.
+func NewOpenAPIValidator(server *Server, spec []byte, opts ValidatorOptions) (func(http.Handler) http.Handler, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi spec: %w", err)
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("build openapi router: %w", err)
+	}
+
+	filters := &openapi3filter.Options{
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+		MultiError:            true,
+	}
+	filters.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	v := &openAPIValidator{
+		server:  server,
+		router:  router,
+		opts:    opts,
+		filters: filters,
+	}
+	return v.middleware, nil
+}
+
+// schemaErrorMessage keeps the field path and reason but drops the schema
+// and value dumps kin-openapi appends by default.
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	pointer := err.JSONPointer()
+	if len(pointer) == 0 {
+		return err.Reason
+	}
+	return fmt.Sprintf("field %q: %s", strings.Join(pointer, "."), err.Reason)
+}
+
+func (v *openAPIValidator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Handlers decode JSON unless the spec says otherwise, so a body sent
+		// without a content type is validated as JSON instead of being
+		// rejected outright.
+		if r.ContentLength != 0 && r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.filters,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), reqInput); err != nil {
+			v.writeRequestError(w, err)
+			return
+		}
+
+		if !v.opts.ValidateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Options:                v.filters,
+		}
+		if err := openapi3filter.ValidateResponse(r.Context(), respInput); err != nil {
+			v.server.logger.Error("response does not match openapi spec",
+				"method", r.Method, "path", r.URL.Path, "status", rec.status, "error", err)
+			v.server.writeUnknownError(w, "response does not match openapi spec: "+err.Error())
+			return
+		}
+
+		rec.flush(w)
+	})
+}
+
+// writeRequestError answers BAD_REQUEST when the body could not be parsed at
+// all and VALIDATION_ERROR with per-field details otherwise.
+func (v *openAPIValidator) writeRequestError(w http.ResponseWriter, err error) {
+	var parseErr *openapi3filter.ParseError
+	if errors.As(err, &parseErr) {
+		v.server.writeBadRequest(w, err.Error())
+		return
+	}
+
+	v.server.writeValidationError(w, "request does not match openapi spec", fieldErrors(err, "")...)
+}
+
+func fieldErrors(err error, field string) []domain.FieldError {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		var out []domain.FieldError
+		for _, inner := range e {
+			out = append(out, fieldErrors(inner, field)...)
+		}
+		return out
+	case *openapi3filter.RequestError:
+		if e.Parameter != nil {
+			field = e.Parameter.Name
+		}
+		if e.Err == nil {
+			return []domain.FieldError{{Field: fieldOrBody(field), Reason: e.Reason}}
+		}
+		return fieldErrors(e.Err, field)
+	case *openapi3.SchemaError:
+		if pointer := e.JSONPointer(); len(pointer) > 0 {
+			field = strings.Join(pointer, ".")
+		}
+		return []domain.FieldError{{Field: fieldOrBody(field), Reason: e.Reason}}
+	}
+
+	reason := err.Error()
+	if errors.Is(err, openapi3filter.ErrInvalidRequired) {
+		reason = "required"
+	}
+	return []domain.FieldError{{Field: fieldOrBody(field), Reason: reason}}
+}
+
+func fieldOrBody(field string) string {
+	if field == "" {
+		return "body"
+	}
+	return field
+}
+
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) flush(w http.ResponseWriter) {
+	for k, vals := range r.header {
+		for _, val := range vals {
+			w.Header().Add(k, val)
+		}
+	}
+	w.WriteHeader(r.status)
+	// #nosec G104 -- the client may have gone away, nothing to do about it
+	_, _ = w.Write(r.body.Bytes())
+}
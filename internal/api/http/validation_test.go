@@ -0,0 +1,898 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/openapi"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func newTestRouter(t *testing.T) http.Handler {
+	t.Helper()
+
+	openPR := &domain.PullRequest{
+		ID:        "pr-1",
+		Name:      "Add search",
+		AuthorID:  "u1",
+		Status:    domain.PRStatusOpen,
+		CreatedAt: 1_700_000_000,
+		Version:   4,
+	}
+	mergedPR := *openPR
+	mergedPR.Status = domain.PRStatusMerged
+	mergedPR.MergedAt = 1_700_000_100
+	mergedPR.Version = 5
+
+	listedMerged := mergedPR
+	listedMerged.AssignedReviewers = []string{"u2", "u3"}
+	listedOpen := *openPR
+	listedOpen.AssignedReviewers = []string{"u2"}
+
+	prRepo := &mocks.MockPRRepository{
+		GetByIDResult:         openPR,
+		GetByIDReviewers:      []string{"u2", "u3"},
+		SetMergedResult:       &mergedPR,
+		SetMergedReviewers:    []string{"u2", "u3"},
+		UpdateResult:          openPR,
+		UpdateReviewersResult: []string{"u4", "u3"},
+		DeactivateResult: domain.TeamDeactivationResult{
+			TeamName: "backend", DeactivatedUsers: 2, DeactivationID: 7,
+		},
+		ListResult: domain.PullRequestPage{
+			PullRequests: []domain.PullRequest{listedMerged, listedOpen},
+			Next:         &domain.PullRequestCursor{CreatedAtMicro: 1_700_000_000_000_000, ID: "pr-1"},
+		},
+	}
+	prUsers := &mocks.MockPRUserRepository{
+		GetByIDResult: &domain.User{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+		ListByTeamResult: []domain.User{
+			{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+			{ID: "u3", Username: "Carol", TeamName: "backend", IsActive: true},
+			{ID: "u4", Username: "Dave", TeamName: "backend", IsActive: true},
+		},
+	}
+	teamRepo := &mocks.MockTeamRepository{
+		GetWithMembersResult: &domain.Team{
+			Name:    "backend",
+			Members: []domain.User{{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+		},
+		GetSubtreeResult: &domain.Team{
+			Name:       "backend",
+			ParentTeam: "engineering",
+			Members:    []domain.User{{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+			Subteams: []domain.Team{{
+				Name:       "backend-payments",
+				ParentTeam: "backend",
+				Members:    []domain.User{{ID: "u5", Username: "Eve", TeamName: "backend-payments", IsActive: true}},
+			}},
+		},
+		ListSubtreeMembersResult: []domain.User{
+			{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+			{ID: "u5", Username: "Eve", TeamName: "backend-payments", IsActive: true},
+		},
+		FindDeactivationResult: &domain.TeamDeactivation{ID: 7, TeamName: "backend", UserIDs: []string{"u1", "u2"}},
+		ReactivateResult:       []string{"u1", "u2"},
+		ListResult: domain.TeamPage{
+			Teams: []domain.TeamSummary{
+				{Name: "backend", ParentTeam: "engineering", MemberCount: 3, ActiveMemberCount: 2},
+				{Name: "backend-payments", ParentTeam: "backend", MemberCount: 1, ActiveMemberCount: 1},
+			},
+			Next: "backend-payments",
+		},
+	}
+	teamUserRepo := &mocks.MockTeamUserRepository{
+		GetByIDResult: &domain.User{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+	}
+	userRepo := &mocks.MockUserRepository{
+		GetByIDResult:     &domain.User{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true},
+		SetIsActiveResult: &domain.User{ID: "u2", Username: "Bob", TeamName: "backend", IsActive: false},
+		ListResult: domain.UserPage{
+			Users: []domain.User{{ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+		},
+	}
+	userPRRepo := &mocks.MockUserPRRepository{
+		ListByReviewerResult: []domain.PullRequest{*openPR},
+		ReviewSummaryResult:  domain.ReviewSummary{Open: 1, Completed: 4},
+	}
+	statsRepo := &mocks.MockAssignmentStatsRepo{
+		CountByReviewerResult: map[string]int64{"u2": 1},
+		CountByPRResult:       map[string]int64{"pr-1": 2},
+	}
+
+	rosterRepo := &mocks.MockRosterRepository{
+		TeamsResult: map[string]bool{"backend": false},
+		UsersResult: map[string]domain.User{"u1": {ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+		MembershipsResult: map[string]map[string]domain.MemberRole{
+			"u1": {"backend": domain.RoleMember},
+		},
+		ExportResult: []domain.RosterEntry{
+			{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember},
+		},
+		// u9 is created concurrently by somebody else.
+		CreateUserErrs: map[string]error{
+			"u9": domain.NewDomainError(domain.ErrorCodeUserExists, "user already exists"),
+		},
+	}
+
+	oooRepo := &mocks.MockOutOfOfficeRepository{
+		ListResult: []domain.OutOfOfficePeriod{
+			{ID: 1, UserID: "u2", StartsAt: 1_700_000_000, EndsAt: 1_700_600_000, Reason: "vacation", ReassignedAt: 1_700_000_060},
+		},
+		DeleteResult: &domain.OutOfOfficePeriod{ID: 1, UserID: "u2", StartsAt: 1_700_000_000, EndsAt: 1_700_600_000},
+	}
+
+	app := service.NewApp(
+		service.NewTeamService(teamRepo, teamUserRepo, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{}),
+		service.NewUserService(userRepo, userPRRepo, &mocks.MockTxManager{}),
+		service.NewPRService(prRepo, prUsers, func() time.Time { return time.Unix(1_700_000_000, 0) }),
+		service.NewStatsService(statsRepo),
+		service.NewRosterService(rosterRepo, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{}),
+		service.NewOutOfOfficeService(oooRepo, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{}, func() time.Time { return time.Unix(1_700_000_000, 0) }),
+	)
+	app.WithIdempotency(service.NewIdempotencyService(newMemoryIdempotencyRepo(), time.Now, time.Hour))
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewServer(app, logger)
+
+	validator, err := NewOpenAPIValidator(server, openapi.Spec, ValidatorOptions{ValidateResponses: true})
+	if err != nil {
+		t.Fatalf("build validator: %v", err)
+	}
+	return NewRouter(server, logger, validator)
+}
+
+func TestOpenAPIValidator(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		contentType   string
+		noContentType bool
+		ifMatch       string
+		wantStatus    int
+		wantCode      domain.ErrorCode
+		wantField     string
+	}{
+		{
+			name:       "создание PR",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "создание PR в дополнительной команде автора",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","team_name":"guild"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "создание рискованного PR",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","labels":["risky"]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "пустая метка при создании PR",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","labels":[""]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "labels.0",
+		},
+		{
+			name:       "создание PR со ссылкой на GitHub",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","github_repo":"acme/api","github_number":12}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "репозиторий GitHub без владельца",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","github_repo":"api","github_number":12}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "github_repo",
+		},
+		{
+			name:       "пустая team_name при создании PR",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search","author_id":"u1","team_name":""}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "team_name",
+		},
+		{
+			name:       "пустой pull_request_id",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"","pull_request_name":"Add search","author_id":"u1"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "pull_request_id",
+		},
+		{
+			name:       "нет обязательного author_id",
+			method:     http.MethodPost,
+			target:     "/pullRequest/create",
+			body:       `{"pull_request_id":"pr-2","pull_request_name":"Add search"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "author_id",
+		},
+		{
+			name:       "неверный тип поля",
+			method:     http.MethodPost,
+			target:     "/users/setIsActive",
+			body:       `{"user_id":"u2","is_active":"no"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "is_active",
+		},
+		{
+			name:       "битый JSON",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeBadRequest,
+		},
+		{
+			name:          "тело без Content-Type считается JSON",
+			method:        http.MethodPost,
+			target:        "/pullRequest/merge",
+			body:          `{"pull_request_id":"pr-1"}`,
+			noContentType: true,
+			wantStatus:    http.StatusOK,
+		},
+		{
+			name:       "мерж PR",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"pr-1"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "мерж с актуальным If-Match",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"pr-1"}`,
+			ifMatch:    `"4"`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "мерж с устаревшим If-Match",
+			method:     http.MethodPost,
+			target:     "/pullRequest/merge",
+			body:       `{"pull_request_id":"pr-1"}`,
+			ifMatch:    `"3"`,
+			wantStatus: http.StatusConflict,
+			wantCode:   domain.ErrorCodeVersionConflict,
+		},
+		{
+			name:       "некорректный If-Match",
+			method:     http.MethodPost,
+			target:     "/pullRequest/reassign",
+			body:       `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`,
+			ifMatch:    "4",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "If-Match",
+		},
+		{
+			name:       "добавление участников в команду",
+			method:     http.MethodPost,
+			target:     "/team/members/add",
+			body:       `{"team_name":"backend","members":[{"user_id":"u3","username":"Carol","is_active":true}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "добавление без участников",
+			method:     http.MethodPost,
+			target:     "/team/members/add",
+			body:       `{"team_name":"backend","members":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "members",
+		},
+		{
+			name:       "удаление участника с переназначением",
+			method:     http.MethodPost,
+			target:     "/team/members/remove",
+			body:       `{"team_name":"backend","user_ids":["u1"],"reassign_reviews":true}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "удаление не участника команды",
+			method:     http.MethodPost,
+			target:     "/team/members/remove",
+			body:       `{"team_name":"backend","user_ids":["u9"]}`,
+			wantStatus: http.StatusNotFound,
+			wantCode:   domain.ErrorCodeNotFound,
+		},
+		{
+			name:       "добавление в дополнительную команду",
+			method:     http.MethodPost,
+			target:     "/team/memberships/add",
+			body:       `{"team_name":"guild","user_ids":["u1"]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "добавление в команду без user_ids",
+			method:     http.MethodPost,
+			target:     "/team/memberships/add",
+			body:       `{"team_name":"guild","user_ids":[]}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "user_ids",
+		},
+		{
+			name:       "смена роли участника",
+			method:     http.MethodPost,
+			target:     "/team/members/setRole",
+			body:       `{"team_name":"backend","user_id":"u1","role":"lead"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неизвестная роль участника",
+			method:     http.MethodPost,
+			target:     "/team/members/setRole",
+			body:       `{"team_name":"backend","user_id":"u1","role":"owner"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "role",
+		},
+		{
+			name:       "смена роли без user_id",
+			method:     http.MethodPost,
+			target:     "/team/members/setRole",
+			body:       `{"team_name":"backend","role":"lead"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "user_id",
+		},
+		{
+			name:       "лимит открытых ревью команды",
+			method:     http.MethodPost,
+			target:     "/team/setMaxOpenReviews",
+			body:       `{"team_name":"backend","max_open_reviews":5}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "отрицательный лимит открытых ревью команды",
+			method:     http.MethodPost,
+			target:     "/team/setMaxOpenReviews",
+			body:       `{"team_name":"backend","max_open_reviews":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "max_open_reviews",
+		},
+		{
+			name:       "обновление состава команды",
+			method:     http.MethodPost,
+			target:     "/team/update",
+			body:       `{"team_name":"backend","members":[{"user_id":"u1","username":"Alice","is_active":true}]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "переназначение ревьювера",
+			method:     http.MethodPost,
+			target:     "/pullRequest/reassign",
+			body:       `{"pull_request_id":"pr-1","old_reviewer_id":"u2"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "смена активности пользователя",
+			method:     http.MethodPost,
+			target:     "/users/setIsActive",
+			body:       `{"user_id":"u2","is_active":false}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "получение команды",
+			method:     http.MethodGet,
+			target:     "/team/get?team_name=backend",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "список команд",
+			method:     http.MethodGet,
+			target:     "/teams?name_prefix=back&min_active_members=1&max_active_members=5&limit=2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "отрицательное число активных участников",
+			method:     http.MethodGet,
+			target:     "/teams?min_active_members=-1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "min_active_members",
+		},
+		{
+			name:       "максимум активных участников меньше минимума",
+			method:     http.MethodGet,
+			target:     "/teams?min_active_members=3&max_active_members=1",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "max_active_members",
+		},
+		{
+			name:       "некорректный курсор списка команд",
+			method:     http.MethodGet,
+			target:     "/teams?cursor=%21%21",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "cursor",
+		},
+		{
+			name:       "список пользователей",
+			method:     http.MethodGet,
+			target:     "/users?team_name=backend&is_active=true&username=al",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "некорректный is_active",
+			method:     http.MethodGet,
+			target:     "/users?is_active=maybe",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeBadRequest,
+		},
+		{
+			name:       "пользователь со сводкой ревью",
+			method:     http.MethodGet,
+			target:     "/users/get?user_id=u2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "user_id не передан в /users/get",
+			method:     http.MethodGet,
+			target:     "/users/get",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "user_id",
+		},
+		{
+			name:       "участники поддерева команды",
+			method:     http.MethodGet,
+			target:     "/team/members?team_name=backend",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "перенос команды в отдел",
+			method:     http.MethodPost,
+			target:     "/team/setParent",
+			body:       `{"team_name":"backend","parent_team":"engineering"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "перенос команды без team_name",
+			method:     http.MethodPost,
+			target:     "/team/setParent",
+			body:       `{"parent_team":"engineering"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "team_name",
+		},
+		{
+			name:       "team_name не передан",
+			method:     http.MethodGet,
+			target:     "/team/get",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "team_name",
+		},
+		{
+			name:       "создание команды",
+			method:     http.MethodPost,
+			target:     "/team/add",
+			body:       `{"team_name":"payments","members":[{"user_id":"u5","username":"Eve","is_active":true}]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "создание команды с переводом участников",
+			method:     http.MethodPost,
+			target:     "/team/add",
+			body:       `{"team_name":"payments","members":[{"user_id":"u1","username":"Alice","is_active":true}],"move":true}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "перевод пользователя в другую команду",
+			method:     http.MethodPost,
+			target:     "/users/moveTeam",
+			body:       `{"user_id":"u1","team_name":"payments","open_reviews":"reassign"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неизвестная политика open_reviews",
+			method:     http.MethodPost,
+			target:     "/users/moveTeam",
+			body:       `{"user_id":"u1","team_name":"payments","open_reviews":"drop"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "open_reviews",
+		},
+		{
+			name:       "отпуск пользователя",
+			method:     http.MethodPost,
+			target:     "/users/ooo/add",
+			body:       `{"user_id":"u2","starts_at":"2023-11-20T00:00:00Z","ends_at":"2023-11-27T00:00:00Z","reason":"vacation"}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "отпуск без ends_at",
+			method:     http.MethodPost,
+			target:     "/users/ooo/add",
+			body:       `{"user_id":"u2","starts_at":"2023-11-20T00:00:00Z"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "ends_at",
+		},
+		{
+			name:       "отпуск, который заканчивается раньше начала",
+			method:     http.MethodPost,
+			target:     "/users/ooo/add",
+			body:       `{"user_id":"u2","starts_at":"2023-11-27T00:00:00Z","ends_at":"2023-11-20T00:00:00Z"}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "ends_at",
+		},
+		{
+			name:       "список отпусков пользователя",
+			method:     http.MethodGet,
+			target:     "/users/ooo?user_id=u2&include_past=true",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "список отпусков без user_id",
+			method:     http.MethodGet,
+			target:     "/users/ooo",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "user_id",
+		},
+		{
+			name:       "рабочие часы пользователя",
+			method:     http.MethodPost,
+			target:     "/users/setWorkingHours",
+			body:       `{"user_id":"u2","working_hours":{"time_zone":"Asia/Novosibirsk","start":"09:00","end":"18:00"}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "сброс рабочих часов",
+			method:     http.MethodPost,
+			target:     "/users/setWorkingHours",
+			body:       `{"user_id":"u2"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "рабочие часы не в формате HH:MM",
+			method:     http.MethodPost,
+			target:     "/users/setWorkingHours",
+			body:       `{"user_id":"u2","working_hours":{"time_zone":"Asia/Novosibirsk","start":"9:00","end":"18:00"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "working_hours.start",
+		},
+		{
+			name:       "неизвестный часовой пояс",
+			method:     http.MethodPost,
+			target:     "/users/setWorkingHours",
+			body:       `{"user_id":"u2","working_hours":{"time_zone":"Mars/Olympus","start":"09:00","end":"18:00"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "working_hours.time_zone",
+		},
+		{
+			name:       "лимит открытых ревью пользователя",
+			method:     http.MethodPost,
+			target:     "/users/setMaxOpenReviews",
+			body:       `{"user_id":"u2","max_open_reviews":3}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "лимит открытых ревью без user_id",
+			method:     http.MethodPost,
+			target:     "/users/setMaxOpenReviews",
+			body:       `{"max_open_reviews":3}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "user_id",
+		},
+		{
+			name:       "недельная квота ревью",
+			method:     http.MethodPost,
+			target:     "/users/setWeeklyReviewQuota",
+			body:       `{"user_id":"u2","weekly_review_quota":10}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "отрицательная недельная квота ревью",
+			method:     http.MethodPost,
+			target:     "/users/setWeeklyReviewQuota",
+			body:       `{"user_id":"u2","weekly_review_quota":-1}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "weekly_review_quota",
+		},
+		{
+			name:       "отмена отпуска",
+			method:     http.MethodPost,
+			target:     "/users/ooo/remove",
+			body:       `{"user_id":"u2","ooo_id":1}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "архивация команды",
+			method:     http.MethodPost,
+			target:     "/team/archive",
+			body:       `{"team_name":"backend"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "отмена деактивации команды",
+			method:     http.MethodPost,
+			target:     "/team/reactivate",
+			body:       `{"team_name":"backend","deactivation_id":7,"refill_reviewers":true}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "отмена деактивации с нулевым deactivation_id",
+			method:     http.MethodPost,
+			target:     "/team/reactivate",
+			body:       `{"team_name":"backend","deactivation_id":0}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "deactivation_id",
+		},
+		{
+			name:       "удаление команды без team_name",
+			method:     http.MethodPost,
+			target:     "/team/delete",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "team_name",
+		},
+		{
+			name:       "удаление команды",
+			method:     http.MethodPost,
+			target:     "/team/delete",
+			body:       `{"team_name":"backend"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "деактивация команды",
+			method:     http.MethodPost,
+			target:     "/team/deactivate",
+			body:       `{"team_name":"backend"}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "получение PR",
+			method:     http.MethodGet,
+			target:     "/pullRequest/get?pull_request_id=pr-1",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "pull_request_id не передан",
+			method:     http.MethodGet,
+			target:     "/pullRequest/get",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "pull_request_id",
+		},
+		{
+			name:       "список PR с фильтрами",
+			method:     http.MethodGet,
+			target:     "/pullRequests?status=OPEN&team_name=backend&reviewer_id=u2&created_from=2023-11-01T00:00:00Z&limit=2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неизвестный статус в фильтре",
+			method:     http.MethodGet,
+			target:     "/pullRequests?status=CLOSED",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "status",
+		},
+		{
+			name:       "лимит вне диапазона",
+			method:     http.MethodGet,
+			target:     "/pullRequests?limit=0",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "limit",
+		},
+		{
+			name:       "битая дата",
+			method:     http.MethodGet,
+			target:     "/pullRequests?merged_to=yesterday",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "merged_to",
+		},
+		{
+			name:       "битый курсор",
+			method:     http.MethodGet,
+			target:     "/pullRequests?cursor=%21%21",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "cursor",
+		},
+		{
+			name:       "ревью пользователя",
+			method:     http.MethodGet,
+			target:     "/users/getReview?user_id=u2",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "ревью пользователя с фильтрами и ревьюверами",
+			method:     http.MethodGet,
+			target:     "/users/getReview?user_id=u2&status=OPEN&since=2023-11-01T00:00:00Z&sort=oldest&include_reviewers=true&limit=10",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неизвестная сортировка ревью",
+			method:     http.MethodGet,
+			target:     "/users/getReview?user_id=u2&sort=age",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "sort",
+		},
+		{
+			name:        "пробный импорт YAML",
+			method:      http.MethodPost,
+			target:      "/admin/import?dry_run=true",
+			body:        "- team: backend\n  user_id: u1\n  username: Alice\n  role: lead\n- team: guild\n  user_id: u1\n  username: Alice\n",
+			contentType: "application/yaml",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "импорт CSV",
+			method:      http.MethodPost,
+			target:      "/admin/import",
+			body:        "team,user_id,username,active,role\nbackend,u2,Bob,false,\n",
+			contentType: "text/csv",
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "импорт пользователя, созданного параллельно",
+			method:      http.MethodPost,
+			target:      "/admin/import",
+			body:        "team,user_id,username\nbackend,u9,Ivan\n",
+			contentType: "text/csv",
+			wantStatus:  http.StatusConflict,
+			wantCode:    domain.ErrorCodeUserExists,
+		},
+		{
+			name:        "неизвестная роль в YAML",
+			method:      http.MethodPost,
+			target:      "/admin/import",
+			body:        "- team: backend\n  user_id: u1\n  username: Alice\n  role: owner\n",
+			contentType: "application/yaml",
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    domain.ErrorCodeValidation,
+			wantField:   "0.role",
+		},
+		{
+			name:        "CSV без обязательной колонки",
+			method:      http.MethodPost,
+			target:      "/admin/import",
+			body:        "team,user_id\nbackend,u1\n",
+			contentType: "text/csv",
+			wantStatus:  http.StatusBadRequest,
+			wantCode:    domain.ErrorCodeValidation,
+			wantField:   "header",
+		},
+		{
+			name:       "импорт в JSON",
+			method:     http.MethodPost,
+			target:     "/admin/import",
+			body:       `[{"team":"backend","user_id":"u1","username":"Alice"}]`,
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+		},
+		{
+			name:       "выгрузка YAML",
+			method:     http.MethodGet,
+			target:     "/admin/export",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "выгрузка CSV",
+			method:     http.MethodGet,
+			target:     "/admin/export?format=csv",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "неизвестный формат выгрузки",
+			method:     http.MethodGet,
+			target:     "/admin/export?format=xml",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   domain.ErrorCodeValidation,
+			wantField:  "format",
+		},
+		{
+			name:       "статистика",
+			method:     http.MethodGet,
+			target:     "/stats/assignments",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "маршрут вне спецификации не валидируется",
+			method:     http.MethodGet,
+			target:     "/healthz",
+			wantStatus: http.StatusOK,
+		},
+	}
+
+	router := newTestRouter(t)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			req := httptest.NewRequest(tt.method, tt.target, body)
+			if tt.body != "" && !tt.noContentType {
+				contentType := tt.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				req.Header.Set("Content-Type", contentType)
+			}
+			if tt.ifMatch != "" {
+				req.Header.Set(ifMatchHeader, tt.ifMatch)
+			}
+			rec := httptest.NewRecorder()
+
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if rec.Code >= http.StatusBadRequest {
+				var resp errorResponse
+				if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+					t.Fatalf("decode error response: %v", err)
+				}
+				if resp.Error.Message == "" {
+					t.Errorf("expected error message in response")
+				}
+				if resp.Error.Code != string(tt.wantCode) {
+					t.Errorf("expected code %s, got %s", tt.wantCode, resp.Error.Code)
+				}
+				if tt.wantField != "" && !hasField(resp.Error.Details, tt.wantField) {
+					t.Errorf("expected details for field %q, got %+v", tt.wantField, resp.Error.Details)
+				}
+			}
+		})
+	}
+}
+
+func hasField(details []fieldErrorDTO, field string) bool {
+	for _, d := range details {
+		if d.Field == field {
+			return true
+		}
+	}
+	return false
+}
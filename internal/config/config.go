@@ -4,11 +4,17 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type HTTPConfig struct {
+	Addr              string `yaml:"addr"`
+	ValidateResponses bool   `yaml:"validate_responses"`
+}
+
+type GRPCConfig struct {
 	Addr string `yaml:"addr"`
 }
 
@@ -21,9 +27,54 @@ type DatabaseConfig struct {
 	SSLMode  string `yaml:"ssl_mode"`
 }
 
+type GitHubConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	BaseURL      string        `yaml:"base_url"`
+	Token        string        `yaml:"token"`
+	MaxAttempts  int           `yaml:"max_attempts"`
+	RetryBackoff time.Duration `yaml:"retry_backoff"`
+	Timeout      time.Duration `yaml:"timeout"`
+}
+
+// SCIMConfig enables the /scim/v2 provisioning endpoints for an identity
+// provider, which authenticates with Token as a bearer token.
+type SCIMConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Token   string `yaml:"token"`
+}
+
+type IdempotencyConfig struct {
+	TTL         time.Duration `yaml:"ttl"`
+	LockTimeout time.Duration `yaml:"lock_timeout"`
+}
+
+// OutOfOfficeConfig sets how often the open reviews of users whose
+// out-of-office period has started are handed over.
+type OutOfOfficeConfig struct {
+	CheckInterval time.Duration `yaml:"check_interval"`
+}
+
+// ReviewersConfig tunes reviewer selection. Prefer is "" (random),
+// "working_now" or "hours_overlap". Overflow is what happens when every
+// candidate is at their open review limit: "reject" (the default) or
+// "least_loaded". FairnessDays is how many days of assignments make a
+// candidate less likely to be picked; zero turns the weighting off.
+type ReviewersConfig struct {
+	Prefer       string `yaml:"prefer"`
+	Overflow     string `yaml:"overflow"`
+	FairnessDays int    `yaml:"fairness_days"`
+}
+
 type Config struct {
-	HTTP *HTTPConfig    `yaml:"http"`
-	DB   DatabaseConfig `yaml:"database"`
+	HTTP   *HTTPConfig    `yaml:"http"`
+	GRPC   *GRPCConfig    `yaml:"grpc"`
+	DB     DatabaseConfig `yaml:"database"`
+	GitHub *GitHubConfig  `yaml:"github"`
+	SCIM   *SCIMConfig    `yaml:"scim"`
+
+	Idempotency *IdempotencyConfig `yaml:"idempotency"`
+	OutOfOffice *OutOfOfficeConfig `yaml:"out_of_office"`
+	Reviewers   *ReviewersConfig   `yaml:"reviewers"`
 }
 
 func (c Config) HTTPAddr() string {
@@ -33,6 +84,121 @@ func (c Config) HTTPAddr() string {
 	return c.HTTP.Addr
 }
 
+func (c Config) HTTPValidateResponses() bool {
+	return c.HTTP != nil && c.HTTP.ValidateResponses
+}
+
+func (c Config) GRPCAddr() string {
+	if c.GRPC == nil {
+		return ":9090"
+	}
+	return c.GRPC.Addr
+}
+
+// IdempotencyTTL is how long a response stored under an Idempotency-Key
+// is replayed.
+func (c Config) IdempotencyTTL() time.Duration {
+	if c.Idempotency == nil || c.Idempotency.TTL <= 0 {
+		return 24 * time.Hour
+	}
+	return c.Idempotency.TTL
+}
+
+// IdempotencyLockTimeout is how long a request in progress holds its
+// Idempotency-Key before a retry may take it over.
+func (c Config) IdempotencyLockTimeout() time.Duration {
+	if c.Idempotency == nil || c.Idempotency.LockTimeout <= 0 {
+		return time.Minute
+	}
+	return c.Idempotency.LockTimeout
+}
+
+func (c Config) OutOfOfficeCheckInterval() time.Duration {
+	if c.OutOfOffice == nil || c.OutOfOffice.CheckInterval <= 0 {
+		return time.Minute
+	}
+	return c.OutOfOffice.CheckInterval
+}
+
+func (c Config) ReviewerPreference() string {
+	if c.Reviewers == nil {
+		return ""
+	}
+	return c.Reviewers.Prefer
+}
+
+func (c Config) CapacityOverflow() string {
+	if c.Reviewers == nil || c.Reviewers.Overflow == "" {
+		return "reject"
+	}
+	return c.Reviewers.Overflow
+}
+
+func (c Config) FairnessWindow() time.Duration {
+	if c.Reviewers == nil {
+		return 0
+	}
+	return time.Duration(c.Reviewers.FairnessDays) * 24 * time.Hour
+}
+
+func (c Config) GitHubEnabled() bool {
+	return c.GitHub != nil && c.GitHub.Enabled
+}
+
+func (c Config) SCIMEnabled() bool {
+	return c.SCIM != nil && c.SCIM.Enabled
+}
+
+func (s SCIMConfig) TokenOrEnv() string {
+	if s.Token == "" {
+		return os.Getenv("SCIM_TOKEN")
+	}
+	return s.Token
+}
+
+func (g GitHubConfig) BaseURLOrDefault() string {
+	if g.BaseURL == "" {
+		return "https://api.github.com"
+	}
+	return g.BaseURL
+}
+
+func (g GitHubConfig) TokenOrEnv() string {
+	if g.Token == "" {
+		return os.Getenv("GITHUB_TOKEN")
+	}
+	return g.Token
+}
+
+func (g GitHubConfig) MaxAttemptsOrDefault() int {
+	if g.MaxAttempts <= 0 {
+		return 3
+	}
+	return g.MaxAttempts
+}
+
+func (g GitHubConfig) RetryBackoffOrDefault() time.Duration {
+	if g.RetryBackoff <= 0 {
+		return 500 * time.Millisecond
+	}
+	return g.RetryBackoff
+}
+
+func (g GitHubConfig) TimeoutOrDefault() time.Duration {
+	if g.Timeout <= 0 {
+		return 10 * time.Second
+	}
+	return g.Timeout
+}
+
+// SyncTimeout bounds one background sync: a remove and a request call,
+// each with all retries and backoffs.
+func (g GitHubConfig) SyncTimeout() time.Duration {
+	attempts := time.Duration(g.MaxAttemptsOrDefault())
+	perCall := attempts*g.TimeoutOrDefault() + attempts*attempts*g.RetryBackoffOrDefault()
+	return 2 * perCall
+}
+
 func (db DatabaseConfig) ConnString() string {
 	host := db.Host
 	if host == "" {
@@ -60,7 +226,8 @@ func (db DatabaseConfig) ConnString() string {
 	)
 }
 
-func load(path string) (*Config, error) {
+// Load reads and checks the config file at path.
+func Load(path string) (*Config, error) {
 	// #nosec G304 -- config file path is provided via command line flag
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -76,6 +243,26 @@ func load(path string) (*Config, error) {
 		return &Config{}, fmt.Errorf("database user and password must be set in config")
 	}
 
+	if cfg.SCIMEnabled() && cfg.SCIM.TokenOrEnv() == "" {
+		return &Config{}, fmt.Errorf("scim token must be set when scim is enabled")
+	}
+
+	switch cfg.ReviewerPreference() {
+	case "", "working_now", "hours_overlap":
+	default:
+		return &Config{}, fmt.Errorf("reviewers prefer must be working_now or hours_overlap, got %q", cfg.ReviewerPreference())
+	}
+
+	switch cfg.CapacityOverflow() {
+	case "reject", "least_loaded":
+	default:
+		return &Config{}, fmt.Errorf("reviewers overflow must be reject or least_loaded, got %q", cfg.CapacityOverflow())
+	}
+
+	if cfg.Reviewers != nil && cfg.Reviewers.FairnessDays < 0 {
+		return &Config{}, fmt.Errorf("reviewers fairness_days must not be negative, got %d", cfg.Reviewers.FairnessDays)
+	}
+
 	return cfg, nil
 }
 
@@ -88,7 +275,7 @@ func ParseConfig() (*Config, error) {
 		return nil, fmt.Errorf("config path is required")
 	}
 
-	cfg, err := load(*configPath)
+	cfg, err := Load(*configPath)
 	if err != nil {
 		return nil, err
 	}
@@ -5,17 +5,44 @@ import "fmt"
 type ErrorCode string
 
 const (
-	ErrorCodeTeamExists  ErrorCode = "TEAM_EXISTS"
-	ErrorCodePRExists    ErrorCode = "PR_EXISTS"
-	ErrorCodePRMerged    ErrorCode = "PR_MERGED"
-	ErrorCodeNotAssigned ErrorCode = "NOT_ASSIGNED"
-	ErrorCodeNoCandidate ErrorCode = "NO_CANDIDATE"
-	ErrorCodeNotFound    ErrorCode = "NOT_FOUND"
+	ErrorCodeTeamExists      ErrorCode = "TEAM_EXISTS"
+	ErrorCodeUserExists      ErrorCode = "USER_EXISTS"
+	ErrorCodePRExists        ErrorCode = "PR_EXISTS"
+	ErrorCodePRMerged        ErrorCode = "PR_MERGED"
+	ErrorCodeNotAssigned     ErrorCode = "NOT_ASSIGNED"
+	ErrorCodeNoCandidate     ErrorCode = "NO_CANDIDATE"
+	ErrorCodeNotFound        ErrorCode = "NOT_FOUND"
+	ErrorCodeVersionConflict ErrorCode = "VERSION_CONFLICT"
+	ErrorCodeBadRequest      ErrorCode = "BAD_REQUEST"
+	ErrorCodeValidation      ErrorCode = "VALIDATION_ERROR"
+	ErrorCodeInternal        ErrorCode = "INTERNAL"
+
+	ErrorCodeIdempotencyInProgress ErrorCode = "IDEMPOTENCY_IN_PROGRESS"
+	ErrorCodeIdempotencyKeyReused  ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+
+	ErrorCodeHasOpenReviews  ErrorCode = "HAS_OPEN_REVIEWS"
+	ErrorCodeUserInOtherTeam ErrorCode = "USER_IN_OTHER_TEAM"
+	ErrorCodeTeamArchived    ErrorCode = "TEAM_ARCHIVED"
+	ErrorCodeTeamHasHistory  ErrorCode = "TEAM_HAS_HISTORY"
+
+	ErrorCodeAlreadyReactivated ErrorCode = "ALREADY_REACTIVATED"
+
+	ErrorCodeTeamCycle       ErrorCode = "TEAM_CYCLE"
+	ErrorCodeTeamHasSubteams ErrorCode = "TEAM_HAS_SUBTEAMS"
+
+	ErrorCodeAllAtCapacity ErrorCode = "ALL_AT_CAPACITY"
 )
 
+// FieldError points at a single invalid input field.
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
 type DomainError struct {
 	Code    ErrorCode
 	Message string
+	Details []FieldError
 }
 
 func (e *DomainError) Error() string {
@@ -31,3 +58,11 @@ func NewDomainError(code ErrorCode, msg string) *DomainError {
 		Message: msg,
 	}
 }
+
+func NewValidationError(msg string, details ...FieldError) *DomainError {
+	return &DomainError{
+		Code:    ErrorCodeValidation,
+		Message: msg,
+		Details: details,
+	}
+}
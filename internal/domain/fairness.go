@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"math/rand"
+	"time"
+)
+
+// FairWeight is how likely the user is picked as a reviewer compared to the
+// other candidates: inversely proportional to one plus their recent reviews.
+func (u User) FairWeight() float64 {
+	return 1 / float64(1+u.RecentReviews)
+}
+
+// PickFair picks up to max candidates at random, each with a chance
+// proportional to their FairWeight. Without recent reviews filled in every
+// candidate is equally likely.
+func PickFair(candidates []User, max int) []string {
+	if max <= 0 || len(candidates) == 0 {
+		return nil
+	}
+	if len(candidates) <= max {
+		out := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			out = append(out, c.ID)
+		}
+		return out
+	}
+
+	// #nosec G404 -- non-cryptographic random is acceptable for reviewer selection
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+
+	pool := make([]User, len(candidates))
+	copy(pool, candidates)
+
+	out := make([]string, 0, max)
+	for len(out) < max && len(pool) > 0 {
+		var total float64
+		for _, c := range pool {
+			total += c.FairWeight()
+		}
+		x := r.Float64() * total
+		idx := len(pool) - 1
+		for i, c := range pool {
+			if x -= c.FairWeight(); x < 0 {
+				idx = i
+				break
+			}
+		}
+		out = append(out, pool[idx].ID)
+		pool[idx] = pool[len(pool)-1]
+		pool = pool[:len(pool)-1]
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPickFair(t *testing.T) {
+	candidates := []User{
+		{ID: "u1", RecentReviews: 1_000_000},
+		{ID: "u2"},
+		{ID: "u3", RecentReviews: 2},
+	}
+
+	// The picks are random, so repeat; u1 has almost no weight.
+	seen := make(map[string]bool)
+	for range 50 {
+		got := PickFair(candidates, 2)
+		if len(got) != 2 || slices.Contains(got, "u1") {
+			t.Fatalf("expected two of the less assigned, got %v", got)
+		}
+		seen[got[0]] = true
+	}
+	if !seen["u2"] {
+		t.Errorf("expected u2 to be picked first at least once")
+	}
+
+	if got := PickFair(candidates, 3); !slices.Equal(got, []string{"u1", "u2", "u3"}) {
+		t.Errorf("expected everybody when there are enough slots, got %v", got)
+	}
+	if got := PickFair(candidates, 0); got != nil {
+		t.Errorf("expected nothing without slots, got %v", got)
+	}
+}
@@ -1,15 +1,130 @@
 package domain
 
+import "time"
+
+// User belongs to its primary team, TeamName, and through team memberships
+// possibly to further teams.
 type User struct {
 	ID       string
 	Username string
 	TeamName string
 	IsActive bool
+	// Role is the user's role in the team they were listed for; empty when
+	// the user is loaded outside a team.
+	Role MemberRole
+	// OutOfOffice is set when one of the user's out-of-office periods
+	// covers the current moment. Like Role it is filled only for users
+	// loaded as team members.
+	OutOfOffice bool
+	// WorkingHours is nil when the user has not set any. It is filled for
+	// users loaded by ID or as team members.
+	WorkingHours *WorkingHours
+	// MaxOpenReviews caps the open PRs the user reviews at once: their own
+	// cap, or else the default of their primary team. Zero means no cap.
+	MaxOpenReviews int
+	// OpenReviews is the number of open PRs the user reviews. Like
+	// MaxOpenReviews it is filled for users loaded by ID or as team members.
+	OpenReviews int
+	// WeeklyReviewQuota caps the reviews assigned to the user over the last
+	// QuotaWindow. Zero means no quota.
+	WeeklyReviewQuota int
+	// WeeklyReviews is the number of reviews assigned to the user over the
+	// last QuotaWindow. Both are filled like OpenReviews.
+	WeeklyReviews int
+	// RecentReviews is the number of reviews assigned to the user within the
+	// fairness window of reviewer selection; zero when it is not in use.
+	RecentReviews int
+}
+
+// QuotaWindow is the rolling period WeeklyReviewQuota applies to.
+const QuotaWindow = 7 * 24 * time.Hour
+
+// WorkingHours is the daily window in which a user works, in minutes since
+// midnight in TimeZone (an IANA name). A window with End before Start spans
+// midnight.
+type WorkingHours struct {
+	TimeZone string
+	Start    int
+	End      int
+}
+
+// IsAvailable reports whether the user can be picked as a reviewer: active,
+// not out of office and below their weekly quota.
+func (u User) IsAvailable() bool {
+	return u.IsActive && !u.OutOfOffice && u.UnderWeeklyQuota()
+}
+
+// UnderWeeklyQuota reports whether the user can be assigned one more review
+// without going over WeeklyReviewQuota.
+func (u User) UnderWeeklyQuota() bool {
+	return u.WeeklyReviewQuota == 0 || u.WeeklyReviews < u.WeeklyReviewQuota
+}
+
+// HasCapacity reports whether the user can take one more review without
+// going over MaxOpenReviews.
+func (u User) HasCapacity() bool {
+	return u.MaxOpenReviews == 0 || u.OpenReviews < u.MaxOpenReviews
+}
+
+// CapacityOverflow decides what reviewer selection does when every
+// available candidate is at their MaxOpenReviews.
+type CapacityOverflow string
+
+const (
+	// OverflowReject fails the selection with ALL_AT_CAPACITY. Bulk
+	// reassignments leave the slot empty instead.
+	OverflowReject CapacityOverflow = "reject"
+	// OverflowLeastLoaded picks the candidates with the fewest open reviews
+	// anyway.
+	OverflowLeastLoaded CapacityOverflow = "least_loaded"
+)
+
+// OutOfOfficePeriod is a window, in unix seconds with EndsAt exclusive, in
+// which the user is not picked as a reviewer. ReassignedAt is when their
+// open reviews were handed over after the window started; zero until then.
+type OutOfOfficePeriod struct {
+	ID           int64
+	UserID       string
+	StartsAt     int64
+	EndsAt       int64
+	Reason       string
+	ReassignedAt int64
+}
+
+// MemberRole is a user's role in one of their teams.
+type MemberRole string
+
+const (
+	RoleMember     MemberRole = "member"
+	RoleMaintainer MemberRole = "maintainer"
+	// RoleLead members are the mandatory reviewers of risky PRs.
+	RoleLead MemberRole = "lead"
+)
+
+func (r MemberRole) IsValid() bool {
+	switch r {
+	case RoleMember, RoleMaintainer, RoleLead:
+		return true
+	}
+	return false
 }
 
 type Team struct {
 	Name    string
 	Members []User
+	// ArchivedAt is unix seconds; zero for an active team.
+	ArchivedAt int64
+	// ParentTeam is the enclosing team (department); empty for a root team.
+	ParentTeam string
+	// MaxOpenReviews is the default cap of members without their own; zero
+	// means no default.
+	MaxOpenReviews int
+	// Subteams is filled only when the team is loaded with its subtree.
+	Subteams []Team
+}
+
+func (t Team) IsArchived() bool {
+	return t.ArchivedAt != 0
 }
 
 type PRStatus string
@@ -27,14 +142,294 @@ type PullRequest struct {
 	AssignedReviewers []string
 	CreatedAt         int64
 	MergedAt          int64
+	// Version is bumped by every mutation and backs optimistic locking.
+	Version int64
+	// TeamName is the team the PR was created under: the author's primary
+	// team or one of their other teams. Reviewers are picked from it.
+	TeamName string
+	Labels   []string
+	// GitHub is the pull request on GitHub the reviewers are synced to; a
+	// zero link means the PR is not synced.
+	GitHub GitHubLink
+}
+
+// GitHubLink identifies a pull request on GitHub: Repo is "owner/name".
+type GitHubLink struct {
+	Repo   string
+	Number int
 }
 
+func (l GitHubLink) IsZero() bool {
+	return l.Repo == "" && l.Number == 0
+}
+
+// LabelRisky marks a PR that must be reviewed by a lead of its team.
+const LabelRisky = "risky"
+
 func (p PullRequest) IsMerged() bool {
 	return p.Status == PRStatusMerged
 }
 
+func (p PullRequest) IsRisky() bool {
+	for _, l := range p.Labels {
+		if l == LabelRisky {
+			return true
+		}
+	}
+	return false
+}
+
+// PullRequestFilter selects pull requests to list. Empty fields match any
+// PR. Time bounds are unix seconds: From is inclusive, To is exclusive.
+type PullRequestFilter struct {
+	Status      PRStatus
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string
+	CreatedFrom int64
+	CreatedTo   int64
+	MergedFrom  int64
+	MergedTo    int64
+}
+
+// PullRequestCursor is the position of the last PR on a page. PRs are listed
+// newest first with ties broken by ID, so the next page starts strictly
+// after (CreatedAtMicro, ID).
+type PullRequestCursor struct {
+	CreatedAtMicro int64
+	ID             string
+}
+
+// ReviewFilter narrows the PRs a user is assigned to review. Since is unix
+// seconds (created at or after); zero fields match any PR.
+type ReviewFilter struct {
+	Status      PRStatus
+	Since       int64
+	OldestFirst bool
+	// IncludeReviewers loads AssignedReviewers; otherwise it stays nil.
+	IncludeReviewers bool
+}
+
+type PullRequestPage struct {
+	PullRequests []PullRequest
+	// Next is nil on the last page.
+	Next *PullRequestCursor
+}
+
+// TeamFilter narrows the teams listed; archived teams are never listed.
+// Zero fields match any team, and a nil MaxActiveMembers means no upper
+// bound.
+type TeamFilter struct {
+	NamePrefix       string
+	MinActiveMembers int
+	MaxActiveMembers *int
+}
+
+// TeamSummary is a team as listed: member counts instead of members.
+type TeamSummary struct {
+	Name              string
+	ParentTeam        string
+	MemberCount       int
+	ActiveMemberCount int
+}
+
+// TeamPage is a page of teams ordered by name. Next is the name of the last
+// team on the page, or "" on the last page.
+type TeamPage struct {
+	Teams []TeamSummary
+	Next  string
+}
+
+// UserFilter narrows the users listed. TeamName matches any of a user's
+// teams, a nil IsActive matches both states, and Username and
+// UsernameContains are case-insensitive. Zero fields match any user.
+type UserFilter struct {
+	TeamName         string
+	IsActive         *bool
+	Username         string
+	UsernameContains string
+}
+
+// UserPage is a page of users ordered by ID. Next is the ID of the last user
+// on the page, or "" on the last page.
+type UserPage struct {
+	Users []User
+	Next  string
+}
+
+// ReviewSummary counts the PRs a user is assigned to review: Open ones and
+// Completed, i.e. merged, ones.
+type ReviewSummary struct {
+	Open      int
+	Completed int
+}
+
+// UserDetails is a user with a summary of their reviews.
+type UserDetails struct {
+	User    User
+	Reviews ReviewSummary
+}
+
+// TeamMembershipResult is a team after a membership change, with the number
+// of open PRs whose reviewers were reassigned because members left.
+type TeamMembershipResult struct {
+	Team                Team
+	RemovedMembers      []string
+	UpdatedPullRequests int
+	Changes             []ReviewerChange
+}
+
+// ReviewerChange is an open PR whose reviewers a bulk reassignment replaced,
+// with the reviewers taken off it. Results that count UpdatedPullRequests
+// list them in Changes, so the new reviewers can be synced to the Git host.
+type ReviewerChange struct {
+	PullRequestID string
+	Removed       []string
+}
+
+// OpenReviewsPolicy decides what happens to the open reviews a user leaves
+// behind in their old team.
+type OpenReviewsPolicy string
+
+const (
+	// OpenReviewsReject refuses the change while such reviews exist.
+	OpenReviewsReject OpenReviewsPolicy = "reject"
+	// OpenReviewsReassign hands them to other members of the author's team.
+	OpenReviewsReassign OpenReviewsPolicy = "reassign"
+	// OpenReviewsKeep leaves the user assigned to them.
+	OpenReviewsKeep OpenReviewsPolicy = "keep"
+)
+
+type UserMoveResult struct {
+	User                User
+	FromTeam            string
+	UpdatedPullRequests int
+	Changes             []ReviewerChange
+}
+
 type TeamDeactivationResult struct {
 	TeamName            string
 	DeactivatedUsers    int
 	UpdatedPullRequests int
+	Changes             []ReviewerChange
+	// DeactivationID identifies the recorded run for /team/reactivate; zero
+	// when nobody was deactivated.
+	DeactivationID int64
+}
+
+// TeamDeactivation is a recorded deactivation run of a team with the users
+// it deactivated. Times are unix seconds; ReactivatedAt is zero until the
+// run is reverted.
+type TeamDeactivation struct {
+	ID            int64
+	TeamName      string
+	UserIDs       []string
+	DeactivatedAt int64
+	ReactivatedAt int64
+}
+
+func (d TeamDeactivation) IsReactivated() bool {
+	return d.ReactivatedAt != 0
+}
+
+type TeamReactivationResult struct {
+	TeamName         string
+	DeactivationID   int64
+	ReactivatedUsers []string
+	// UpdatedPullRequests counts open PRs that got missing reviewers when
+	// a refill was requested.
+	UpdatedPullRequests int
+	Changes             []ReviewerChange
+}
+
+type TeamArchiveResult struct {
+	TeamDeactivationResult
+	ArchivedAt int64
+}
+
+type TeamDeletionResult struct {
+	TeamName     string
+	DeletedUsers int
+}
+
+// RosterFormat is the file format of an imported or exported roster.
+type RosterFormat string
+
+const (
+	RosterFormatYAML RosterFormat = "yaml"
+	RosterFormatCSV  RosterFormat = "csv"
+)
+
+func (f RosterFormat) IsValid() bool {
+	return f == RosterFormatYAML || f == RosterFormatCSV
+}
+
+// RosterEntry is one row of a roster: a user and their membership in a team.
+// A user listed in several teams has one entry per team with the same
+// Username and IsActive. An empty Role means member for a new membership and
+// keeps the role of an existing one.
+type RosterEntry struct {
+	TeamName string
+	UserID   string
+	Username string
+	IsActive bool
+	Role     MemberRole
+}
+
+type RosterChangeKind string
+
+const (
+	RosterCreateTeam    RosterChangeKind = "create_team"
+	RosterCreateUser    RosterChangeKind = "create_user"
+	RosterUpdateUser    RosterChangeKind = "update_user"
+	RosterAddMembership RosterChangeKind = "add_membership"
+	RosterSetRole       RosterChangeKind = "set_role"
+)
+
+// RosterChange is one change an import makes; Detail describes it for
+// people, e.g. "member -> lead".
+type RosterChange struct {
+	Kind     RosterChangeKind
+	TeamName string
+	UserID   string
+	Detail   string
+}
+
+// RosterImportResult lists the changes of an import in the order they are
+// applied. With DryRun nothing was changed.
+type RosterImportResult struct {
+	DryRun  bool
+	Changes []RosterChange
+}
+
+type ReviewerSyncStatus string
+
+const (
+	ReviewerSyncPending ReviewerSyncStatus = "PENDING"
+	ReviewerSyncSynced  ReviewerSyncStatus = "SYNCED"
+	ReviewerSyncFailed  ReviewerSyncStatus = "FAILED"
+)
+
+type ReviewerSync struct {
+	PullRequestID string
+	Status        ReviewerSyncStatus
+	Attempts      int
+	LastError     string
+	UpdatedAt     int64
+}
+
+// IdempotencyRecord is a stored response for an Idempotency-Key. A record
+// that is not Completed yet belongs to a request that is still running.
+// ResponseHeaders holds the response headers that are replayed with the
+// body, such as Content-Type and ETag.
+type IdempotencyRecord struct {
+	Key             string
+	Endpoint        string
+	RequestHash     string
+	Completed       bool
+	StatusCode      int
+	ResponseHeaders map[string]string
+	ResponseBody    []byte
+	CreatedAt       int64
+	ExpiresAt       int64
 }
@@ -0,0 +1,121 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	BaseURL      string
+	Token        string
+	MaxAttempts  int
+	RetryBackoff time.Duration
+	Timeout      time.Duration
+}
+
+// Client talks to the GitHub REST "requested reviewers" endpoint.
+type Client struct {
+	httpClient   *http.Client
+	baseURL      string
+	token        string
+	maxAttempts  int
+	retryBackoff time.Duration
+}
+
+func NewClient(cfg Config) *Client {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	return &Client{
+		httpClient:   &http.Client{Timeout: cfg.Timeout},
+		baseURL:      strings.TrimRight(cfg.BaseURL, "/"),
+		token:        cfg.Token,
+		maxAttempts:  maxAttempts,
+		retryBackoff: cfg.RetryBackoff,
+	}
+}
+
+type requestedReviewersBody struct {
+	Reviewers []string `json:"reviewers"`
+}
+
+// RequestReviewers requests reviews on pull request number of repo
+// ("owner/name"). It returns the number of attempts made alongside the final
+// error.
+func (c *Client) RequestReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	return c.doWithRetry(ctx, http.MethodPost, repo, number, logins)
+}
+
+func (c *Client) RemoveRequestedReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	return c.doWithRetry(ctx, http.MethodDelete, repo, number, logins)
+}
+
+func (c *Client) doWithRetry(ctx context.Context, method, repo string, number int, logins []string) (int, error) {
+	body, err := json.Marshal(requestedReviewersBody{Reviewers: logins})
+	if err != nil {
+		return 0, fmt.Errorf("marshal requested reviewers: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		retryable, err := c.do(ctx, method, repo, number, body)
+		if err == nil {
+			return attempt, nil
+		}
+		lastErr = err
+		if !retryable || attempt == c.maxAttempts {
+			return attempt, lastErr
+		}
+
+		timer := time.NewTimer(c.retryBackoff * time.Duration(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, fmt.Errorf("%w (retry aborted: %w)", lastErr, ctx.Err())
+		case <-timer.C:
+		}
+	}
+	return c.maxAttempts, lastErr
+}
+
+func (c *Client) do(ctx context.Context, method, repo string, number int, body []byte) (bool, error) {
+	url := fmt.Sprintf("%s/repos/%s/pulls/%d/requested_reviewers", c.baseURL, repo, number)
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("build github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("github %s requested_reviewers: %w", method, err)
+	}
+	defer func() {
+		// #nosec G104 -- response body is drained best-effort
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("github %s requested_reviewers: status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(msg)))
+
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+	return retryable, err
+}
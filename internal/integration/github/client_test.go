@@ -0,0 +1,67 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClient_RequestReviewers(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantAttempts int
+	}{
+		{name: "успех с первой попытки", statuses: []int{http.StatusCreated}, wantAttempts: 1},
+		{name: "повтор после 502", statuses: []int{http.StatusBadGateway, http.StatusCreated}, wantAttempts: 2},
+		{name: "422 не повторяется", statuses: []int{http.StatusUnprocessableEntity}, wantErr: true, wantAttempts: 1},
+		{name: "все попытки исчерпаны", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, wantErr: true, wantAttempts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/repos/acme/api/pulls/12/requested_reviewers" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					t.Errorf("unexpected authorization header %q", r.Header.Get("Authorization"))
+				}
+				var body requestedReviewersBody
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("decode body: %v", err)
+				}
+				if len(body.Reviewers) != 2 {
+					t.Errorf("expected 2 reviewers, got %v", body.Reviewers)
+				}
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+			}))
+			defer srv.Close()
+
+			client := NewClient(Config{
+				BaseURL:      srv.URL,
+				Token:        "secret",
+				MaxAttempts:  3,
+				RetryBackoff: time.Millisecond,
+				Timeout:      time.Second,
+			})
+
+			attempts, err := client.RequestReviewers(context.Background(), "acme/api", 12, []string{"alice", "bob"})
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, attempts)
+			}
+		})
+	}
+}
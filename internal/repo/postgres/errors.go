@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// SQLSTATE codes of the constraint violations we translate.
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+type constraintViolation struct {
+	code domain.ErrorCode
+	msg  string
+}
+
+// constraintErrors maps constraint names (Postgres defaults for the tables in
+// migrations/) to the domain error a violation of that constraint means.
+var constraintErrors = map[string]constraintViolation{
+	"teams_pkey":                              {domain.ErrorCodeTeamExists, "team already exists"},
+	"pull_requests_pkey":                      {domain.ErrorCodePRExists, "pull request already exists"},
+	"pull_requests_github_link_key":           {domain.ErrorCodePRExists, "github pull request already linked"},
+	"users_pkey":                              {domain.ErrorCodeUserExists, "user already exists"},
+	"users_team_name_fkey":                    {domain.ErrorCodeNotFound, "team not found"},
+	"teams_parent_team_fkey":                  {domain.ErrorCodeNotFound, "parent team not found"},
+	"pull_requests_author_id_fkey":            {domain.ErrorCodeNotFound, "author not found"},
+	"pull_requests_team_name_fkey":            {domain.ErrorCodeNotFound, "team not found"},
+	"team_memberships_team_name_fkey":         {domain.ErrorCodeNotFound, "team not found"},
+	"team_memberships_user_id_fkey":           {domain.ErrorCodeNotFound, "user not found"},
+	"pull_request_reviewers_pr_id_fkey":       {domain.ErrorCodeNotFound, "pull request not found"},
+	"pull_request_reviewers_reviewer_id_fkey": {domain.ErrorCodeNotFound, "reviewer not found"},
+	"user_out_of_office_user_id_fkey":         {domain.ErrorCodeNotFound, "user not found"},
+	"user_working_hours_user_id_fkey":         {domain.ErrorCodeNotFound, "user not found"},
+}
+
+// constraintError translates a unique or foreign key violation into a domain
+// error. It returns nil for any other error.
+func constraintError(err error) *domain.DomainError {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	if pgErr.Code != pgUniqueViolation && pgErr.Code != pgForeignKeyViolation {
+		return nil
+	}
+
+	if v, ok := constraintErrors[pgErr.ConstraintName]; ok {
+		return domain.NewDomainError(v.code, v.msg)
+	}
+	if pgErr.Code == pgForeignKeyViolation {
+		return domain.NewDomainError(domain.ErrorCodeNotFound, "referenced resource not found")
+	}
+	return nil
+}
+
+// dbError returns the domain error for a constraint violation, or err
+// wrapped with msg otherwise.
+func dbError(msg string, err error) error {
+	if de := constraintError(err); de != nil {
+		return de
+	}
+	return fmt.Errorf("%s: %w", msg, err)
+}
+
+// violatedConstraint returns the name of the unique or foreign key
+// constraint err violates, or "" for any other error.
+func violatedConstraint(err error) string {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return ""
+	}
+	if pgErr.Code != pgUniqueViolation && pgErr.Code != pgForeignKeyViolation {
+		return ""
+	}
+	return pgErr.ConstraintName
+}
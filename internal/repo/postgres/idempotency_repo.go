@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type IdempotencyRepo struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepo(db *sql.DB) *IdempotencyRepo {
+	return &IdempotencyRepo{db: db}
+}
+
+// Claim inserts a pending record for the key. An expired record, or a
+// pending one created at or before staleBefore (unix seconds), is taken
+// over; any other is left alone and Claim reports false.
+func (r *IdempotencyRepo) Claim(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore int64) (bool, error) {
+	var key string
+	err := r.db.QueryRowContext(ctx,
+		`INSERT INTO idempotency_keys (key, endpoint, request_hash, completed, status_code, response_body, created_at, expires_at)
+         VALUES ($1, $2, $3, FALSE, 0, NULL, $4, $5)
+         ON CONFLICT (key, endpoint) DO UPDATE
+         SET request_hash = EXCLUDED.request_hash,
+             completed = FALSE,
+             status_code = 0,
+             response_headers = NULL,
+             response_body = NULL,
+             created_at = EXCLUDED.created_at,
+             expires_at = EXCLUDED.expires_at
+         WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+            OR (NOT idempotency_keys.completed AND idempotency_keys.created_at <= $6)
+         RETURNING key`,
+		rec.Key,
+		rec.Endpoint,
+		rec.RequestHash,
+		time.Unix(rec.CreatedAt, 0),
+		time.Unix(rec.ExpiresAt, 0),
+		time.Unix(staleBefore, 0),
+	).Scan(&key)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+	return true, nil
+}
+
+func (r *IdempotencyRepo) Get(ctx context.Context, key, endpoint string) (*domain.IdempotencyRecord, error) {
+	var (
+		rec       domain.IdempotencyRecord
+		headers   []byte
+		createdAt time.Time
+		expiresAt time.Time
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT key, endpoint, request_hash, completed, status_code, response_headers, response_body, created_at, expires_at
+         FROM idempotency_keys
+         WHERE key = $1 AND endpoint = $2`,
+		key,
+		endpoint,
+	).Scan(&rec.Key, &rec.Endpoint, &rec.RequestHash, &rec.Completed, &rec.StatusCode, &headers, &rec.ResponseBody, &createdAt, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+	if headers != nil {
+		if err := json.Unmarshal(headers, &rec.ResponseHeaders); err != nil {
+			return nil, fmt.Errorf("decode idempotency response headers: %w", err)
+		}
+	}
+
+	rec.CreatedAt = createdAt.Unix()
+	rec.ExpiresAt = expiresAt.Unix()
+	return &rec, nil
+}
+
+func (r *IdempotencyRepo) Complete(
+	ctx context.Context,
+	key, endpoint string,
+	statusCode int,
+	headers map[string]string,
+	body []byte,
+) error {
+	encoded, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("encode idempotency response headers: %w", err)
+	}
+
+	_, err = r.db.ExecContext(ctx,
+		`UPDATE idempotency_keys
+         SET completed = TRUE, status_code = $3, response_headers = $4, response_body = $5
+         WHERE key = $1 AND endpoint = $2`,
+		key,
+		endpoint,
+		statusCode,
+		encoded,
+		body,
+	)
+	if err != nil {
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release drops a pending record so the request can be retried with the
+// same key.
+func (r *IdempotencyRepo) Release(ctx context.Context, key, endpoint string) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys
+         WHERE key = $1 AND endpoint = $2 AND completed = FALSE`,
+		key,
+		endpoint,
+	)
+	if err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE expires_at <= $1`,
+		now,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys rows affected: %w", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// outOfOfficeNow is true for a user (u) inside one of their out-of-office
+// periods right now.
+const outOfOfficeNow = `EXISTS (
+              SELECT 1 FROM user_out_of_office o
+              WHERE o.user_id = u.id AND o.starts_at <= now() AND o.ends_at > now())`
+
+const outOfOfficeColumns = `id, user_id, starts_at, ends_at, reason, reassigned_at`
+
+type OutOfOfficeRepo struct {
+	db *sql.DB
+}
+
+func NewOutOfOfficeRepo(db *sql.DB) *OutOfOfficeRepo {
+	return &OutOfOfficeRepo{db: db}
+}
+
+// Create stores the period and returns it with its ID. An unknown user
+// yields NOT_FOUND.
+func (r *OutOfOfficeRepo) Create(ctx context.Context, p domain.OutOfOfficePeriod) (*domain.OutOfOfficePeriod, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx,
+		`INSERT INTO user_out_of_office (user_id, starts_at, ends_at, reason)
+         VALUES ($1, $2, $3, $4)
+         RETURNING `+outOfOfficeColumns,
+		p.UserID, time.Unix(p.StartsAt, 0), time.Unix(p.EndsAt, 0), p.Reason,
+	)
+	created, err := scanOutOfOffice(row)
+	if err != nil {
+		return nil, dbError("insert out-of-office period", err)
+	}
+	return created, nil
+}
+
+// ListByUser returns the user's periods ordered by start. Periods that have
+// already ended are left out unless includePast is set.
+func (r *OutOfOfficeRepo) ListByUser(ctx context.Context, userID string, includePast bool) ([]domain.OutOfOfficePeriod, error) {
+	query := `SELECT ` + outOfOfficeColumns + `
+        FROM user_out_of_office
+        WHERE user_id = $1`
+	if !includePast {
+		query += " AND ends_at > now()"
+	}
+	query += " ORDER BY starts_at, id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list out-of-office periods: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	periods := make([]domain.OutOfOfficePeriod, 0)
+	for rows.Next() {
+		p, err := scanOutOfOffice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan out-of-office period: %w", err)
+		}
+		periods = append(periods, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate out-of-office periods: %w", err)
+	}
+	return periods, nil
+}
+
+// Delete removes the user's period and returns it; a period of another
+// user is not found.
+func (r *OutOfOfficeRepo) Delete(ctx context.Context, userID string, id int64) (*domain.OutOfOfficePeriod, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx,
+		`DELETE FROM user_out_of_office
+         WHERE id = $1 AND user_id = $2
+         RETURNING `+outOfOfficeColumns,
+		id, userID,
+	)
+	p, err := scanOutOfOffice(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("delete out-of-office period: %w", err)
+	}
+	return p, nil
+}
+
+// ClaimStarted marks the periods that have started, have not ended and
+// were not handed over yet, and returns them. Rows locked by a concurrent
+// claim are skipped, so each period is claimed once.
+func (r *OutOfOfficeRepo) ClaimStarted(ctx context.Context) ([]domain.OutOfOfficePeriod, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`UPDATE user_out_of_office
+         SET reassigned_at = now()
+         WHERE id IN (
+             SELECT id FROM user_out_of_office
+             WHERE reassigned_at IS NULL AND starts_at <= now() AND ends_at > now()
+             FOR UPDATE SKIP LOCKED)
+         RETURNING `+outOfOfficeColumns,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim started out-of-office periods: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	periods := make([]domain.OutOfOfficePeriod, 0)
+	for rows.Next() {
+		p, err := scanOutOfOffice(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan out-of-office period: %w", err)
+		}
+		periods = append(periods, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate claimed out-of-office periods: %w", err)
+	}
+	return periods, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOutOfOffice(row rowScanner) (*domain.OutOfOfficePeriod, error) {
+	var (
+		p            domain.OutOfOfficePeriod
+		startsAt     time.Time
+		endsAt       time.Time
+		reassignedAt sql.NullTime
+	)
+	if err := row.Scan(&p.ID, &p.UserID, &startsAt, &endsAt, &p.Reason, &reassignedAt); err != nil {
+		return nil, err
+	}
+	p.StartsAt = startsAt.Unix()
+	p.EndsAt = endsAt.Unix()
+	if reassignedAt.Valid {
+		p.ReassignedAt = reassignedAt.Time.Unix()
+	}
+	return &p, nil
+}
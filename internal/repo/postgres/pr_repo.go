@@ -6,27 +6,49 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgtype"
+
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
 
 type PRRepo struct {
-	db *sql.DB
+	db       *sql.DB
+	overflow domain.CapacityOverflow
+	fairness time.Duration
+	nowFunc  func() time.Time
 }
 
 func NewPRRepo(db *sql.DB) *PRRepo {
-	return &PRRepo{db: db}
+	return &PRRepo{db: db, nowFunc: time.Now}
 }
 
-func (r *PRRepo) CreateWithReviewers(ctx context.Context, pr *domain.PullRequest, reviewerIDs []string) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin create PR tx: %w", err)
+// SetCapacityOverflow sets what bulk reassignments do with a slot nobody
+// with spare review capacity can fill: leave it empty (the default) or, with
+// domain.OverflowLeastLoaded, give it to the least loaded candidate.
+func (r *PRRepo) SetCapacityOverflow(o domain.CapacityOverflow) {
+	r.overflow = o
+}
+
+// SetFairness makes bulk reassignments weight candidates like PRService:
+// inversely to the reviews assigned to them over the window before nowFunc.
+// A zero window picks among candidates with equal chance. PRService reads
+// the same window through FairnessWindow.
+func (r *PRRepo) SetFairness(window time.Duration, nowFunc func() time.Time) {
+	if nowFunc == nil {
+		nowFunc = time.Now
 	}
-	defer func() {
-		// #nosec G104 -- error is ignored in defer rollback
-		_ = tx.Rollback()
-	}()
+	r.fairness = window
+	r.nowFunc = nowFunc
+}
+
+// FairnessWindow returns the window set by SetFairness.
+func (r *PRRepo) FairnessWindow() time.Duration {
+	return r.fairness
+}
 
+// CreateWithReviewers inserts the PR and its reviewers. A duplicate ID yields
+// a PR_EXISTS domain error, an unknown author, team or reviewer NOT_FOUND.
+func (r *PRRepo) CreateWithReviewers(ctx context.Context, pr *domain.PullRequest, reviewerIDs []string) error {
 	var createdAt *time.Time
 	if pr.CreatedAt != 0 {
 		t := time.Unix(pr.CreatedAt, 0)
@@ -37,56 +59,96 @@ func (r *PRRepo) CreateWithReviewers(ctx context.Context, pr *domain.PullRequest
 		t := time.Unix(pr.MergedAt, 0)
 		mergedAt = &t
 	}
-
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO pull_requests (id, name, author_id, status, created_at, merged_at)
-         VALUES ($1, $2, $3, $4, COALESCE($5, now()), $6)`,
-		pr.ID,
-		pr.Name,
-		pr.AuthorID,
-		string(pr.Status),
-		createdAt,
-		mergedAt,
-	)
-	if err != nil {
-		return fmt.Errorf("insert pull_request: %w", err)
+	labels := pr.Labels
+	if labels == nil {
+		labels = []string{}
 	}
 
-	for _, reviewerID := range reviewerIDs {
-		if _, err := tx.ExecContext(ctx,
-			`INSERT INTO pull_request_reviewers (pr_id, reviewer_id)
-             VALUES ($1, $2)`,
-			pr.ID, reviewerID,
-		); err != nil {
-			return fmt.Errorf("insert pull_request_reviewer %s: %w", reviewerID, err)
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO pull_requests (id, name, author_id, team_name, labels, status, created_at, merged_at, version,
+                                        github_repo, github_number)
+             VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, COALESCE($7, now()), $8, 1, NULLIF($9, ''), NULLIF($10, 0))`,
+			pr.ID,
+			pr.Name,
+			pr.AuthorID,
+			pr.TeamName,
+			labels,
+			string(pr.Status),
+			createdAt,
+			mergedAt,
+			pr.GitHub.Repo,
+			pr.GitHub.Number,
+		)
+		if err != nil {
+			return dbError("insert pull_request", err)
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit create PR tx: %w", err)
+		for _, reviewerID := range reviewerIDs {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO pull_request_reviewers (pr_id, reviewer_id)
+                 VALUES ($1, $2)`,
+				pr.ID, reviewerID,
+			); err != nil {
+				return dbError("insert pull_request_reviewer "+reviewerID, err)
+			}
+			if err := logAssignment(ctx, tx, pr.ID, reviewerID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	pr.Version = 1
 	return nil
 }
 
 func (r *PRRepo) GetByID(ctx context.Context, id string) (*domain.PullRequest, []string, error) {
-	row := r.db.QueryRowContext(ctx,
-		`SELECT id, name, author_id, status, created_at, merged_at
+	return r.getByID(ctx, id, false)
+}
+
+// WithLockedPR reads the PR with SELECT ... FOR UPDATE and runs fn in the
+// same transaction, so nothing else can change the PR between fn's checks
+// and its writes. Repository calls made with the ctx passed to fn join that
+// transaction.
+func (r *PRRepo) WithLockedPR(ctx context.Context, id string, fn func(ctx context.Context, pr *domain.PullRequest) error) error {
+	return withTx(ctx, r.db, func(ctx context.Context, _ *sql.Tx) error {
+		pr, _, err := r.getByID(ctx, id, true)
+		if err != nil {
+			return err
+		}
+		return fn(ctx, pr)
+	})
+}
+
+func (r *PRRepo) getByID(ctx context.Context, id string, forUpdate bool) (*domain.PullRequest, []string, error) {
+	query := `SELECT id, name, author_id, COALESCE(team_name, ''), labels, status, created_at, merged_at, version,
+                COALESCE(github_repo, ''), COALESCE(github_number, 0)
          FROM pull_requests
-         WHERE id = $1`,
-		id,
-	)
+         WHERE id = $1`
+	if forUpdate {
+		query += " FOR UPDATE"
+	}
+	row := conn(ctx, r.db).QueryRowContext(ctx, query, id)
 
 	var (
 		prID       string
 		name       string
 		authorID   string
+		teamName   string
+		labels     []string
 		statusStr  string
 		createdRaw sql.NullTime
 		mergedRaw  sql.NullTime
+		version    int64
+		github     domain.GitHubLink
 	)
 
-	if err := row.Scan(&prID, &name, &authorID, &statusStr, &createdRaw, &mergedRaw); err != nil {
+	if err := row.Scan(&prID, &name, &authorID, &teamName, pgtype.NewMap().SQLScanner(&labels),
+		&statusStr, &createdRaw, &mergedRaw, &version, &github.Repo, &github.Number); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil, sql.ErrNoRows
 		}
@@ -106,10 +168,14 @@ func (r *PRRepo) GetByID(ctx context.Context, id string) (*domain.PullRequest, [
 		ID:                prID,
 		Name:              name,
 		AuthorID:          authorID,
+		TeamName:          teamName,
+		Labels:            labels,
 		Status:            domain.PRStatus(statusStr),
 		AssignedReviewers: nil,
 		CreatedAt:         createdAt,
 		MergedAt:          mergedAt,
+		Version:           version,
+		GitHub:            github,
 	}
 
 	reviewers, err := r.loadReviewers(ctx, prID)
@@ -121,28 +187,37 @@ func (r *PRRepo) GetByID(ctx context.Context, id string) (*domain.PullRequest, [
 	return pr, reviewers, nil
 }
 
-func (r *PRRepo) SetMerged(ctx context.Context, id string, mergedAt time.Time) (*domain.PullRequest, []string, error) {
-	row := r.db.QueryRowContext(ctx,
+// SetMerged merges the PR if it is still at the given version. A stale
+// version yields a VERSION_CONFLICT domain error.
+func (r *PRRepo) SetMerged(ctx context.Context, id string, mergedAt time.Time, version int64) (*domain.PullRequest, []string, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx,
 		`UPDATE pull_requests
          SET status = 'MERGED',
-             merged_at = $2
-         WHERE id = $1
-         RETURNING id, name, author_id, status, created_at, merged_at`,
-		id, mergedAt,
+             merged_at = $2,
+             version = version + 1
+         WHERE id = $1 AND version = $3
+         RETURNING id, name, author_id, COALESCE(team_name, ''), labels, status, created_at, merged_at, version,
+                   COALESCE(github_repo, ''), COALESCE(github_number, 0)`,
+		id, mergedAt, version,
 	)
 
 	var (
 		prID       string
 		name       string
 		authorID   string
+		teamName   string
+		labels     []string
 		statusStr  string
 		createdRaw sql.NullTime
 		mergedRaw  sql.NullTime
+		newVersion int64
+		github     domain.GitHubLink
 	)
 
-	if err := row.Scan(&prID, &name, &authorID, &statusStr, &createdRaw, &mergedRaw); err != nil {
+	if err := row.Scan(&prID, &name, &authorID, &teamName, pgtype.NewMap().SQLScanner(&labels),
+		&statusStr, &createdRaw, &mergedRaw, &newVersion, &github.Repo, &github.Number); err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil, sql.ErrNoRows
+			return nil, nil, r.missingOrConflict(ctx, id)
 		}
 		return nil, nil, fmt.Errorf("set merged: %w", err)
 	}
@@ -160,10 +235,14 @@ func (r *PRRepo) SetMerged(ctx context.Context, id string, mergedAt time.Time) (
 		ID:                prID,
 		Name:              name,
 		AuthorID:          authorID,
+		TeamName:          teamName,
+		Labels:            labels,
 		Status:            domain.PRStatus(statusStr),
 		AssignedReviewers: nil,
 		CreatedAt:         createdAt,
 		MergedAt:          mergedAtUnix,
+		Version:           newVersion,
+		GitHub:            github,
 	}
 
 	reviewers, err := r.loadReviewers(ctx, prID)
@@ -175,35 +254,31 @@ func (r *PRRepo) SetMerged(ctx context.Context, id string, mergedAt time.Time) (
 	return pr, reviewers, nil
 }
 
-func (r *PRRepo) UpdateReviewers(ctx context.Context, id string, reviewerIDs []string) (*domain.PullRequest, []string, error) {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("begin update reviewers tx: %w", err)
-	}
-	defer func() {
-		// #nosec G104 -- error is ignored in defer rollback
-		_ = tx.Rollback()
-	}()
-
-	if _, err := tx.ExecContext(ctx,
-		`DELETE FROM pull_request_reviewers WHERE pr_id = $1`,
-		id,
-	); err != nil {
-		return nil, nil, fmt.Errorf("delete old reviewers: %w", err)
-	}
-
-	for _, reviewerID := range reviewerIDs {
-		if _, err := tx.ExecContext(ctx,
-			`INSERT INTO pull_request_reviewers (pr_id, reviewer_id)
-             VALUES ($1, $2)`,
-			id, reviewerID,
-		); err != nil {
-			return nil, nil, fmt.Errorf("insert new reviewer %s: %w", reviewerID, err)
+// UpdateReviewers replaces the reviewer set if the PR is still at the given
+// version. A stale version yields a VERSION_CONFLICT domain error.
+func (r *PRRepo) UpdateReviewers(ctx context.Context, id string, reviewerIDs []string, version int64) (*domain.PullRequest, []string, error) {
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			`UPDATE pull_requests
+             SET version = version + 1
+             WHERE id = $1 AND version = $2`,
+			id, version,
+		)
+		if err != nil {
+			return fmt.Errorf("bump PR version: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("bump PR version rows affected: %w", err)
+		}
+		if affected == 0 {
+			return r.missingOrConflict(ctx, id)
 		}
-	}
 
-	if err := tx.Commit(); err != nil {
-		return nil, nil, fmt.Errorf("commit update reviewers tx: %w", err)
+		return replaceReviewers(ctx, tx, id, reviewerIDs)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
 
 	pr, reviewers, err := r.GetByID(ctx, id)
@@ -213,68 +288,58 @@ func (r *PRRepo) UpdateReviewers(ctx context.Context, id string, reviewerIDs []s
 	return pr, reviewers, nil
 }
 
-func (r *PRRepo) ListByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
-	dbRows, err := r.db.QueryContext(ctx,
-		`SELECT p.id, p.name, p.author_id, p.status, p.created_at, p.merged_at
-         FROM pull_requests p
-         INNER JOIN pull_request_reviewers r
-             ON p.id = r.pr_id
-         WHERE r.reviewer_id = $1
-         ORDER BY p.created_at DESC`,
-		userID,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("list pull_requests by reviewer: %w", err)
+// replaceReviewers makes reviewerIDs the reviewers of the PR. Reviewers who
+// stay keep their row; only the newly added ones are logged as assigned.
+func replaceReviewers(ctx context.Context, tx *sql.Tx, prID string, reviewerIDs []string) error {
+	if reviewerIDs == nil {
+		reviewerIDs = []string{}
 	}
-	defer func() {
-		if err := dbRows.Close(); err != nil {
-			// Log error but don't fail - rows are already read
-		}
-	}()
 
-	result := make([]domain.PullRequest, 0)
-	for dbRows.Next() {
-		var (
-			prID       string
-			name       string
-			authorID   string
-			statusStr  string
-			createdRaw sql.NullTime
-			mergedRaw  sql.NullTime
-		)
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM pull_request_reviewers WHERE pr_id = $1 AND reviewer_id <> ALL($2)`,
+		prID, reviewerIDs,
+	); err != nil {
+		return fmt.Errorf("delete old reviewers: %w", err)
+	}
 
-		if err := dbRows.Scan(&prID, &name, &authorID, &statusStr, &createdRaw, &mergedRaw); err != nil {
-			return nil, fmt.Errorf("scan pull_request: %w", err)
+	for _, reviewerID := range reviewerIDs {
+		result, err := tx.ExecContext(ctx,
+			`INSERT INTO pull_request_reviewers (pr_id, reviewer_id)
+             VALUES ($1, $2)
+             ON CONFLICT (pr_id, reviewer_id) DO NOTHING`,
+			prID, reviewerID,
+		)
+		if err != nil {
+			return dbError("insert new reviewer "+reviewerID, err)
 		}
-
-		var createdAt int64
-		if createdRaw.Valid {
-			createdAt = createdRaw.Time.Unix()
+		added, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("insert new reviewer rows affected: %w", err)
 		}
-		var mergedAt int64
-		if mergedRaw.Valid {
-			mergedAt = mergedRaw.Time.Unix()
+		if added == 0 {
+			continue
 		}
-
-		pr := domain.PullRequest{
-			ID:        prID,
-			Name:      name,
-			AuthorID:  authorID,
-			Status:    domain.PRStatus(statusStr),
-			CreatedAt: createdAt,
-			MergedAt:  mergedAt,
+		if err := logAssignment(ctx, tx, prID, reviewerID); err != nil {
+			return err
 		}
-		result = append(result, pr)
-	}
-	if err := dbRows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate pull_requests by reviewer: %w", err)
 	}
+	return nil
+}
 
-	return result, nil
+// logAssignment appends the assignment of a reviewer to the PR to
+// review_assignments, which keeps it after the reviewer is taken off.
+func logAssignment(ctx context.Context, tx *sql.Tx, prID, reviewerID string) error {
+	if _, err := tx.ExecContext(ctx,
+		`INSERT INTO review_assignments (pr_id, reviewer_id) VALUES ($1, $2)`,
+		prID, reviewerID,
+	); err != nil {
+		return dbError("log review assignment "+reviewerID, err)
+	}
+	return nil
 }
 
 func (r *PRRepo) loadReviewers(ctx context.Context, prID string) ([]string, error) {
-	dbRows, err := r.db.QueryContext(ctx,
+	dbRows, err := conn(ctx, r.db).QueryContext(ctx,
 		`SELECT reviewer_id
          FROM pull_request_reviewers
          WHERE pr_id = $1
@@ -305,9 +370,21 @@ func (r *PRRepo) loadReviewers(ctx context.Context, prID string) ([]string, erro
 	return reviewers, nil
 }
 
+// missingOrConflict explains why a versioned update matched no rows.
+func (r *PRRepo) missingOrConflict(ctx context.Context, id string) error {
+	exists, err := r.Exists(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return sql.ErrNoRows
+	}
+	return domain.NewDomainError(domain.ErrorCodeVersionConflict, "pull request was modified concurrently")
+}
+
 func (r *PRRepo) Exists(ctx context.Context, id string) (bool, error) {
 	var dummy string
-	err := r.db.QueryRowContext(ctx,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
 		`SELECT id FROM pull_requests WHERE id = $1`,
 		id,
 	).Scan(&dummy)
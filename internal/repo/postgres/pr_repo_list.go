@@ -0,0 +1,292 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// List returns up to limit PRs matching filter, newest first, starting after
+// the given cursor (nil for the first page). Reviewers are loaded for every
+// PR on the page.
+func (r *PRRepo) List(
+	ctx context.Context,
+	filter domain.PullRequestFilter,
+	after *domain.PullRequestCursor,
+	limit int,
+) (domain.PullRequestPage, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Status != "" {
+		conds = append(conds, "p.status = "+arg(string(filter.Status)))
+	}
+	if filter.AuthorID != "" {
+		conds = append(conds, "p.author_id = "+arg(filter.AuthorID))
+	}
+	if filter.ReviewerID != "" {
+		conds = append(conds, `EXISTS (
+            SELECT 1 FROM pull_request_reviewers r
+            WHERE r.pr_id = p.id AND r.reviewer_id = `+arg(filter.ReviewerID)+`)`)
+	}
+	if filter.TeamName != "" {
+		conds = append(conds, "p.team_name = "+arg(filter.TeamName))
+	}
+	if filter.CreatedFrom != 0 {
+		conds = append(conds, "p.created_at >= "+arg(time.Unix(filter.CreatedFrom, 0)))
+	}
+	if filter.CreatedTo != 0 {
+		conds = append(conds, "p.created_at < "+arg(time.Unix(filter.CreatedTo, 0)))
+	}
+	if filter.MergedFrom != 0 {
+		conds = append(conds, "p.merged_at >= "+arg(time.Unix(filter.MergedFrom, 0)))
+	}
+	if filter.MergedTo != 0 {
+		conds = append(conds, "p.merged_at < "+arg(time.Unix(filter.MergedTo, 0)))
+	}
+	if after != nil {
+		conds = append(conds, fmt.Sprintf("(p.created_at, p.id) < (%s, %s)",
+			arg(time.UnixMicro(after.CreatedAtMicro)), arg(after.ID)))
+	}
+
+	query := `SELECT p.id, p.name, p.author_id, COALESCE(p.team_name, ''), p.labels, p.status, p.created_at, p.merged_at, p.version,
+                COALESCE(p.github_repo, ''), COALESCE(p.github_number, 0)
+         FROM pull_requests p`
+	if len(conds) > 0 {
+		query += "\n         WHERE " + strings.Join(conds, "\n           AND ")
+	}
+	// One extra row tells whether there is a next page.
+	query += "\n         ORDER BY p.created_at DESC, p.id DESC\n         LIMIT " + arg(limit+1)
+
+	dbRows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.PullRequestPage{}, fmt.Errorf("list pull_requests: %w", err)
+	}
+	defer func() {
+		if err := dbRows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	var (
+		page      domain.PullRequestPage
+		createdAt []time.Time
+		typeMap   = pgtype.NewMap()
+	)
+	page.PullRequests = make([]domain.PullRequest, 0, limit)
+	for dbRows.Next() {
+		var (
+			pr         domain.PullRequest
+			statusStr  string
+			createdRaw sql.NullTime
+			mergedRaw  sql.NullTime
+		)
+		if err := dbRows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, typeMap.SQLScanner(&pr.Labels), &statusStr, &createdRaw, &mergedRaw, &pr.Version,
+			&pr.GitHub.Repo, &pr.GitHub.Number); err != nil {
+			return domain.PullRequestPage{}, fmt.Errorf("scan pull_request: %w", err)
+		}
+		pr.Status = domain.PRStatus(statusStr)
+		if createdRaw.Valid {
+			pr.CreatedAt = createdRaw.Time.Unix()
+		}
+		if mergedRaw.Valid {
+			pr.MergedAt = mergedRaw.Time.Unix()
+		}
+		page.PullRequests = append(page.PullRequests, pr)
+		createdAt = append(createdAt, createdRaw.Time)
+	}
+	if err := dbRows.Err(); err != nil {
+		return domain.PullRequestPage{}, fmt.Errorf("iterate pull_requests: %w", err)
+	}
+
+	if len(page.PullRequests) > limit {
+		page.PullRequests = page.PullRequests[:limit]
+		last := page.PullRequests[limit-1]
+		page.Next = &domain.PullRequestCursor{
+			CreatedAtMicro: createdAt[limit-1].UnixMicro(),
+			ID:             last.ID,
+		}
+	}
+
+	if err := r.loadPageReviewers(ctx, page.PullRequests); err != nil {
+		return domain.PullRequestPage{}, err
+	}
+	return page, nil
+}
+
+func (r *PRRepo) loadPageReviewers(ctx context.Context, prs []domain.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(prs))
+	ids := make([]string, 0, len(prs))
+	for i := range prs {
+		prs[i].AssignedReviewers = make([]string, 0, 2)
+		index[prs[i].ID] = i
+		ids = append(ids, prs[i].ID)
+	}
+
+	query, args := buildInClause(`
+        SELECT pr_id, reviewer_id
+        FROM pull_request_reviewers
+        WHERE pr_id IN (`, ids)
+	query += " ORDER BY pr_id, reviewer_id"
+
+	dbRows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("list page reviewers: %w", err)
+	}
+	defer func() {
+		if err := dbRows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for dbRows.Next() {
+		var prID, reviewerID string
+		if err := dbRows.Scan(&prID, &reviewerID); err != nil {
+			return fmt.Errorf("scan page reviewer: %w", err)
+		}
+		if i, ok := index[prID]; ok {
+			prs[i].AssignedReviewers = append(prs[i].AssignedReviewers, reviewerID)
+		}
+	}
+	if err := dbRows.Err(); err != nil {
+		return fmt.Errorf("iterate page reviewers: %w", err)
+	}
+	return nil
+}
+
+// ListByReviewer returns up to limit PRs userID is assigned to, newest first
+// unless filter.OldestFirst, starting after the given cursor. Co-reviewers
+// are aggregated in the same query when filter.IncludeReviewers is set.
+func (r *PRRepo) ListByReviewer(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+	after *domain.PullRequestCursor,
+	limit int,
+) (domain.PullRequestPage, error) {
+	args := []any{userID}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	reviewersCol := `ARRAY[]::text[]`
+	if filter.IncludeReviewers {
+		reviewersCol = `ARRAY(
+                SELECT r2.reviewer_id FROM pull_request_reviewers r2
+                WHERE r2.pr_id = p.id
+                ORDER BY r2.reviewer_id)`
+	}
+
+	query := `SELECT p.id, p.name, p.author_id, COALESCE(p.team_name, ''), p.labels, p.status, p.created_at, p.merged_at, p.version,
+                COALESCE(p.github_repo, ''), COALESCE(p.github_number, 0),
+             ` + reviewersCol + `
+         FROM pull_requests p
+         INNER JOIN pull_request_reviewers r
+             ON p.id = r.pr_id
+         WHERE r.reviewer_id = $1`
+	if filter.Status != "" {
+		query += "\n           AND p.status = " + arg(string(filter.Status))
+	}
+	if filter.Since != 0 {
+		query += "\n           AND p.created_at >= " + arg(time.Unix(filter.Since, 0))
+	}
+
+	cmp, order := "<", "DESC"
+	if filter.OldestFirst {
+		cmp, order = ">", "ASC"
+	}
+	if after != nil {
+		query += fmt.Sprintf("\n           AND (p.created_at, p.id) %s (%s, %s)",
+			cmp, arg(time.UnixMicro(after.CreatedAtMicro)), arg(after.ID))
+	}
+	query += fmt.Sprintf("\n         ORDER BY p.created_at %s, p.id %s\n         LIMIT %s", order, order, arg(limit+1))
+
+	dbRows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.PullRequestPage{}, fmt.Errorf("list pull_requests by reviewer: %w", err)
+	}
+	defer func() {
+		if err := dbRows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	var (
+		page      domain.PullRequestPage
+		createdAt []time.Time
+		typeMap   = pgtype.NewMap()
+	)
+	page.PullRequests = make([]domain.PullRequest, 0, limit)
+	for dbRows.Next() {
+		var (
+			pr         domain.PullRequest
+			statusStr  string
+			createdRaw sql.NullTime
+			mergedRaw  sql.NullTime
+			reviewers  []string
+		)
+		if err := dbRows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.TeamName, typeMap.SQLScanner(&pr.Labels), &statusStr, &createdRaw, &mergedRaw, &pr.Version,
+			&pr.GitHub.Repo, &pr.GitHub.Number, typeMap.SQLScanner(&reviewers)); err != nil {
+			return domain.PullRequestPage{}, fmt.Errorf("scan pull_request: %w", err)
+		}
+		pr.Status = domain.PRStatus(statusStr)
+		if createdRaw.Valid {
+			pr.CreatedAt = createdRaw.Time.Unix()
+		}
+		if mergedRaw.Valid {
+			pr.MergedAt = mergedRaw.Time.Unix()
+		}
+		if filter.IncludeReviewers {
+			pr.AssignedReviewers = append(make([]string, 0, len(reviewers)), reviewers...)
+		}
+		page.PullRequests = append(page.PullRequests, pr)
+		createdAt = append(createdAt, createdRaw.Time)
+	}
+	if err := dbRows.Err(); err != nil {
+		return domain.PullRequestPage{}, fmt.Errorf("iterate pull_requests by reviewer: %w", err)
+	}
+
+	if len(page.PullRequests) > limit {
+		page.PullRequests = page.PullRequests[:limit]
+		page.Next = &domain.PullRequestCursor{
+			CreatedAtMicro: createdAt[limit-1].UnixMicro(),
+			ID:             page.PullRequests[limit-1].ID,
+		}
+	}
+	return page, nil
+}
+
+// ReviewSummary counts the PRs userID is currently assigned to review, open
+// and merged.
+func (r *PRRepo) ReviewSummary(ctx context.Context, userID string) (domain.ReviewSummary, error) {
+	var summary domain.ReviewSummary
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT count(*) FILTER (WHERE p.status = $2),
+                count(*) FILTER (WHERE p.status = $3)
+         FROM pull_request_reviewers r
+         JOIN pull_requests p ON p.id = r.pr_id
+         WHERE r.reviewer_id = $1`,
+		userID, string(domain.PRStatusOpen), string(domain.PRStatusMerged),
+	).Scan(&summary.Open, &summary.Completed)
+	if err != nil {
+		return domain.ReviewSummary{}, fmt.Errorf("summarize reviews: %w", err)
+	}
+	return summary, nil
+}
@@ -3,10 +3,11 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 func (r *PRRepo) CountAssignmentsByReviewer(ctx context.Context) (map[string]int64, error) {
-	rows, err := r.db.QueryContext(ctx,
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
 		`SELECT reviewer_id, COUNT(*) AS cnt
          FROM pull_request_reviewers
          GROUP BY reviewer_id
@@ -40,7 +41,7 @@ func (r *PRRepo) CountAssignmentsByReviewer(ctx context.Context) (map[string]int
 }
 
 func (r *PRRepo) CountAssignmentsByPR(ctx context.Context) (map[string]int64, error) {
-	rows, err := r.db.QueryContext(ctx,
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
 		`SELECT pr_id, COUNT(*) AS cnt
          FROM pull_request_reviewers
          GROUP BY pr_id
@@ -72,3 +73,46 @@ func (r *PRRepo) CountAssignmentsByPR(ctx context.Context) (map[string]int64, er
 
 	return result, nil
 }
+
+// CountAssignmentsSince returns how many reviews were assigned to each of
+// userIDs at or after since, from the review_assignments log, so reviews
+// later taken away from a user still count. Users without such reviews are
+// left out.
+func (r *PRRepo) CountAssignmentsSince(ctx context.Context, userIDs []string, since time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(userIDs) == 0 {
+		return counts, nil
+	}
+
+	query, args := buildInClause(`
+        SELECT reviewer_id, count(*)
+        FROM review_assignments
+        WHERE reviewer_id IN (`, userIDs)
+	query += fmt.Sprintf(" AND assigned_at >= $%d GROUP BY reviewer_id", len(args)+1)
+	args = append(args, since)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("count recent assignments: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			id string
+			n  int
+		)
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan recent assignments: %w", err)
+		}
+		counts[id] = n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate recent assignments: %w", err)
+	}
+	return counts, nil
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
@@ -14,79 +17,217 @@ func (r *PRRepo) DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName
 		TeamName: teamName,
 	}
 
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return result, fmt.Errorf("begin tx deactivate team %s: %w", teamName, err)
-	}
-	defer func() {
-		// #nosec G104 -- error is ignored in defer rollback
-		_ = tx.Rollback()
-	}()
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if err := r.checkTeamExists(ctx, tx, teamName); err != nil {
+			return err
+		}
 
-	if err := r.checkTeamExists(ctx, tx, teamName); err != nil {
-		return result, err
-	}
+		deactivatedIDs, err := r.deactivateUsers(ctx, tx, teamName)
+		if err != nil {
+			return err
+		}
+
+		result.DeactivatedUsers = len(deactivatedIDs)
+
+		result.DeactivationID, err = r.recordDeactivation(ctx, tx, teamName, deactivatedIDs)
+		if err != nil {
+			return err
+		}
 
-	deactivatedIDs, err := r.deactivateUsers(ctx, tx, teamName)
+		changes, err := r.reassignReviewsOf(ctx, tx, deactivatedIDs, false)
+		if err != nil {
+			return err
+		}
+		result.UpdatedPullRequests = len(changes)
+		result.Changes = changes
+		return nil
+	})
 	if err != nil {
 		return result, err
 	}
 
-	result.DeactivatedUsers = len(deactivatedIDs)
-	if len(deactivatedIDs) == 0 {
-		if err := tx.Commit(); err != nil {
-			return result, fmt.Errorf("commit empty deactivation tx: %w", err)
+	return result, nil
+}
+
+// RefillOpenPRs tops up open PRs created under teamName that have fewer than
+// two reviewers, picking available members of the team (or of the teams
+// above it, as in calculateNewReviewers). It returns the PRs changed.
+func (r *PRRepo) RefillOpenPRs(ctx context.Context, teamName string) ([]domain.ReviewerChange, error) {
+	var changes []domain.ReviewerChange
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		prMap, err := r.loadUnderstaffedPRs(ctx, tx, teamName)
+		if err != nil {
+			return err
+		}
+		if len(prMap) == 0 {
+			return nil
+		}
+
+		if err := r.loadCurrentReviewers(ctx, tx, prMap); err != nil {
+			return err
+		}
+
+		pool, err := r.loadCandidates(ctx, tx, prMap)
+		if err != nil {
+			return err
 		}
-		return result, nil
+
+		newReviewersByPR := r.calculateNewReviewers(prMap, pool)
+		for prID, reviewers := range newReviewersByPR {
+			// Nobody new to pick: leave the PR and its version alone.
+			if len(reviewers) == len(prMap[prID].current) {
+				delete(newReviewersByPR, prID)
+			}
+		}
+
+		if err := r.updatePRReviewers(ctx, tx, newReviewersByPR); err != nil {
+			return err
+		}
+		changes = reviewerChanges(prMap, newReviewersByPR)
+		return nil
+	})
+	return changes, err
+}
+
+// ReassignOpenReviews takes userIDs off the open PRs they review outside
+// their own teams (every PR for users without a team) and refills those PRs
+// from the available members of the PR's team. Call it after the users
+// have left or changed team. It returns the PRs changed.
+func (r *PRRepo) ReassignOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error) {
+	var changes []domain.ReviewerChange
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		changes, err = r.reassignReviewsOf(ctx, tx, userIDs, true)
+		return err
+	})
+	return changes, err
+}
+
+// HandOverOpenReviews takes userIDs off every open PR they review and
+// refills those PRs from the available members of the PR's team, e.g. when
+// the users go out of office. It returns the PRs changed.
+func (r *PRRepo) HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error) {
+	var changes []domain.ReviewerChange
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		var err error
+		changes, err = r.reassignReviewsOf(ctx, tx, userIDs, false)
+		return err
+	})
+	return changes, err
+}
+
+// OpenReviewCounts returns how many open PRs outside their own teams each of
+// userIDs reviews, i.e. the PRs ReassignOpenReviews would change. Users
+// without such reviews are left out.
+func (r *PRRepo) OpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	counts := make(map[string]int)
+	if len(userIDs) == 0 {
+		return counts, nil
 	}
 
-	prMap, err := r.loadAffectedPRs(ctx, tx, deactivatedIDs)
+	query, args := buildInClause(`
+        SELECT r.reviewer_id, count(*)
+        FROM pull_request_reviewers r
+        JOIN pull_requests p ON p.id = r.pr_id
+        WHERE p.status = 'OPEN' AND r.reviewer_id IN (`, userIDs)
+	query += foreignReviewCond + " GROUP BY r.reviewer_id"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
-		return result, err
+		return nil, fmt.Errorf("count open reviews: %w", err)
 	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
 
-	if len(prMap) == 0 {
-		if err := tx.Commit(); err != nil {
-			return result, fmt.Errorf("commit tx without pr updates: %w", err)
+	for rows.Next() {
+		var (
+			id string
+			n  int
+		)
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, fmt.Errorf("scan open review count: %w", err)
 		}
-		return result, nil
+		counts[id] = n
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate open review counts: %w", err)
+	}
+	return counts, nil
+}
 
-	if err := r.loadCurrentReviewers(ctx, tx, prMap); err != nil {
-		return result, err
+// foreignReviewCond keeps the reviews (r) of PRs (p) created under a team
+// the reviewer is not a member of. A NULL team_name never matches, so every
+// review of a PR without a team counts as foreign.
+const foreignReviewCond = `
+          AND NOT EXISTS (
+              SELECT 1 FROM team_memberships m
+              WHERE m.team_name = p.team_name AND m.user_id = r.reviewer_id)`
+
+// reassignReviewsOf replaces userIDs on open PRs; with foreignOnly only on
+// PRs outside their own team.
+func (r *PRRepo) reassignReviewsOf(ctx context.Context, tx *sql.Tx, userIDs []string, foreignOnly bool) ([]domain.ReviewerChange, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
 	}
 
-	authorTeam, err := r.loadAuthorTeams(ctx, tx, prMap)
+	prMap, err := r.loadAffectedPRs(ctx, tx, userIDs, foreignOnly)
 	if err != nil {
-		return result, err
+		return nil, err
+	}
+	if len(prMap) == 0 {
+		return nil, nil
 	}
 
-	candidatesByTeam, err := r.loadCandidates(ctx, tx, authorTeam)
+	if err := r.loadCurrentReviewers(ctx, tx, prMap); err != nil {
+		return nil, err
+	}
+
+	pool, err := r.loadCandidates(ctx, tx, prMap)
 	if err != nil {
-		return result, err
+		return nil, err
 	}
 
-	newReviewersByPR := r.calculateNewReviewers(prMap, authorTeam, candidatesByTeam)
+	newReviewersByPR := r.calculateNewReviewers(prMap, pool)
 
 	if err := r.updatePRReviewers(ctx, tx, newReviewersByPR); err != nil {
-		return result, err
+		return nil, err
 	}
+	return reviewerChanges(prMap, newReviewersByPR), nil
+}
 
-	result.UpdatedPullRequests = len(newReviewersByPR)
-
-	if err := tx.Commit(); err != nil {
-		return result, fmt.Errorf("commit deactivate team tx: %w", err)
+// reviewerChanges lists the PRs in newReviewersByPR by ID, each with the
+// reviewers it lost.
+func reviewerChanges(prMap map[string]*prInfo, newReviewersByPR map[string][]string) []domain.ReviewerChange {
+	changes := make([]domain.ReviewerChange, 0, len(newReviewersByPR))
+	for prID, reviewers := range newReviewersByPR {
+		change := domain.ReviewerChange{PullRequestID: prID}
+		for _, id := range prMap[prID].current {
+			if !slices.Contains(reviewers, id) {
+				change.Removed = append(change.Removed, id)
+			}
+		}
+		changes = append(changes, change)
 	}
-
-	return result, nil
+	slices.SortFunc(changes, func(a, b domain.ReviewerChange) int {
+		return strings.Compare(a.PullRequestID, b.PullRequestID)
+	})
+	return changes
 }
 
 type prInfo struct {
 	authorID    string
+	teamName    string
+	risky       bool
 	deactivated map[string]struct{}
 	current     []string
 }
 
+// riskyPR is true for a PR (p) labelled domain.LabelRisky.
+const riskyPR = `'` + domain.LabelRisky + `' = ANY(p.labels)`
+
 func (r *PRRepo) checkTeamExists(ctx context.Context, tx *sql.Tx, teamName string) error {
 	var tmp string
 	if err := tx.QueryRowContext(ctx,
@@ -133,12 +274,90 @@ func (r *PRRepo) deactivateUsers(ctx context.Context, tx *sql.Tx, teamName strin
 	return deactivatedIDs, nil
 }
 
-func (r *PRRepo) loadAffectedPRs(ctx context.Context, tx *sql.Tx, deactivatedIDs []string) (map[string]*prInfo, error) {
+// recordDeactivation stores the run so /team/reactivate can restore exactly
+// these users. Nothing is recorded when nobody was deactivated.
+func (r *PRRepo) recordDeactivation(ctx context.Context, tx *sql.Tx, teamName string, userIDs []string) (int64, error) {
+	if len(userIDs) == 0 {
+		return 0, nil
+	}
+
+	var id int64
+	if err := tx.QueryRowContext(ctx,
+		`INSERT INTO team_deactivations (team_name) VALUES ($1) RETURNING id`,
+		teamName,
+	).Scan(&id); err != nil {
+		return 0, fmt.Errorf("record deactivation of team %s: %w", teamName, err)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO team_deactivation_users (deactivation_id, user_id)
+             VALUES ($1, $2)`,
+			id, userID,
+		); err != nil {
+			return 0, fmt.Errorf("record deactivated user %s: %w", userID, err)
+		}
+	}
+	return id, nil
+}
+
+// loadUnderstaffedPRs locks the open PRs created under teamName that have
+// fewer than two reviewers.
+func (r *PRRepo) loadUnderstaffedPRs(ctx context.Context, tx *sql.Tx, teamName string) (map[string]*prInfo, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT p.id, p.author_id, `+riskyPR+`
+         FROM pull_requests p
+         WHERE p.status = 'OPEN'
+           AND p.team_name = $1
+           AND (SELECT count(*) FROM pull_request_reviewers r WHERE r.pr_id = p.id) < 2
+         FOR UPDATE OF p`,
+		teamName,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("select understaffed pull requests: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	prMap := make(map[string]*prInfo)
+	for rows.Next() {
+		var (
+			prID     string
+			authorID string
+			risky    bool
+		)
+		if err := rows.Scan(&prID, &authorID, &risky); err != nil {
+			return nil, fmt.Errorf("scan understaffed pr row: %w", err)
+		}
+		prMap[prID] = &prInfo{
+			authorID:    authorID,
+			teamName:    teamName,
+			risky:       risky,
+			deactivated: make(map[string]struct{}),
+			current:     make([]string, 0, 2),
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate understaffed prs: %w", err)
+	}
+	return prMap, nil
+}
+
+func (r *PRRepo) loadAffectedPRs(ctx context.Context, tx *sql.Tx, reviewerIDs []string, foreignOnly bool) (map[string]*prInfo, error) {
 	query, args := buildInClause(`
-        SELECT p.id, p.author_id, r.reviewer_id
+        SELECT p.id, p.author_id, COALESCE(p.team_name, ''), `+riskyPR+`, r.reviewer_id
         FROM pull_request_reviewers r
         JOIN pull_requests p ON p.id = r.pr_id
-        WHERE p.status = 'OPEN' AND r.reviewer_id IN (`, deactivatedIDs)
+        WHERE p.status = 'OPEN' AND r.reviewer_id IN (`, reviewerIDs)
+	if foreignOnly {
+		query += foreignReviewCond
+	}
+	// Lock the PRs like WithLockedPR does, so a concurrent reassign or merge
+	// waits for the deactivation instead of overwriting its reviewer changes.
+	query += " FOR UPDATE OF p"
 
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -155,9 +374,11 @@ func (r *PRRepo) loadAffectedPRs(ctx context.Context, tx *sql.Tx, deactivatedIDs
 		var (
 			prID       string
 			authorID   string
+			teamName   string
+			risky      bool
 			reviewerID string
 		)
-		if err := rows.Scan(&prID, &authorID, &reviewerID); err != nil {
+		if err := rows.Scan(&prID, &authorID, &teamName, &risky, &reviewerID); err != nil {
 			return nil, fmt.Errorf("scan affected pr row: %w", err)
 		}
 
@@ -165,6 +386,8 @@ func (r *PRRepo) loadAffectedPRs(ctx context.Context, tx *sql.Tx, deactivatedIDs
 		if !ok {
 			info = &prInfo{
 				authorID:    authorID,
+				teamName:    teamName,
+				risky:       risky,
 				deactivated: make(map[string]struct{}),
 				current:     make([]string, 0, 2),
 			}
@@ -216,25 +439,64 @@ func (r *PRRepo) loadCurrentReviewers(ctx context.Context, tx *sql.Tx, prMap map
 	return rows.Err()
 }
 
-func (r *PRRepo) loadAuthorTeams(ctx context.Context, tx *sql.Tx, prMap map[string]*prInfo) (map[string]string, error) {
-	authorSet := make(map[string]struct{})
+// reviewLoad is a candidate's number of open reviews and their cap, the
+// reviews assigned to them over the last week and their weekly quota (zero
+// for no cap or quota), and the reviews assigned over the fairness window.
+type reviewLoad struct {
+	open   int
+	max    int
+	weekly int
+	quota  int
+	recent int
+}
+
+func (l *reviewLoad) hasCapacity() bool {
+	return l.max == 0 || l.open < l.max
+}
+
+func (l *reviewLoad) underQuota() bool {
+	return l.quota == 0 || l.weekly < l.quota
+}
+
+// candidatePool is what a bulk reassignment picks from: the candidates of
+// each PR team, and for each such team the candidates of the subtree of every
+// team above it, nearest first. loads covers all of them; leads holds the
+// leads of each PR team, available or not.
+type candidatePool struct {
+	byTeam    map[string][]string
+	ancestors map[string][][]string
+	loads     map[string]*reviewLoad
+	leads     map[string]map[string]struct{}
+}
+
+// loadCandidates returns the active members of the PRs' teams, and of the
+// subtrees of the teams above them, that are not out of office. Members at
+// their review cap or weekly quota are included; loads tells them apart.
+func (r *PRRepo) loadCandidates(ctx context.Context, tx *sql.Tx, prMap map[string]*prInfo) (*candidatePool, error) {
+	teamSet := make(map[string]struct{})
 	for _, info := range prMap {
-		authorSet[info.authorID] = struct{}{}
+		teamSet[info.teamName] = struct{}{}
+	}
+	teamNames := make([]string, 0, len(teamSet))
+	for t := range teamSet {
+		teamNames = append(teamNames, t)
 	}
 
-	authorIDs := make([]string, 0, len(authorSet))
-	for id := range authorSet {
-		authorIDs = append(authorIDs, id)
+	if len(teamNames) == 0 {
+		teamNames = nil
 	}
 
 	query, args := buildInClause(`
-        SELECT id, team_name
-        FROM users
-        WHERE id IN (`, authorIDs)
+        SELECT u.id, m.team_name, `+reviewLoadColumns+`
+        FROM team_memberships m
+        JOIN users u ON u.id = m.user_id
+        `+reviewLoadJoin+`
+        WHERE u.is_active = true AND NOT `+outOfOfficeNow+` AND m.team_name IN (`, teamNames)
+	query += " ORDER BY u.id"
 
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("load authors' teams: %w", err)
+		return nil, fmt.Errorf("load active candidates: %w", err)
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -242,45 +504,125 @@ func (r *PRRepo) loadAuthorTeams(ctx context.Context, tx *sql.Tx, prMap map[stri
 		}
 	}()
 
-	authorTeam := make(map[string]string)
+	pool := &candidatePool{
+		byTeam:    make(map[string][]string),
+		ancestors: make(map[string][][]string),
+		loads:     make(map[string]*reviewLoad),
+		leads:     make(map[string]map[string]struct{}),
+	}
 	for rows.Next() {
 		var (
 			id       string
 			teamName string
+			load     reviewLoad
 		)
-		if err := rows.Scan(&id, &teamName); err != nil {
-			return nil, fmt.Errorf("scan author team: %w", err)
+		if err := rows.Scan(&id, &teamName, &load.open, &load.max, &load.weekly, &load.quota); err != nil {
+			return nil, fmt.Errorf("scan candidate: %w", err)
 		}
-		authorTeam[id] = teamName
+		pool.byTeam[teamName] = append(pool.byTeam[teamName], id)
+		pool.loads[id] = &load
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate authors' teams: %w", err)
+		return nil, fmt.Errorf("iterate candidates: %w", err)
+	}
+
+	if err := r.loadLeads(ctx, tx, teamNames, pool); err != nil {
+		return nil, err
+	}
+	if err := r.loadAncestorCandidates(ctx, tx, teamNames, pool); err != nil {
+		return nil, err
+	}
+	if err := r.loadRecentReviews(ctx, pool); err != nil {
+		return nil, err
 	}
-	return authorTeam, nil
+	return pool, nil
 }
 
-func (r *PRRepo) loadCandidates(ctx context.Context, tx *sql.Tx, authorTeam map[string]string) (map[string][]string, error) {
-	teamSet := make(map[string]struct{})
-	for _, t := range authorTeam {
-		teamSet[t] = struct{}{}
+// loadRecentReviews fills in the reviews assigned to every candidate over
+// the fairness window, if there is one.
+func (r *PRRepo) loadRecentReviews(ctx context.Context, pool *candidatePool) error {
+	if r.fairness <= 0 || len(pool.loads) == 0 {
+		return nil
 	}
-	teamNames := make([]string, 0, len(teamSet))
-	for t := range teamSet {
-		teamNames = append(teamNames, t)
+	ids := slices.Sorted(maps.Keys(pool.loads))
+	counts, err := r.CountAssignmentsSince(ctx, ids, r.nowFunc().Add(-r.fairness))
+	if err != nil {
+		return err
+	}
+	for id, n := range counts {
+		pool.loads[id].recent = n
 	}
+	return nil
+}
 
-	if len(teamNames) == 0 {
-		teamNames = nil
+// loadLeads fills pool.leads for teamNames.
+func (r *PRRepo) loadLeads(ctx context.Context, tx *sql.Tx, teamNames []string, pool *candidatePool) error {
+	query, args := buildInClause(`
+        SELECT team_name, user_id
+        FROM team_memberships
+        WHERE role = '`+string(domain.RoleLead)+`' AND team_name IN (`, teamNames)
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("load team leads: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var teamName, id string
+		if err := rows.Scan(&teamName, &id); err != nil {
+			return fmt.Errorf("scan team lead: %w", err)
+		}
+		if pool.leads[teamName] == nil {
+			pool.leads[teamName] = make(map[string]struct{})
+		}
+		pool.leads[teamName][id] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate team leads: %w", err)
 	}
+	return nil
+}
 
+// loadAncestorCandidates fills pool.ancestors for teamNames: for each team
+// above one of them, nearest first, the active members of that team's
+// subtree who are not out of office. A level without candidates is left
+// empty.
+func (r *PRRepo) loadAncestorCandidates(ctx context.Context, tx *sql.Tx, teamNames []string, pool *candidatePool) error {
 	query, args := buildInClause(`
-        SELECT id, team_name
-        FROM users
-        WHERE is_active = true AND team_name IN (`, teamNames)
+        WITH RECURSIVE ancestors (team, name, depth) AS (
+            SELECT name, parent_team, 1 FROM teams
+            WHERE parent_team IS NOT NULL AND name IN (`, teamNames)
+	query += `
+            UNION
+            SELECT a.team, t.parent_team, a.depth + 1
+            FROM teams t
+            JOIN ancestors a ON t.name = a.name
+            WHERE t.parent_team IS NOT NULL
+        ),
+        subtree (root, name) AS (
+            SELECT DISTINCT name, name FROM ancestors
+            UNION
+            SELECT s.root, t.name
+            FROM teams t
+            JOIN subtree s ON t.parent_team = s.name
+        )
+        SELECT DISTINCT a.team, a.depth, u.id, ` + reviewLoadColumns + `
+        FROM ancestors a
+        JOIN subtree s ON s.root = a.name
+        JOIN team_memberships m ON m.team_name = s.name
+        JOIN users u ON u.id = m.user_id
+        ` + reviewLoadJoin + `
+        WHERE u.is_active = true AND NOT ` + outOfOfficeNow + `
+        ORDER BY a.team, a.depth, u.id`
 
 	rows, err := tx.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("load active candidates: %w", err)
+		return fmt.Errorf("load parent team candidates: %w", err)
 	}
 	defer func() {
 		if err := rows.Close(); err != nil {
@@ -288,32 +630,60 @@ func (r *PRRepo) loadCandidates(ctx context.Context, tx *sql.Tx, authorTeam map[
 		}
 	}()
 
-	candidatesByTeam := make(map[string][]string)
 	for rows.Next() {
 		var (
-			id       string
 			teamName string
+			depth    int
+			id       string
+			load     reviewLoad
 		)
-		if err := rows.Scan(&id, &teamName); err != nil {
-			return nil, fmt.Errorf("scan candidate: %w", err)
+		if err := rows.Scan(&teamName, &depth, &id, &load.open, &load.max, &load.weekly, &load.quota); err != nil {
+			return fmt.Errorf("scan parent team candidate: %w", err)
+		}
+		levels := pool.ancestors[teamName]
+		for len(levels) < depth {
+			levels = append(levels, nil)
+		}
+		levels[depth-1] = append(levels[depth-1], id)
+		pool.ancestors[teamName] = levels
+		if _, ok := pool.loads[id]; !ok {
+			pool.loads[id] = &load
 		}
-		candidatesByTeam[teamName] = append(candidatesByTeam[teamName], id)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterate candidates: %w", err)
+		return fmt.Errorf("iterate parent team candidates: %w", err)
 	}
-	return candidatesByTeam, nil
+	return nil
 }
 
-func (r *PRRepo) calculateNewReviewers(prMap map[string]*prInfo, authorTeam map[string]string, candidatesByTeam map[string][]string) map[string][]string {
+// calculateNewReviewers keeps the reviewers of each PR that stay and fills
+// the free slots from the team's candidates with spare capacity. Like
+// PRService, candidates are picked at random weighted by their recent
+// reviews, a risky PR left without a lead gets an available lead of its team
+// first, and selection climbs to the subtrees of the teams above once the
+// team has nobody left. Candidates at their weekly quota are never picked.
+// The loads are updated as reviewers are picked, so one run does not push
+// anybody over their cap or quota and spreads its own picks fairly.
+func (r *PRRepo) calculateNewReviewers(prMap map[string]*prInfo, pool *candidatePool) map[string][]string {
 	newReviewersByPR := make(map[string][]string, len(prMap))
+	loads := pool.loads
+
+	// The reviews being taken away no longer count against the reviewers.
+	for _, info := range prMap {
+		for id := range info.deactivated {
+			if load, ok := loads[id]; ok {
+				load.open--
+			}
+		}
+	}
 
 	for prID, info := range prMap {
 		authorID := info.authorID
-		teamName := authorTeam[authorID]
+		teamName := info.teamName
 
 		deactSet := info.deactivated
-		present := make(map[string]struct{})
+		// The reviewers taken off are not candidates for the PR either.
+		present := maps.Clone(deactSet)
 		newReviewers := make([]string, 0, 2)
 
 		for _, id := range info.current {
@@ -327,19 +697,62 @@ func (r *PRRepo) calculateNewReviewers(prMap map[string]*prInfo, authorTeam map[
 			present[id] = struct{}{}
 		}
 
-		candidates := candidatesByTeam[teamName]
-		for _, cand := range candidates {
+		pick := func(cand string) {
+			newReviewers = append(newReviewers, cand)
+			present[cand] = struct{}{}
+			loads[cand].open++
+			loads[cand].weekly++
+			loads[cand].recent++
+		}
+
+		// fill picks eligible candidates until the PR has n reviewers.
+		fill := func(candidates []string, n int) {
+			if len(newReviewers) >= n {
+				return
+			}
+			eligible := make([]domain.User, 0, len(candidates))
+			for _, cand := range candidates {
+				if cand == authorID {
+					continue
+				}
+				if _, ok := present[cand]; ok {
+					continue
+				}
+				if !loads[cand].hasCapacity() || !loads[cand].underQuota() {
+					continue
+				}
+				eligible = append(eligible, domain.User{ID: cand, RecentReviews: loads[cand].recent})
+			}
+			for _, cand := range domain.PickFair(eligible, n-len(newReviewers)) {
+				pick(cand)
+			}
+		}
+
+		candidates := pool.byTeam[teamName]
+		leads := pool.leads[teamName]
+		if info.risky && !hasLead(newReviewers, leads) && len(newReviewers) < 2 {
+			teamLeads := make([]string, 0, len(leads))
+			for _, cand := range candidates {
+				if _, ok := leads[cand]; ok {
+					teamLeads = append(teamLeads, cand)
+				}
+			}
+			fill(teamLeads, len(newReviewers)+1)
+		}
+		fill(candidates, 2)
+		for _, level := range pool.ancestors[teamName] {
 			if len(newReviewers) >= 2 {
 				break
 			}
-			if cand == authorID {
-				continue
-			}
-			if _, ok := present[cand]; ok {
-				continue
+			fill(level, 2)
+		}
+
+		for r.overflow == domain.OverflowLeastLoaded && len(newReviewers) < 2 {
+			cand := leastLoaded(candidates, authorID, present, loads)
+			if cand == "" {
+				break
 			}
-			newReviewers = append(newReviewers, cand)
-			present[cand] = struct{}{}
+			pick(cand)
 		}
 
 		newReviewersByPR[prID] = newReviewers
@@ -348,23 +761,49 @@ func (r *PRRepo) calculateNewReviewers(prMap map[string]*prInfo, authorTeam map[
 	return newReviewersByPR
 }
 
+// hasLead reports whether one of reviewers is among leads.
+func hasLead(reviewers []string, leads map[string]struct{}) bool {
+	for _, id := range reviewers {
+		if _, ok := leads[id]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// leastLoaded returns the candidate below their weekly quota, other than the
+// author and those present, with the fewest open reviews; "" if there is
+// none.
+func leastLoaded(candidates []string, authorID string, present map[string]struct{}, loads map[string]*reviewLoad) string {
+	best := ""
+	for _, cand := range candidates {
+		if cand == authorID {
+			continue
+		}
+		if _, ok := present[cand]; ok {
+			continue
+		}
+		if !loads[cand].underQuota() {
+			continue
+		}
+		if best == "" || loads[cand].open < loads[best].open {
+			best = cand
+		}
+	}
+	return best
+}
+
 func (r *PRRepo) updatePRReviewers(ctx context.Context, tx *sql.Tx, newReviewersByPR map[string][]string) error {
 	for prID, reviewers := range newReviewersByPR {
 		if _, err := tx.ExecContext(ctx,
-			`DELETE FROM pull_request_reviewers WHERE pr_id = $1`,
+			`UPDATE pull_requests SET version = version + 1 WHERE id = $1`,
 			prID,
 		); err != nil {
-			return fmt.Errorf("delete old reviewers for pr %s: %w", prID, err)
+			return fmt.Errorf("bump version for pr %s: %w", prID, err)
 		}
 
-		for _, reviewerID := range reviewers {
-			if _, err := tx.ExecContext(ctx,
-				`INSERT INTO pull_request_reviewers (pr_id, reviewer_id)
-                 VALUES ($1, $2)`,
-				prID, reviewerID,
-			); err != nil {
-				return fmt.Errorf("insert new reviewer %s for pr %s: %w", reviewerID, prID, err)
-			}
+		if err := replaceReviewers(ctx, tx, prID, reviewers); err != nil {
+			return fmt.Errorf("replace reviewers for pr %s: %w", prID, err)
 		}
 	}
 	return nil
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type ReviewerSyncRepo struct {
+	db *sql.DB
+}
+
+func NewReviewerSyncRepo(db *sql.DB) *ReviewerSyncRepo {
+	return &ReviewerSyncRepo{db: db}
+}
+
+func (r *ReviewerSyncRepo) SaveSyncStatus(ctx context.Context, s *domain.ReviewerSync) error {
+	updatedAt := time.Now()
+	if s.UpdatedAt != 0 {
+		updatedAt = time.Unix(s.UpdatedAt, 0)
+	}
+
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO pull_request_reviewer_sync (pr_id, status, attempts, last_error, updated_at)
+         VALUES ($1, $2, $3, $4, $5)
+         ON CONFLICT (pr_id) DO UPDATE
+         SET status = EXCLUDED.status,
+             attempts = EXCLUDED.attempts,
+             last_error = EXCLUDED.last_error,
+             updated_at = EXCLUDED.updated_at`,
+		s.PullRequestID,
+		string(s.Status),
+		s.Attempts,
+		s.LastError,
+		updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("save reviewer sync status: %w", err)
+	}
+	return nil
+}
+
+func (r *ReviewerSyncRepo) GetSyncStatus(ctx context.Context, prID string) (*domain.ReviewerSync, error) {
+	var (
+		s         domain.ReviewerSync
+		statusStr string
+		updatedAt time.Time
+	)
+	err := r.db.QueryRowContext(ctx,
+		`SELECT pr_id, status, attempts, last_error, updated_at
+         FROM pull_request_reviewer_sync
+         WHERE pr_id = $1`,
+		prID,
+	).Scan(&s.PullRequestID, &statusStr, &s.Attempts, &s.LastError, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("get reviewer sync status: %w", err)
+	}
+
+	s.Status = domain.ReviewerSyncStatus(statusStr)
+	s.UpdatedAt = updatedAt.Unix()
+	return &s, nil
+}
@@ -0,0 +1,233 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// RosterRepo reads and writes teams, users and memberships the way a roster
+// import needs them: in bulk and without the checks of the team endpoints.
+type RosterRepo struct {
+	db *sql.DB
+}
+
+func NewRosterRepo(db *sql.DB) *RosterRepo {
+	return &RosterRepo{db: db}
+}
+
+// Export returns the memberships of all non-archived teams grouped by user,
+// with each user's primary team first so that importing the result again
+// keeps it primary.
+func (r *RosterRepo) Export(ctx context.Context) ([]domain.RosterEntry, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT m.team_name, u.id, u.username, u.is_active, m.role
+         FROM team_memberships m
+         JOIN teams t ON t.name = m.team_name
+         JOIN users u ON u.id = m.user_id
+         WHERE t.archived_at IS NULL
+         ORDER BY u.id, m.team_name IS DISTINCT FROM u.team_name, m.team_name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export roster: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	entries := make([]domain.RosterEntry, 0)
+	for rows.Next() {
+		var e domain.RosterEntry
+		if err := rows.Scan(&e.TeamName, &e.UserID, &e.Username, &e.IsActive, &e.Role); err != nil {
+			return nil, fmt.Errorf("scan roster entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster: %w", err)
+	}
+	return entries, nil
+}
+
+// Teams maps each of names that exists to whether it is archived.
+func (r *RosterRepo) Teams(ctx context.Context, names []string) (map[string]bool, error) {
+	teams := make(map[string]bool, len(names))
+	if len(names) == 0 {
+		return teams, nil
+	}
+
+	query, args := buildInClause(`
+        SELECT name, archived_at IS NOT NULL
+        FROM teams
+        WHERE name IN (`, names)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select roster teams: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			name     string
+			archived bool
+		)
+		if err := rows.Scan(&name, &archived); err != nil {
+			return nil, fmt.Errorf("scan roster team: %w", err)
+		}
+		teams[name] = archived
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster teams: %w", err)
+	}
+	return teams, nil
+}
+
+// Users returns each of ids that exists, with their number of open reviews.
+// The rows are locked until the end of the transaction.
+func (r *RosterRepo) Users(ctx context.Context, ids []string) (map[string]domain.User, error) {
+	users := make(map[string]domain.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	query, args := buildInClause(`
+        SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active,
+               (SELECT count(*) FROM pull_request_reviewers r
+                JOIN pull_requests p ON p.id = r.pr_id
+                WHERE r.reviewer_id = u.id AND p.status = 'OPEN')
+        FROM users u
+        WHERE u.id IN (`, ids)
+	query += " FOR UPDATE"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select roster users: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.OpenReviews); err != nil {
+			return nil, fmt.Errorf("scan roster user: %w", err)
+		}
+		users[u.ID] = u
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster users: %w", err)
+	}
+	return users, nil
+}
+
+// Memberships maps each of userIDs to the teams they belong to and their
+// role in each.
+func (r *RosterRepo) Memberships(ctx context.Context, userIDs []string) (map[string]map[string]domain.MemberRole, error) {
+	memberships := make(map[string]map[string]domain.MemberRole, len(userIDs))
+	if len(userIDs) == 0 {
+		return memberships, nil
+	}
+
+	query, args := buildInClause(`
+        SELECT user_id, team_name, role
+        FROM team_memberships
+        WHERE user_id IN (`, userIDs)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select roster memberships: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var (
+			userID, teamName string
+			role             domain.MemberRole
+		)
+		if err := rows.Scan(&userID, &teamName, &role); err != nil {
+			return nil, fmt.Errorf("scan roster membership: %w", err)
+		}
+		if memberships[userID] == nil {
+			memberships[userID] = make(map[string]domain.MemberRole)
+		}
+		memberships[userID][teamName] = role
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate roster memberships: %w", err)
+	}
+	return memberships, nil
+}
+
+// CreateTeam inserts a root team; a duplicate name yields TEAM_EXISTS.
+func (r *RosterRepo) CreateTeam(ctx context.Context, name string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO teams (name) VALUES ($1)`,
+		name,
+	)
+	if err != nil {
+		return dbError("insert roster team", err)
+	}
+	return nil
+}
+
+// CreateUser inserts the user with TeamName as their primary team, which
+// also makes them a member of it.
+func (r *RosterRepo) CreateUser(ctx context.Context, u domain.User) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO users (id, username, team_name, is_active)
+         VALUES ($1, $2, $3, $4)`,
+		u.ID, u.Username, u.TeamName, u.IsActive,
+	)
+	if err != nil {
+		return dbError("insert roster user "+u.ID, err)
+	}
+	return nil
+}
+
+// UpdateUser sets the username and activity of the user; their teams stay
+// unchanged.
+func (r *RosterRepo) UpdateUser(ctx context.Context, u domain.User) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET username = $2,
+             is_active = $3,
+             updated_at = now()
+         WHERE id = $1`,
+		u.ID, u.Username, u.IsActive,
+	)
+	if err != nil {
+		return fmt.Errorf("update roster user %s: %w", u.ID, err)
+	}
+	return nil
+}
+
+// SetMembership makes the user a member of teamName with role, or changes
+// their role if they already are one.
+func (r *RosterRepo) SetMembership(ctx context.Context, teamName, userID string, role domain.MemberRole) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO team_memberships (team_name, user_id, role)
+         VALUES ($1, $2, $3)
+         ON CONFLICT (team_name, user_id) DO UPDATE
+         SET role = EXCLUDED.role`,
+		teamName, userID, string(role),
+	)
+	if err != nil {
+		return dbError("set roster membership of user "+userID, err)
+	}
+	return nil
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
@@ -17,20 +20,23 @@ func NewTeamRepo(db *sql.DB) *TeamRepo {
 	return &TeamRepo{db: db}
 }
 
-func (r *TeamRepo) Create(ctx context.Context, name string) error {
-	_, err := r.db.ExecContext(ctx,
-		`INSERT INTO teams (name) VALUES ($1)`,
-		name,
+// Create inserts the team under parentTeam ("" for a root team). A
+// duplicate name yields a TEAM_EXISTS domain error, a missing parent
+// NOT_FOUND.
+func (r *TeamRepo) Create(ctx context.Context, name, parentTeam string) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO teams (name, parent_team) VALUES ($1, NULLIF($2, ''))`,
+		name, parentTeam,
 	)
 	if err != nil {
-		return fmt.Errorf("insert team: %w", err)
+		return dbError("insert team", err)
 	}
 	return nil
 }
 
 func (r *TeamRepo) Exists(ctx context.Context, name string) (bool, error) {
 	var dummy int
-	err := r.db.QueryRowContext(ctx,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
 		`SELECT 1 FROM teams WHERE name = $1`,
 		name,
 	).Scan(&dummy)
@@ -43,12 +49,20 @@ func (r *TeamRepo) Exists(ctx context.Context, name string) (bool, error) {
 	return true, nil
 }
 
+// GetWithMembers loads the team with all its members and their roles,
+// including those for whom it is not the primary team.
 func (r *TeamRepo) GetWithMembers(ctx context.Context, name string) (*domain.Team, error) {
-	var teamName string
-	err := r.db.QueryRowContext(ctx,
-		`SELECT name FROM teams WHERE name = $1`,
+	var (
+		teamName       string
+		archivedAt     sql.NullTime
+		parentTeam     string
+		maxOpenReviews int
+	)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT name, archived_at, COALESCE(parent_team, ''), COALESCE(default_max_open_reviews, 0)
+         FROM teams WHERE name = $1`,
 		name,
-	).Scan(&teamName)
+	).Scan(&teamName, &archivedAt, &parentTeam, &maxOpenReviews)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
@@ -56,11 +70,15 @@ func (r *TeamRepo) GetWithMembers(ctx context.Context, name string) (*domain.Tea
 		return nil, fmt.Errorf("get team: %w", err)
 	}
 
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, username, team_name, is_active
-         FROM users
-         WHERE team_name = $1
-         ORDER BY id`,
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active, m.role, `+outOfOfficeNow+`,
+                `+workingHoursColumns+`, `+reviewLoadColumns+`
+         FROM team_memberships m
+         JOIN users u ON u.id = m.user_id
+         `+workingHoursJoin+`
+         `+reviewLoadJoin+`
+         WHERE m.team_name = $1
+         ORDER BY u.id`,
 		name,
 	)
 	if err != nil {
@@ -74,18 +92,528 @@ func (r *TeamRepo) GetWithMembers(ctx context.Context, name string) (*domain.Tea
 
 	members := make([]domain.User, 0)
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		var (
+			u  domain.User
+			wh nullWorkingHours
+		)
+		dest := append([]any{&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Role, &u.OutOfOffice}, wh.dest()...)
+		dest = append(dest, &u.OpenReviews, &u.MaxOpenReviews, &u.WeeklyReviews, &u.WeeklyReviewQuota)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
+		u.WorkingHours = wh.value()
 		members = append(members, u)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterate team members: %w", err)
 	}
 
-	return &domain.Team{
-		Name:    teamName,
-		Members: members,
-	}, nil
+	team := &domain.Team{
+		Name:           teamName,
+		Members:        members,
+		ParentTeam:     parentTeam,
+		MaxOpenReviews: maxOpenReviews,
+	}
+	if archivedAt.Valid {
+		team.ArchivedAt = archivedAt.Time.Unix()
+	}
+	return team, nil
+}
+
+// List returns up to limit non-archived teams matching filter, ordered by
+// name and starting after the team named after ("" for the first page).
+func (r *TeamRepo) List(ctx context.Context, filter domain.TeamFilter, after string, limit int) (domain.TeamPage, error) {
+	var (
+		conds  = []string{"t.archived_at IS NULL"}
+		having []string
+		args   []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.NamePrefix != "" {
+		conds = append(conds, "starts_with(t.name, "+arg(filter.NamePrefix)+")")
+	}
+	if after != "" {
+		conds = append(conds, "t.name > "+arg(after))
+	}
+	const activeCount = "count(u.id) FILTER (WHERE u.is_active)"
+	if filter.MinActiveMembers > 0 {
+		having = append(having, activeCount+" >= "+arg(filter.MinActiveMembers))
+	}
+	if filter.MaxActiveMembers != nil {
+		having = append(having, activeCount+" <= "+arg(*filter.MaxActiveMembers))
+	}
+
+	query := `SELECT t.name, COALESCE(t.parent_team, ''), count(u.id), ` + activeCount + `
+         FROM teams t
+         LEFT JOIN team_memberships m ON m.team_name = t.name
+         LEFT JOIN users u ON u.id = m.user_id
+         WHERE ` + strings.Join(conds, "\n           AND ") + `
+         GROUP BY t.name`
+	if len(having) > 0 {
+		query += "\n         HAVING " + strings.Join(having, "\n            AND ")
+	}
+	// One extra row tells whether there is a next page.
+	query += "\n         ORDER BY t.name\n         LIMIT " + arg(limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.TeamPage{}, fmt.Errorf("list teams: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	page := domain.TeamPage{Teams: make([]domain.TeamSummary, 0, limit)}
+	for rows.Next() {
+		var t domain.TeamSummary
+		if err := rows.Scan(&t.Name, &t.ParentTeam, &t.MemberCount, &t.ActiveMemberCount); err != nil {
+			return domain.TeamPage{}, fmt.Errorf("scan team: %w", err)
+		}
+		page.Teams = append(page.Teams, t)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.TeamPage{}, fmt.Errorf("iterate teams: %w", err)
+	}
+
+	if len(page.Teams) > limit {
+		page.Teams = page.Teams[:limit]
+		page.Next = page.Teams[limit-1].Name
+	}
+	return page, nil
+}
+
+// Archive marks the team archived and returns when, as unix seconds. It
+// returns sql.ErrNoRows if the team does not exist or is already archived.
+func (r *TeamRepo) Archive(ctx context.Context, name string) (int64, error) {
+	var archivedAt time.Time
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`UPDATE teams
+         SET archived_at = now()
+         WHERE name = $1 AND archived_at IS NULL
+         RETURNING archived_at`,
+		name,
+	).Scan(&archivedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, sql.ErrNoRows
+		}
+		return 0, fmt.Errorf("archive team: %w", err)
+	}
+	return archivedAt.Unix(), nil
+}
+
+// CountHistory returns how many pull requests were created under the team or
+// are authored, reviewed or were once reviewed (review_assignments) by users
+// whose primary team it is.
+func (r *TeamRepo) CountHistory(ctx context.Context, name string) (int, error) {
+	var n int
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT count(DISTINCT p.id)
+         FROM pull_requests p
+         LEFT JOIN pull_request_reviewers r ON r.pr_id = p.id
+         LEFT JOIN review_assignments a ON a.pr_id = p.id
+         WHERE p.team_name = $1
+            OR p.author_id IN (SELECT id FROM users WHERE team_name = $1)
+            OR r.reviewer_id IN (SELECT id FROM users WHERE team_name = $1)
+            OR a.reviewer_id IN (SELECT id FROM users WHERE team_name = $1)`,
+		name,
+	).Scan(&n)
+	if err != nil {
+		return 0, fmt.Errorf("count team history: %w", err)
+	}
+	return n, nil
+}
+
+// Delete removes the team together with the users whose primary team it is
+// and returns their number; other members only lose the membership. Members
+// or a team referenced by a pull request make it fail with TEAM_HAS_HISTORY.
+func (r *TeamRepo) Delete(ctx context.Context, name string) (int, error) {
+	var deleted int
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `DELETE FROM users WHERE team_name = $1`, name)
+		if err != nil {
+			// A PR created for a member after the caller's history check
+			// still references them.
+			if constraintError(err) != nil {
+				return domain.NewDomainError(domain.ErrorCodeTeamHasHistory, "team members have pull request history")
+			}
+			return fmt.Errorf("delete team members: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("delete team members: %w", err)
+		}
+		deleted = int(n)
+
+		res, err = tx.ExecContext(ctx, `DELETE FROM teams WHERE name = $1`, name)
+		if err != nil {
+			switch violatedConstraint(err) {
+			case "":
+				return fmt.Errorf("delete team: %w", err)
+			case "teams_parent_team_fkey":
+				return domain.NewDomainError(domain.ErrorCodeTeamHasSubteams, "team "+name+" has subteams")
+			default:
+				// A PR created under the team after the history check.
+				return domain.NewDomainError(domain.ErrorCodeTeamHasHistory, "team has pull request history")
+			}
+		}
+		n, err = res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("delete team: %w", err)
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// FindDeactivation locks a recorded deactivation run of the team and loads
+// its users. A zero id selects the team's latest run. It returns
+// sql.ErrNoRows if there is no such run.
+func (r *TeamRepo) FindDeactivation(ctx context.Context, teamName string, id int64) (*domain.TeamDeactivation, error) {
+	var (
+		d             domain.TeamDeactivation
+		deactivatedAt time.Time
+		reactivatedAt sql.NullTime
+	)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT id, team_name, deactivated_at, reactivated_at
+         FROM team_deactivations
+         WHERE team_name = $1 AND ($2 = 0 OR id = $2)
+         ORDER BY id DESC
+         LIMIT 1
+         FOR UPDATE`,
+		teamName, id,
+	).Scan(&d.ID, &d.TeamName, &deactivatedAt, &reactivatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("get team deactivation: %w", err)
+	}
+	d.DeactivatedAt = deactivatedAt.Unix()
+	if reactivatedAt.Valid {
+		d.ReactivatedAt = reactivatedAt.Time.Unix()
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT user_id
+         FROM team_deactivation_users
+         WHERE deactivation_id = $1
+         ORDER BY user_id`,
+		d.ID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list deactivated users: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	d.UserIDs = make([]string, 0)
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("scan deactivated user: %w", err)
+		}
+		d.UserIDs = append(d.UserIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate deactivated users: %w", err)
+	}
+	return &d, nil
+}
+
+// Reactivate marks the deactivation run reverted and activates its users
+// that are still inactive members of the run's team. Users activated or
+// moved since then are left alone. It returns the IDs activated.
+func (r *TeamRepo) Reactivate(ctx context.Context, d *domain.TeamDeactivation) ([]string, error) {
+	reactivated := make([]string, 0, len(d.UserIDs))
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE team_deactivations SET reactivated_at = now() WHERE id = $1`,
+			d.ID,
+		); err != nil {
+			return fmt.Errorf("mark deactivation %d reactivated: %w", d.ID, err)
+		}
+		if len(d.UserIDs) == 0 {
+			return nil
+		}
+
+		query, args := buildInClause(`
+        UPDATE users
+        SET is_active = true,
+            updated_at = now()
+        WHERE is_active = false AND id IN (`, d.UserIDs)
+		args = append(args, d.TeamName)
+		query += fmt.Sprintf(" AND team_name = $%d RETURNING id", len(args))
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("reactivate users of team %s: %w", d.TeamName, err)
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				// Log error but don't fail - rows are already read
+			}
+		}()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("scan reactivated user id: %w", err)
+			}
+			reactivated = append(reactivated, id)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate reactivated user ids: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(reactivated)
+	return reactivated, nil
+}
+
+// subtreeCTE lists the team $1 and all teams below it with their depth.
+// UNION rather than UNION ALL keeps the query finite even on a corrupt tree.
+const subtreeCTE = `
+WITH RECURSIVE subtree (name, depth) AS (
+    SELECT name, 0 FROM teams WHERE name = $1
+    UNION
+    SELECT t.name, s.depth + 1
+    FROM teams t
+    JOIN subtree s ON t.parent_team = s.name
+)`
+
+// GetSubtree loads the team with its members and, recursively, all its
+// subteams with theirs. Subteams are ordered by name.
+func (r *TeamRepo) GetSubtree(ctx context.Context, name string) (*domain.Team, error) {
+	root, err := r.GetWithMembers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, subtreeCTE+`
+        SELECT t.name, t.parent_team, t.archived_at, COALESCE(t.default_max_open_reviews, 0)
+        FROM subtree s
+        JOIN teams t ON t.name = s.name
+        WHERE s.depth > 0
+        ORDER BY t.name`,
+		name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list subteams: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	teams := map[string]*domain.Team{root.Name: root}
+	children := make(map[string][]string)
+	for rows.Next() {
+		var (
+			t          domain.Team
+			archivedAt sql.NullTime
+		)
+		if err := rows.Scan(&t.Name, &t.ParentTeam, &archivedAt, &t.MaxOpenReviews); err != nil {
+			return nil, fmt.Errorf("scan subteam: %w", err)
+		}
+		if archivedAt.Valid {
+			t.ArchivedAt = archivedAt.Time.Unix()
+		}
+		t.Members = make([]domain.User, 0)
+		teams[t.Name] = &t
+		children[t.ParentTeam] = append(children[t.ParentTeam], t.Name)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subteams: %w", err)
+	}
+	if len(children) == 0 {
+		return root, nil
+	}
+
+	members, err := r.ListSubtreeMembers(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range members {
+		if t, ok := teams[u.TeamName]; ok && t != root {
+			t.Members = append(t.Members, u)
+		}
+	}
+
+	var build func(name string) domain.Team
+	build = func(name string) domain.Team {
+		t := *teams[name]
+		for _, child := range children[name] {
+			t.Subteams = append(t.Subteams, build(child))
+		}
+		return t
+	}
+	tree := build(root.Name)
+	return &tree, nil
+}
+
+// ListSubtreeMembers returns the members of the team and of all teams below
+// it, ordered by team and ID. TeamName is the team of the membership, so a
+// user in several of these teams is listed once per team.
+func (r *TeamRepo) ListSubtreeMembers(ctx context.Context, name string) ([]domain.User, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, subtreeCTE+`
+        SELECT u.id, u.username, m.team_name, u.is_active, m.role, `+outOfOfficeNow+`,
+               `+workingHoursColumns+`, `+reviewLoadColumns+`
+        FROM team_memberships m
+        JOIN subtree s ON s.name = m.team_name
+        JOIN users u ON u.id = m.user_id
+        `+workingHoursJoin+`
+        `+reviewLoadJoin+`
+        ORDER BY m.team_name, u.id`,
+		name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list subtree members: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	members := make([]domain.User, 0)
+	for rows.Next() {
+		var (
+			u  domain.User
+			wh nullWorkingHours
+		)
+		dest := append([]any{&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Role, &u.OutOfOffice}, wh.dest()...)
+		dest = append(dest, &u.OpenReviews, &u.MaxOpenReviews, &u.WeeklyReviews, &u.WeeklyReviewQuota)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scan subtree member: %w", err)
+		}
+		u.WorkingHours = wh.value()
+		members = append(members, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate subtree members: %w", err)
+	}
+	return members, nil
+}
+
+// Ancestors returns the teams above the given one, nearest first.
+func (r *TeamRepo) Ancestors(ctx context.Context, name string) ([]string, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, `
+        WITH RECURSIVE ancestors (name, parent_team, depth) AS (
+            SELECT name, parent_team, 0 FROM teams WHERE name = $1
+            UNION
+            SELECT t.name, t.parent_team, a.depth + 1
+            FROM teams t
+            JOIN ancestors a ON t.name = a.parent_team
+        )
+        SELECT name FROM ancestors WHERE depth > 0 ORDER BY depth`,
+		name,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("list team ancestors: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	ancestors := make([]string, 0)
+	for rows.Next() {
+		var team string
+		if err := rows.Scan(&team); err != nil {
+			return nil, fmt.Errorf("scan team ancestor: %w", err)
+		}
+		ancestors = append(ancestors, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate team ancestors: %w", err)
+	}
+	return ancestors, nil
+}
+
+// hierarchyLockKey serialises parent changes, so two concurrent moves
+// cannot each pass the cycle check and close a loop together.
+const hierarchyLockKey = 0x7465616d73 // "teams"
+
+// SetParent moves the team under parentTeam ("" makes it a root team). It
+// fails with TEAM_CYCLE if parentTeam is the team itself or one of its
+// subteams, with NOT_FOUND if parentTeam does not exist, and returns
+// sql.ErrNoRows if the team does not.
+func (r *TeamRepo) SetParent(ctx context.Context, name, parentTeam string) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, hierarchyLockKey); err != nil {
+			return fmt.Errorf("lock team hierarchy: %w", err)
+		}
+
+		if parentTeam != "" {
+			var inSubtree bool
+			if err := tx.QueryRowContext(ctx, subtreeCTE+`
+                SELECT EXISTS (SELECT 1 FROM subtree WHERE name = $2)`,
+				name, parentTeam,
+			).Scan(&inSubtree); err != nil {
+				return fmt.Errorf("check team cycle: %w", err)
+			}
+			if inSubtree {
+				return domain.NewDomainError(domain.ErrorCodeTeamCycle,
+					fmt.Sprintf("team %s is %s or one of its subteams", parentTeam, name))
+			}
+		}
+
+		res, err := tx.ExecContext(ctx,
+			`UPDATE teams SET parent_team = NULLIF($2, '') WHERE name = $1`,
+			name, parentTeam,
+		)
+		if err != nil {
+			return dbError("set parent team", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("set parent team: %w", err)
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// SetDefaultMaxOpenReviews sets the review cap of members without their
+// own; zero clears it. It returns sql.ErrNoRows if the team does not exist.
+func (r *TeamRepo) SetDefaultMaxOpenReviews(ctx context.Context, name string, limit int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE teams SET default_max_open_reviews = NULLIF($2, 0) WHERE name = $1`,
+		name, limit,
+	)
+	if err != nil {
+		return fmt.Errorf("set default max open reviews: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set default max open reviews: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
@@ -16,16 +18,9 @@ func NewUserRepo(db *sql.DB) *UserRepo {
 	return &UserRepo{db: db}
 }
 
+// UpsertForTeam creates or updates the users with teamName as their primary
+// team. A non-empty Role also sets their role in it.
 func (r *UserRepo) UpsertForTeam(ctx context.Context, teamName string, users []domain.User) error {
-	tx, err := r.db.BeginTx(ctx, nil)
-	if err != nil {
-		return fmt.Errorf("begin upsert users tx: %w", err)
-	}
-	defer func() {
-		// #nosec G104 -- error is ignored in defer rollback
-		_ = tx.Rollback()
-	}()
-
 	stmt := `
 INSERT INTO users (id, username, team_name, is_active)
 VALUES ($1, $2, $3, $4)
@@ -35,49 +30,96 @@ SET username = EXCLUDED.username,
     is_active = EXCLUDED.is_active,
     updated_at = now()
 `
-	for _, u := range users {
-		if _, err := tx.ExecContext(ctx, stmt,
-			u.ID,
-			u.Username,
-			teamName,
-			u.IsActive,
-		); err != nil {
-			return fmt.Errorf("upsert user %s: %w", u.ID, err)
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		for _, u := range users {
+			if _, err := tx.ExecContext(ctx, stmt,
+				u.ID,
+				u.Username,
+				teamName,
+				u.IsActive,
+			); err != nil {
+				return dbError("upsert user "+u.ID, err)
+			}
+			if u.Role == "" {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx,
+				`UPDATE team_memberships SET role = $3 WHERE team_name = $1 AND user_id = $2`,
+				teamName, u.ID, string(u.Role),
+			); err != nil {
+				return fmt.Errorf("set role of user %s: %w", u.ID, err)
+			}
 		}
-	}
+		return nil
+	})
+}
 
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit upsert users tx: %w", err)
+func (r *UserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	var (
+		u  domain.User
+		wh nullWorkingHours
+	)
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active, `+workingHoursColumns+`,
+                `+reviewLoadColumns+`
+         FROM users u
+         `+workingHoursJoin+`
+         `+reviewLoadJoin+`
+         WHERE u.id = $1`,
+		id,
+	).Scan(append(append([]any{&u.ID, &u.Username, &u.TeamName, &u.IsActive}, wh.dest()...), &u.OpenReviews, &u.MaxOpenReviews, &u.WeeklyReviews, &u.WeeklyReviewQuota)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, fmt.Errorf("get user by id: %w", err)
 	}
+	u.WorkingHours = wh.value()
+	return &u, nil
+}
 
+// Create inserts the user with TeamName as their primary team, or without
+// one if it is empty. An existing ID yields USER_EXISTS.
+func (r *UserRepo) Create(ctx context.Context, u domain.User) error {
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO users (id, username, team_name, is_active)
+         VALUES ($1, $2, NULLIF($3, ''), $4)`,
+		u.ID, u.Username, u.TeamName, u.IsActive,
+	)
+	if err != nil {
+		return dbError("insert user", err)
+	}
 	return nil
 }
 
-func (r *UserRepo) GetByID(ctx context.Context, id string) (*domain.User, error) {
+// SetUsername renames the user and returns the updated user.
+func (r *UserRepo) SetUsername(ctx context.Context, id, username string) (*domain.User, error) {
 	var u domain.User
-	err := r.db.QueryRowContext(ctx,
-		`SELECT id, username, team_name, is_active
-         FROM users
-         WHERE id = $1`,
-		id,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`UPDATE users
+         SET username = $2,
+             updated_at = now()
+         WHERE id = $1
+         RETURNING id, username, COALESCE(team_name, ''), is_active`,
+		id, username,
 	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sql.ErrNoRows
 		}
-		return nil, fmt.Errorf("get user by id: %w", err)
+		return nil, fmt.Errorf("set username: %w", err)
 	}
 	return &u, nil
 }
 
 func (r *UserRepo) SetIsActive(ctx context.Context, id string, active bool) (*domain.User, error) {
 	var u domain.User
-	err := r.db.QueryRowContext(ctx,
+	err := conn(ctx, r.db).QueryRowContext(ctx,
 		`UPDATE users
          SET is_active = $2,
              updated_at = now()
          WHERE id = $1
-         RETURNING id, username, team_name, is_active`,
+         RETURNING id, username, COALESCE(team_name, ''), is_active`,
 		id, active,
 	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
 	if err != nil {
@@ -89,12 +131,19 @@ func (r *UserRepo) SetIsActive(ctx context.Context, id string, active bool) (*do
 	return &u, nil
 }
 
+// ListByTeam returns every member of the team with their role in it,
+// whether it is their primary team or not. TeamName of each user is their
+// primary team.
 func (r *UserRepo) ListByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
-	rows, err := r.db.QueryContext(ctx,
-		`SELECT id, username, team_name, is_active
-         FROM users
-         WHERE team_name = $1
-         ORDER BY id`,
+	rows, err := conn(ctx, r.db).QueryContext(ctx,
+		`SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active, m.role, `+outOfOfficeNow+`,
+                `+workingHoursColumns+`, `+reviewLoadColumns+`
+         FROM team_memberships m
+         JOIN users u ON u.id = m.user_id
+         `+workingHoursJoin+`
+         `+reviewLoadJoin+`
+         WHERE m.team_name = $1
+         ORDER BY u.id`,
 		teamName,
 	)
 	if err != nil {
@@ -108,10 +157,16 @@ func (r *UserRepo) ListByTeam(ctx context.Context, teamName string) ([]domain.Us
 
 	users := make([]domain.User, 0)
 	for rows.Next() {
-		var u domain.User
-		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+		var (
+			u  domain.User
+			wh nullWorkingHours
+		)
+		dest := append([]any{&u.ID, &u.Username, &u.TeamName, &u.IsActive, &u.Role, &u.OutOfOffice}, wh.dest()...)
+		dest = append(dest, &u.OpenReviews, &u.MaxOpenReviews, &u.WeeklyReviews, &u.WeeklyReviewQuota)
+		if err := rows.Scan(dest...); err != nil {
 			return nil, fmt.Errorf("scan user: %w", err)
 		}
+		u.WorkingHours = wh.value()
 		users = append(users, u)
 	}
 	if err := rows.Err(); err != nil {
@@ -120,3 +175,344 @@ func (r *UserRepo) ListByTeam(ctx context.Context, teamName string) ([]domain.Us
 
 	return users, nil
 }
+
+// List returns up to limit users matching filter, ordered by ID and starting
+// after the user with ID after ("" for the first page).
+func (r *UserRepo) List(ctx context.Context, filter domain.UserFilter, after string, limit int) (domain.UserPage, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.TeamName != "" {
+		conds = append(conds, `EXISTS (
+            SELECT 1 FROM team_memberships m
+            WHERE m.user_id = u.id AND m.team_name = `+arg(filter.TeamName)+`)`)
+	}
+	if filter.IsActive != nil {
+		conds = append(conds, "u.is_active = "+arg(*filter.IsActive))
+	}
+	if filter.Username != "" {
+		conds = append(conds, "lower(u.username) = lower("+arg(filter.Username)+")")
+	}
+	if filter.UsernameContains != "" {
+		conds = append(conds, "strpos(lower(u.username), lower("+arg(filter.UsernameContains)+")) > 0")
+	}
+	if after != "" {
+		conds = append(conds, "u.id > "+arg(after))
+	}
+
+	query := `SELECT u.id, u.username, COALESCE(u.team_name, ''), u.is_active
+         FROM users u`
+	if len(conds) > 0 {
+		query += "\n         WHERE " + strings.Join(conds, "\n           AND ")
+	}
+	// One extra row tells whether there is a next page.
+	query += "\n         ORDER BY u.id\n         LIMIT " + arg(limit+1)
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return domain.UserPage{}, fmt.Errorf("list users: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	page := domain.UserPage{Users: make([]domain.User, 0, limit)}
+	for rows.Next() {
+		var u domain.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return domain.UserPage{}, fmt.Errorf("scan user: %w", err)
+		}
+		page.Users = append(page.Users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.UserPage{}, fmt.Errorf("iterate users: %w", err)
+	}
+
+	if len(page.Users) > limit {
+		page.Users = page.Users[:limit]
+		page.Next = page.Users[limit-1].ID
+	}
+	return page, nil
+}
+
+// RemoveFromTeam detaches the given members from teamName. Users for whom
+// it was the primary team are left without one. It returns the IDs that
+// were actually members.
+func (r *UserRepo) RemoveFromTeam(ctx context.Context, teamName string, userIDs []string) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	removed := make([]string, 0, len(userIDs))
+	err := withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		query, args := buildInClause(`
+        DELETE FROM team_memberships
+        WHERE user_id IN (`, userIDs)
+		args = append(args, teamName)
+		query += fmt.Sprintf(" AND team_name = $%d RETURNING user_id", len(args))
+
+		rows, err := tx.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("remove users from team %s: %w", teamName, err)
+		}
+		defer func() {
+			if err := rows.Close(); err != nil {
+				// Log error but don't fail - rows are already read
+			}
+		}()
+
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return fmt.Errorf("scan removed user id: %w", err)
+			}
+			removed = append(removed, id)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("iterate removed user ids: %w", err)
+		}
+
+		query, args = buildInClause(`
+        UPDATE users
+        SET team_name = NULL,
+            updated_at = now()
+        WHERE id IN (`, userIDs)
+		args = append(args, teamName)
+		query += fmt.Sprintf(" AND team_name = $%d", len(args))
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("clear primary team %s: %w", teamName, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// AddMemberships makes the users members of teamName with the given role
+// without changing their primary team. An empty role means member for new
+// memberships and keeps the role of existing ones. An unknown team or user
+// yields NOT_FOUND.
+func (r *UserRepo) AddMemberships(ctx context.Context, teamName string, userIDs []string, role domain.MemberRole) error {
+	return withTx(ctx, r.db, func(ctx context.Context, tx *sql.Tx) error {
+		for _, id := range userIDs {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO team_memberships (team_name, user_id, role)
+                 VALUES ($1, $2, COALESCE(NULLIF($3, ''), 'member'))
+                 ON CONFLICT (team_name, user_id) DO UPDATE
+                 SET role = EXCLUDED.role
+                 WHERE $3 <> ''`,
+				teamName, id, string(role),
+			); err != nil {
+				return dbError("add membership of user "+id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// CurrentTeams returns the team of each of userIDs that exists and has one.
+// The rows are locked until the end of the transaction, so the answer holds
+// while the caller moves the users.
+func (r *UserRepo) CurrentTeams(ctx context.Context, userIDs []string) (map[string]string, error) {
+	teams := make(map[string]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return teams, nil
+	}
+
+	query, args := buildInClause(`
+        SELECT id, team_name
+        FROM users
+        WHERE team_name IS NOT NULL AND id IN (`, userIDs)
+	query += " FOR UPDATE"
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("select current teams: %w", err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			// Log error but don't fail - rows are already read
+		}
+	}()
+
+	for rows.Next() {
+		var id, team string
+		if err := rows.Scan(&id, &team); err != nil {
+			return nil, fmt.Errorf("scan current team: %w", err)
+		}
+		teams[id] = team
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate current teams: %w", err)
+	}
+	return teams, nil
+}
+
+// SetTeam moves the user to teamName and returns the updated user. A missing
+// team yields NOT_FOUND.
+func (r *UserRepo) SetTeam(ctx context.Context, id, teamName string) (*domain.User, error) {
+	var u domain.User
+	err := conn(ctx, r.db).QueryRowContext(ctx,
+		`UPDATE users
+         SET team_name = $2,
+             updated_at = now()
+         WHERE id = $1
+         RETURNING id, username, team_name, is_active`,
+		id, teamName,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sql.ErrNoRows
+		}
+		return nil, dbError("set user team", err)
+	}
+	return &u, nil
+}
+
+// SetRole changes the user's role in teamName. It returns sql.ErrNoRows if
+// the user is not a member of the team.
+func (r *UserRepo) SetRole(ctx context.Context, teamName, userID string, role domain.MemberRole) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE team_memberships SET role = $3 WHERE team_name = $1 AND user_id = $2`,
+		teamName, userID, string(role),
+	)
+	if err != nil {
+		return fmt.Errorf("set member role: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set member role: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetWorkingHours replaces the user's working hours, or clears them when h
+// is nil. An unknown user yields NOT_FOUND.
+func (r *UserRepo) SetWorkingHours(ctx context.Context, userID string, h *domain.WorkingHours) error {
+	if h == nil {
+		if _, err := conn(ctx, r.db).ExecContext(ctx,
+			`DELETE FROM user_working_hours WHERE user_id = $1`, userID,
+		); err != nil {
+			return fmt.Errorf("clear working hours: %w", err)
+		}
+		return nil
+	}
+
+	_, err := conn(ctx, r.db).ExecContext(ctx,
+		`INSERT INTO user_working_hours (user_id, time_zone, start_minute, end_minute)
+         VALUES ($1, $2, $3, $4)
+         ON CONFLICT (user_id) DO UPDATE
+         SET time_zone = EXCLUDED.time_zone,
+             start_minute = EXCLUDED.start_minute,
+             end_minute = EXCLUDED.end_minute,
+             updated_at = now()`,
+		userID, h.TimeZone, h.Start, h.End,
+	)
+	if err != nil {
+		return dbError("set working hours", err)
+	}
+	return nil
+}
+
+// SetMaxOpenReviews sets the user's own review cap; zero clears it. It
+// returns sql.ErrNoRows if the user does not exist.
+func (r *UserRepo) SetMaxOpenReviews(ctx context.Context, userID string, limit int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET max_open_reviews = NULLIF($2, 0),
+             updated_at = now()
+         WHERE id = $1`,
+		userID, limit,
+	)
+	if err != nil {
+		return fmt.Errorf("set max open reviews: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set max open reviews: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// SetWeeklyReviewQuota sets the user's weekly review quota; zero clears it.
+// It returns sql.ErrNoRows if the user does not exist.
+func (r *UserRepo) SetWeeklyReviewQuota(ctx context.Context, userID string, quota int) error {
+	res, err := conn(ctx, r.db).ExecContext(ctx,
+		`UPDATE users
+         SET weekly_review_quota = NULLIF($2, 0),
+             updated_at = now()
+         WHERE id = $1`,
+		userID, quota,
+	)
+	if err != nil {
+		return fmt.Errorf("set weekly review quota: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("set weekly review quota: %w", err)
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// reviewLoadColumns adds a user's (u) number of open reviews, the cap in
+// effect for them, the reviews assigned to them over the last week (including
+// ones since taken away) and their weekly quota to a query; reviewLoadJoin
+// brings in the default cap of their primary team.
+const (
+	reviewLoadColumns = `(SELECT count(*) FROM pull_request_reviewers rr
+                 JOIN pull_requests rp ON rp.id = rr.pr_id
+                 WHERE rr.reviewer_id = u.id AND rp.status = 'OPEN'),
+                COALESCE(u.max_open_reviews, pt.default_max_open_reviews, 0),
+                (SELECT count(*) FROM review_assignments wr
+                 WHERE wr.reviewer_id = u.id AND wr.assigned_at > now() - interval '7 days'),
+                COALESCE(u.weekly_review_quota, 0)`
+	reviewLoadJoin = `LEFT JOIN teams pt ON pt.name = u.team_name`
+)
+
+// workingHoursColumns and workingHoursJoin add a user's (u) working hours to
+// a query; scan them with nullWorkingHours.
+const (
+	workingHoursColumns = `wh.time_zone, wh.start_minute, wh.end_minute`
+	workingHoursJoin    = `LEFT JOIN user_working_hours wh ON wh.user_id = u.id`
+)
+
+type nullWorkingHours struct {
+	timeZone sql.NullString
+	start    sql.NullInt32
+	end      sql.NullInt32
+}
+
+func (w *nullWorkingHours) dest() []any {
+	return []any{&w.timeZone, &w.start, &w.end}
+}
+
+func (w *nullWorkingHours) value() *domain.WorkingHours {
+	if !w.timeZone.Valid {
+		return nil
+	}
+	return &domain.WorkingHours{
+		TimeZone: w.timeZone.String,
+		Start:    int(w.start.Int32),
+		End:      int(w.end.Int32),
+	}
+}
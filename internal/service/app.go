@@ -1,17 +1,49 @@
 package service
 
 type App struct {
-	Team  *TeamService
-	User  *UserService
-	PR    *PRService
-	Stats *StatsService
+	Team   *TeamService
+	User   *UserService
+	PR     *PRService
+	Stats  *StatsService
+	Roster *RosterService
+	Sync   *ReviewerSyncService
+
+	OutOfOffice *OutOfOfficeService
+
+	Idempotency *IdempotencyService
 }
 
-func NewApp(team *TeamService, user *UserService, pr *PRService, stats *StatsService) *App {
+func NewApp(
+	team *TeamService,
+	user *UserService,
+	pr *PRService,
+	stats *StatsService,
+	roster *RosterService,
+	ooo *OutOfOfficeService,
+) *App {
 	return &App{
-		Team:  team,
-		User:  user,
-		PR:    pr,
-		Stats: stats,
+		Team:        team,
+		User:        user,
+		PR:          pr,
+		Stats:       stats,
+		Roster:      roster,
+		OutOfOffice: ooo,
 	}
 }
+
+// WithReviewerSync attaches the optional Git hosting integration.
+func (a *App) WithReviewerSync(sync *ReviewerSyncService) *App {
+	a.Sync = sync
+	a.PR.SetReviewerSync(sync)
+	a.Team.SetReviewerSync(a.PR)
+	a.OutOfOffice.SetReviewerSync(a.PR)
+	a.Roster.SetReviewerSync(a.PR)
+	a.User.SetReviewerSync(a.PR)
+	return a
+}
+
+// WithIdempotency enables Idempotency-Key handling for mutating requests.
+func (a *App) WithIdempotency(idempotency *IdempotencyService) *App {
+	a.Idempotency = idempotency
+	return a
+}
@@ -0,0 +1,48 @@
+package converter
+
+import (
+	"fmt"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// workingHoursFromClock builds working hours from HH:MM times; an invalid
+// time is reported as a validation error of its field.
+func workingHoursFromClock(timeZone, start, end string) (*domain.WorkingHours, error) {
+	h := &domain.WorkingHours{TimeZone: timeZone}
+	var fields []domain.FieldError
+	var ok bool
+	if h.Start, ok = parseClock(start); !ok {
+		fields = append(fields, domain.FieldError{Field: "working_hours.start", Reason: "must be HH:MM"})
+	}
+	if h.End, ok = parseClock(end); !ok {
+		fields = append(fields, domain.FieldError{Field: "working_hours.end", Reason: "must be HH:MM"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid working hours", fields...)
+	}
+	return h, nil
+}
+
+// parseClock returns the minutes since midnight of an HH:MM time.
+func parseClock(s string) (int, bool) {
+	if len(s) != 5 || s[2] != ':' {
+		return 0, false
+	}
+	digits := [4]int{}
+	for i, c := range []byte{s[0], s[1], s[3], s[4]} {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		digits[i] = int(c - '0')
+	}
+	hour, minute := digits[0]*10+digits[1], digits[2]*10+digits[3]
+	if hour > 23 || minute > 59 {
+		return 0, false
+	}
+	return hour*60 + minute, true
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
@@ -17,10 +17,14 @@ func TeamFromOpenAPI(t *openapi.Team) domain.Team {
 		members = append(members, UserFromTeamMember(&m, t.TeamName))
 	}
 
-	return domain.Team{
+	team := domain.Team{
 		Name:    t.TeamName,
 		Members: members,
 	}
+	if t.ParentTeam != nil {
+		team.ParentTeam = *t.ParentTeam
+	}
+	return team
 }
 
 func TeamToOpenAPI(t *domain.Team) openapi.Team {
@@ -33,9 +37,35 @@ func TeamToOpenAPI(t *domain.Team) openapi.Team {
 		members = append(members, TeamMemberFromDomain(&u))
 	}
 
-	return openapi.Team{
-		TeamName: t.Name,
-		Members:  members,
+	team := openapi.Team{
+		TeamName:       t.Name,
+		Members:        members,
+		ArchivedAt:     unixToTimePtr(t.ArchivedAt),
+		MaxOpenReviews: limitPtr(t.MaxOpenReviews),
+	}
+	if t.ParentTeam != "" {
+		parent := t.ParentTeam
+		team.ParentTeam = &parent
+	}
+	if len(t.Subteams) > 0 {
+		subteams := make([]openapi.Team, 0, len(t.Subteams))
+		for i := range t.Subteams {
+			subteams = append(subteams, TeamToOpenAPI(&t.Subteams[i]))
+		}
+		team.Subteams = &subteams
+	}
+	return team
+}
+
+func TeamMembershipToOpenAPI(r *domain.TeamMembershipResult) openapi.TeamMembershipResponse {
+	if r == nil {
+		return openapi.TeamMembershipResponse{}
+	}
+
+	return openapi.TeamMembershipResponse{
+		Team:                TeamToOpenAPI(&r.Team),
+		RemovedMembers:      append(make([]string, 0, len(r.RemovedMembers)), r.RemovedMembers...),
+		UpdatedPullRequests: int32(r.UpdatedPullRequests),
 	}
 }
 
@@ -44,11 +74,61 @@ func TeamMemberFromDomain(u *domain.User) openapi.TeamMember {
 		return openapi.TeamMember{}
 	}
 
-	return openapi.TeamMember{
+	m := openapi.TeamMember{
 		UserId:   u.ID,
 		Username: u.Username,
 		IsActive: u.IsActive,
 	}
+	if u.Role != "" {
+		role := openapi.TeamRole(u.Role)
+		m.Role = &role
+	}
+	return m
+}
+
+func TeamSummaryToOpenAPI(t *domain.TeamSummary) openapi.TeamSummary {
+	if t == nil {
+		return openapi.TeamSummary{}
+	}
+
+	summary := openapi.TeamSummary{
+		TeamName:          t.Name,
+		MemberCount:       int32(t.MemberCount),
+		ActiveMemberCount: int32(t.ActiveMemberCount),
+	}
+	if t.ParentTeam != "" {
+		parent := t.ParentTeam
+		summary.ParentTeam = &parent
+	}
+	return summary
+}
+
+func RosterImportResultToOpenAPI(r *domain.RosterImportResult) openapi.RosterImportResult {
+	if r == nil {
+		return openapi.RosterImportResult{Changes: []openapi.RosterChange{}}
+	}
+
+	result := openapi.RosterImportResult{
+		DryRun:  r.DryRun,
+		Changes: make([]openapi.RosterChange, 0, len(r.Changes)),
+	}
+	for _, c := range r.Changes {
+		change := openapi.RosterChange{Kind: openapi.RosterChangeKind(c.Kind)}
+		if c.TeamName != "" {
+			teamName := c.TeamName
+			change.TeamName = &teamName
+		}
+		if c.UserID != "" {
+			userID := c.UserID
+			change.UserId = &userID
+		}
+		if c.Detail != "" {
+			detail := c.Detail
+			change.Detail = &detail
+		}
+		result.Changes = append(result.Changes, change)
+	}
+	return result
 }
 
 func UserFromTeamMember(m *openapi.TeamMember, teamName string) domain.User {
@@ -61,9 +141,18 @@ func UserFromTeamMember(m *openapi.TeamMember, teamName string) domain.User {
 		Username: m.Username,
 		TeamName: teamName,
 		IsActive: m.IsActive,
+		Role:     TeamRoleFromOpenAPI(m.Role),
 	}
 }
 
+// TeamRoleFromOpenAPI maps an omitted role to "".
+func TeamRoleFromOpenAPI(r *openapi.TeamRole) domain.MemberRole {
+	if r == nil {
+		return ""
+	}
+	return domain.MemberRole(*r)
+}
+
 func UserFromOpenAPI(u *openapi.User) domain.User {
 	if u == nil {
 		return domain.User{}
@@ -90,12 +179,95 @@ func UserToOpenAPI(u *domain.User) openapi.User {
 	}
 }
 
+func UserDetailsToOpenAPI(d *domain.UserDetails) openapi.UserDetails {
+	if d == nil {
+		return openapi.UserDetails{}
+	}
+
+	weekly := int32(d.User.WeeklyReviews)
+	return openapi.UserDetails{
+		User: UserToOpenAPI(&d.User),
+		Reviews: openapi.ReviewSummary{
+			Open:      int32(d.Reviews.Open),
+			Completed: int32(d.Reviews.Completed),
+		},
+		WorkingHours:      WorkingHoursToOpenAPI(d.User.WorkingHours),
+		MaxOpenReviews:    limitPtr(d.User.MaxOpenReviews),
+		WeeklyReviewQuota: limitPtr(d.User.WeeklyReviewQuota),
+		WeeklyReviews:     &weekly,
+	}
+}
+
+// WorkingHoursFromOpenAPI returns nil for nil, which clears the working
+// hours.
+func WorkingHoursFromOpenAPI(h *openapi.WorkingHours) (*domain.WorkingHours, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return workingHoursFromClock(h.TimeZone, h.Start, h.End)
+}
+
+func WorkingHoursToOpenAPI(h *domain.WorkingHours) *openapi.WorkingHours {
+	if h == nil {
+		return nil
+	}
+
+	return &openapi.WorkingHours{
+		TimeZone: h.TimeZone,
+		Start:    formatClock(h.Start),
+		End:      formatClock(h.End),
+	}
+}
+
+// MaxOpenReviewsToOpenAPI reports the limit in effect for the user.
+func MaxOpenReviewsToOpenAPI(u *domain.User) openapi.MaxOpenReviewsResponse {
+	if u == nil {
+		return openapi.MaxOpenReviewsResponse{}
+	}
+
+	open := int32(u.OpenReviews)
+	return openapi.MaxOpenReviewsResponse{
+		UserId:         u.ID,
+		MaxOpenReviews: limitPtr(u.MaxOpenReviews),
+		OpenReviews:    &open,
+	}
+}
+
+// WeeklyReviewQuotaToOpenAPI reports the user's quota and their reviews of
+// the quota window.
+func WeeklyReviewQuotaToOpenAPI(u *domain.User) openapi.WeeklyReviewQuotaResponse {
+	if u == nil {
+		return openapi.WeeklyReviewQuotaResponse{}
+	}
+
+	return openapi.WeeklyReviewQuotaResponse{
+		UserId:            u.ID,
+		WeeklyReviewQuota: limitPtr(u.WeeklyReviewQuota),
+		WeeklyReviews:     int32(u.WeeklyReviews),
+	}
+}
+
+func OutOfOfficePeriodToOpenAPI(p *domain.OutOfOfficePeriod) openapi.OutOfOfficePeriod {
+	if p == nil {
+		return openapi.OutOfOfficePeriod{}
+	}
+
+	return openapi.OutOfOfficePeriod{
+		OooId:        p.ID,
+		UserId:       p.UserID,
+		StartsAt:     time.Unix(p.StartsAt, 0).UTC(),
+		EndsAt:       time.Unix(p.EndsAt, 0).UTC(),
+		Reason:       p.Reason,
+		ReassignedAt: unixToTimePtr(p.ReassignedAt),
+	}
+}
+
 func PullRequestFromOpenAPI(p *openapi.PullRequest) domain.PullRequest {
 	if p == nil {
 		return domain.PullRequest{}
 	}
 
-	return domain.PullRequest{
+	pr := domain.PullRequest{
 		ID:                p.PullRequestId,
 		Name:              p.PullRequestName,
 		AuthorID:          p.AuthorId,
@@ -104,6 +276,13 @@ func PullRequestFromOpenAPI(p *openapi.PullRequest) domain.PullRequest {
 		CreatedAt:         timePtrToUnix(p.CreatedAt),
 		MergedAt:          timePtrToUnix(p.MergedAt),
 	}
+	if p.TeamName != nil {
+		pr.TeamName = *p.TeamName
+	}
+	if p.Labels != nil {
+		pr.Labels = append([]string(nil), *p.Labels...)
+	}
+	return pr
 }
 
 func PullRequestToOpenAPI(p *domain.PullRequest) openapi.PullRequest {
@@ -113,7 +292,7 @@ func PullRequestToOpenAPI(p *domain.PullRequest) openapi.PullRequest {
 
 	assigned := append([]string(nil), p.AssignedReviewers...)
 
-	return openapi.PullRequest{
+	pr := openapi.PullRequest{
 		PullRequestId:     p.ID,
 		PullRequestName:   p.Name,
 		AuthorId:          p.AuthorID,
@@ -122,6 +301,20 @@ func PullRequestToOpenAPI(p *domain.PullRequest) openapi.PullRequest {
 		CreatedAt:         unixToTimePtr(p.CreatedAt),
 		MergedAt:          unixToTimePtr(p.MergedAt),
 	}
+	if p.TeamName != "" {
+		teamName := p.TeamName
+		pr.TeamName = &teamName
+	}
+	if len(p.Labels) > 0 {
+		labels := append([]string(nil), p.Labels...)
+		pr.Labels = &labels
+	}
+	if !p.GitHub.IsZero() {
+		repo, number := p.GitHub.Repo, p.GitHub.Number
+		pr.GithubRepo = &repo
+		pr.GithubNumber = &number
+	}
+	return pr
 }
 
 func PullRequestShortFromDomain(p *domain.PullRequest) openapi.PullRequestShort {
@@ -129,12 +322,19 @@ func PullRequestShortFromDomain(p *domain.PullRequest) openapi.PullRequestShort
 		return openapi.PullRequestShort{}
 	}
 
-	return openapi.PullRequestShort{
+	short := openapi.PullRequestShort{
 		PullRequestId:   p.ID,
 		PullRequestName: p.Name,
 		AuthorId:        p.AuthorID,
 		Status:          openapi.PullRequestShortStatus(p.Status),
+		CreatedAt:       unixToTimePtr(p.CreatedAt),
+		MergedAt:        unixToTimePtr(p.MergedAt),
 	}
+	if p.AssignedReviewers != nil {
+		reviewers := append(make([]string, 0, len(p.AssignedReviewers)), p.AssignedReviewers...)
+		short.AssignedReviewers = &reviewers
+	}
+	return short
 }
 
 func unixToTimePtr(v int64) *time.Time {
@@ -145,6 +345,15 @@ func unixToTimePtr(v int64) *time.Time {
 	return &t
 }
 
+// limitPtr returns nil for zero, i.e. no limit.
+func limitPtr(v int) *int32 {
+	if v == 0 {
+		return nil
+	}
+	n := int32(v)
+	return &n
+}
+
 func timePtrToUnix(t *time.Time) int64 {
 	if t == nil {
 		return 0
@@ -0,0 +1,331 @@
+package converter
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	reviewerv1 "github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/api/proto/reviewer/v1"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+func TeamFromProto(t *reviewerv1.Team) domain.Team {
+	if t == nil {
+		return domain.Team{}
+	}
+
+	members := make([]domain.User, 0, len(t.GetMembers()))
+	for _, m := range t.GetMembers() {
+		members = append(members, domain.User{
+			ID:       m.GetUserId(),
+			Username: m.GetUsername(),
+			TeamName: t.GetTeamName(),
+			IsActive: m.GetIsActive(),
+			Role:     TeamRoleFromProto(m.GetRole()),
+		})
+	}
+
+	return domain.Team{
+		Name:       t.GetTeamName(),
+		Members:    members,
+		ParentTeam: t.GetParentTeam(),
+	}
+}
+
+func TeamToProto(t *domain.Team) *reviewerv1.Team {
+	if t == nil {
+		return &reviewerv1.Team{}
+	}
+
+	members := make([]*reviewerv1.TeamMember, 0, len(t.Members))
+	for _, u := range t.Members {
+		members = append(members, &reviewerv1.TeamMember{
+			UserId:   u.ID,
+			Username: u.Username,
+			IsActive: u.IsActive,
+			Role:     teamRoleToProto(u.Role),
+		})
+	}
+
+	subteams := make([]*reviewerv1.Team, 0, len(t.Subteams))
+	for i := range t.Subteams {
+		subteams = append(subteams, TeamToProto(&t.Subteams[i]))
+	}
+
+	return &reviewerv1.Team{
+		TeamName:       t.Name,
+		Members:        members,
+		ArchivedAt:     unixToTimestamp(t.ArchivedAt),
+		ParentTeam:     t.ParentTeam,
+		Subteams:       subteams,
+		MaxOpenReviews: int32(t.MaxOpenReviews),
+	}
+}
+
+func TeamMembershipToProto(r *domain.TeamMembershipResult) *reviewerv1.TeamMembershipResponse {
+	if r == nil {
+		return &reviewerv1.TeamMembershipResponse{}
+	}
+
+	return &reviewerv1.TeamMembershipResponse{
+		Team:                TeamToProto(&r.Team),
+		RemovedMembers:      r.RemovedMembers,
+		UpdatedPullRequests: int32(r.UpdatedPullRequests),
+	}
+}
+
+func TeamArchiveToProto(r *domain.TeamArchiveResult) *reviewerv1.ArchiveTeamResponse {
+	if r == nil {
+		return &reviewerv1.ArchiveTeamResponse{}
+	}
+
+	return &reviewerv1.ArchiveTeamResponse{
+		TeamName:            r.TeamName,
+		ArchivedAt:          unixToTimestamp(r.ArchivedAt),
+		DeactivatedUsers:    int32(r.DeactivatedUsers),
+		UpdatedPullRequests: int32(r.UpdatedPullRequests),
+	}
+}
+
+func TeamSummaryToProto(t *domain.TeamSummary) *reviewerv1.TeamSummary {
+	if t == nil {
+		return &reviewerv1.TeamSummary{}
+	}
+
+	return &reviewerv1.TeamSummary{
+		TeamName:          t.Name,
+		ParentTeam:        t.ParentTeam,
+		MemberCount:       int32(t.MemberCount),
+		ActiveMemberCount: int32(t.ActiveMemberCount),
+	}
+}
+
+func UserToProto(u *domain.User) *reviewerv1.User {
+	if u == nil {
+		return &reviewerv1.User{}
+	}
+
+	return &reviewerv1.User{
+		UserId:   u.ID,
+		Username: u.Username,
+		TeamName: u.TeamName,
+		IsActive: u.IsActive,
+	}
+}
+
+func UserDetailsToProto(d *domain.UserDetails) *reviewerv1.GetUserResponse {
+	if d == nil {
+		return &reviewerv1.GetUserResponse{}
+	}
+
+	return &reviewerv1.GetUserResponse{
+		User: UserToProto(&d.User),
+		Reviews: &reviewerv1.ReviewSummary{
+			Open:      int32(d.Reviews.Open),
+			Completed: int32(d.Reviews.Completed),
+		},
+		WorkingHours:      WorkingHoursToProto(d.User.WorkingHours),
+		MaxOpenReviews:    int32(d.User.MaxOpenReviews),
+		WeeklyReviewQuota: int32(d.User.WeeklyReviewQuota),
+		WeeklyReviews:     int32(d.User.WeeklyReviews),
+	}
+}
+
+// WorkingHoursFromProto returns nil for nil, which clears the working hours.
+func WorkingHoursFromProto(h *reviewerv1.WorkingHours) (*domain.WorkingHours, error) {
+	if h == nil {
+		return nil, nil
+	}
+	return workingHoursFromClock(h.GetTimeZone(), h.GetStart(), h.GetEnd())
+}
+
+func WorkingHoursToProto(h *domain.WorkingHours) *reviewerv1.WorkingHours {
+	if h == nil {
+		return nil
+	}
+
+	return &reviewerv1.WorkingHours{
+		TimeZone: h.TimeZone,
+		Start:    formatClock(h.Start),
+		End:      formatClock(h.End),
+	}
+}
+
+func OutOfOfficePeriodToProto(p *domain.OutOfOfficePeriod) *reviewerv1.OutOfOfficePeriod {
+	if p == nil {
+		return &reviewerv1.OutOfOfficePeriod{}
+	}
+
+	return &reviewerv1.OutOfOfficePeriod{
+		OooId:        p.ID,
+		UserId:       p.UserID,
+		StartsAt:     unixToTimestamp(p.StartsAt),
+		EndsAt:       unixToTimestamp(p.EndsAt),
+		Reason:       p.Reason,
+		ReassignedAt: unixToTimestamp(p.ReassignedAt),
+	}
+}
+
+func PullRequestToProto(p *domain.PullRequest) *reviewerv1.PullRequest {
+	if p == nil {
+		return &reviewerv1.PullRequest{}
+	}
+
+	return &reviewerv1.PullRequest{
+		PullRequestId:     p.ID,
+		PullRequestName:   p.Name,
+		AuthorId:          p.AuthorID,
+		Status:            prStatusToProto(p.Status),
+		AssignedReviewers: append([]string(nil), p.AssignedReviewers...),
+		CreatedAt:         unixToTimestamp(p.CreatedAt),
+		MergedAt:          unixToTimestamp(p.MergedAt),
+		Version:           p.Version,
+		TeamName:          p.TeamName,
+		Labels:            append([]string(nil), p.Labels...),
+		GithubRepo:        p.GitHub.Repo,
+		GithubNumber:      int32(p.GitHub.Number),
+	}
+}
+
+func PullRequestShortToProto(p *domain.PullRequest) *reviewerv1.PullRequestShort {
+	if p == nil {
+		return &reviewerv1.PullRequestShort{}
+	}
+
+	return &reviewerv1.PullRequestShort{
+		PullRequestId:     p.ID,
+		PullRequestName:   p.Name,
+		AuthorId:          p.AuthorID,
+		Status:            prStatusToProto(p.Status),
+		AssignedReviewers: append([]string(nil), p.AssignedReviewers...),
+		CreatedAt:         unixToTimestamp(p.CreatedAt),
+		MergedAt:          unixToTimestamp(p.MergedAt),
+	}
+}
+
+// PRStatusFromProto maps UNSPECIFIED (and unknown values) to "", which
+// filters match as any status.
+func PRStatusFromProto(s reviewerv1.PullRequestStatus) domain.PRStatus {
+	switch s {
+	case reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN:
+		return domain.PRStatusOpen
+	case reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED:
+		return domain.PRStatusMerged
+	default:
+		return ""
+	}
+}
+
+func OpenReviewsPolicyFromProto(p reviewerv1.OpenReviewsPolicy) domain.OpenReviewsPolicy {
+	switch p {
+	case reviewerv1.OpenReviewsPolicy_OPEN_REVIEWS_POLICY_REJECT:
+		return domain.OpenReviewsReject
+	case reviewerv1.OpenReviewsPolicy_OPEN_REVIEWS_POLICY_REASSIGN:
+		return domain.OpenReviewsReassign
+	case reviewerv1.OpenReviewsPolicy_OPEN_REVIEWS_POLICY_KEEP:
+		return domain.OpenReviewsKeep
+	default:
+		return ""
+	}
+}
+
+// TeamRoleFromProto maps UNSPECIFIED (and unknown values) to "", which
+// keeps existing roles and defaults new members to domain.RoleMember.
+// RosterFormatFromProto maps an unspecified format to YAML.
+func RosterFormatFromProto(f reviewerv1.RosterFormat) domain.RosterFormat {
+	if f == reviewerv1.RosterFormat_ROSTER_FORMAT_CSV {
+		return domain.RosterFormatCSV
+	}
+	return domain.RosterFormatYAML
+}
+
+func RosterImportResultToProto(r *domain.RosterImportResult) *reviewerv1.ImportRosterResponse {
+	if r == nil {
+		return &reviewerv1.ImportRosterResponse{}
+	}
+
+	resp := &reviewerv1.ImportRosterResponse{
+		DryRun:  r.DryRun,
+		Changes: make([]*reviewerv1.RosterChange, 0, len(r.Changes)),
+	}
+	for _, c := range r.Changes {
+		resp.Changes = append(resp.Changes, &reviewerv1.RosterChange{
+			Kind:     string(c.Kind),
+			TeamName: c.TeamName,
+			UserId:   c.UserID,
+			Detail:   c.Detail,
+		})
+	}
+	return resp
+}
+
+func TeamRoleFromProto(r reviewerv1.TeamRole) domain.MemberRole {
+	switch r {
+	case reviewerv1.TeamRole_TEAM_ROLE_MEMBER:
+		return domain.RoleMember
+	case reviewerv1.TeamRole_TEAM_ROLE_MAINTAINER:
+		return domain.RoleMaintainer
+	case reviewerv1.TeamRole_TEAM_ROLE_LEAD:
+		return domain.RoleLead
+	default:
+		return ""
+	}
+}
+
+func ReviewerSyncToProto(s *domain.ReviewerSync) *reviewerv1.ReviewerSync {
+	if s == nil {
+		return &reviewerv1.ReviewerSync{}
+	}
+
+	return &reviewerv1.ReviewerSync{
+		PullRequestId: s.PullRequestID,
+		Status:        reviewerSyncStatusToProto(s.Status),
+		Attempts:      int32(s.Attempts),
+		LastError:     s.LastError,
+		UpdatedAt:     unixToTimestamp(s.UpdatedAt),
+	}
+}
+
+func reviewerSyncStatusToProto(s domain.ReviewerSyncStatus) reviewerv1.ReviewerSyncStatus {
+	switch s {
+	case domain.ReviewerSyncPending:
+		return reviewerv1.ReviewerSyncStatus_REVIEWER_SYNC_STATUS_PENDING
+	case domain.ReviewerSyncSynced:
+		return reviewerv1.ReviewerSyncStatus_REVIEWER_SYNC_STATUS_SYNCED
+	case domain.ReviewerSyncFailed:
+		return reviewerv1.ReviewerSyncStatus_REVIEWER_SYNC_STATUS_FAILED
+	default:
+		return reviewerv1.ReviewerSyncStatus_REVIEWER_SYNC_STATUS_UNSPECIFIED
+	}
+}
+
+func teamRoleToProto(r domain.MemberRole) reviewerv1.TeamRole {
+	switch r {
+	case domain.RoleMember:
+		return reviewerv1.TeamRole_TEAM_ROLE_MEMBER
+	case domain.RoleMaintainer:
+		return reviewerv1.TeamRole_TEAM_ROLE_MAINTAINER
+	case domain.RoleLead:
+		return reviewerv1.TeamRole_TEAM_ROLE_LEAD
+	default:
+		return reviewerv1.TeamRole_TEAM_ROLE_UNSPECIFIED
+	}
+}
+
+func prStatusToProto(s domain.PRStatus) reviewerv1.PullRequestStatus {
+	switch s {
+	case domain.PRStatusOpen:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_OPEN
+	case domain.PRStatusMerged:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_MERGED
+	default:
+		return reviewerv1.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED
+	}
+}
+
+func unixToTimestamp(v int64) *timestamppb.Timestamp {
+	if v == 0 {
+		return nil
+	}
+	return timestamppb.New(time.Unix(v, 0).UTC())
+}
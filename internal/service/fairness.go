@@ -0,0 +1,13 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// AssignmentHistory counts the reviews assigned to users over time and
+// knows the window fair selection looks back over; zero turns it off.
+type AssignmentHistory interface {
+	CountAssignmentsSince(ctx context.Context, userIDs []string, since time.Time) (map[string]int, error)
+	FairnessWindow() time.Duration
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type IdempotencyRepository interface {
+	Claim(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore int64) (bool, error)
+	Get(ctx context.Context, key, endpoint string) (*domain.IdempotencyRecord, error)
+	Complete(ctx context.Context, key, endpoint string, statusCode int, headers map[string]string, body []byte) error
+	Release(ctx context.Context, key, endpoint string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdempotencyService remembers responses of mutating requests by their
+// Idempotency-Key so that a retried request is answered without running
+// it a second time.
+type IdempotencyService struct {
+	repo        IdempotencyRepository
+	nowFunc     func() time.Time
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+func NewIdempotencyService(repo IdempotencyRepository, nowFunc func() time.Time, ttl time.Duration) *IdempotencyService {
+	if nowFunc == nil {
+		nowFunc = time.Now
+	}
+	return &IdempotencyService{
+		repo:    repo,
+		nowFunc: nowFunc,
+		ttl:     ttl,
+	}
+}
+
+// SetLockTimeout sets how long a request holds its key while it runs. A key
+// still in progress after that, e.g. because the instance running the
+// request died, is handed to the next request with it. Zero (the default)
+// holds the key until the TTL expires.
+func (s *IdempotencyService) SetLockTimeout(d time.Duration) {
+	s.lockTimeout = d
+}
+
+// Begin reserves the key for a new request. It returns nil when the caller
+// should run the request, or the stored record when the response has to be
+// replayed.
+func (s *IdempotencyService) Begin(ctx context.Context, key, endpoint, requestHash string) (*domain.IdempotencyRecord, error) {
+	now := s.nowFunc()
+	var staleBefore int64
+	if s.lockTimeout > 0 {
+		staleBefore = now.Add(-s.lockTimeout).Unix()
+	}
+	claimed, err := s.repo.Claim(ctx, &domain.IdempotencyRecord{
+		Key:         key,
+		Endpoint:    endpoint,
+		RequestHash: requestHash,
+		CreatedAt:   now.Unix(),
+		ExpiresAt:   now.Add(s.ttl).Unix(),
+	}, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	rec, err := s.repo.Get(ctx, key, endpoint)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// Released or purged between Claim and Get: the first request
+			// failed, let the client retry.
+			return nil, domain.NewDomainError(domain.ErrorCodeIdempotencyInProgress, "request with this idempotency key is in progress")
+		}
+		return nil, fmt.Errorf("load idempotency record: %w", err)
+	}
+
+	if rec.RequestHash != requestHash {
+		return nil, domain.NewDomainError(domain.ErrorCodeIdempotencyKeyReused, "idempotency key was used with a different request body")
+	}
+	if !rec.Completed {
+		return nil, domain.NewDomainError(domain.ErrorCodeIdempotencyInProgress, "request with this idempotency key is in progress")
+	}
+	return rec, nil
+}
+
+// Complete stores the response to replay: its status, the headers to send
+// again and the body.
+func (s *IdempotencyService) Complete(
+	ctx context.Context,
+	key, endpoint string,
+	statusCode int,
+	headers map[string]string,
+	body []byte,
+) error {
+	return s.repo.Complete(ctx, key, endpoint, statusCode, headers, body)
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, key, endpoint string) error {
+	return s.repo.Release(ctx, key, endpoint)
+}
+
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, s.nowFunc())
+}
+
+// TTL is how long a stored response is replayed.
+func (s *IdempotencyService) TTL() time.Duration {
+	return s.ttl
+}
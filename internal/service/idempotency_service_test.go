@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	completed := &domain.IdempotencyRecord{
+		Key:          "k1",
+		Endpoint:     "POST /pullRequest/create",
+		RequestHash:  "hash-a",
+		Completed:    true,
+		StatusCode:   201,
+		ResponseBody: []byte(`{"pr":{}}`),
+	}
+	pending := *completed
+	pending.Completed = false
+
+	tests := []struct {
+		name       string
+		repo       *mocks.MockIdempotencyRepository
+		hash       string
+		wantReplay bool
+		wantCode   domain.ErrorCode
+		wantErr    bool
+	}{
+		{
+			name: "новый ключ",
+			repo: &mocks.MockIdempotencyRepository{ClaimResult: true},
+			hash: "hash-a",
+		},
+		{
+			name:       "повтор завершённого запроса",
+			repo:       &mocks.MockIdempotencyRepository{GetResult: completed},
+			hash:       "hash-a",
+			wantReplay: true,
+		},
+		{
+			name:     "ключ с другим телом",
+			repo:     &mocks.MockIdempotencyRepository{GetResult: completed},
+			hash:     "hash-b",
+			wantCode: domain.ErrorCodeIdempotencyKeyReused,
+		},
+		{
+			name:     "первый запрос ещё выполняется",
+			repo:     &mocks.MockIdempotencyRepository{GetResult: &pending},
+			hash:     "hash-a",
+			wantCode: domain.ErrorCodeIdempotencyInProgress,
+		},
+		{
+			name:     "запись исчезла между Claim и Get",
+			repo:     &mocks.MockIdempotencyRepository{GetErr: sql.ErrNoRows},
+			hash:     "hash-a",
+			wantCode: domain.ErrorCodeIdempotencyInProgress,
+		},
+		{
+			name:    "ошибка БД",
+			repo:    &mocks.MockIdempotencyRepository{ClaimErr: errors.New("db down")},
+			hash:    "hash-a",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewIdempotencyService(tt.repo, func() time.Time { return time.Unix(1000, 0) }, time.Hour)
+
+			rec, err := svc.Begin(context.Background(), "k1", "POST /pullRequest/create", tt.hash)
+
+			if tt.wantCode != "" {
+				var de *domain.DomainError
+				if !errors.As(err, &de) {
+					t.Fatalf("expected domain error %s, got %v", tt.wantCode, err)
+				}
+				if de.Code != tt.wantCode {
+					t.Errorf("expected code %s, got %s", tt.wantCode, de.Code)
+				}
+				return
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (rec != nil) != tt.wantReplay {
+				t.Errorf("expected replay %v, got record %+v", tt.wantReplay, rec)
+			}
+
+			claimed := tt.repo.Claimed[0]
+			if claimed.CreatedAt != 1000 || claimed.ExpiresAt != 4600 {
+				t.Errorf("expected claim window 1000..4600, got %d..%d", claimed.CreatedAt, claimed.ExpiresAt)
+			}
+		})
+	}
+}
+
+func TestIdempotencyService_BeginLockTimeout(t *testing.T) {
+	repo := &mocks.MockIdempotencyRepository{ClaimResult: true}
+	svc := NewIdempotencyService(repo, func() time.Time { return time.Unix(1000, 0) }, time.Hour)
+
+	if _, err := svc.Begin(context.Background(), "k1", "POST /pullRequest/create", "hash-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.StaleBefore != 0 {
+		t.Errorf("expected pending keys to be held until the TTL without a lock timeout, got staleBefore %d", repo.StaleBefore)
+	}
+
+	svc.SetLockTimeout(time.Minute)
+	if _, err := svc.Begin(context.Background(), "k1", "POST /pullRequest/create", "hash-a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if repo.StaleBefore != 940 {
+		t.Errorf("expected pending keys claimed before 940 to be taken over, got staleBefore %d", repo.StaleBefore)
+	}
+}
@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+	"time"
+)
+
+type MockAssignmentHistory struct {
+	Window time.Duration
+	Counts map[string]int
+	Err    error
+	// Since records the last CountAssignmentsSince call.
+	Since time.Time
+}
+
+func (m *MockAssignmentHistory) CountAssignmentsSince(ctx context.Context, userIDs []string, since time.Time) (map[string]int, error) {
+	m.Since = since
+	return m.Counts, m.Err
+}
+
+func (m *MockAssignmentHistory) FairnessWindow() time.Duration {
+	return m.Window
+}
@@ -0,0 +1,55 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockIdempotencyRepository struct {
+	ClaimResult bool
+	ClaimErr    error
+	Claimed     []domain.IdempotencyRecord
+	StaleBefore int64
+
+	GetResult *domain.IdempotencyRecord
+	GetErr    error
+
+	CompleteErr      error
+	CompletedCode    int
+	CompletedHeaders map[string]string
+	CompletedBody    []byte
+	ReleaseErr       error
+	Released         int
+	DeleteResult     int64
+	DeleteErr        error
+	DeleteExpiredAt  time.Time
+}
+
+func (m *MockIdempotencyRepository) Claim(ctx context.Context, rec *domain.IdempotencyRecord, staleBefore int64) (bool, error) {
+	m.Claimed = append(m.Claimed, *rec)
+	m.StaleBefore = staleBefore
+	return m.ClaimResult, m.ClaimErr
+}
+
+func (m *MockIdempotencyRepository) Get(ctx context.Context, key, endpoint string) (*domain.IdempotencyRecord, error) {
+	return m.GetResult, m.GetErr
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, key, endpoint string, statusCode int, headers map[string]string, body []byte) error {
+	m.CompletedCode = statusCode
+	m.CompletedHeaders = headers
+	m.CompletedBody = append([]byte(nil), body...)
+	return m.CompleteErr
+}
+
+func (m *MockIdempotencyRepository) Release(ctx context.Context, key, endpoint string) error {
+	m.Released++
+	return m.ReleaseErr
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	m.DeleteExpiredAt = now
+	return m.DeleteResult, m.DeleteErr
+}
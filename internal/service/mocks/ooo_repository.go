@@ -0,0 +1,60 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockOutOfOfficeRepository struct {
+	CreateErr error
+	// Created records the periods passed to Create; Create returns them with
+	// IDs numbered from 1.
+	Created []domain.OutOfOfficePeriod
+
+	ListResult      []domain.OutOfOfficePeriod
+	ListErr         error
+	ListIncludePast bool
+
+	DeleteResult *domain.OutOfOfficePeriod
+	DeleteErr    error
+
+	ClaimResult []domain.OutOfOfficePeriod
+	ClaimErr    error
+	ClaimCalls  int
+}
+
+func (m *MockOutOfOfficeRepository) Create(ctx context.Context, p domain.OutOfOfficePeriod) (*domain.OutOfOfficePeriod, error) {
+	if m.CreateErr != nil {
+		return nil, m.CreateErr
+	}
+	m.Created = append(m.Created, p)
+	p.ID = int64(len(m.Created))
+	return &p, nil
+}
+
+func (m *MockOutOfOfficeRepository) ListByUser(ctx context.Context, userID string, includePast bool) ([]domain.OutOfOfficePeriod, error) {
+	m.ListIncludePast = includePast
+	return m.ListResult, m.ListErr
+}
+
+func (m *MockOutOfOfficeRepository) Delete(ctx context.Context, userID string, id int64) (*domain.OutOfOfficePeriod, error) {
+	return m.DeleteResult, m.DeleteErr
+}
+
+func (m *MockOutOfOfficeRepository) ClaimStarted(ctx context.Context) ([]domain.OutOfOfficePeriod, error) {
+	m.ClaimCalls++
+	return m.ClaimResult, m.ClaimErr
+}
+
+type MockOutOfOfficePRRepository struct {
+	HandOverResult []domain.ReviewerChange
+	HandOverErr    error
+	// HandedOver records the user IDs of each HandOverOpenReviews call.
+	HandedOver [][]string
+}
+
+func (m *MockOutOfOfficePRRepository) HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error) {
+	m.HandedOver = append(m.HandedOver, userIDs)
+	return m.HandOverResult, m.HandOverErr
+}
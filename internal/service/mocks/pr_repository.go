@@ -2,14 +2,13 @@ package mocks
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
 
 type MockPRRepository struct {
-	ExistsResult          bool
-	ExistsErr             error
 	CreateErr             error
 	GetByIDResult         *domain.PullRequest
 	GetByIDReviewers      []string
@@ -20,8 +19,21 @@ type MockPRRepository struct {
 	UpdateResult          *domain.PullRequest
 	UpdateReviewersResult []string
 	UpdateErr             error
-	DeactivateResult      domain.TeamDeactivationResult
-	DeactivateErr         error
+	// UpdateErrSeq is returned by successive UpdateReviewers calls before
+	// falling back to UpdateErr.
+	UpdateErrSeq     []error
+	UpdateCalls      int
+	UpdateVersions   []int64
+	UpdatedReviewers []string
+	SetMergedVersion int64
+	LockedCalls      int
+	DeactivateResult domain.TeamDeactivationResult
+	DeactivateErr    error
+	ListResult       domain.PullRequestPage
+	ListErr          error
+	ListFilter       domain.PullRequestFilter
+	ListAfter        *domain.PullRequestCursor
+	ListLimit        int
 }
 
 func (m *MockPRRepository) CreateWithReviewers(ctx context.Context, pr *domain.PullRequest, reviewerIDs []string) error {
@@ -32,20 +44,45 @@ func (m *MockPRRepository) GetByID(ctx context.Context, id string) (*domain.Pull
 	return m.GetByIDResult, m.GetByIDReviewers, m.GetByIDErr
 }
 
-func (m *MockPRRepository) SetMerged(ctx context.Context, id string, mergedAt time.Time) (*domain.PullRequest, []string, error) {
-	return m.SetMergedResult, m.SetMergedReviewers, m.SetMergedErr
+// WithLockedPR hands fn a copy of GetByIDResult with GetByIDReviewers
+// assigned, as the real repository does after locking the row.
+func (m *MockPRRepository) WithLockedPR(ctx context.Context, id string, fn func(ctx context.Context, pr *domain.PullRequest) error) error {
+	m.LockedCalls++
+	if m.GetByIDErr != nil {
+		return m.GetByIDErr
+	}
+	if m.GetByIDResult == nil {
+		return sql.ErrNoRows
+	}
+	pr := *m.GetByIDResult
+	pr.AssignedReviewers = append([]string(nil), m.GetByIDReviewers...)
+	return fn(ctx, &pr)
 }
 
-func (m *MockPRRepository) UpdateReviewers(ctx context.Context, id string, reviewerIDs []string) (*domain.PullRequest, []string, error) {
-	return m.UpdateResult, m.UpdateReviewersResult, m.UpdateErr
+func (m *MockPRRepository) SetMerged(ctx context.Context, id string, mergedAt time.Time, version int64) (*domain.PullRequest, []string, error) {
+	m.SetMergedVersion = version
+	return m.SetMergedResult, m.SetMergedReviewers, m.SetMergedErr
 }
 
-func (m *MockPRRepository) ListByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
-	return nil, nil
+func (m *MockPRRepository) UpdateReviewers(ctx context.Context, id string, reviewerIDs []string, version int64) (*domain.PullRequest, []string, error) {
+	m.UpdateCalls++
+	m.UpdateVersions = append(m.UpdateVersions, version)
+	m.UpdatedReviewers = reviewerIDs
+	if len(m.UpdateErrSeq) > 0 {
+		err := m.UpdateErrSeq[0]
+		m.UpdateErrSeq = m.UpdateErrSeq[1:]
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return m.UpdateResult, m.UpdateReviewersResult, m.UpdateErr
 }
 
-func (m *MockPRRepository) Exists(ctx context.Context, id string) (bool, error) {
-	return m.ExistsResult, m.ExistsErr
+func (m *MockPRRepository) List(ctx context.Context, filter domain.PullRequestFilter, after *domain.PullRequestCursor, limit int) (domain.PullRequestPage, error) {
+	m.ListFilter = filter
+	m.ListAfter = after
+	m.ListLimit = limit
+	return m.ListResult, m.ListErr
 }
 
 func (m *MockPRRepository) DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName string) (domain.TeamDeactivationResult, error) {
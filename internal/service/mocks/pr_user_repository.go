@@ -11,6 +11,8 @@ type MockPRUserRepository struct {
 	GetByIDErr       error
 	ListByTeamResult []domain.User
 	ListByTeamErr    error
+	// ListedTeams records the team of every ListByTeam call.
+	ListedTeams []string
 }
 
 func (m *MockPRUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
@@ -18,5 +20,6 @@ func (m *MockPRUserRepository) GetByID(ctx context.Context, id string) (*domain.
 }
 
 func (m *MockPRUserRepository) ListByTeam(ctx context.Context, teamName string) ([]domain.User, error) {
+	m.ListedTeams = append(m.ListedTeams, teamName)
 	return m.ListByTeamResult, m.ListByTeamErr
 }
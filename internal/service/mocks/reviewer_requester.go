@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+)
+
+type MockReviewerRequester struct {
+	RequestAttempts int
+	RequestErr      error
+	RemoveAttempts  int
+	RemoveErr       error
+	RequestedRepo   string
+	RequestedNumber int
+	RequestedLogins []string
+	RemovedLogins   []string
+}
+
+func (m *MockReviewerRequester) RequestReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	m.RequestedRepo = repo
+	m.RequestedNumber = number
+	m.RequestedLogins = logins
+	return m.RequestAttempts, m.RequestErr
+}
+
+func (m *MockReviewerRequester) RemoveRequestedReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	m.RemovedLogins = logins
+	return m.RemoveAttempts, m.RemoveErr
+}
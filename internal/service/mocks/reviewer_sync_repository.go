@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockReviewerSyncRepository struct {
+	mu            sync.Mutex
+	Saved         []domain.ReviewerSync
+	SaveErr       error
+	GetSyncResult *domain.ReviewerSync
+	GetSyncErr    error
+}
+
+func (m *MockReviewerSyncRepository) SaveSyncStatus(ctx context.Context, s *domain.ReviewerSync) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Saved = append(m.Saved, *s)
+	return m.SaveErr
+}
+
+func (m *MockReviewerSyncRepository) GetSyncStatus(ctx context.Context, prID string) (*domain.ReviewerSync, error) {
+	return m.GetSyncResult, m.GetSyncErr
+}
@@ -0,0 +1,17 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockReviewerSyncScheduler struct {
+	Scheduled []domain.PullRequest
+	Removed   [][]string
+}
+
+func (m *MockReviewerSyncScheduler) Schedule(ctx context.Context, pr *domain.PullRequest, removed []string) {
+	m.Scheduled = append(m.Scheduled, *pr)
+	m.Removed = append(m.Removed, removed)
+}
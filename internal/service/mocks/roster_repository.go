@@ -0,0 +1,78 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockRosterRepository struct {
+	ExportResult []domain.RosterEntry
+	ExportErr    error
+
+	// TeamsResult maps existing teams to whether they are archived.
+	TeamsResult       map[string]bool
+	UsersResult       map[string]domain.User
+	MembershipsResult map[string]map[string]domain.MemberRole
+
+	CreateTeamErr error
+	CreatedTeams  []string
+	// CreateUserErrs fails CreateUser for the users with these IDs.
+	CreateUserErrs map[string]error
+	CreatedUsers   []domain.User
+	UpdatedUsers   []domain.User
+	// SetMemberships records SetMembership calls in order; TeamName and Role
+	// are those of the membership.
+	SetMemberships []domain.User
+}
+
+func (m *MockRosterRepository) Export(ctx context.Context) ([]domain.RosterEntry, error) {
+	return m.ExportResult, m.ExportErr
+}
+
+func (m *MockRosterRepository) Teams(ctx context.Context, names []string) (map[string]bool, error) {
+	if m.TeamsResult == nil {
+		return map[string]bool{}, nil
+	}
+	return m.TeamsResult, nil
+}
+
+func (m *MockRosterRepository) Users(ctx context.Context, ids []string) (map[string]domain.User, error) {
+	if m.UsersResult == nil {
+		return map[string]domain.User{}, nil
+	}
+	return m.UsersResult, nil
+}
+
+func (m *MockRosterRepository) Memberships(ctx context.Context, userIDs []string) (map[string]map[string]domain.MemberRole, error) {
+	if m.MembershipsResult == nil {
+		return map[string]map[string]domain.MemberRole{}, nil
+	}
+	return m.MembershipsResult, nil
+}
+
+func (m *MockRosterRepository) CreateTeam(ctx context.Context, name string) error {
+	if m.CreateTeamErr != nil {
+		return m.CreateTeamErr
+	}
+	m.CreatedTeams = append(m.CreatedTeams, name)
+	return nil
+}
+
+func (m *MockRosterRepository) CreateUser(ctx context.Context, u domain.User) error {
+	if err := m.CreateUserErrs[u.ID]; err != nil {
+		return err
+	}
+	m.CreatedUsers = append(m.CreatedUsers, u)
+	return nil
+}
+
+func (m *MockRosterRepository) UpdateUser(ctx context.Context, u domain.User) error {
+	m.UpdatedUsers = append(m.UpdatedUsers, u)
+	return nil
+}
+
+func (m *MockRosterRepository) SetMembership(ctx context.Context, teamName, userID string, role domain.MemberRole) error {
+	m.SetMemberships = append(m.SetMemberships, domain.User{ID: userID, TeamName: teamName, Role: role})
+	return nil
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockTeamHierarchy struct {
+	AncestorsResult []string
+	AncestorsErr    error
+
+	// SubtreeMembers is keyed by the team whose subtree is listed.
+	SubtreeMembers map[string][]domain.User
+	SubtreeErr     error
+	SubtreeCalls   []string
+}
+
+func (m *MockTeamHierarchy) Ancestors(ctx context.Context, teamName string) ([]string, error) {
+	return m.AncestorsResult, m.AncestorsErr
+}
+
+func (m *MockTeamHierarchy) ListSubtreeMembers(ctx context.Context, teamName string) ([]domain.User, error) {
+	m.SubtreeCalls = append(m.SubtreeCalls, teamName)
+	return m.SubtreeMembers[teamName], m.SubtreeErr
+}
@@ -0,0 +1,61 @@
+package mocks
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type MockTeamPRRepository struct {
+	OpenReviewCountsResult map[string]int
+	OpenReviewCountsErr    error
+
+	ReassignResult []domain.ReviewerChange
+	ReassignErr    error
+	ReassignedIDs  []string
+
+	DeactivateResult domain.TeamDeactivationResult
+	DeactivateErr    error
+
+	RefillResult []domain.ReviewerChange
+	RefillErr    error
+	RefillCalls  int
+}
+
+func (m *MockTeamPRRepository) OpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error) {
+	if m.OpenReviewCountsErr != nil {
+		return nil, m.OpenReviewCountsErr
+	}
+	if m.OpenReviewCountsResult == nil {
+		return map[string]int{}, nil
+	}
+	return m.OpenReviewCountsResult, nil
+}
+
+func (m *MockTeamPRRepository) ReassignOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error) {
+	m.ReassignedIDs = userIDs
+	return m.ReassignResult, m.ReassignErr
+}
+
+func (m *MockTeamPRRepository) DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName string) (domain.TeamDeactivationResult, error) {
+	return m.DeactivateResult, m.DeactivateErr
+}
+
+func (m *MockTeamPRRepository) RefillOpenPRs(ctx context.Context, teamName string) ([]domain.ReviewerChange, error) {
+	m.RefillCalls++
+	return m.RefillResult, m.RefillErr
+}
+
+// ReviewerChanges returns n changes to PRs "pr-1".."pr-n", each with one
+// removed reviewer, for mocks that report bulk reassignments.
+func ReviewerChanges(n int) []domain.ReviewerChange {
+	changes := make([]domain.ReviewerChange, 0, n)
+	for i := 1; i <= n; i++ {
+		changes = append(changes, domain.ReviewerChange{
+			PullRequestID: "pr-" + strconv.Itoa(i),
+			Removed:       []string{"u" + strconv.Itoa(i)},
+		})
+	}
+	return changes
+}
@@ -7,21 +7,106 @@ import (
 )
 
 type MockTeamRepository struct {
-	ExistsResult         bool
-	ExistsErr            error
 	CreateErr            error
+	CreatedParent        string
 	GetWithMembersResult *domain.Team
 	GetWithMembersErr    error
+
+	// GetSubtreeResult defaults to GetWithMembersResult.
+	GetSubtreeResult *domain.Team
+
+	ListSubtreeMembersResult []domain.User
+	ListSubtreeMembersErr    error
+
+	SetParentErr   error
+	SetParentCalls int
+
+	// MaxOpenReviews records the last SetDefaultMaxOpenReviews call.
+	MaxOpenReviews       int
+	SetMaxOpenReviewsErr error
+
+	ArchiveResult int64
+	ArchiveErr    error
+
+	CountHistoryResult int
+	CountHistoryErr    error
+
+	DeleteResult int
+	DeleteErr    error
+	DeleteCalls  int
+
+	FindDeactivationResult *domain.TeamDeactivation
+	FindDeactivationErr    error
+
+	ReactivateResult []string
+	ReactivateErr    error
+	ReactivateCalls  int
+
+	ListResult domain.TeamPage
+	ListErr    error
+	ListFilter domain.TeamFilter
+	ListAfter  string
+	ListLimit  int
 }
 
-func (m *MockTeamRepository) Create(ctx context.Context, name string) error {
+func (m *MockTeamRepository) Create(ctx context.Context, name, parentTeam string) error {
+	m.CreatedParent = parentTeam
 	return m.CreateErr
 }
 
-func (m *MockTeamRepository) Exists(ctx context.Context, name string) (bool, error) {
-	return m.ExistsResult, m.ExistsErr
+func (m *MockTeamRepository) GetWithMembers(ctx context.Context, name string) (*domain.Team, error) {
+	return m.GetWithMembersResult, m.GetWithMembersErr
 }
 
-func (m *MockTeamRepository) GetWithMembers(ctx context.Context, name string) (*domain.Team, error) {
+func (m *MockTeamRepository) GetSubtree(ctx context.Context, name string) (*domain.Team, error) {
+	if m.GetSubtreeResult != nil {
+		return m.GetSubtreeResult, nil
+	}
 	return m.GetWithMembersResult, m.GetWithMembersErr
 }
+
+func (m *MockTeamRepository) ListSubtreeMembers(ctx context.Context, name string) ([]domain.User, error) {
+	return m.ListSubtreeMembersResult, m.ListSubtreeMembersErr
+}
+
+func (m *MockTeamRepository) SetParent(ctx context.Context, name, parentTeam string) error {
+	m.SetParentCalls++
+	return m.SetParentErr
+}
+
+func (m *MockTeamRepository) SetDefaultMaxOpenReviews(ctx context.Context, name string, limit int) error {
+	if m.SetMaxOpenReviewsErr != nil {
+		return m.SetMaxOpenReviewsErr
+	}
+	m.MaxOpenReviews = limit
+	return nil
+}
+
+func (m *MockTeamRepository) Archive(ctx context.Context, name string) (int64, error) {
+	return m.ArchiveResult, m.ArchiveErr
+}
+
+func (m *MockTeamRepository) CountHistory(ctx context.Context, name string) (int, error) {
+	return m.CountHistoryResult, m.CountHistoryErr
+}
+
+func (m *MockTeamRepository) Delete(ctx context.Context, name string) (int, error) {
+	m.DeleteCalls++
+	return m.DeleteResult, m.DeleteErr
+}
+
+func (m *MockTeamRepository) FindDeactivation(ctx context.Context, teamName string, id int64) (*domain.TeamDeactivation, error) {
+	return m.FindDeactivationResult, m.FindDeactivationErr
+}
+
+func (m *MockTeamRepository) Reactivate(ctx context.Context, d *domain.TeamDeactivation) ([]string, error) {
+	m.ReactivateCalls++
+	return m.ReactivateResult, m.ReactivateErr
+}
+
+func (m *MockTeamRepository) List(ctx context.Context, filter domain.TeamFilter, after string, limit int) (domain.TeamPage, error) {
+	m.ListFilter = filter
+	m.ListAfter = after
+	m.ListLimit = limit
+	return m.ListResult, m.ListErr
+}
@@ -7,9 +7,96 @@ import (
 )
 
 type MockTeamUserRepository struct {
+	GetByIDResult *domain.User
+	GetByIDErr    error
+
+	CurrentTeamsResult map[string]string
+	CurrentTeamsErr    error
+
 	UpsertErr error
+
+	UpsertedTeam  string
+	UpsertedUsers []domain.User
+
+	SetTeamErr error
+	// SetTeamCalls counts SetTeam calls; the returned user is GetByIDResult
+	// with the new team.
+	SetTeamCalls int
+
+	// RemoveResult is returned by RemoveFromTeam; when nil the requested
+	// IDs are echoed back.
+	RemoveResult []string
+	RemoveErr    error
+	RemovedIDs   []string
+
+	AddMembershipsErr error
+	MembershipTeam    string
+	MembershipIDs     []string
+	MembershipRole    domain.MemberRole
+
+	SetRoleErr error
+	// RoleChanges records SetRole calls as user ID -> role.
+	RoleChanges map[string]domain.MemberRole
+}
+
+func (m *MockTeamUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	return m.GetByIDResult, m.GetByIDErr
+}
+
+func (m *MockTeamUserRepository) CurrentTeams(ctx context.Context, userIDs []string) (map[string]string, error) {
+	if m.CurrentTeamsErr != nil {
+		return nil, m.CurrentTeamsErr
+	}
+	if m.CurrentTeamsResult == nil {
+		return map[string]string{}, nil
+	}
+	return m.CurrentTeamsResult, nil
 }
 
 func (m *MockTeamUserRepository) UpsertForTeam(ctx context.Context, teamName string, users []domain.User) error {
+	m.UpsertedTeam = teamName
+	m.UpsertedUsers = users
 	return m.UpsertErr
 }
+
+func (m *MockTeamUserRepository) SetTeam(ctx context.Context, id, teamName string) (*domain.User, error) {
+	m.SetTeamCalls++
+	if m.SetTeamErr != nil {
+		return nil, m.SetTeamErr
+	}
+	u := domain.User{ID: id, TeamName: teamName}
+	if m.GetByIDResult != nil {
+		u = *m.GetByIDResult
+		u.TeamName = teamName
+	}
+	return &u, nil
+}
+
+func (m *MockTeamUserRepository) RemoveFromTeam(ctx context.Context, teamName string, userIDs []string) ([]string, error) {
+	m.RemovedIDs = userIDs
+	if m.RemoveErr != nil {
+		return nil, m.RemoveErr
+	}
+	if m.RemoveResult != nil {
+		return m.RemoveResult, nil
+	}
+	return userIDs, nil
+}
+
+func (m *MockTeamUserRepository) AddMemberships(ctx context.Context, teamName string, userIDs []string, role domain.MemberRole) error {
+	m.MembershipTeam = teamName
+	m.MembershipIDs = userIDs
+	m.MembershipRole = role
+	return m.AddMembershipsErr
+}
+
+func (m *MockTeamUserRepository) SetRole(ctx context.Context, teamName, userID string, role domain.MemberRole) error {
+	if m.SetRoleErr != nil {
+		return m.SetRoleErr
+	}
+	if m.RoleChanges == nil {
+		m.RoleChanges = make(map[string]domain.MemberRole)
+	}
+	m.RoleChanges[userID] = role
+	return nil
+}
@@ -0,0 +1,22 @@
+package mocks
+
+import "context"
+
+type MockTxManager struct {
+	// Err is returned instead of running fn, as if the transaction could not
+	// be started.
+	Err   error
+	Calls int
+	// FnErr is what fn returned on the last call, i.e. what a real manager
+	// would have rolled back on.
+	FnErr error
+}
+
+func (m *MockTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Calls++
+	if m.Err != nil {
+		return m.Err
+	}
+	m.FnErr = fn(ctx)
+	return m.FnErr
+}
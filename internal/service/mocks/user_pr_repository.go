@@ -8,9 +8,37 @@ import (
 
 type MockUserPRRepository struct {
 	ListByReviewerResult []domain.PullRequest
+	ListByReviewerNext   *domain.PullRequestCursor
 	ListByReviewerErr    error
+	ListByReviewerFilter domain.ReviewFilter
+	ListByReviewerAfter  *domain.PullRequestCursor
+	ListByReviewerLimit  int
+	ReviewSummaryResult  domain.ReviewSummary
+	ReviewSummaryErr     error
+	HandOverResult       []domain.ReviewerChange
+	HandOverErr          error
+	// HandedOver records the user IDs of each HandOverOpenReviews call.
+	HandedOver [][]string
 }
 
-func (m *MockUserPRRepository) ListByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error) {
-	return m.ListByReviewerResult, m.ListByReviewerErr
+func (m *MockUserPRRepository) ListByReviewer(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+	after *domain.PullRequestCursor,
+	limit int,
+) (domain.PullRequestPage, error) {
+	m.ListByReviewerFilter = filter
+	m.ListByReviewerAfter = after
+	m.ListByReviewerLimit = limit
+	return domain.PullRequestPage{PullRequests: m.ListByReviewerResult, Next: m.ListByReviewerNext}, m.ListByReviewerErr
+}
+
+func (m *MockUserPRRepository) ReviewSummary(ctx context.Context, userID string) (domain.ReviewSummary, error) {
+	return m.ReviewSummaryResult, m.ReviewSummaryErr
+}
+
+func (m *MockUserPRRepository) HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error) {
+	m.HandedOver = append(m.HandedOver, userIDs)
+	return m.HandOverResult, m.HandOverErr
 }
@@ -7,16 +7,95 @@ import (
 )
 
 type MockUserRepository struct {
-	GetByIDResult     *domain.User
-	GetByIDErr        error
-	SetIsActiveResult *domain.User
-	SetIsActiveErr    error
+	CreateErr          error
+	Created            []domain.User
+	GetByIDResult      *domain.User
+	GetByIDErr         error
+	SetUsernameErr     error
+	SetIsActiveResult  *domain.User
+	SetIsActiveErr     error
+	SetIsActiveCalls   int
+	ListResult         domain.UserPage
+	ListErr            error
+	ListFilter         domain.UserFilter
+	ListAfter          string
+	ListLimit          int
+	SetWorkingHoursErr error
+	// WorkingHours records the last SetWorkingHours call; GetByID returns
+	// GetByIDResult with it once called.
+	WorkingHours    *domain.WorkingHours
+	WorkingHoursSet bool
+	// MaxOpenReviews records the last SetMaxOpenReviews call.
+	MaxOpenReviews       int
+	SetMaxOpenReviewsErr error
+	// WeeklyReviewQuota records the last SetWeeklyReviewQuota call.
+	WeeklyReviewQuota       int
+	SetWeeklyReviewQuotaErr error
 }
 
 func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*domain.User, error) {
+	if m.WorkingHoursSet && m.GetByIDResult != nil {
+		u := *m.GetByIDResult
+		u.WorkingHours = m.WorkingHours
+		return &u, m.GetByIDErr
+	}
 	return m.GetByIDResult, m.GetByIDErr
 }
 
+func (m *MockUserRepository) Create(ctx context.Context, u domain.User) error {
+	if m.CreateErr != nil {
+		return m.CreateErr
+	}
+	m.Created = append(m.Created, u)
+	return nil
+}
+
+// SetUsername returns GetByIDResult renamed.
+func (m *MockUserRepository) SetUsername(ctx context.Context, id, username string) (*domain.User, error) {
+	if m.SetUsernameErr != nil {
+		return nil, m.SetUsernameErr
+	}
+	u := domain.User{ID: id}
+	if m.GetByIDResult != nil {
+		u = *m.GetByIDResult
+	}
+	u.Username = username
+	return &u, nil
+}
+
 func (m *MockUserRepository) SetIsActive(ctx context.Context, id string, active bool) (*domain.User, error) {
+	m.SetIsActiveCalls++
 	return m.SetIsActiveResult, m.SetIsActiveErr
 }
+
+func (m *MockUserRepository) List(ctx context.Context, filter domain.UserFilter, after string, limit int) (domain.UserPage, error) {
+	m.ListFilter = filter
+	m.ListAfter = after
+	m.ListLimit = limit
+	return m.ListResult, m.ListErr
+}
+
+func (m *MockUserRepository) SetWorkingHours(ctx context.Context, userID string, h *domain.WorkingHours) error {
+	if m.SetWorkingHoursErr != nil {
+		return m.SetWorkingHoursErr
+	}
+	m.WorkingHours = h
+	m.WorkingHoursSet = true
+	return nil
+}
+
+func (m *MockUserRepository) SetMaxOpenReviews(ctx context.Context, userID string, limit int) error {
+	if m.SetMaxOpenReviewsErr != nil {
+		return m.SetMaxOpenReviewsErr
+	}
+	m.MaxOpenReviews = limit
+	return nil
+}
+
+func (m *MockUserRepository) SetWeeklyReviewQuota(ctx context.Context, userID string, quota int) error {
+	if m.SetWeeklyReviewQuotaErr != nil {
+		return m.SetWeeklyReviewQuotaErr
+	}
+	m.WeeklyReviewQuota = quota
+	return nil
+}
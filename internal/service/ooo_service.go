@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type OutOfOfficeRepository interface {
+	Create(ctx context.Context, p domain.OutOfOfficePeriod) (*domain.OutOfOfficePeriod, error)
+	ListByUser(ctx context.Context, userID string, includePast bool) ([]domain.OutOfOfficePeriod, error)
+	Delete(ctx context.Context, userID string, id int64) (*domain.OutOfOfficePeriod, error)
+	ClaimStarted(ctx context.Context) ([]domain.OutOfOfficePeriod, error)
+}
+
+type OutOfOfficePRRepository interface {
+	HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error)
+}
+
+// OutOfOfficeService keeps users' out-of-office periods. A user inside one is
+// not picked as a reviewer while is_active stays as it is, and once the
+// period starts HandOverStarted reassigns the reviews they hold.
+type OutOfOfficeService struct {
+	periods OutOfOfficeRepository
+	prs     OutOfOfficePRRepository
+	tx      TxManager
+	nowFunc func() time.Time
+	sync    ReviewerChangeScheduler
+}
+
+func NewOutOfOfficeService(
+	periods OutOfOfficeRepository,
+	prs OutOfOfficePRRepository,
+	tx TxManager,
+	nowFunc func() time.Time,
+) *OutOfOfficeService {
+	if tx == nil {
+		tx = noTx{}
+	}
+	if nowFunc == nil {
+		nowFunc = time.Now
+	}
+	return &OutOfOfficeService{
+		periods: periods,
+		prs:     prs,
+		tx:      tx,
+		nowFunc: nowFunc,
+	}
+}
+
+// SetReviewerSync enables pushing the reviewers of PRs changed by hand-overs
+// to the Git hosting API.
+func (s *OutOfOfficeService) SetReviewerSync(sync ReviewerChangeScheduler) {
+	s.sync = sync
+}
+
+// AddPeriod registers a period for p.UserID. It may already have started,
+// but must not have ended.
+func (s *OutOfOfficeService) AddPeriod(ctx context.Context, p domain.OutOfOfficePeriod) (*domain.OutOfOfficePeriod, error) {
+	var fields []domain.FieldError
+	if p.UserID == "" {
+		fields = append(fields, domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	if p.EndsAt <= p.StartsAt {
+		fields = append(fields, domain.FieldError{Field: "ends_at", Reason: "must be after starts_at"})
+	} else if p.EndsAt <= s.nowFunc().Unix() {
+		fields = append(fields, domain.FieldError{Field: "ends_at", Reason: "must be in the future"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid out-of-office period", fields...)
+	}
+
+	created, err := s.periods.Create(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("create out-of-office period: %w", err)
+	}
+	return created, nil
+}
+
+// ListPeriods returns the user's current and upcoming periods ordered by
+// start, and the ended ones too with includePast.
+func (s *OutOfOfficeService) ListPeriods(ctx context.Context, userID string, includePast bool) ([]domain.OutOfOfficePeriod, error) {
+	if userID == "" {
+		return nil, domain.NewValidationError("user_id is required", domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	periods, err := s.periods.ListByUser(ctx, userID, includePast)
+	if err != nil {
+		return nil, fmt.Errorf("list out-of-office periods: %w", err)
+	}
+	return periods, nil
+}
+
+// RemovePeriod cancels a period, e.g. a vacation called off or ended early.
+// Reviews already handed over stay with their new reviewers.
+func (s *OutOfOfficeService) RemovePeriod(ctx context.Context, userID string, id int64) (*domain.OutOfOfficePeriod, error) {
+	p, err := s.periods.Delete(ctx, userID, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound,
+				fmt.Sprintf("out-of-office period %d of user %s not found", id, userID))
+		}
+		return nil, fmt.Errorf("delete out-of-office period: %w", err)
+	}
+	return p, nil
+}
+
+// HandOverStarted reassigns the open reviews of users whose periods have
+// started since the last run. Each period is handled once; it returns the
+// periods handled and the number of PRs changed.
+func (s *OutOfOfficeService) HandOverStarted(ctx context.Context) ([]domain.OutOfOfficePeriod, int, error) {
+	var (
+		started []domain.OutOfOfficePeriod
+		changes []domain.ReviewerChange
+	)
+	// Claiming and reassigning share a transaction, so a failed
+	// reassignment leaves the periods to the next run.
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		started, err = s.periods.ClaimStarted(ctx)
+		if err != nil {
+			return fmt.Errorf("claim started out-of-office periods: %w", err)
+		}
+		if len(started) == 0 {
+			return nil
+		}
+
+		seen := make(map[string]struct{}, len(started))
+		userIDs := make([]string, 0, len(started))
+		for _, p := range started {
+			if _, ok := seen[p.UserID]; !ok {
+				seen[p.UserID] = struct{}{}
+				userIDs = append(userIDs, p.UserID)
+			}
+		}
+		changes, err = s.prs.HandOverOpenReviews(ctx, userIDs)
+		if err != nil {
+			return fmt.Errorf("hand over open reviews: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.sync != nil && len(changes) > 0 {
+		s.sync.ScheduleChanges(ctx, changes)
+	}
+	return started, len(changes), nil
+}
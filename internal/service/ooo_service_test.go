@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func TestOutOfOfficeService_AddPeriod(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	hour := int64(time.Hour / time.Second)
+
+	tests := []struct {
+		name      string
+		period    domain.OutOfOfficePeriod
+		wantField string
+	}{
+		{
+			name:   "будущий отпуск",
+			period: domain.OutOfOfficePeriod{UserID: "u1", StartsAt: now.Unix() + hour, EndsAt: now.Unix() + 48*hour, Reason: "vacation"},
+		},
+		{
+			name:   "отпуск, который уже начался",
+			period: domain.OutOfOfficePeriod{UserID: "u1", StartsAt: now.Unix() - hour, EndsAt: now.Unix() + hour},
+		},
+		{
+			name:      "без user_id",
+			period:    domain.OutOfOfficePeriod{StartsAt: now.Unix(), EndsAt: now.Unix() + hour},
+			wantField: "user_id",
+		},
+		{
+			name:      "конец раньше начала",
+			period:    domain.OutOfOfficePeriod{UserID: "u1", StartsAt: now.Unix() + hour, EndsAt: now.Unix()},
+			wantField: "ends_at",
+		},
+		{
+			name:      "отпуск уже закончился",
+			period:    domain.OutOfOfficePeriod{UserID: "u1", StartsAt: now.Unix() - 2*hour, EndsAt: now.Unix() - hour},
+			wantField: "ends_at",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockOutOfOfficeRepository{}
+			svc := NewOutOfOfficeService(repo, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{}, func() time.Time { return now })
+
+			created, err := svc.AddPeriod(context.Background(), tt.period)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if created.ID != 1 || created.Reason != tt.period.Reason {
+					t.Errorf("unexpected period: %+v", created)
+				}
+				return
+			}
+
+			var domainErr *domain.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if len(domainErr.Details) != 1 || domainErr.Details[0].Field != tt.wantField {
+				t.Errorf("expected field %s, got %+v", tt.wantField, domainErr.Details)
+			}
+			if len(repo.Created) != 0 {
+				t.Errorf("invalid period should not be stored")
+			}
+		})
+	}
+}
+
+func TestOutOfOfficeService_RemovePeriod(t *testing.T) {
+	repo := &mocks.MockOutOfOfficeRepository{DeleteErr: sql.ErrNoRows}
+	svc := NewOutOfOfficeService(repo, &mocks.MockOutOfOfficePRRepository{}, nil, nil)
+
+	_, err := svc.RemovePeriod(context.Background(), "u1", 42)
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeNotFound {
+		t.Fatalf("expected NOT_FOUND, got %v", err)
+	}
+}
+
+func TestOutOfOfficeService_HandOverStarted(t *testing.T) {
+	tests := []struct {
+		name        string
+		claimed     []domain.OutOfOfficePeriod
+		handOverErr error
+		wantUsers   [][]string
+		wantUpdated int
+		wantErr     bool
+	}{
+		{
+			name:    "нет начавшихся отпусков",
+			claimed: nil,
+		},
+		{
+			name: "два периода одного пользователя передаются одним вызовом",
+			claimed: []domain.OutOfOfficePeriod{
+				{ID: 1, UserID: "u1"},
+				{ID: 2, UserID: "u2"},
+				{ID: 3, UserID: "u1"},
+			},
+			wantUsers:   [][]string{{"u1", "u2"}},
+			wantUpdated: 3,
+		},
+		{
+			name:        "ошибка переназначения откатывает захват",
+			claimed:     []domain.OutOfOfficePeriod{{ID: 1, UserID: "u1"}},
+			handOverErr: errors.New("database error"),
+			wantUsers:   [][]string{{"u1"}},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			periods := &mocks.MockOutOfOfficeRepository{ClaimResult: tt.claimed}
+			prs := &mocks.MockOutOfOfficePRRepository{HandOverResult: mocks.ReviewerChanges(3), HandOverErr: tt.handOverErr}
+			tx := &mocks.MockTxManager{}
+			svc := NewOutOfOfficeService(periods, prs, tx, nil)
+
+			started, updated, err := svc.HandOverStarted(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tx.FnErr == nil {
+					t.Error("expected the transaction to be rolled back")
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(started) != len(tt.claimed) || updated != tt.wantUpdated {
+					t.Errorf("expected %d periods and %d PRs, got %d and %d",
+						len(tt.claimed), tt.wantUpdated, len(started), updated)
+				}
+			}
+			if tx.Calls != 1 {
+				t.Errorf("expected one transaction, got %d", tx.Calls)
+			}
+			if !slices.EqualFunc(prs.HandedOver, tt.wantUsers, slices.Equal[[]string]) {
+				t.Errorf("expected hand over of %v, got %v", tt.wantUsers, prs.HandedOver)
+			}
+		})
+	}
+}
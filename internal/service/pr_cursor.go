@@ -0,0 +1,89 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+const (
+	DefaultPageLimit = 50
+	MaxPageLimit     = 100
+)
+
+// pageLimit applies DefaultPageLimit to a zero limit and rejects limits
+// outside 1..MaxPageLimit.
+func pageLimit(limit int) (int, error) {
+	if limit == 0 {
+		return DefaultPageLimit, nil
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return 0, domain.NewValidationError("invalid limit", domain.FieldError{
+			Field:  "limit",
+			Reason: fmt.Sprintf("must be between 1 and %d", MaxPageLimit),
+		})
+	}
+	return limit, nil
+}
+
+// encodePRCursor turns a page position into the opaque string handed to
+// clients. A nil cursor (last page) encodes to "".
+func encodePRCursor(c *domain.PullRequestCursor) string {
+	if c == nil {
+		return ""
+	}
+	raw := strconv.FormatInt(c.CreatedAtMicro, 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePRCursor(s string) (*domain.PullRequestCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	invalid := domain.NewValidationError("invalid cursor", domain.FieldError{
+		Field:  "cursor",
+		Reason: "malformed cursor",
+	})
+
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+	micros, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, invalid
+	}
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &domain.PullRequestCursor{CreatedAtMicro: createdAt, ID: id}, nil
+}
+
+// encodeKeyCursor turns the sort key of the last row on a page, a team name
+// or a user ID, into an opaque cursor. The last page's "" stays "".
+func encodeKeyCursor(key string) string {
+	if key == "" {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeKeyCursor(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(raw) == 0 {
+		return "", domain.NewValidationError("invalid cursor", domain.FieldError{
+			Field:  "cursor",
+			Reason: "malformed cursor",
+		})
+	}
+	return string(raw), nil
+}
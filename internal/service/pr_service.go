@@ -3,7 +3,8 @@ package service
 import (
 	"context"
 	"fmt"
-	"math/rand"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
@@ -12,10 +13,10 @@ import (
 type PRRepository interface {
 	CreateWithReviewers(ctx context.Context, pr *domain.PullRequest, reviewerIDs []string) error
 	GetByID(ctx context.Context, id string) (*domain.PullRequest, []string, error)
-	SetMerged(ctx context.Context, id string, mergedAt time.Time) (*domain.PullRequest, []string, error)
-	UpdateReviewers(ctx context.Context, id string, reviewerIDs []string) (*domain.PullRequest, []string, error)
-	ListByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error)
-	Exists(ctx context.Context, id string) (bool, error)
+	WithLockedPR(ctx context.Context, id string, fn func(ctx context.Context, pr *domain.PullRequest) error) error
+	SetMerged(ctx context.Context, id string, mergedAt time.Time, version int64) (*domain.PullRequest, []string, error)
+	UpdateReviewers(ctx context.Context, id string, reviewerIDs []string, version int64) (*domain.PullRequest, []string, error)
+	List(ctx context.Context, filter domain.PullRequestFilter, after *domain.PullRequestCursor, limit int) (domain.PullRequestPage, error)
 	DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName string) (domain.TeamDeactivationResult, error)
 }
 
@@ -24,10 +25,32 @@ type PRUserRepository interface {
 	ListByTeam(ctx context.Context, teamName string) ([]domain.User, error)
 }
 
+// TeamHierarchy lets reviewer selection climb from a team to the teams
+// above it.
+type TeamHierarchy interface {
+	Ancestors(ctx context.Context, teamName string) ([]string, error)
+	ListSubtreeMembers(ctx context.Context, teamName string) ([]domain.User, error)
+}
+
+type ReviewerSyncScheduler interface {
+	Schedule(ctx context.Context, pr *domain.PullRequest, removed []string)
+}
+
+// ReviewerChangeScheduler syncs the PRs changed by a bulk reassignment to
+// the Git hosting API; PRService implements it.
+type ReviewerChangeScheduler interface {
+	ScheduleChanges(ctx context.Context, changes []domain.ReviewerChange)
+}
+
 type PRService struct {
-	prs     PRRepository
-	users   PRUserRepository
-	nowFunc func() time.Time
+	prs      PRRepository
+	users    PRUserRepository
+	nowFunc  func() time.Time
+	sync     ReviewerSyncScheduler
+	teams    TeamHierarchy
+	prefer   ReviewerPreference
+	overflow domain.CapacityOverflow
+	history  AssignmentHistory
 }
 
 func NewPRService(prs PRRepository, users PRUserRepository, nowFunc func() time.Time) *PRService {
@@ -41,85 +64,239 @@ func NewPRService(prs PRRepository, users PRUserRepository, nowFunc func() time.
 	}
 }
 
+// SetReviewerSync enables pushing assignments to the Git hosting API.
+func (s *PRService) SetReviewerSync(sync ReviewerSyncScheduler) {
+	s.sync = sync
+}
+
+// SetTeamHierarchy enables the parent-team fallback: when a team has no
+// candidate, reviewers are picked from the subtree of its parent, then of
+// the grandparent, and so on.
+func (s *PRService) SetTeamHierarchy(teams TeamHierarchy) {
+	s.teams = teams
+}
+
+// SetReviewerPreference makes selection try the candidates preferred by p
+// first. Reviewers are still picked only among available members.
+func (s *PRService) SetReviewerPreference(p ReviewerPreference) {
+	s.prefer = p
+}
+
+// SetCapacityOverflow sets what selection does when every available
+// candidate is at their review cap: fail with ALL_AT_CAPACITY (the default)
+// or, with domain.OverflowLeastLoaded, pick the least loaded of them.
+func (s *PRService) SetCapacityOverflow(o domain.CapacityOverflow) {
+	s.overflow = o
+}
+
+// SetFairness makes selection favour candidates who were assigned fewer
+// reviews over the history's window: each is picked with a chance inversely
+// proportional to one plus that number. A zero window turns it off.
+func (s *PRService) SetFairness(history AssignmentHistory) {
+	s.history = history
+}
+
+// CreatePullRequest opens the PR under teamName, or under the author's
+// primary team when teamName is empty, and assigns reviewers from that team.
+// The author must be a member of an explicit teamName. A PR labelled
+// domain.LabelRisky gets one of the team's leads as a reviewer if there is
+// one besides the author. Members at their open review limit are skipped;
+// if that leaves nobody, the policy set with SetCapacityOverflow applies.
+// Members at their weekly quota are never picked. A non-zero github link
+// ties the PR to the pull request its reviewers are synced to.
 func (s *PRService) CreatePullRequest(
 	ctx context.Context,
 	id string,
 	name string,
 	authorID string,
+	teamName string,
+	labels []string,
+	github domain.GitHubLink,
 ) (*domain.PullRequest, error) {
-	exists, err := s.prs.Exists(ctx, id)
+	labels, err := normalizeLabels(labels)
 	if err != nil {
-		return nil, fmt.Errorf("check PR exists: %w", err)
+		return nil, err
 	}
-	if exists {
-		return nil, domain.NewDomainError(domain.ErrorCodePRExists, "pull request already exists")
+	if err := validateGitHubLink(github); err != nil {
+		return nil, err
 	}
 
 	author, err := s.users.GetByID(ctx, authorID)
 	if err != nil {
 		return nil, fmt.Errorf("get author: %w", err)
 	}
+	if teamName == "" {
+		teamName = author.TeamName
+	}
 
-	teamMembers, err := s.users.ListByTeam(ctx, author.TeamName)
+	teamMembers, err := s.users.ListByTeam(ctx, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("list team members: %w", err)
 	}
+	if teamName != author.TeamName && !hasMember(teamMembers, author.ID) {
+		return nil, domain.NewValidationError("author is not a member of team "+teamName, domain.FieldError{
+			Field:  "team_name",
+			Reason: "author is not a member of this team",
+		})
+	}
+	if err := s.withRecentReviews(ctx, teamMembers); err != nil {
+		return nil, err
+	}
 
-	reviewerIDs := selectInitialReviewers(author.ID, teamMembers)
+	rank := s.reviewerRank(author)
+	reviewerIDs := selectInitialReviewers(author.ID, teamMembers, rank)
+	if slices.Contains(labels, domain.LabelRisky) {
+		reviewerIDs = ensureLeadReviewer(author.ID, reviewerIDs, teamMembers, rank)
+	}
+	if len(reviewerIDs) == 0 {
+		reviewerIDs, err = s.pickFromAncestors(ctx, teamName, func(members []domain.User) []string {
+			return selectInitialReviewers(author.ID, members, rank)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(reviewerIDs) == 0 {
+		reviewerIDs, err = s.pickOverCapacity(author.ID, nil, teamMembers, 2)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	now := s.nowFunc()
 	pr := &domain.PullRequest{
 		ID:                id,
 		Name:              name,
 		AuthorID:          author.ID,
+		TeamName:          teamName,
+		Labels:            labels,
+		GitHub:            github,
 		Status:            domain.PRStatusOpen,
 		AssignedReviewers: reviewerIDs,
 		CreatedAt:         now.Unix(),
 		MergedAt:          0,
 	}
 
+	// A duplicate ID is reported by the repository as PR_EXISTS, which also
+	// covers two creates racing on the same ID.
 	if err := s.prs.CreateWithReviewers(ctx, pr, reviewerIDs); err != nil {
 		return nil, fmt.Errorf("create PR with reviewers: %w", err)
 	}
 
+	s.scheduleSync(ctx, pr, nil)
+
 	return pr, nil
 }
 
-func (s *PRService) MergePullRequest(ctx context.Context, id string) (*domain.PullRequest, error) {
+func (s *PRService) GetPullRequest(ctx context.Context, id string) (*domain.PullRequest, error) {
 	pr, reviewers, err := s.prs.GetByID(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("get PR for merge: %w", err)
+		return nil, fmt.Errorf("get PR: %w", err)
 	}
+	pr.AssignedReviewers = reviewers
+	return pr, nil
+}
 
-	if pr.IsMerged() {
-		pr.AssignedReviewers = reviewers
-		return pr, nil
+// ListPullRequests returns one page of PRs matching filter, newest first.
+// cursor is the nextCursor of the previous page ("" for the first one), and
+// nextCursor is "" on the last page. A zero limit means DefaultPageLimit.
+func (s *PRService) ListPullRequests(
+	ctx context.Context,
+	filter domain.PullRequestFilter,
+	cursor string,
+	limit int,
+) (prs []domain.PullRequest, nextCursor string, err error) {
+	limit, err = pageLimit(limit)
+	if err != nil {
+		return nil, "", err
 	}
 
-	mergedAt := s.nowFunc()
-	pr, reviewers, err = s.prs.SetMerged(ctx, id, mergedAt)
+	after, err := decodePRCursor(cursor)
 	if err != nil {
-		return nil, fmt.Errorf("set PR merged: %w", err)
+		return nil, "", err
 	}
-	pr.AssignedReviewers = reviewers
 
-	return pr, nil
+	page, err := s.prs.List(ctx, filter, after, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("list PRs: %w", err)
+	}
+
+	return page.PullRequests, encodePRCursor(page.Next), nil
+}
+
+// MergePullRequest merges the PR. A non-zero expectedVersion is the
+// client's If-Match: the merge fails with VERSION_CONFLICT unless the PR is
+// still at that version.
+func (s *PRService) MergePullRequest(ctx context.Context, id string, expectedVersion int64) (*domain.PullRequest, error) {
+	var merged *domain.PullRequest
+	err := s.prs.WithLockedPR(ctx, id, func(ctx context.Context, pr *domain.PullRequest) error {
+		if err := checkVersion(pr, expectedVersion); err != nil {
+			return err
+		}
+
+		if pr.IsMerged() {
+			merged = pr
+			return nil
+		}
+
+		updated, reviewers, err := s.prs.SetMerged(ctx, id, s.nowFunc(), pr.Version)
+		if err != nil {
+			return fmt.Errorf("set PR merged: %w", err)
+		}
+		updated.AssignedReviewers = reviewers
+		merged = updated
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("merge PR: %w", err)
+	}
+
+	return merged, nil
 }
 
+// ReassignReviewer replaces oldReviewerID on the PR. The PR stays locked
+// from the checks to the write, so a concurrent merge or reassign waits
+// instead of being overwritten. expectedVersion works as in
+// MergePullRequest. It returns the updated PR and the reviewer who took
+// oldReviewerID's place.
 func (s *PRService) ReassignReviewer(
 	ctx context.Context,
 	prID string,
 	oldReviewerID string,
-) (*domain.PullRequest, error) {
-	pr, reviewers, err := s.prs.GetByID(ctx, prID)
+	expectedVersion int64,
+) (*domain.PullRequest, string, error) {
+	var (
+		updated    *domain.PullRequest
+		replacedBy string
+	)
+	err := s.prs.WithLockedPR(ctx, prID, func(ctx context.Context, pr *domain.PullRequest) error {
+		if err := checkVersion(pr, expectedVersion); err != nil {
+			return err
+		}
+
+		var err error
+		updated, replacedBy, err = s.reassignLocked(ctx, pr, oldReviewerID)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("get PR for reassign: %w", err)
+		return nil, "", fmt.Errorf("reassign reviewer: %w", err)
 	}
 
+	s.scheduleSync(ctx, updated, []string{oldReviewerID})
+
+	return updated, replacedBy, nil
+}
+
+func (s *PRService) reassignLocked(
+	ctx context.Context,
+	pr *domain.PullRequest,
+	oldReviewerID string,
+) (*domain.PullRequest, string, error) {
 	if pr.IsMerged() {
-		return nil, domain.NewDomainError(domain.ErrorCodePRMerged, "cannot reassign reviewers for merged PR")
+		return nil, "", domain.NewDomainError(domain.ErrorCodePRMerged, "cannot reassign reviewers for merged PR")
 	}
 
+	reviewers := pr.AssignedReviewers
 	reviewerIndex := -1
 	for i, rID := range reviewers {
 		if rID == oldReviewerID {
@@ -128,54 +305,357 @@ func (s *PRService) ReassignReviewer(
 		}
 	}
 	if reviewerIndex == -1 {
-		return nil, domain.NewDomainError(domain.ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
+		return nil, "", domain.NewDomainError(domain.ErrorCodeNotAssigned, "reviewer is not assigned to this PR")
 	}
 
-	oldReviewer, err := s.users.GetByID(ctx, oldReviewerID)
+	// Replacements come from the team the PR was created under; PRs without
+	// one fall back to the old reviewer's primary team.
+	teamName := pr.TeamName
+	if teamName == "" {
+		oldReviewer, err := s.users.GetByID(ctx, oldReviewerID)
+		if err != nil {
+			return nil, "", fmt.Errorf("get old reviewer: %w", err)
+		}
+		teamName = oldReviewer.TeamName
+	}
+
+	teamMembers, err := s.users.ListByTeam(ctx, teamName)
 	if err != nil {
-		return nil, fmt.Errorf("get old reviewer: %w", err)
+		return nil, "", fmt.Errorf("list team members for reassign: %w", err)
+	}
+	if err := s.withRecentReviews(ctx, teamMembers); err != nil {
+		return nil, "", err
 	}
 
-	teamMembers, err := s.users.ListByTeam(ctx, oldReviewer.TeamName)
+	rank, err := s.reviewerRankOf(ctx, pr.AuthorID)
 	if err != nil {
-		return nil, fmt.Errorf("list team members for reassign: %w", err)
+		return nil, "", err
+	}
+
+	candidates := teamMembers
+	if pr.IsRisky() && !hasLeadReviewer(reviewers, oldReviewerID, teamMembers) {
+		// A risky PR left without a lead gets one if any can step in.
+		if leads := leadsOf(teamMembers); len(leads) > 0 {
+			if _, err := selectReplacementReviewer(pr.AuthorID, oldReviewerID, reviewers, leads, nil); err == nil {
+				candidates = leads
+			}
+		}
 	}
 
-	newReviewerID, err := selectReplacementReviewer(pr.AuthorID, oldReviewerID, reviewers, teamMembers)
+	newReviewerID, err := selectReplacementReviewer(pr.AuthorID, oldReviewerID, reviewers, candidates, rank)
 	if err != nil {
-		return nil, err
+		fallback, ferr := s.pickFromAncestors(ctx, teamName, func(members []domain.User) []string {
+			id, err := selectReplacementReviewer(pr.AuthorID, oldReviewerID, reviewers, members, rank)
+			if err != nil {
+				return nil
+			}
+			return []string{id}
+		})
+		if ferr != nil {
+			return nil, "", ferr
+		}
+		if len(fallback) == 0 {
+			skip := append([]string{oldReviewerID}, reviewers...)
+			fallback, ferr = s.pickOverCapacity(pr.AuthorID, skip, teamMembers, 1)
+			if ferr != nil {
+				return nil, "", ferr
+			}
+		}
+		if len(fallback) == 0 {
+			return nil, "", err
+		}
+		newReviewerID = fallback[0]
 	}
 
 	newReviewers := make([]string, len(reviewers))
 	copy(newReviewers, reviewers)
 	newReviewers[reviewerIndex] = newReviewerID
 
-	updated, updatedReviewers, err := s.prs.UpdateReviewers(ctx, prID, newReviewers)
+	updated, updatedReviewers, err := s.prs.UpdateReviewers(ctx, pr.ID, newReviewers, pr.Version)
 	if err != nil {
-		return nil, fmt.Errorf("update reviewers: %w", err)
+		return nil, "", fmt.Errorf("update reviewers: %w", err)
 	}
 	updated.AssignedReviewers = updatedReviewers
 
-	return updated, nil
+	return updated, newReviewerID, nil
+}
+
+// pickFromAncestors runs pick on the members of each team above teamName,
+// nearest first, and returns the first non-empty result. It returns nil
+// when the fallback is disabled or no level has a candidate.
+func (s *PRService) pickFromAncestors(ctx context.Context, teamName string, pick func([]domain.User) []string) ([]string, error) {
+	if s.teams == nil || teamName == "" {
+		return nil, nil
+	}
+
+	ancestors, err := s.teams.Ancestors(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("list parent teams: %w", err)
+	}
+	for _, ancestor := range ancestors {
+		members, err := s.teams.ListSubtreeMembers(ctx, ancestor)
+		if err != nil {
+			return nil, fmt.Errorf("list members of team %s: %w", ancestor, err)
+		}
+		if err := s.withRecentReviews(ctx, members); err != nil {
+			return nil, err
+		}
+		if picked := pick(members); len(picked) > 0 {
+			return picked, nil
+		}
+	}
+	return nil, nil
+}
+
+// withRecentReviews fills in RecentReviews of members when selection is
+// weighted by fairness.
+func (s *PRService) withRecentReviews(ctx context.Context, members []domain.User) error {
+	if s.history == nil || len(members) == 0 {
+		return nil
+	}
+	window := s.history.FairnessWindow()
+	if window <= 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.ID)
+	}
+	counts, err := s.history.CountAssignmentsSince(ctx, ids, s.nowFunc().Add(-window))
+	if err != nil {
+		return fmt.Errorf("count recent assignments: %w", err)
+	}
+	for i := range members {
+		members[i].RecentReviews = counts[members[i].ID]
+	}
+	return nil
+}
+
+// pickOverCapacity is the last resort when no member with spare capacity,
+// here or up the hierarchy, could be picked. It applies the overflow policy
+// to the available members of the team, other than the author and skip,
+// that are at their cap. It returns nil when there are none.
+func (s *PRService) pickOverCapacity(authorID string, skip []string, members []domain.User, max int) ([]string, error) {
+	full := make([]domain.User, 0)
+	for _, m := range members {
+		if !m.IsAvailable() || m.HasCapacity() || m.ID == authorID || slices.Contains(skip, m.ID) {
+			continue
+		}
+		if !hasMember(full, m.ID) {
+			full = append(full, m)
+		}
+	}
+	if len(full) == 0 {
+		return nil, nil
+	}
+
+	if s.overflow != domain.OverflowLeastLoaded {
+		return nil, domain.NewDomainError(domain.ErrorCodeAllAtCapacity,
+			"every available candidate is at their open review limit")
+	}
+	return pickRanked(full, max, func(u domain.User) int {
+		return -u.OpenReviews
+	}), nil
+}
+
+// reviewerRank ranks candidates for a PR of author by the preference set
+// with SetReviewerPreference. It returns nil for no preference, and for
+// PreferHoursOverlap when the author has no working hours.
+func (s *PRService) reviewerRank(author *domain.User) reviewerRank {
+	now := s.nowFunc()
+	switch s.prefer {
+	case PreferWorkingNow:
+		return func(u domain.User) int {
+			if workingAt(u.WorkingHours, now) {
+				return 1
+			}
+			return 0
+		}
+	case PreferHoursOverlap:
+		if author.WorkingHours == nil {
+			return nil
+		}
+		return func(u domain.User) int {
+			return hoursOverlap(author.WorkingHours, u.WorkingHours, now)
+		}
+	default:
+		return nil
+	}
+}
+
+// reviewerRankOf is reviewerRank for an author known by ID, loaded only
+// when the preference needs their working hours.
+func (s *PRService) reviewerRankOf(ctx context.Context, authorID string) (reviewerRank, error) {
+	author := &domain.User{ID: authorID}
+	if s.prefer == PreferHoursOverlap {
+		var err error
+		if author, err = s.users.GetByID(ctx, authorID); err != nil {
+			return nil, fmt.Errorf("get author: %w", err)
+		}
+	}
+	return s.reviewerRank(author), nil
 }
 
 func (s *PRService) DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName string) (domain.TeamDeactivationResult, error) {
-	return s.prs.DeactivateTeamAndReassignOpenPRs(ctx, teamName)
+	result, err := s.prs.DeactivateTeamAndReassignOpenPRs(ctx, teamName)
+	if err != nil {
+		return result, err
+	}
+	s.ScheduleChanges(ctx, result.Changes)
+	return result, nil
+}
+
+// ScheduleChanges syncs the current reviewers of PRs changed by a committed
+// bulk reassignment. A PR that cannot be read is skipped; its sync status
+// stays as it was.
+func (s *PRService) ScheduleChanges(ctx context.Context, changes []domain.ReviewerChange) {
+	if s.sync == nil {
+		return
+	}
+	for _, c := range changes {
+		pr, reviewers, err := s.prs.GetByID(ctx, c.PullRequestID)
+		if err != nil {
+			continue
+		}
+		pr.AssignedReviewers = reviewers
+		s.sync.Schedule(ctx, pr, c.Removed)
+	}
+}
+
+func (s *PRService) scheduleSync(ctx context.Context, pr *domain.PullRequest, removed []string) {
+	if s.sync == nil {
+		return
+	}
+	s.sync.Schedule(ctx, pr, removed)
 }
 
-func selectInitialReviewers(authorID string, members []domain.User) []string {
-	candidates := make([]string, 0, len(members))
+func checkVersion(pr *domain.PullRequest, expectedVersion int64) error {
+	if expectedVersion != 0 && pr.Version != expectedVersion {
+		return domain.NewDomainError(domain.ErrorCodeVersionConflict,
+			fmt.Sprintf("pull request is at version %d, expected %d", pr.Version, expectedVersion))
+	}
+	return nil
+}
+
+func selectInitialReviewers(authorID string, members []domain.User, rank reviewerRank) []string {
+	candidates := make([]domain.User, 0, len(members))
+	// A subtree lists a user once per team they belong to.
+	seen := make(map[string]struct{}, len(members))
 	for _, m := range members {
-		if !m.IsActive {
+		if !m.IsAvailable() || !m.HasCapacity() {
 			continue
 		}
 		if m.ID == authorID {
 			continue
 		}
-		candidates = append(candidates, m.ID)
+		if _, ok := seen[m.ID]; ok {
+			continue
+		}
+		seen[m.ID] = struct{}{}
+		candidates = append(candidates, m)
+	}
+
+	return pickRanked(candidates, 2, rank)
+}
+
+// ensureLeadReviewer makes one of reviewers an available lead of the team
+// with spare capacity, replacing the last one if both slots are taken.
+// Reviewers are returned unchanged when one of them already is a lead or the
+// team has no other.
+func ensureLeadReviewer(authorID string, reviewers []string, members []domain.User, rank reviewerRank) []string {
+	if hasLeadReviewer(reviewers, "", members) {
+		return reviewers
+	}
+
+	leads := make([]domain.User, 0)
+	for _, m := range leadsOf(members) {
+		if m.IsAvailable() && m.HasCapacity() && m.ID != authorID {
+			leads = append(leads, m)
+		}
+	}
+	if len(leads) == 0 {
+		return reviewers
+	}
+
+	lead := pickRanked(leads, 1, rank)[0]
+	out := append(make([]string, 0, 2), reviewers...)
+	if len(out) < 2 {
+		return append(out, lead)
+	}
+	out[len(out)-1] = lead
+	return out
+}
+
+// hasLeadReviewer reports whether a reviewer other than exceptID is a lead
+// among members.
+func hasLeadReviewer(reviewers []string, exceptID string, members []domain.User) bool {
+	for _, m := range leadsOf(members) {
+		if m.ID != exceptID && slices.Contains(reviewers, m.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+func leadsOf(members []domain.User) []domain.User {
+	leads := make([]domain.User, 0)
+	for _, m := range members {
+		if m.Role == domain.RoleLead {
+			leads = append(leads, m)
+		}
+	}
+	return leads
+}
+
+// normalizeLabels drops duplicate labels and sorts them. Empty labels are
+// rejected.
+func normalizeLabels(labels []string) ([]string, error) {
+	out := make([]string, 0, len(labels))
+	for _, l := range labels {
+		if l == "" {
+			return nil, domain.NewValidationError("labels must not be empty", domain.FieldError{
+				Field:  "labels",
+				Reason: "must not contain empty labels",
+			})
+		}
+		if !slices.Contains(out, l) {
+			out = append(out, l)
+		}
 	}
+	slices.Sort(out)
+	return out, nil
+}
 
-	return pickRandomSubset(candidates, 2)
+// validateGitHubLink accepts a zero link or one with both an "owner/name"
+// repository and a positive number.
+func validateGitHubLink(l domain.GitHubLink) error {
+	if l.IsZero() {
+		return nil
+	}
+	owner, name, ok := strings.Cut(l.Repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return domain.NewValidationError("invalid github repository", domain.FieldError{
+			Field:  "github_repo",
+			Reason: `must be "owner/name"`,
+		})
+	}
+	if l.Number <= 0 {
+		return domain.NewValidationError("invalid github pull request number", domain.FieldError{
+			Field:  "github_number",
+			Reason: "must be positive",
+		})
+	}
+	return nil
+}
+
+func hasMember(members []domain.User, userID string) bool {
+	for _, m := range members {
+		if m.ID == userID {
+			return true
+		}
+	}
+	return false
 }
 
 func selectReplacementReviewer(
@@ -183,15 +663,16 @@ func selectReplacementReviewer(
 	oldReviewerID string,
 	currentReviewerIDs []string,
 	teamMembers []domain.User,
+	rank reviewerRank,
 ) (string, error) {
 	currentSet := make(map[string]struct{}, len(currentReviewerIDs))
 	for _, id := range currentReviewerIDs {
 		currentSet[id] = struct{}{}
 	}
 
-	candidates := make([]string, 0, len(teamMembers))
+	candidates := make([]domain.User, 0, len(teamMembers))
 	for _, m := range teamMembers {
-		if !m.IsActive {
+		if !m.IsAvailable() || !m.HasCapacity() {
 			continue
 		}
 		if m.ID == authorID {
@@ -203,39 +684,13 @@ func selectReplacementReviewer(
 		if _, exists := currentSet[m.ID]; exists {
 			continue
 		}
-		candidates = append(candidates, m.ID)
+		candidates = append(candidates, m)
 	}
 
 	if len(candidates) == 0 {
 		return "", domain.NewDomainError(domain.ErrorCodeNoCandidate, "no available candidate for reassignment")
 	}
 
-	selected := pickRandomSubset(candidates, 1)
+	selected := pickRanked(candidates, 1, rank)
 	return selected[0], nil
 }
-
-func pickRandomSubset(ids []string, max int) []string {
-	if max <= 0 || len(ids) == 0 {
-		return nil
-	}
-	if len(ids) <= max {
-		out := make([]string, len(ids))
-		copy(out, ids)
-		return out
-	}
-
-	// #nosec G404 -- non-cryptographic random is acceptable for reviewer selection
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	pool := make([]string, len(ids))
-	copy(pool, ids)
-
-	out := make([]string, 0, max)
-	for len(out) < max && len(pool) > 0 {
-		idx := r.Intn(len(pool))
-		out = append(out, pool[idx])
-		pool[idx] = pool[len(pool)-1]
-		pool = pool[:len(pool)-1]
-	}
-	return out
-}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 	"time"
 
@@ -16,8 +17,6 @@ func TestPRService_CreatePullRequest(t *testing.T) {
 		id                 string
 		prName             string
 		authorID           string
-		mockPRExists       bool
-		mockPRExistsErr    error
 		mockAuthor         *domain.User
 		mockAuthorErr      error
 		mockTeamMembers    []domain.User
@@ -29,11 +28,10 @@ func TestPRService_CreatePullRequest(t *testing.T) {
 		validateResult     func(t *testing.T, pr *domain.PullRequest)
 	}{
 		{
-			name:         "успешное создание PR",
-			id:           "pr-1",
-			prName:       "Test PR",
-			authorID:     "user-1",
-			mockPRExists: false,
+			name:     "успешное создание PR",
+			id:       "pr-1",
+			prName:   "Test PR",
+			authorID: "user-1",
 			mockAuthor: &domain.User{
 				ID:       "user-1",
 				Username: "author",
@@ -68,28 +66,39 @@ func TestPRService_CreatePullRequest(t *testing.T) {
 			},
 		},
 		{
-			name:         "PR уже существует",
-			id:           "pr-1",
-			prName:       "Test PR",
-			authorID:     "user-1",
-			mockPRExists: true,
-			wantErr:      true,
-			wantErrCode:  domain.ErrorCodePRExists,
+			name:     "PR уже существует",
+			id:       "pr-1",
+			prName:   "Test PR",
+			authorID: "user-1",
+			mockAuthor: &domain.User{
+				ID:       "user-1",
+				Username: "author",
+				TeamName: "team-1",
+				IsActive: true,
+			},
+			mockCreateErr: domain.NewDomainError(domain.ErrorCodePRExists, "pull request already exists"),
+			wantErr:       true,
+			wantErrCode:   domain.ErrorCodePRExists,
 		},
 		{
-			name:            "ошибка при проверке существования PR",
-			id:              "pr-1",
-			prName:          "Test PR",
-			authorID:        "user-1",
-			mockPRExistsErr: errors.New("database error"),
-			wantErr:         true,
+			name:     "ошибка при создании PR",
+			id:       "pr-1",
+			prName:   "Test PR",
+			authorID: "user-1",
+			mockAuthor: &domain.User{
+				ID:       "user-1",
+				Username: "author",
+				TeamName: "team-1",
+				IsActive: true,
+			},
+			mockCreateErr: errors.New("database error"),
+			wantErr:       true,
 		},
 		{
 			name:          "автор не найден",
 			id:            "pr-1",
 			prName:        "Test PR",
 			authorID:      "user-1",
-			mockPRExists:  false,
 			mockAuthorErr: errors.New("user not found"),
 			wantErr:       true,
 		},
@@ -98,9 +107,7 @@ func TestPRService_CreatePullRequest(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockPRRepo := &mocks.MockPRRepository{
-				ExistsResult: tt.mockPRExists,
-				ExistsErr:    tt.mockPRExistsErr,
-				CreateErr:    tt.mockCreateErr,
+				CreateErr: tt.mockCreateErr,
 			}
 			mockUserRepo := &mocks.MockPRUserRepository{
 				GetByIDResult:    tt.mockAuthor,
@@ -117,7 +124,7 @@ func TestPRService_CreatePullRequest(t *testing.T) {
 			service := NewPRService(mockPRRepo, mockUserRepo, nowFunc)
 			ctx := context.Background()
 
-			result, err := service.CreatePullRequest(ctx, tt.id, tt.prName, tt.authorID)
+			result, err := service.CreatePullRequest(ctx, tt.id, tt.prName, tt.authorID, "", nil, domain.GitHubLink{})
 
 			if tt.wantErr {
 				if err == nil {
@@ -232,7 +239,7 @@ func TestPRService_MergePullRequest(t *testing.T) {
 			service := NewPRService(mockPRRepo, mockUserRepo, nowFunc)
 			ctx := context.Background()
 
-			result, err := service.MergePullRequest(ctx, tt.id)
+			result, err := service.MergePullRequest(ctx, tt.id, 0)
 
 			if tt.wantErr {
 				if err == nil {
@@ -363,7 +370,7 @@ func TestPRService_ReassignReviewer(t *testing.T) {
 			service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
 			ctx := context.Background()
 
-			result, err := service.ReassignReviewer(ctx, tt.prID, tt.oldReviewerID)
+			result, _, err := service.ReassignReviewer(ctx, tt.prID, tt.oldReviewerID, 0)
 
 			if tt.wantErr {
 				if err == nil {
@@ -432,6 +439,17 @@ func TestSelectInitialReviewers(t *testing.T) {
 			wantCount:    1,
 			wantMaxCount: 1,
 		},
+		{
+			name:     "исключение пользователей в отпуске",
+			authorID: "user-1",
+			members: []domain.User{
+				{ID: "user-1", IsActive: true},
+				{ID: "user-2", IsActive: true, OutOfOffice: true},
+				{ID: "user-3", IsActive: true},
+			},
+			wantCount:    1,
+			wantMaxCount: 1,
+		},
 		{
 			name:     "меньше кандидатов чем требуется",
 			authorID: "user-1",
@@ -453,7 +471,7 @@ func TestSelectInitialReviewers(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := selectInitialReviewers(tt.authorID, tt.members)
+			result := selectInitialReviewers(tt.authorID, tt.members, nil)
 			if len(result) < tt.wantCount || len(result) > tt.wantMaxCount {
 				t.Errorf("expected %d-%d reviewers, got %d", tt.wantCount, tt.wantMaxCount, len(result))
 			}
@@ -465,3 +483,872 @@ func TestSelectInitialReviewers(t *testing.T) {
 		})
 	}
 }
+
+func TestPRService_SchedulesReviewerSync(t *testing.T) {
+	mockPRRepo := &mocks.MockPRRepository{
+		GetByIDResult:         &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen},
+		GetByIDReviewers:      []string{"user-2"},
+		UpdateResult:          &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen},
+		UpdateReviewersResult: []string{"user-3"},
+	}
+	mockUserRepo := &mocks.MockPRUserRepository{
+		GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+		ListByTeamResult: []domain.User{
+			{ID: "user-1", TeamName: "team-1", IsActive: true},
+			{ID: "user-2", TeamName: "team-1", IsActive: true},
+			{ID: "user-3", TeamName: "team-1", IsActive: true},
+		},
+	}
+	scheduler := &mocks.MockReviewerSyncScheduler{}
+
+	service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+	service.SetReviewerSync(scheduler)
+	ctx := context.Background()
+
+	if _, err := service.CreatePullRequest(ctx, "pr-1", "Test PR", "user-1", "", nil, domain.GitHubLink{}); err != nil {
+		t.Fatalf("unexpected create error: %v", err)
+	}
+	if _, _, err := service.ReassignReviewer(ctx, "pr-1", "user-2", 0); err != nil {
+		t.Fatalf("unexpected reassign error: %v", err)
+	}
+
+	if len(scheduler.Scheduled) != 2 {
+		t.Fatalf("expected 2 scheduled syncs, got %d", len(scheduler.Scheduled))
+	}
+	if len(scheduler.Removed[0]) != 0 {
+		t.Errorf("expected no removed reviewers on create, got %v", scheduler.Removed[0])
+	}
+	if len(scheduler.Removed[1]) != 1 || scheduler.Removed[1][0] != "user-2" {
+		t.Errorf("expected user-2 removed on reassign, got %v", scheduler.Removed[1])
+	}
+}
+
+func TestPRService_ParentTeamFallback(t *testing.T) {
+	hierarchy := &mocks.MockTeamHierarchy{
+		AncestorsResult: []string{"platform", "engineering"},
+		SubtreeMembers: map[string][]domain.User{
+			// platform has nobody else active, so selection climbs further.
+			"platform": {
+				{ID: "user-1", TeamName: "team-1", IsActive: true},
+				{ID: "user-4", TeamName: "platform", IsActive: false},
+			},
+			"engineering": {
+				{ID: "user-1", TeamName: "team-1", IsActive: true},
+				{ID: "user-5", TeamName: "mobile", IsActive: true},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		hierarchy     *mocks.MockTeamHierarchy
+		wantReviewers []string
+		wantErrCode   domain.ErrorCode
+	}{
+		{
+			name:          "ревьюер из ближайшего отдела с кандидатами",
+			hierarchy:     hierarchy,
+			wantReviewers: []string{"user-5"},
+		},
+		{
+			name:          "без иерархии кандидатов нет",
+			wantReviewers: nil,
+			wantErrCode:   domain.ErrorCodeNoCandidate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockPRRepository{
+				GetByIDResult:         &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen},
+				GetByIDReviewers:      []string{"user-2"},
+				UpdateResult:          &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen},
+				UpdateReviewersResult: []string{"user-5"},
+			}
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: []domain.User{
+					{ID: "user-1", TeamName: "team-1", IsActive: true},
+					{ID: "user-2", TeamName: "team-1", IsActive: false},
+				},
+			}
+
+			service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+			if tt.hierarchy != nil {
+				service.SetTeamHierarchy(tt.hierarchy)
+			}
+			ctx := context.Background()
+
+			pr, err := service.CreatePullRequest(ctx, "pr-1", "Test PR", "user-1", "", nil, domain.GitHubLink{})
+			if err != nil {
+				t.Fatalf("unexpected create error: %v", err)
+			}
+			if !slices.Equal(pr.AssignedReviewers, tt.wantReviewers) {
+				t.Errorf("expected reviewers %v, got %v", tt.wantReviewers, pr.AssignedReviewers)
+			}
+
+			_, _, err = service.ReassignReviewer(ctx, "pr-1", "user-2", 0)
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected reassign error: %v", err)
+			}
+			if got := mockPRRepo.UpdatedReviewers; !slices.Equal(got, []string{"user-5"}) {
+				t.Errorf("expected user-2 replaced by user-5, got %v", got)
+			}
+		})
+	}
+}
+
+func TestPRService_CreatePullRequestTeamContext(t *testing.T) {
+	guild := []domain.User{
+		{ID: "user-1", TeamName: "team-1", IsActive: true},
+		{ID: "user-7", TeamName: "team-2", IsActive: true},
+	}
+
+	tests := []struct {
+		name        string
+		teamName    string
+		members     []domain.User
+		wantTeam    string
+		wantErrCode domain.ErrorCode
+	}{
+		{
+			name:     "без команды — основная команда автора",
+			members:  []domain.User{{ID: "user-1", TeamName: "team-1", IsActive: true}, {ID: "user-2", TeamName: "team-1", IsActive: true}},
+			wantTeam: "team-1",
+		},
+		{
+			name:     "дополнительная команда автора",
+			teamName: "guild",
+			members:  guild,
+			wantTeam: "guild",
+		},
+		{
+			name:        "команда, в которой автор не состоит",
+			teamName:    "mobile",
+			members:     []domain.User{{ID: "user-8", TeamName: "mobile", IsActive: true}},
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockPRRepository{}
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult:    &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: tt.members,
+			}
+			service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+
+			pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", tt.teamName, nil, domain.GitHubLink{})
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pr.TeamName != tt.wantTeam {
+				t.Errorf("expected team %s, got %s", tt.wantTeam, pr.TeamName)
+			}
+			if !slices.Equal(mockUserRepo.ListedTeams, []string{tt.wantTeam}) {
+				t.Errorf("expected candidates from %s, got %v", tt.wantTeam, mockUserRepo.ListedTeams)
+			}
+		})
+	}
+}
+
+func TestPRService_ReassignReviewerFromPRTeam(t *testing.T) {
+	mockPRRepo := &mocks.MockPRRepository{
+		GetByIDResult:         &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", TeamName: "guild", Status: domain.PRStatusOpen},
+		GetByIDReviewers:      []string{"user-2"},
+		UpdateResult:          &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", TeamName: "guild", Status: domain.PRStatusOpen},
+		UpdateReviewersResult: []string{"user-7"},
+	}
+	mockUserRepo := &mocks.MockPRUserRepository{
+		// The old reviewer's primary team is not the PR's team.
+		GetByIDResult: &domain.User{ID: "user-2", TeamName: "team-1", IsActive: true},
+		ListByTeamResult: []domain.User{
+			{ID: "user-1", TeamName: "team-1", IsActive: true},
+			{ID: "user-2", TeamName: "team-1", IsActive: true},
+			{ID: "user-7", TeamName: "team-2", IsActive: true},
+		},
+	}
+	service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+
+	if _, _, err := service.ReassignReviewer(context.Background(), "pr-1", "user-2", 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(mockUserRepo.ListedTeams, []string{"guild"}) {
+		t.Errorf("expected candidates from guild, got %v", mockUserRepo.ListedTeams)
+	}
+	if got := mockPRRepo.UpdatedReviewers; !slices.Equal(got, []string{"user-7"}) {
+		t.Errorf("expected user-2 replaced by user-7, got %v", got)
+	}
+}
+
+func TestPRService_RiskyPullRequestLead(t *testing.T) {
+	tests := []struct {
+		name       string
+		labels     []string
+		members    []domain.User
+		wantLead   bool
+		wantLabels []string
+	}{
+		{
+			name:   "рискованный PR получает лида",
+			labels: []string{domain.LabelRisky},
+			members: []domain.User{
+				{ID: "user-1", IsActive: true},
+				{ID: "user-2", IsActive: true},
+				{ID: "user-3", IsActive: true},
+				{ID: "user-4", IsActive: true, Role: domain.RoleLead},
+			},
+			wantLead:   true,
+			wantLabels: []string{domain.LabelRisky},
+		},
+		{
+			name:   "неактивный лид не назначается",
+			labels: []string{domain.LabelRisky},
+			members: []domain.User{
+				{ID: "user-1", IsActive: true},
+				{ID: "user-2", IsActive: true},
+				{ID: "user-4", IsActive: false, Role: domain.RoleLead},
+			},
+			wantLabels: []string{domain.LabelRisky},
+		},
+		{
+			name:   "автор-лид не ревьюит свой PR",
+			labels: []string{domain.LabelRisky},
+			members: []domain.User{
+				{ID: "user-1", IsActive: true, Role: domain.RoleLead},
+				{ID: "user-2", IsActive: true},
+			},
+			wantLabels: []string{domain.LabelRisky},
+		},
+		{
+			name:   "метки без risky не требуют лида",
+			labels: []string{"ui", "ui", "docs"},
+			members: []domain.User{
+				{ID: "user-1", IsActive: true},
+				{ID: "user-2", IsActive: true},
+				{ID: "user-3", IsActive: true},
+				{ID: "user-4", IsActive: false, Role: domain.RoleLead},
+			},
+			wantLabels: []string{"docs", "ui"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult:    &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: tt.members,
+			}
+			service := NewPRService(&mocks.MockPRRepository{}, mockUserRepo, time.Now)
+
+			// Selection is random, so repeat to catch a lead being dropped.
+			for range 20 {
+				pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", tt.labels, domain.GitHubLink{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if got := slices.Contains(pr.AssignedReviewers, "user-4"); got != tt.wantLead {
+					t.Fatalf("expected lead assigned %v, got reviewers %v", tt.wantLead, pr.AssignedReviewers)
+				}
+				if slices.Contains(pr.AssignedReviewers, "user-1") {
+					t.Fatalf("author must not review, got %v", pr.AssignedReviewers)
+				}
+				if !slices.Equal(pr.Labels, tt.wantLabels) {
+					t.Fatalf("expected labels %v, got %v", tt.wantLabels, pr.Labels)
+				}
+			}
+		})
+	}
+}
+
+func TestPRService_CreatePullRequestEmptyLabel(t *testing.T) {
+	service := NewPRService(&mocks.MockPRRepository{}, &mocks.MockPRUserRepository{}, time.Now)
+
+	_, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", []string{"ui", ""}, domain.GitHubLink{})
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+		t.Fatalf("expected VALIDATION_ERROR, got %v", err)
+	}
+	if len(domainErr.Details) != 1 || domainErr.Details[0].Field != "labels" {
+		t.Errorf("expected labels field error, got %+v", domainErr.Details)
+	}
+}
+
+func TestPRService_CreatePullRequestGitHubLink(t *testing.T) {
+	tests := []struct {
+		name      string
+		link      domain.GitHubLink
+		wantField string
+	}{
+		{name: "PR без ссылки", link: domain.GitHubLink{}},
+		{name: "ссылка на PR в GitHub", link: domain.GitHubLink{Repo: "acme/api", Number: 12}},
+		{name: "номер без репозитория", link: domain.GitHubLink{Number: 12}, wantField: "github_repo"},
+		{name: "репозиторий без владельца", link: domain.GitHubLink{Repo: "api", Number: 12}, wantField: "github_repo"},
+		{name: "репозиторий без номера", link: domain.GitHubLink{Repo: "acme/api"}, wantField: "github_number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: []domain.User{
+					{ID: "user-1", IsActive: true},
+					{ID: "user-2", IsActive: true},
+				},
+			}
+			service := NewPRService(&mocks.MockPRRepository{}, mockUserRepo, time.Now)
+
+			pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", nil, tt.link)
+			if tt.wantField != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+					t.Fatalf("expected VALIDATION_ERROR, got %v", err)
+				}
+				if len(domainErr.Details) != 1 || domainErr.Details[0].Field != tt.wantField {
+					t.Errorf("expected %s field error, got %+v", tt.wantField, domainErr.Details)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pr.GitHub != tt.link {
+				t.Errorf("expected github link %+v, got %+v", tt.link, pr.GitHub)
+			}
+		})
+	}
+}
+
+func TestPRService_ReassignLeadOnRiskyPullRequest(t *testing.T) {
+	members := []domain.User{
+		{ID: "user-1", IsActive: true},
+		{ID: "user-2", IsActive: true},
+		{ID: "user-3", IsActive: true, Role: domain.RoleLead},
+		{ID: "user-5", IsActive: true},
+		{ID: "user-6", IsActive: true},
+		{ID: "user-7", IsActive: true, Role: domain.RoleLead},
+	}
+
+	tests := []struct {
+		name    string
+		labels  []string
+		wantIDs []string
+	}{
+		{
+			name:    "лид заменяется другим лидом",
+			labels:  []string{domain.LabelRisky},
+			wantIDs: []string{"user-7"},
+		},
+		{
+			name:    "без метки risky — любой участник",
+			wantIDs: []string{"user-5", "user-6", "user-7"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 20 {
+				pr := &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", TeamName: "team-1", Labels: tt.labels, Status: domain.PRStatusOpen}
+				mockPRRepo := &mocks.MockPRRepository{
+					GetByIDResult:    pr,
+					GetByIDReviewers: []string{"user-2", "user-3"},
+					UpdateResult:     pr,
+				}
+				mockUserRepo := &mocks.MockPRUserRepository{
+					GetByIDResult:    &domain.User{ID: "user-3", TeamName: "team-1", IsActive: true},
+					ListByTeamResult: members,
+				}
+				service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+
+				if _, _, err := service.ReassignReviewer(context.Background(), "pr-1", "user-3", 0); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got := mockPRRepo.UpdatedReviewers
+				if len(got) != 2 || got[0] != "user-2" || !slices.Contains(tt.wantIDs, got[1]) {
+					t.Fatalf("expected user-3 replaced by one of %v, got %v", tt.wantIDs, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPRService_ReassignReviewer_ExpectedVersion(t *testing.T) {
+	conflict := domain.NewDomainError(domain.ErrorCodeVersionConflict, "pull request was modified concurrently")
+
+	tests := []struct {
+		name            string
+		expectedVersion int64
+		updateErrSeq    []error
+		wantErrCode     domain.ErrorCode
+		wantCalls       int
+	}{
+		{
+			name:      "без If-Match",
+			wantCalls: 1,
+		},
+		{
+			name:            "If-Match совпадает",
+			expectedVersion: 5,
+			wantCalls:       1,
+		},
+		{
+			name:            "If-Match не совпадает с текущей версией",
+			expectedVersion: 3,
+			wantErrCode:     domain.ErrorCodeVersionConflict,
+			wantCalls:       0,
+		},
+		{
+			name:         "конфликт из репозитория пробрасывается",
+			updateErrSeq: []error{conflict},
+			wantErrCode:  domain.ErrorCodeVersionConflict,
+			wantCalls:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockPRRepository{
+				GetByIDResult:         &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen, Version: 5},
+				GetByIDReviewers:      []string{"user-2", "user-3"},
+				UpdateResult:          &domain.PullRequest{ID: "pr-1", AuthorID: "user-1", Status: domain.PRStatusOpen, Version: 6},
+				UpdateReviewersResult: []string{"user-4", "user-3"},
+				UpdateErrSeq:          tt.updateErrSeq,
+			}
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult: &domain.User{ID: "user-2", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: []domain.User{
+					{ID: "user-1", TeamName: "team-1", IsActive: true},
+					{ID: "user-2", TeamName: "team-1", IsActive: true},
+					{ID: "user-3", TeamName: "team-1", IsActive: true},
+					{ID: "user-4", TeamName: "team-1", IsActive: true},
+				},
+			}
+
+			service := NewPRService(mockPRRepo, mockUserRepo, time.Now)
+			result, replacedBy, err := service.ReassignReviewer(context.Background(), "pr-1", "user-2", tt.expectedVersion)
+
+			if mockPRRepo.UpdateCalls != tt.wantCalls {
+				t.Errorf("expected %d UpdateReviewers calls, got %d", tt.wantCalls, mockPRRepo.UpdateCalls)
+			}
+			for _, v := range mockPRRepo.UpdateVersions {
+				if v != 5 {
+					t.Errorf("expected update guarded by version 5, got %d", v)
+				}
+			}
+
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Version != 6 {
+				t.Errorf("expected version 6, got %d", result.Version)
+			}
+			if replacedBy != "user-4" {
+				t.Errorf("expected user-2 replaced by user-4, got %q", replacedBy)
+			}
+		})
+	}
+}
+
+func TestPRService_ListPullRequests(t *testing.T) {
+	next := &domain.PullRequestCursor{CreatedAtMicro: 1_700_000_000_123_456, ID: "pr-2"}
+
+	tests := []struct {
+		name        string
+		cursor      string
+		limit       int
+		listResult  domain.PullRequestPage
+		listErr     error
+		wantErr     bool
+		wantErrCode domain.ErrorCode
+		wantLimit   int
+		wantAfter   *domain.PullRequestCursor
+		wantNext    bool
+	}{
+		{
+			name:       "первая страница с лимитом по умолчанию",
+			listResult: domain.PullRequestPage{PullRequests: []domain.PullRequest{{ID: "pr-1"}}},
+			wantLimit:  DefaultPageLimit,
+		},
+		{
+			name:   "следующая страница по курсору",
+			cursor: encodePRCursor(next),
+			limit:  10,
+			listResult: domain.PullRequestPage{
+				PullRequests: []domain.PullRequest{{ID: "pr-3"}},
+				Next:         &domain.PullRequestCursor{CreatedAtMicro: 1, ID: "pr-3"},
+			},
+			wantLimit: 10,
+			wantAfter: next,
+			wantNext:  true,
+		},
+		{
+			name:        "лимит больше максимального",
+			limit:       MaxPageLimit + 1,
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:        "битый курсор",
+			cursor:      "not a cursor",
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:    "ошибка репозитория",
+			listErr: errors.New("database error"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockPRRepository{
+				ListResult: tt.listResult,
+				ListErr:    tt.listErr,
+			}
+			service := NewPRService(mockPRRepo, &mocks.MockPRUserRepository{}, time.Now)
+			filter := domain.PullRequestFilter{Status: domain.PRStatusOpen, TeamName: "team-1"}
+
+			prs, nextCursor, err := service.ListPullRequests(context.Background(), filter, tt.cursor, tt.limit)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if mockPRRepo.ListFilter != filter {
+				t.Errorf("expected filter %+v, got %+v", filter, mockPRRepo.ListFilter)
+			}
+			if mockPRRepo.ListLimit != tt.wantLimit {
+				t.Errorf("expected limit %d, got %d", tt.wantLimit, mockPRRepo.ListLimit)
+			}
+			switch {
+			case tt.wantAfter == nil && mockPRRepo.ListAfter != nil:
+				t.Errorf("expected no cursor, got %+v", mockPRRepo.ListAfter)
+			case tt.wantAfter != nil && (mockPRRepo.ListAfter == nil || *mockPRRepo.ListAfter != *tt.wantAfter):
+				t.Errorf("expected cursor %+v, got %+v", tt.wantAfter, mockPRRepo.ListAfter)
+			}
+			if len(prs) != len(tt.listResult.PullRequests) {
+				t.Errorf("expected %d PRs, got %d", len(tt.listResult.PullRequests), len(prs))
+			}
+			if tt.wantNext != (nextCursor != "") {
+				t.Errorf("expected next cursor %v, got %q", tt.wantNext, nextCursor)
+			}
+		})
+	}
+}
+
+func TestPRService_ReviewerPreference(t *testing.T) {
+	// 2025-01-15 07:30 UTC: 10:30 in Moscow, 02:30 in New York.
+	now := time.Date(2025, time.January, 15, 7, 30, 0, 0, time.UTC)
+	moscow := &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60}
+	newYork := &domain.WorkingHours{TimeZone: "America/New_York", Start: 9 * 60, End: 18 * 60}
+	nightShift := &domain.WorkingHours{TimeZone: "America/New_York", Start: 0, End: 4 * 60}
+
+	tests := []struct {
+		name   string
+		prefer ReviewerPreference
+		author *domain.WorkingHours
+		want   []string
+	}{
+		{
+			name:   "сначала те, кто сейчас работает",
+			prefer: PreferWorkingNow,
+			want:   []string{"user-2", "user-4"},
+		},
+		{
+			name:   "сначала те, чьи часы пересекаются с часами автора",
+			prefer: PreferHoursOverlap,
+			author: moscow,
+			want:   []string{"user-2", "user-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true, WorkingHours: tt.author},
+				ListByTeamResult: []domain.User{
+					{ID: "user-1", IsActive: true, WorkingHours: tt.author},
+					{ID: "user-2", IsActive: true, WorkingHours: moscow},
+					{ID: "user-3", IsActive: true, WorkingHours: &domain.WorkingHours{TimeZone: "Europe/Berlin", Start: 9 * 60, End: 18 * 60}},
+					{ID: "user-4", IsActive: true, WorkingHours: nightShift},
+					{ID: "user-5", IsActive: true, WorkingHours: newYork},
+				},
+			}
+			service := NewPRService(&mocks.MockPRRepository{}, mockUserRepo, func() time.Time { return now })
+			service.SetReviewerPreference(tt.prefer)
+
+			for range 20 {
+				pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", nil, domain.GitHubLink{})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got := slices.Sorted(slices.Values(pr.AssignedReviewers))
+				if !slices.Equal(got, tt.want) {
+					t.Fatalf("expected reviewers %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestPRService_ReassignReviewerPrefersOverlap(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 7, 30, 0, 0, time.UTC)
+	moscow := &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60}
+
+	mockPRRepo := &mocks.MockPRRepository{
+		GetByIDResult: &domain.PullRequest{
+			ID: "pr-1", AuthorID: "user-1", TeamName: "team-1", Status: domain.PRStatusOpen, Version: 1,
+		},
+		GetByIDReviewers: []string{"user-2", "user-3"},
+		UpdateResult:     &domain.PullRequest{ID: "pr-1", Status: domain.PRStatusOpen},
+	}
+	mockUserRepo := &mocks.MockPRUserRepository{
+		GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true, WorkingHours: moscow},
+		ListByTeamResult: []domain.User{
+			{ID: "user-1", IsActive: true, WorkingHours: moscow},
+			{ID: "user-2", IsActive: true, WorkingHours: moscow},
+			{ID: "user-3", IsActive: true, WorkingHours: moscow},
+			{ID: "user-4", IsActive: true, WorkingHours: &domain.WorkingHours{TimeZone: "America/Los_Angeles", Start: 9 * 60, End: 18 * 60}},
+			{ID: "user-5", IsActive: true, WorkingHours: &domain.WorkingHours{TimeZone: "Europe/Istanbul", Start: 9 * 60, End: 18 * 60}},
+		},
+	}
+	service := NewPRService(mockPRRepo, mockUserRepo, func() time.Time { return now })
+	service.SetReviewerPreference(PreferHoursOverlap)
+
+	for range 20 {
+		if _, _, err := service.ReassignReviewer(context.Background(), "pr-1", "user-2", 0); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := mockPRRepo.UpdatedReviewers; !slices.Equal(got, []string{"user-5", "user-3"}) {
+			t.Fatalf("expected user-2 replaced by user-5, got %v", got)
+		}
+	}
+}
+
+func TestPRService_ReviewCapacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		overflow    domain.CapacityOverflow
+		members     []domain.User
+		want        []string
+		wantErrCode domain.ErrorCode
+	}{
+		{
+			name: "занятые до лимита пропускаются",
+			members: []domain.User{
+				{ID: "user-2", IsActive: true, MaxOpenReviews: 2, OpenReviews: 2},
+				{ID: "user-3", IsActive: true, MaxOpenReviews: 3, OpenReviews: 2},
+				{ID: "user-4", IsActive: true, OpenReviews: 10},
+			},
+			want: []string{"user-3", "user-4"},
+		},
+		{
+			name: "все на пределе",
+			members: []domain.User{
+				{ID: "user-2", IsActive: true, MaxOpenReviews: 2, OpenReviews: 2},
+				{ID: "user-3", IsActive: true, MaxOpenReviews: 1, OpenReviews: 1},
+			},
+			wantErrCode: domain.ErrorCodeAllAtCapacity,
+		},
+		{
+			name:     "все на пределе, назначаются наименее загруженные",
+			overflow: domain.OverflowLeastLoaded,
+			members: []domain.User{
+				{ID: "user-2", IsActive: true, MaxOpenReviews: 2, OpenReviews: 2},
+				{ID: "user-3", IsActive: true, MaxOpenReviews: 1, OpenReviews: 1},
+				{ID: "user-4", IsActive: true, MaxOpenReviews: 4, OpenReviews: 5},
+			},
+			want: []string{"user-2", "user-3"},
+		},
+		{
+			name: "исчерпавшие недельную квоту пропускаются",
+			members: []domain.User{
+				{ID: "user-2", IsActive: true, WeeklyReviewQuota: 3, WeeklyReviews: 3},
+				{ID: "user-3", IsActive: true, WeeklyReviewQuota: 3, WeeklyReviews: 2},
+			},
+			want: []string{"user-3"},
+		},
+		{
+			name:     "квота не превышается и сверх лимита",
+			overflow: domain.OverflowLeastLoaded,
+			members: []domain.User{
+				{ID: "user-2", IsActive: true, MaxOpenReviews: 1, OpenReviews: 1, WeeklyReviewQuota: 1, WeeklyReviews: 1},
+				{ID: "user-3", IsActive: true, MaxOpenReviews: 1, OpenReviews: 1},
+			},
+			want: []string{"user-3"},
+		},
+		{
+			name: "неактивные на пределе не считаются",
+			members: []domain.User{
+				{ID: "user-2", IsActive: false, MaxOpenReviews: 1, OpenReviews: 1},
+				{ID: "user-3", IsActive: true, OutOfOffice: true, MaxOpenReviews: 1, OpenReviews: 1},
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockPRUserRepository{
+				GetByIDResult:    &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+				ListByTeamResult: append([]domain.User{{ID: "user-1", IsActive: true}}, tt.members...),
+			}
+			service := NewPRService(&mocks.MockPRRepository{}, mockUserRepo, nil)
+			service.SetCapacityOverflow(tt.overflow)
+
+			pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", nil, domain.GitHubLink{})
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := slices.Sorted(slices.Values(pr.AssignedReviewers))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expected reviewers %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPRService_Fairness(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)
+	mockUserRepo := &mocks.MockPRUserRepository{
+		GetByIDResult: &domain.User{ID: "user-1", TeamName: "team-1", IsActive: true},
+		ListByTeamResult: []domain.User{
+			{ID: "user-1", IsActive: true},
+			{ID: "user-2", IsActive: true},
+			{ID: "user-3", IsActive: true},
+			{ID: "user-4", IsActive: true},
+			{ID: "user-5", IsActive: true},
+		},
+	}
+	history := &mocks.MockAssignmentHistory{
+		Window: 14 * 24 * time.Hour,
+		Counts: map[string]int{"user-2": 1, "user-4": 1_000_000, "user-5": 1_000_000},
+	}
+	service := NewPRService(&mocks.MockPRRepository{}, mockUserRepo, func() time.Time { return now })
+	service.SetFairness(history)
+
+	// The weights are random, so repeat; user-4 and user-5 have almost none.
+	for range 20 {
+		pr, err := service.CreatePullRequest(context.Background(), "pr-1", "Test PR", "user-1", "", nil, domain.GitHubLink{})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		got := slices.Sorted(slices.Values(pr.AssignedReviewers))
+		if !slices.Equal(got, []string{"user-2", "user-3"}) {
+			t.Fatalf("expected the least assigned reviewers, got %v", got)
+		}
+	}
+	if want := now.Add(-14 * 24 * time.Hour); !history.Since.Equal(want) {
+		t.Errorf("expected assignments counted since %v, got %v", want, history.Since)
+	}
+
+	history.Err = errors.New("db down")
+	if _, err := service.CreatePullRequest(context.Background(), "pr-2", "Test PR", "user-1", "", nil, domain.GitHubLink{}); err == nil {
+		t.Errorf("expected the history error")
+	}
+
+	history.Window = 0
+	if _, err := service.CreatePullRequest(context.Background(), "pr-3", "Test PR", "user-1", "", nil, domain.GitHubLink{}); err != nil {
+		t.Errorf("expected no history lookup without a window, got %v", err)
+	}
+}
+
+func TestPRService_ReassignReviewerCapacity(t *testing.T) {
+	tests := []struct {
+		name        string
+		overflow    domain.CapacityOverflow
+		want        []string
+		wantErrCode domain.ErrorCode
+	}{
+		{
+			name:        "замены на пределе",
+			wantErrCode: domain.ErrorCodeAllAtCapacity,
+		},
+		{
+			name:     "замена наименее загруженным",
+			overflow: domain.OverflowLeastLoaded,
+			want:     []string{"user-5", "user-3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockPRRepository{
+				GetByIDResult: &domain.PullRequest{
+					ID: "pr-1", AuthorID: "user-1", TeamName: "team-1", Status: domain.PRStatusOpen, Version: 1,
+				},
+				GetByIDReviewers: []string{"user-2", "user-3"},
+				UpdateResult:     &domain.PullRequest{ID: "pr-1", Status: domain.PRStatusOpen},
+			}
+			mockUserRepo := &mocks.MockPRUserRepository{
+				ListByTeamResult: []domain.User{
+					{ID: "user-1", IsActive: true},
+					{ID: "user-2", IsActive: true},
+					{ID: "user-3", IsActive: true},
+					{ID: "user-4", IsActive: true, MaxOpenReviews: 3, OpenReviews: 3},
+					{ID: "user-5", IsActive: true, MaxOpenReviews: 1, OpenReviews: 2},
+					{ID: "user-6", IsActive: true, MaxOpenReviews: 2, OpenReviews: 4},
+				},
+			}
+			service := NewPRService(mockPRRepo, mockUserRepo, nil)
+			service.SetCapacityOverflow(tt.overflow)
+
+			_, _, err := service.ReassignReviewer(context.Background(), "pr-1", "user-2", 0)
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := mockPRRepo.UpdatedReviewers; !slices.Equal(got, tt.want) {
+				t.Errorf("expected reviewers %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
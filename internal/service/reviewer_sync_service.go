@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// ReviewerRequester requests and removes reviews on pull request number of
+// repo ("owner/name").
+type ReviewerRequester interface {
+	RequestReviewers(ctx context.Context, repo string, number int, logins []string) (int, error)
+	RemoveRequestedReviewers(ctx context.Context, repo string, number int, logins []string) (int, error)
+}
+
+type ReviewerSyncRepository interface {
+	SaveSyncStatus(ctx context.Context, s *domain.ReviewerSync) error
+	GetSyncStatus(ctx context.Context, prID string) (*domain.ReviewerSync, error)
+}
+
+type ReviewerSyncUserRepository interface {
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+}
+
+// ReviewerSyncService pushes reviewer assignments to the Git hosting API.
+type ReviewerSyncService struct {
+	requester ReviewerRequester
+	syncs     ReviewerSyncRepository
+	users     ReviewerSyncUserRepository
+	nowFunc   func() time.Time
+	timeout   time.Duration
+	wg        sync.WaitGroup
+
+	// mu guards queued: the syncs of each PR waiting behind the running one.
+	// A PR has a worker while it has an entry, so its syncs run one at a
+	// time in the order they were scheduled.
+	mu     sync.Mutex
+	queued map[string][]reviewerSyncJob
+}
+
+type reviewerSyncJob struct {
+	pr      domain.PullRequest
+	removed []string
+}
+
+func NewReviewerSyncService(
+	requester ReviewerRequester,
+	syncs ReviewerSyncRepository,
+	users ReviewerSyncUserRepository,
+	nowFunc func() time.Time,
+	timeout time.Duration,
+) *ReviewerSyncService {
+	if nowFunc == nil {
+		nowFunc = time.Now
+	}
+	return &ReviewerSyncService{
+		requester: requester,
+		syncs:     syncs,
+		users:     users,
+		nowFunc:   nowFunc,
+		timeout:   timeout,
+		queued:    make(map[string][]reviewerSyncJob),
+	}
+}
+
+// Schedule records the PR as pending and syncs it in the background,
+// so a slow or failing Git host never blocks the assignment itself. Syncs
+// of the same PR run in the order they were scheduled, so an older one
+// never undoes a newer one. A PR not linked to a GitHub pull request is
+// skipped and gets no sync status.
+func (s *ReviewerSyncService) Schedule(ctx context.Context, pr *domain.PullRequest, removed []string) {
+	if pr.GitHub.IsZero() {
+		return
+	}
+
+	job := reviewerSyncJob{pr: *pr, removed: append([]string(nil), removed...)}
+	job.pr.AssignedReviewers = append([]string(nil), pr.AssignedReviewers...)
+
+	bgCtx := context.WithoutCancel(ctx)
+
+	// #nosec G104 -- a failure here is retried by SyncReviewers below
+	_ = s.saveStatus(bgCtx, job.pr.ID, domain.ReviewerSyncPending, 0, "")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if queue, running := s.queued[job.pr.ID]; running {
+		s.queued[job.pr.ID] = append(queue, job)
+		return
+	}
+	s.queued[job.pr.ID] = nil
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.run(bgCtx, job)
+
+			s.mu.Lock()
+			queue := s.queued[job.pr.ID]
+			if len(queue) == 0 {
+				delete(s.queued, job.pr.ID)
+				s.mu.Unlock()
+				return
+			}
+			job, s.queued[job.pr.ID] = queue[0], queue[1:]
+			s.mu.Unlock()
+		}
+	}()
+}
+
+func (s *ReviewerSyncService) run(ctx context.Context, job reviewerSyncJob) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	// #nosec G104 -- the outcome is persisted as the sync status
+	_ = s.SyncReviewers(ctx, &job.pr, job.removed)
+}
+
+// Wait blocks until all scheduled syncs have finished.
+func (s *ReviewerSyncService) Wait() {
+	s.wg.Wait()
+}
+
+func (s *ReviewerSyncService) SyncReviewers(ctx context.Context, pr *domain.PullRequest, removed []string) error {
+	attempts, syncErr := s.push(ctx, pr, removed)
+	if syncErr != nil {
+		if err := s.saveStatus(ctx, pr.ID, domain.ReviewerSyncFailed, attempts, syncErr.Error()); err != nil {
+			return err
+		}
+		return syncErr
+	}
+	return s.saveStatus(ctx, pr.ID, domain.ReviewerSyncSynced, attempts, "")
+}
+
+// GetSyncStatus returns the last sync of the PR; a PR that was never synced
+// yields NOT_FOUND.
+func (s *ReviewerSyncService) GetSyncStatus(ctx context.Context, prID string) (*domain.ReviewerSync, error) {
+	status, err := s.syncs.GetSyncStatus(ctx, prID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewDomainError(domain.ErrorCodeNotFound,
+				fmt.Sprintf("reviewer sync of pull request %s not found", prID))
+		}
+		return nil, fmt.Errorf("get reviewer sync status: %w", err)
+	}
+	return status, nil
+}
+
+func (s *ReviewerSyncService) push(ctx context.Context, pr *domain.PullRequest, removed []string) (int, error) {
+	if pr.GitHub.IsZero() {
+		return 0, fmt.Errorf("pull request %s is not linked to github", pr.ID)
+	}
+	repo, number := pr.GitHub.Repo, pr.GitHub.Number
+
+	attempts := 0
+	if len(removed) > 0 {
+		logins, err := s.logins(ctx, removed)
+		if err != nil {
+			return attempts, err
+		}
+		n, err := s.requester.RemoveRequestedReviewers(ctx, repo, number, logins)
+		attempts += n
+		if err != nil {
+			return attempts, fmt.Errorf("remove requested reviewers: %w", err)
+		}
+	}
+
+	if len(pr.AssignedReviewers) == 0 {
+		return attempts, nil
+	}
+
+	logins, err := s.logins(ctx, pr.AssignedReviewers)
+	if err != nil {
+		return attempts, err
+	}
+	n, err := s.requester.RequestReviewers(ctx, repo, number, logins)
+	attempts += n
+	if err != nil {
+		return attempts, fmt.Errorf("request reviewers: %w", err)
+	}
+	return attempts, nil
+}
+
+func (s *ReviewerSyncService) logins(ctx context.Context, userIDs []string) ([]string, error) {
+	logins := make([]string, 0, len(userIDs))
+	for _, id := range userIDs {
+		u, err := s.users.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get reviewer %s: %w", id, err)
+		}
+		logins = append(logins, u.Username)
+	}
+	return logins, nil
+}
+
+func (s *ReviewerSyncService) saveStatus(
+	ctx context.Context,
+	prID string,
+	status domain.ReviewerSyncStatus,
+	attempts int,
+	lastErr string,
+) error {
+	if err := s.syncs.SaveSyncStatus(ctx, &domain.ReviewerSync{
+		PullRequestID: prID,
+		Status:        status,
+		Attempts:      attempts,
+		LastError:     lastErr,
+		UpdatedAt:     s.nowFunc().Unix(),
+	}); err != nil {
+		return fmt.Errorf("save reviewer sync status: %w", err)
+	}
+	return nil
+}
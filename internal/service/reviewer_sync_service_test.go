@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func TestReviewerSyncService_SyncReviewers(t *testing.T) {
+	tests := []struct {
+		name            string
+		pr              *domain.PullRequest
+		removed         []string
+		requestAttempts int
+		requestErr      error
+		removeAttempts  int
+		wantErr         bool
+		wantStatus      domain.ReviewerSyncStatus
+		wantAttempts    int
+		wantNumber      int
+		wantRemoved     int
+	}{
+		{
+			name:            "успешная синхронизация",
+			pr:              &domain.PullRequest{ID: "pr-1", GitHub: domain.GitHubLink{Repo: "acme/api", Number: 1001}, AssignedReviewers: []string{"u2", "u3"}},
+			requestAttempts: 1,
+			wantStatus:      domain.ReviewerSyncSynced,
+			wantAttempts:    1,
+			wantNumber:      1001,
+		},
+		{
+			name:            "переназначение снимает старого ревьювера",
+			pr:              &domain.PullRequest{ID: "pr-2", GitHub: domain.GitHubLink{Repo: "acme/api", Number: 7}, AssignedReviewers: []string{"u3", "u4"}},
+			removed:         []string{"u2"},
+			requestAttempts: 1,
+			removeAttempts:  2,
+			wantStatus:      domain.ReviewerSyncSynced,
+			wantAttempts:    3,
+			wantNumber:      7,
+			wantRemoved:     1,
+		},
+		{
+			name:            "ошибка после всех повторов",
+			pr:              &domain.PullRequest{ID: "pr-3", GitHub: domain.GitHubLink{Repo: "acme/api", Number: 5}, AssignedReviewers: []string{"u2"}},
+			requestAttempts: 3,
+			requestErr:      errors.New("status 502"),
+			wantErr:         true,
+			wantStatus:      domain.ReviewerSyncFailed,
+			wantAttempts:    3,
+			wantNumber:      5,
+		},
+		{
+			name:         "PR без ссылки на GitHub",
+			pr:           &domain.PullRequest{ID: "pr-1001", AssignedReviewers: []string{"u2"}},
+			wantErr:      true,
+			wantStatus:   domain.ReviewerSyncFailed,
+			wantAttempts: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requester := &mocks.MockReviewerRequester{
+				RequestAttempts: tt.requestAttempts,
+				RequestErr:      tt.requestErr,
+				RemoveAttempts:  tt.removeAttempts,
+			}
+			syncRepo := &mocks.MockReviewerSyncRepository{}
+			userRepo := &mocks.MockUserRepository{
+				GetByIDResult: &domain.User{ID: "u", Username: "octocat"},
+			}
+
+			svc := NewReviewerSyncService(requester, syncRepo, userRepo, func() time.Time { return time.Unix(3000, 0) }, 0)
+
+			err := svc.SyncReviewers(context.Background(), tt.pr, tt.removed)
+			if tt.wantErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(syncRepo.Saved) != 1 {
+				t.Fatalf("expected 1 saved status, got %d", len(syncRepo.Saved))
+			}
+			saved := syncRepo.Saved[0]
+			if saved.Status != tt.wantStatus {
+				t.Errorf("expected status %s, got %s", tt.wantStatus, saved.Status)
+			}
+			if saved.Attempts != tt.wantAttempts {
+				t.Errorf("expected %d attempts, got %d", tt.wantAttempts, saved.Attempts)
+			}
+			if saved.UpdatedAt != 3000 {
+				t.Errorf("expected UpdatedAt 3000, got %d", saved.UpdatedAt)
+			}
+			if tt.wantErr && saved.LastError == "" {
+				t.Errorf("expected last error to be stored")
+			}
+			if tt.wantNumber != 0 && requester.RequestedRepo != "acme/api" {
+				t.Errorf("expected repo acme/api, got %q", requester.RequestedRepo)
+			}
+			if requester.RequestedNumber != tt.wantNumber {
+				t.Errorf("expected PR number %d, got %d", tt.wantNumber, requester.RequestedNumber)
+			}
+			if len(requester.RemovedLogins) != tt.wantRemoved {
+				t.Errorf("expected %d removed logins, got %d", tt.wantRemoved, len(requester.RemovedLogins))
+			}
+		})
+	}
+}
+
+func TestReviewerSyncService_Schedule(t *testing.T) {
+	requester := &mocks.MockReviewerRequester{RequestAttempts: 1}
+	syncRepo := &mocks.MockReviewerSyncRepository{}
+	userRepo := &mocks.MockUserRepository{
+		GetByIDResult: &domain.User{ID: "u2", Username: "octocat"},
+	}
+
+	svc := NewReviewerSyncService(requester, syncRepo, userRepo, time.Now, time.Second)
+	svc.Schedule(context.Background(), &domain.PullRequest{
+		ID:                "pr-1",
+		GitHub:            domain.GitHubLink{Repo: "acme/api", Number: 1},
+		AssignedReviewers: []string{"u2"},
+	}, nil)
+	svc.Wait()
+
+	if len(syncRepo.Saved) != 2 {
+		t.Fatalf("expected 2 saved statuses, got %d", len(syncRepo.Saved))
+	}
+	if syncRepo.Saved[0].Status != domain.ReviewerSyncPending {
+		t.Errorf("expected first status PENDING, got %s", syncRepo.Saved[0].Status)
+	}
+	if syncRepo.Saved[1].Status != domain.ReviewerSyncSynced {
+		t.Errorf("expected final status SYNCED, got %s", syncRepo.Saved[1].Status)
+	}
+}
+
+func TestReviewerSyncService_ScheduleSkipsUnlinked(t *testing.T) {
+	requester := &mocks.MockReviewerRequester{RequestAttempts: 1}
+	syncRepo := &mocks.MockReviewerSyncRepository{}
+	userRepo := &mocks.MockUserRepository{
+		GetByIDResult: &domain.User{ID: "u2", Username: "octocat"},
+	}
+
+	svc := NewReviewerSyncService(requester, syncRepo, userRepo, time.Now, time.Second)
+	svc.Schedule(context.Background(), &domain.PullRequest{ID: "pr-1001", AssignedReviewers: []string{"u2"}}, nil)
+	svc.Wait()
+
+	if len(syncRepo.Saved) != 0 {
+		t.Errorf("expected no sync status for an unlinked PR, got %v", syncRepo.Saved)
+	}
+	if requester.RequestedLogins != nil {
+		t.Errorf("expected no request to GitHub, got %v", requester.RequestedLogins)
+	}
+}
+
+// orderedRequester records its calls; the first one waits for release.
+type orderedRequester struct {
+	mu      sync.Mutex
+	calls   []string
+	release chan struct{}
+}
+
+func (r *orderedRequester) record(call string) {
+	r.mu.Lock()
+	first := len(r.calls) == 0
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+	if first {
+		<-r.release
+	}
+}
+
+func (r *orderedRequester) RequestReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	r.record("request")
+	return 1, nil
+}
+
+func (r *orderedRequester) RemoveRequestedReviewers(ctx context.Context, repo string, number int, logins []string) (int, error) {
+	r.record("remove")
+	return 1, nil
+}
+
+func TestReviewerSyncService_ScheduleKeepsOrder(t *testing.T) {
+	requester := &orderedRequester{release: make(chan struct{})}
+	syncRepo := &mocks.MockReviewerSyncRepository{}
+	userRepo := &mocks.MockUserRepository{
+		GetByIDResult: &domain.User{ID: "u2", Username: "octocat"},
+	}
+
+	link := domain.GitHubLink{Repo: "acme/api", Number: 1}
+	svc := NewReviewerSyncService(requester, syncRepo, userRepo, time.Now, time.Second)
+	// The create sync is still running when the reassign sync is scheduled.
+	svc.Schedule(context.Background(), &domain.PullRequest{ID: "pr-1", GitHub: link, AssignedReviewers: []string{"u2"}}, nil)
+	svc.Schedule(context.Background(), &domain.PullRequest{ID: "pr-1", GitHub: link, AssignedReviewers: []string{"u3"}}, []string{"u2"})
+	close(requester.release)
+	svc.Wait()
+
+	if want := []string{"request", "remove", "request"}; !slices.Equal(requester.calls, want) {
+		t.Errorf("expected calls %v, got %v", want, requester.calls)
+	}
+	if last := syncRepo.Saved[len(syncRepo.Saved)-1]; last.Status != domain.ReviewerSyncSynced {
+		t.Errorf("expected final status SYNCED, got %s", last.Status)
+	}
+}
+
+func TestReviewerSyncService_GetSyncStatus(t *testing.T) {
+	syncs := &mocks.MockReviewerSyncRepository{GetSyncErr: sql.ErrNoRows}
+	svc := NewReviewerSyncService(&mocks.MockReviewerRequester{}, syncs, &mocks.MockUserRepository{}, nil, 0)
+
+	_, err := svc.GetSyncStatus(context.Background(), "pr-1")
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for a PR never synced, got %v", err)
+	}
+
+	syncs.GetSyncErr = errors.New("db down")
+	if _, err := svc.GetSyncStatus(context.Background(), "pr-1"); err == nil || errors.As(err, &domainErr) {
+		t.Errorf("expected the repository error, got %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// rosterColumns are the CSV columns in export order; active and role may be
+// left out of an imported file.
+var rosterColumns = []string{"team", "user_id", "username", "active", "role"}
+
+// rosterRow is a roster entry as written in YAML, a list of these. A missing
+// active means true.
+type rosterRow struct {
+	Team     string `yaml:"team"`
+	UserID   string `yaml:"user_id"`
+	Username string `yaml:"username"`
+	Active   *bool  `yaml:"active,omitempty"`
+	Role     string `yaml:"role,omitempty"`
+}
+
+func decodeRoster(format domain.RosterFormat, data []byte) ([]domain.RosterEntry, error) {
+	switch format {
+	case domain.RosterFormatYAML:
+		return decodeRosterYAML(data)
+	case domain.RosterFormatCSV:
+		return decodeRosterCSV(data)
+	}
+	return nil, invalidRosterFormatError()
+}
+
+func encodeRoster(format domain.RosterFormat, entries []domain.RosterEntry) ([]byte, error) {
+	switch format {
+	case domain.RosterFormatYAML:
+		return encodeRosterYAML(entries)
+	case domain.RosterFormatCSV:
+		return encodeRosterCSV(entries)
+	}
+	return nil, invalidRosterFormatError()
+}
+
+func decodeRosterYAML(data []byte) ([]domain.RosterEntry, error) {
+	var rows []rosterRow
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rows); err != nil && !errors.Is(err, io.EOF) {
+		return nil, domain.NewDomainError(domain.ErrorCodeBadRequest, "invalid yaml roster: "+err.Error())
+	}
+
+	entries := make([]domain.RosterEntry, 0, len(rows))
+	for _, row := range rows {
+		entry := domain.RosterEntry{
+			TeamName: strings.TrimSpace(row.Team),
+			UserID:   strings.TrimSpace(row.UserID),
+			Username: strings.TrimSpace(row.Username),
+			IsActive: row.Active == nil || *row.Active,
+			Role:     domain.MemberRole(strings.TrimSpace(row.Role)),
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func encodeRosterYAML(entries []domain.RosterEntry) ([]byte, error) {
+	rows := make([]rosterRow, 0, len(entries))
+	for _, e := range entries {
+		active := e.IsActive
+		rows = append(rows, rosterRow{
+			Team:     e.TeamName,
+			UserID:   e.UserID,
+			Username: e.Username,
+			Active:   &active,
+			Role:     string(e.Role),
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(rows); err != nil {
+		return nil, fmt.Errorf("encode yaml roster: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml roster: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeRosterCSV reads a CSV roster whose first line names the columns, in
+// any order.
+func decodeRosterCSV(data []byte) ([]domain.RosterEntry, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, domain.NewDomainError(domain.ErrorCodeBadRequest, "invalid csv roster: "+err.Error())
+	}
+	if len(records) == 0 {
+		return []domain.RosterEntry{}, nil
+	}
+
+	index := make(map[string]int, len(rosterColumns))
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := index[name]; ok || !slices.Contains(rosterColumns, name) {
+			return nil, domain.NewValidationError("invalid csv header", domain.FieldError{
+				Field:  "header",
+				Reason: fmt.Sprintf("unknown or repeated column %q", name),
+			})
+		}
+		index[name] = i
+	}
+	for _, name := range rosterColumns[:3] {
+		if _, ok := index[name]; !ok {
+			return nil, domain.NewValidationError("invalid csv header", domain.FieldError{
+				Field:  "header",
+				Reason: "missing column " + name,
+			})
+		}
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	entries := make([]domain.RosterEntry, 0, len(records)-1)
+	for i, record := range records[1:] {
+		active := true
+		if v := cell(record, "active"); v != "" {
+			active, err = strconv.ParseBool(v)
+			if err != nil {
+				return nil, domain.NewValidationError("invalid roster", domain.FieldError{
+					Field:  fmt.Sprintf("rows[%d].active", i),
+					Reason: "must be true or false",
+				})
+			}
+		}
+		entries = append(entries, domain.RosterEntry{
+			TeamName: cell(record, "team"),
+			UserID:   cell(record, "user_id"),
+			Username: cell(record, "username"),
+			IsActive: active,
+			Role:     domain.MemberRole(cell(record, "role")),
+		})
+	}
+	return entries, nil
+}
+
+func encodeRosterCSV(entries []domain.RosterEntry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(rosterColumns); err != nil {
+		return nil, fmt.Errorf("encode csv roster: %w", err)
+	}
+	for _, e := range entries {
+		record := []string{e.TeamName, e.UserID, e.Username, strconv.FormatBool(e.IsActive), string(e.Role)}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("encode csv roster: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("encode csv roster: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func invalidRosterFormatError() *domain.DomainError {
+	return domain.NewValidationError("unknown roster format", domain.FieldError{
+		Field:  "format",
+		Reason: "must be one of yaml, csv",
+	})
+}
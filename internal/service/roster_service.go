@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+type RosterRepository interface {
+	Export(ctx context.Context) ([]domain.RosterEntry, error)
+	Teams(ctx context.Context, names []string) (map[string]bool, error)
+	Users(ctx context.Context, ids []string) (map[string]domain.User, error)
+	Memberships(ctx context.Context, userIDs []string) (map[string]map[string]domain.MemberRole, error)
+	CreateTeam(ctx context.Context, name string) error
+	CreateUser(ctx context.Context, u domain.User) error
+	UpdateUser(ctx context.Context, u domain.User) error
+	SetMembership(ctx context.Context, teamName, userID string, role domain.MemberRole) error
+}
+
+type RosterPRRepository interface {
+	HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error)
+}
+
+// RosterService imports and exports the whole set of teams and their
+// members as a YAML or CSV roster.
+type RosterService struct {
+	roster RosterRepository
+	prs    RosterPRRepository
+	tx     TxManager
+	sync   ReviewerChangeScheduler
+}
+
+func NewRosterService(roster RosterRepository, prs RosterPRRepository, tx TxManager) *RosterService {
+	if tx == nil {
+		tx = noTx{}
+	}
+	return &RosterService{
+		roster: roster,
+		prs:    prs,
+		tx:     tx,
+	}
+}
+
+// SetReviewerSync enables pushing the reviewers of PRs changed by
+// deactivations to the Git hosting API.
+func (s *RosterService) SetReviewerSync(sync ReviewerChangeScheduler) {
+	s.sync = sync
+}
+
+// rosterStep is a planned change and how to make it. deactivates marks a
+// step that makes change.UserID inactive.
+type rosterStep struct {
+	change      domain.RosterChange
+	apply       func(ctx context.Context) error
+	deactivates bool
+}
+
+// Import brings teams and users in line with the roster in data. It only
+// adds: missing teams are created as root teams, missing users get the first
+// team they are listed in as their primary team, and existing users get
+// their username, activity, memberships and non-empty roles updated. Nobody
+// is removed or moved. Users made inactive hand their open reviews over to
+// other members, as with /users/setIsActive. All changes are made in one
+// transaction; with dryRun they are only reported.
+func (s *RosterService) Import(
+	ctx context.Context,
+	format domain.RosterFormat,
+	data []byte,
+	dryRun bool,
+) (*domain.RosterImportResult, error) {
+	entries, err := decodeRoster(format, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoster(entries); err != nil {
+		return nil, err
+	}
+
+	var (
+		steps   []rosterStep
+		changes []domain.ReviewerChange
+	)
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		steps, err = s.plan(ctx, entries)
+		if err != nil || dryRun {
+			return err
+		}
+
+		var deactivated []string
+		for _, step := range steps {
+			if err := step.apply(ctx); err != nil {
+				return err
+			}
+			if step.deactivates {
+				deactivated = append(deactivated, step.change.UserID)
+			}
+		}
+		if len(deactivated) == 0 {
+			return nil
+		}
+		changes, err = s.prs.HandOverOpenReviews(ctx, deactivated)
+		if err != nil {
+			return fmt.Errorf("hand over open reviews: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.sync != nil && len(changes) > 0 {
+		s.sync.ScheduleChanges(ctx, changes)
+	}
+
+	result := &domain.RosterImportResult{
+		DryRun:  dryRun,
+		Changes: make([]domain.RosterChange, 0, len(steps)),
+	}
+	for _, step := range steps {
+		result.Changes = append(result.Changes, step.change)
+	}
+	return result, nil
+}
+
+// Export returns the memberships of all non-archived teams as a roster in
+// format; importing it again changes nothing.
+func (s *RosterService) Export(ctx context.Context, format domain.RosterFormat) ([]byte, error) {
+	if !format.IsValid() {
+		return nil, invalidRosterFormatError()
+	}
+	entries, err := s.roster.Export(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("export roster: %w", err)
+	}
+	return encodeRoster(format, entries)
+}
+
+// plan compares entries with the current state, read inside the import
+// transaction, and returns the steps in the order they must be applied:
+// teams first, then each user followed by their memberships.
+func (s *RosterService) plan(ctx context.Context, entries []domain.RosterEntry) ([]rosterStep, error) {
+	var teamNames, userIDs []string
+	seenTeams := make(map[string]bool)
+	seenUsers := make(map[string]bool)
+	for _, e := range entries {
+		if !seenTeams[e.TeamName] {
+			seenTeams[e.TeamName] = true
+			teamNames = append(teamNames, e.TeamName)
+		}
+		if !seenUsers[e.UserID] {
+			seenUsers[e.UserID] = true
+			userIDs = append(userIDs, e.UserID)
+		}
+	}
+
+	teams, err := s.roster.Teams(ctx, teamNames)
+	if err != nil {
+		return nil, fmt.Errorf("get teams: %w", err)
+	}
+	users, err := s.roster.Users(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get users: %w", err)
+	}
+	memberships, err := s.roster.Memberships(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("get memberships: %w", err)
+	}
+
+	var steps []rosterStep
+	for _, name := range teamNames {
+		archived, ok := teams[name]
+		if archived {
+			return nil, teamArchivedError(name)
+		}
+		if ok {
+			continue
+		}
+		steps = append(steps, rosterStep{
+			change: domain.RosterChange{Kind: domain.RosterCreateTeam, TeamName: name},
+			apply: func(ctx context.Context) error {
+				return s.roster.CreateTeam(ctx, name)
+			},
+		})
+	}
+
+	planned := make(map[string]bool, len(userIDs))
+	for _, e := range entries {
+		if !planned[e.UserID] {
+			planned[e.UserID] = true
+			if step, ok := userStep(s.roster, users, e); ok {
+				steps = append(steps, step)
+			}
+		}
+
+		role, member := memberships[e.UserID][e.TeamName]
+		switch {
+		case !member:
+			newRole := e.Role
+			if newRole == "" {
+				newRole = domain.RoleMember
+			}
+			steps = append(steps, rosterStep{
+				change: domain.RosterChange{
+					Kind:     domain.RosterAddMembership,
+					TeamName: e.TeamName,
+					UserID:   e.UserID,
+					Detail:   "role " + string(newRole),
+				},
+				apply: func(ctx context.Context) error {
+					return s.roster.SetMembership(ctx, e.TeamName, e.UserID, newRole)
+				},
+			})
+		case e.Role != "" && e.Role != role:
+			steps = append(steps, rosterStep{
+				change: domain.RosterChange{
+					Kind:     domain.RosterSetRole,
+					TeamName: e.TeamName,
+					UserID:   e.UserID,
+					Detail:   fmt.Sprintf("%s -> %s", role, e.Role),
+				},
+				apply: func(ctx context.Context) error {
+					return s.roster.SetMembership(ctx, e.TeamName, e.UserID, e.Role)
+				},
+			})
+		}
+	}
+	return steps, nil
+}
+
+// userStep creates the user of e, with e's team as the primary one, or
+// updates their username and activity; ok is false if nothing changes. The
+// change of a user made inactive tells how many open reviews they hand over.
+func userStep(roster RosterRepository, users map[string]domain.User, e domain.RosterEntry) (rosterStep, bool) {
+	u := domain.User{
+		ID:       e.UserID,
+		Username: e.Username,
+		TeamName: e.TeamName,
+		IsActive: e.IsActive,
+	}
+
+	current, ok := users[e.UserID]
+	if !ok {
+		return rosterStep{
+			change: domain.RosterChange{
+				Kind:     domain.RosterCreateUser,
+				TeamName: e.TeamName,
+				UserID:   e.UserID,
+				Detail:   fmt.Sprintf("username %s, active %t", e.Username, e.IsActive),
+			},
+			apply: func(ctx context.Context) error {
+				return roster.CreateUser(ctx, u)
+			},
+		}, true
+	}
+
+	var diffs []string
+	if current.Username != e.Username {
+		diffs = append(diffs, fmt.Sprintf("username %s -> %s", current.Username, e.Username))
+	}
+	if current.IsActive != e.IsActive {
+		diffs = append(diffs, fmt.Sprintf("active %t -> %t", current.IsActive, e.IsActive))
+	}
+	deactivates := current.IsActive && !e.IsActive
+	if deactivates && current.OpenReviews > 0 {
+		diffs = append(diffs, fmt.Sprintf("open reviews handed over: %d", current.OpenReviews))
+	}
+	if len(diffs) == 0 {
+		return rosterStep{}, false
+	}
+	return rosterStep{
+		change: domain.RosterChange{
+			Kind:   domain.RosterUpdateUser,
+			UserID: e.UserID,
+			Detail: strings.Join(diffs, "; "),
+		},
+		apply: func(ctx context.Context) error {
+			return roster.UpdateUser(ctx, u)
+		},
+		deactivates: deactivates,
+	}, true
+}
+
+// validateRoster reports every invalid row at once: missing values, unknown
+// roles, a team and user pair listed twice and a user listed with different
+// usernames or activity.
+func validateRoster(entries []domain.RosterEntry) error {
+	if len(entries) == 0 {
+		return domain.NewValidationError("empty roster", domain.FieldError{
+			Field:  "rows",
+			Reason: "must not be empty",
+		})
+	}
+
+	type membership struct{ team, user string }
+	seenRows := make(map[membership]int, len(entries))
+	firstRows := make(map[string]int, len(entries))
+
+	var details []domain.FieldError
+	for i, e := range entries {
+		field := func(name string) string {
+			return fmt.Sprintf("rows[%d].%s", i, name)
+		}
+		add := func(name, reason string) {
+			details = append(details, domain.FieldError{Field: field(name), Reason: reason})
+		}
+
+		if e.TeamName == "" {
+			add("team", "required")
+		}
+		if e.UserID == "" {
+			add("user_id", "required")
+		}
+		if e.Username == "" {
+			add("username", "required")
+		}
+		if e.Role != "" && !e.Role.IsValid() {
+			add("role", "must be one of member, maintainer, lead")
+		}
+		if e.TeamName == "" || e.UserID == "" {
+			continue
+		}
+
+		key := membership{team: e.TeamName, user: e.UserID}
+		if j, ok := seenRows[key]; ok {
+			add("user_id", fmt.Sprintf("already listed in team %s in rows[%d]", e.TeamName, j))
+			continue
+		}
+		seenRows[key] = i
+
+		j, ok := firstRows[e.UserID]
+		if !ok {
+			firstRows[e.UserID] = i
+			continue
+		}
+		if entries[j].Username != e.Username {
+			add("username", fmt.Sprintf("differs from rows[%d]", j))
+		}
+		if entries[j].IsActive != e.IsActive {
+			add("active", fmt.Sprintf("differs from rows[%d]", j))
+		}
+	}
+
+	if len(details) > 0 {
+		return domain.NewValidationError("invalid roster", details...)
+	}
+	return nil
+}
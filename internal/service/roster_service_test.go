@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service/mocks"
+)
+
+func TestRosterService_Import(t *testing.T) {
+	const csvRoster = `team,user_id,username,active,role
+backend,u1,Alice,true,lead
+backend,u2,Bobby,false,
+guild,u1,Alice,true,
+guild,u3,Carol,,maintainer
+`
+	const yamlRoster = `
+- team: backend
+  user_id: u1
+  username: Alice
+  role: lead
+- team: backend
+  user_id: u2
+  username: Bobby
+  active: false
+- team: guild
+  user_id: u1
+  username: Alice
+- team: guild
+  user_id: u3
+  username: Carol
+  role: maintainer
+`
+	// backend exists with u1 (member) and u2 (Bob, active); guild and u3
+	// are new.
+	newRepo := func() *mocks.MockRosterRepository {
+		return &mocks.MockRosterRepository{
+			TeamsResult: map[string]bool{"backend": false},
+			UsersResult: map[string]domain.User{
+				"u1": {ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true},
+				"u2": {ID: "u2", Username: "Bob", TeamName: "backend", IsActive: true, OpenReviews: 2},
+			},
+			MembershipsResult: map[string]map[string]domain.MemberRole{
+				"u1": {"backend": domain.RoleMember},
+				"u2": {"backend": domain.RoleMember},
+			},
+		}
+	}
+	wantChanges := []domain.RosterChange{
+		{Kind: domain.RosterCreateTeam, TeamName: "guild"},
+		{Kind: domain.RosterSetRole, TeamName: "backend", UserID: "u1", Detail: "member -> lead"},
+		{Kind: domain.RosterUpdateUser, UserID: "u2", Detail: "username Bob -> Bobby; active true -> false; open reviews handed over: 2"},
+		{Kind: domain.RosterAddMembership, TeamName: "guild", UserID: "u1", Detail: "role member"},
+		{Kind: domain.RosterCreateUser, TeamName: "guild", UserID: "u3", Detail: "username Carol, active true"},
+		{Kind: domain.RosterAddMembership, TeamName: "guild", UserID: "u3", Detail: "role maintainer"},
+	}
+
+	tests := []struct {
+		name   string
+		format domain.RosterFormat
+		data   string
+		dryRun bool
+	}{
+		{name: "импорт CSV", format: domain.RosterFormatCSV, data: csvRoster},
+		{name: "импорт YAML", format: domain.RosterFormatYAML, data: yamlRoster},
+		{name: "пробный импорт ничего не меняет", format: domain.RosterFormatCSV, data: csvRoster, dryRun: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepo()
+			prs := &mocks.MockOutOfOfficePRRepository{}
+			tx := &mocks.MockTxManager{}
+			svc := NewRosterService(repo, prs, tx)
+
+			result, err := svc.Import(context.Background(), tt.format, []byte(tt.data), tt.dryRun)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.DryRun != tt.dryRun {
+				t.Errorf("expected DryRun %v, got %v", tt.dryRun, result.DryRun)
+			}
+			if !slices.Equal(result.Changes, wantChanges) {
+				t.Errorf("unexpected changes:\n got %+v\nwant %+v", result.Changes, wantChanges)
+			}
+			if tx.Calls != 1 {
+				t.Errorf("expected one transaction, got %d", tx.Calls)
+			}
+
+			if tt.dryRun {
+				if len(repo.CreatedTeams)+len(repo.CreatedUsers)+len(repo.UpdatedUsers)+len(repo.SetMemberships) != 0 {
+					t.Errorf("expected no writes on a dry run, got %+v", repo)
+				}
+				if len(prs.HandedOver) != 0 {
+					t.Errorf("expected no hand-over on a dry run, got %v", prs.HandedOver)
+				}
+				return
+			}
+			if len(prs.HandedOver) != 1 || !slices.Equal(prs.HandedOver[0], []string{"u2"}) {
+				t.Errorf("expected the reviews of deactivated u2 handed over, got %v", prs.HandedOver)
+			}
+			if !slices.Equal(repo.CreatedTeams, []string{"guild"}) {
+				t.Errorf("expected guild created, got %v", repo.CreatedTeams)
+			}
+			if len(repo.CreatedUsers) != 1 || repo.CreatedUsers[0].TeamName != "guild" {
+				t.Errorf("expected u3 created in guild, got %+v", repo.CreatedUsers)
+			}
+			if len(repo.UpdatedUsers) != 1 || repo.UpdatedUsers[0].Username != "Bobby" || repo.UpdatedUsers[0].IsActive {
+				t.Errorf("expected u2 renamed and deactivated, got %+v", repo.UpdatedUsers)
+			}
+			if len(repo.SetMemberships) != 3 || repo.SetMemberships[0].Role != domain.RoleLead {
+				t.Errorf("expected three membership writes starting with u1 lead, got %+v", repo.SetMemberships)
+			}
+		})
+	}
+}
+
+func TestRosterService_ImportNoChanges(t *testing.T) {
+	repo := &mocks.MockRosterRepository{
+		TeamsResult: map[string]bool{"backend": false},
+		UsersResult: map[string]domain.User{"u1": {ID: "u1", Username: "Alice", TeamName: "backend", IsActive: true}},
+		MembershipsResult: map[string]map[string]domain.MemberRole{
+			"u1": {"backend": domain.RoleLead},
+		},
+	}
+	svc := NewRosterService(repo, &mocks.MockOutOfOfficePRRepository{}, &mocks.MockTxManager{})
+
+	// No role keeps the current one.
+	result, err := svc.Import(context.Background(), domain.RosterFormatCSV, []byte("team,user_id,username\nbackend,u1,Alice\n"), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Changes) != 0 || len(repo.SetMemberships) != 0 {
+		t.Errorf("expected no changes, got %+v", result.Changes)
+	}
+}
+
+func TestRosterService_ImportErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    domain.RosterFormat
+		data      string
+		archived  bool
+		createErr error
+		wantCode  domain.ErrorCode
+		wantField string
+	}{
+		{
+			name:      "неизвестный формат",
+			format:    "xml",
+			data:      "<roster/>",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "format",
+		},
+		{
+			name:     "битый YAML",
+			format:   domain.RosterFormatYAML,
+			data:     "- team: [backend",
+			wantCode: domain.ErrorCodeBadRequest,
+		},
+		{
+			name:     "неизвестное поле в YAML",
+			format:   domain.RosterFormatYAML,
+			data:     "- team: backend\n  user_id: u1\n  username: Alice\n  email: a@example.com\n",
+			wantCode: domain.ErrorCodeBadRequest,
+		},
+		{
+			name:      "пустой ростер",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows",
+		},
+		{
+			name:      "неизвестная колонка",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username,email\nbackend,u1,Alice,a@example.com\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "header",
+		},
+		{
+			name:      "нет имени пользователя",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username\nbackend,u1,\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows[0].username",
+		},
+		{
+			name:      "неверное значение active",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username,active\nbackend,u1,Alice,maybe\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows[0].active",
+		},
+		{
+			name:      "неизвестная роль",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username,role\nbackend,u1,Alice,owner\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows[0].role",
+		},
+		{
+			name:      "повтор членства",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username\nbackend,u1,Alice\nbackend,u1,Alice\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows[1].user_id",
+		},
+		{
+			name:      "разные имена одного пользователя",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username\nbackend,u1,Alice\nguild,u1,Alicia\n",
+			wantCode:  domain.ErrorCodeValidation,
+			wantField: "rows[1].username",
+		},
+		{
+			name:     "архивная команда",
+			format:   domain.RosterFormatCSV,
+			data:     "team,user_id,username\nbackend,u1,Alice\n",
+			archived: true,
+			wantCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:      "команду создали параллельно",
+			format:    domain.RosterFormatCSV,
+			data:      "team,user_id,username\nguild,u1,Alice\n",
+			createErr: domain.NewDomainError(domain.ErrorCodeTeamExists, "team already exists"),
+			wantCode:  domain.ErrorCodeTeamExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mocks.MockRosterRepository{
+				TeamsResult:   map[string]bool{"backend": tt.archived},
+				CreateTeamErr: tt.createErr,
+			}
+			tx := &mocks.MockTxManager{}
+			svc := NewRosterService(repo, &mocks.MockOutOfOfficePRRepository{}, tx)
+
+			_, err := svc.Import(context.Background(), tt.format, []byte(tt.data), false)
+			var de *domain.DomainError
+			if !errors.As(err, &de) {
+				t.Fatalf("expected domain error, got %v", err)
+			}
+			if de.Code != tt.wantCode {
+				t.Errorf("expected code %s, got %s (%v)", tt.wantCode, de.Code, err)
+			}
+			if tt.wantField != "" && !slices.ContainsFunc(de.Details, func(d domain.FieldError) bool {
+				return d.Field == tt.wantField
+			}) {
+				t.Errorf("expected details for field %q, got %+v", tt.wantField, de.Details)
+			}
+			if tt.createErr != nil && tx.FnErr == nil {
+				t.Errorf("expected the transaction to be rolled back")
+			}
+		})
+	}
+}
+
+func TestRosterService_Export(t *testing.T) {
+	repo := &mocks.MockRosterRepository{
+		ExportResult: []domain.RosterEntry{
+			{TeamName: "backend", UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleLead},
+			{TeamName: "guild", UserID: "u1", Username: "Alice", IsActive: true, Role: domain.RoleMember},
+			{TeamName: "backend", UserID: "u2", Username: "Bob, Jr.", IsActive: false, Role: domain.RoleMember},
+		},
+	}
+	svc := NewRosterService(repo, &mocks.MockOutOfOfficePRRepository{}, nil)
+
+	csvData, err := svc.Export(context.Background(), domain.RosterFormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantCSV := "team,user_id,username,active,role\n" +
+		"backend,u1,Alice,true,lead\n" +
+		"guild,u1,Alice,true,member\n" +
+		"backend,u2,\"Bob, Jr.\",false,member\n"
+	if string(csvData) != wantCSV {
+		t.Errorf("unexpected csv:\n%s", csvData)
+	}
+
+	yamlData, err := svc.Export(context.Background(), domain.RosterFormatYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(yamlData), "- team: backend\n  user_id: u1\n  username: Alice\n  active: true\n  role: lead\n") {
+		t.Errorf("unexpected yaml:\n%s", yamlData)
+	}
+
+	// Both formats read back to the same entries.
+	for _, data := range []struct {
+		format domain.RosterFormat
+		data   []byte
+	}{{domain.RosterFormatCSV, csvData}, {domain.RosterFormatYAML, yamlData}} {
+		entries, err := decodeRoster(data.format, data.data)
+		if err != nil {
+			t.Fatalf("decode %s: %v", data.format, err)
+		}
+		if !slices.Equal(entries, repo.ExportResult) {
+			t.Errorf("%s round trip: got %+v", data.format, entries)
+		}
+	}
+
+	if _, err := svc.Export(context.Background(), "xml"); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
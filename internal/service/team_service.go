@@ -2,64 +2,951 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
 )
 
 type TeamRepository interface {
-	Create(ctx context.Context, name string) error
-	Exists(ctx context.Context, name string) (bool, error)
+	Create(ctx context.Context, name, parentTeam string) error
 	GetWithMembers(ctx context.Context, name string) (*domain.Team, error)
+	List(ctx context.Context, filter domain.TeamFilter, after string, limit int) (domain.TeamPage, error)
+	GetSubtree(ctx context.Context, name string) (*domain.Team, error)
+	ListSubtreeMembers(ctx context.Context, name string) ([]domain.User, error)
+	SetParent(ctx context.Context, name, parentTeam string) error
+	SetDefaultMaxOpenReviews(ctx context.Context, name string, limit int) error
+	Archive(ctx context.Context, name string) (int64, error)
+	CountHistory(ctx context.Context, name string) (int, error)
+	Delete(ctx context.Context, name string) (int, error)
+	FindDeactivation(ctx context.Context, teamName string, id int64) (*domain.TeamDeactivation, error)
+	Reactivate(ctx context.Context, d *domain.TeamDeactivation) ([]string, error)
 }
 
 type TeamUserRepository interface {
+	GetByID(ctx context.Context, id string) (*domain.User, error)
+	CurrentTeams(ctx context.Context, userIDs []string) (map[string]string, error)
 	UpsertForTeam(ctx context.Context, teamName string, users []domain.User) error
+	SetTeam(ctx context.Context, id, teamName string) (*domain.User, error)
+	RemoveFromTeam(ctx context.Context, teamName string, userIDs []string) ([]string, error)
+	AddMemberships(ctx context.Context, teamName string, userIDs []string, role domain.MemberRole) error
+	SetRole(ctx context.Context, teamName, userID string, role domain.MemberRole) error
+}
+
+// TeamPRRepository is what membership changes need from pull requests: who
+// still reviews open PRs outside their team and how to hand those reviews
+// over.
+type TeamPRRepository interface {
+	OpenReviewCounts(ctx context.Context, userIDs []string) (map[string]int, error)
+	ReassignOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error)
+	DeactivateTeamAndReassignOpenPRs(ctx context.Context, teamName string) (domain.TeamDeactivationResult, error)
+	RefillOpenPRs(ctx context.Context, teamName string) ([]domain.ReviewerChange, error)
 }
 
 type TeamService struct {
 	teams TeamRepository
 	users TeamUserRepository
+	prs   TeamPRRepository
+	tx    TxManager
+	sync  ReviewerChangeScheduler
 }
 
-func NewTeamService(teams TeamRepository, users TeamUserRepository) *TeamService {
+func NewTeamService(teams TeamRepository, users TeamUserRepository, prs TeamPRRepository, tx TxManager) *TeamService {
+	if tx == nil {
+		tx = noTx{}
+	}
 	return &TeamService{
 		teams: teams,
 		users: users,
+		prs:   prs,
+		tx:    tx,
 	}
 }
 
-func (s *TeamService) CreateTeam(ctx context.Context, teamName string, members []domain.User) (*domain.Team, error) {
-	exists, err := s.teams.Exists(ctx, teamName)
+// SetReviewerSync enables pushing the reviewers of PRs changed by membership
+// changes to the Git hosting API.
+func (s *TeamService) SetReviewerSync(sync ReviewerChangeScheduler) {
+	s.sync = sync
+}
+
+// CreateTeam creates the team with its members under parentTeam ("" for a
+// root team). Members that belong to another team are rejected with
+// USER_IN_OTHER_TEAM unless move is set; moved members' open reviews on their
+// old team are reassigned.
+func (s *TeamService) CreateTeam(
+	ctx context.Context,
+	teamName string,
+	parentTeam string,
+	members []domain.User,
+	move bool,
+) (*domain.Team, error) {
+	if err := validateRoles(members); err != nil {
+		return nil, err
+	}
+	for i := range members {
+		members[i].TeamName = teamName
+	}
+
+	// The team and its members are created together: if the upsert fails
+	// the team is rolled back too.
+	var changes []domain.ReviewerChange
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if parentTeam != "" {
+			if _, err := s.activeTeam(ctx, parentTeam); err != nil {
+				return err
+			}
+		}
+
+		// The repository reports a duplicate name as TEAM_EXISTS.
+		if err := s.teams.Create(ctx, teamName, parentTeam); err != nil {
+			return fmt.Errorf("create team: %w", err)
+		}
+
+		var err error
+		changes, err = s.upsertMembers(ctx, teamName, members, move)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("check team exists: %w", err)
+		return nil, err
 	}
-	if exists {
-		return nil, domain.NewDomainError(domain.ErrorCodeTeamExists, "team already exists")
+	s.scheduleSync(ctx, changes)
+
+	return &domain.Team{
+		Name:       teamName,
+		Members:    members,
+		ParentTeam: parentTeam,
+	}, nil
+}
+
+// GetTeam returns the team with its members and its whole subtree of
+// subteams.
+func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+	team, err := s.teams.GetSubtree(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("get team: %w", err)
+	}
+	return team, nil
+}
+
+// ListTeams returns one page of the non-archived teams matching filter,
+// ordered by name. Paging works as in PRService.ListPullRequests.
+func (s *TeamService) ListTeams(
+	ctx context.Context,
+	filter domain.TeamFilter,
+	cursor string,
+	limit int,
+) (teams []domain.TeamSummary, nextCursor string, err error) {
+	if err := validateTeamFilter(filter); err != nil {
+		return nil, "", err
 	}
 
-	if err := s.teams.Create(ctx, teamName); err != nil {
-		return nil, fmt.Errorf("create team: %w", err)
+	limit, err = pageLimit(limit)
+	if err != nil {
+		return nil, "", err
 	}
 
+	after, err := decodeKeyCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, err := s.teams.List(ctx, filter, after, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("list teams: %w", err)
+	}
+	return page.Teams, encodeKeyCursor(page.Next), nil
+}
+
+// ListSubtreeMembers returns the members of the team and of every team below
+// it; each member carries its own team name.
+func (s *TeamService) ListSubtreeMembers(ctx context.Context, teamName string) ([]domain.User, error) {
+	if _, err := s.teams.GetWithMembers(ctx, teamName); err != nil {
+		return nil, fmt.Errorf("get team: %w", err)
+	}
+
+	members, err := s.teams.ListSubtreeMembers(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("list subtree members: %w", err)
+	}
+	return members, nil
+}
+
+// SetParentTeam moves the team under parentTeam, or makes it a root team when
+// parentTeam is empty. A parent inside the team's own subtree fails with
+// TEAM_CYCLE, an archived one with TEAM_ARCHIVED.
+func (s *TeamService) SetParentTeam(ctx context.Context, teamName, parentTeam string) (*domain.Team, error) {
+	if parentTeam == teamName {
+		return nil, domain.NewDomainError(domain.ErrorCodeTeamCycle, "team "+teamName+" cannot be its own parent")
+	}
+
+	var team *domain.Team
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if parentTeam != "" {
+			if _, err := s.activeTeam(ctx, parentTeam); err != nil {
+				return err
+			}
+		}
+
+		if err := s.teams.SetParent(ctx, teamName, parentTeam); err != nil {
+			return fmt.Errorf("set parent team: %w", err)
+		}
+
+		var err error
+		team, err = s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("get team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// SetMaxOpenReviews sets the default open review limit of members whose
+// primary team this is and who have no limit of their own; zero clears it.
+func (s *TeamService) SetMaxOpenReviews(ctx context.Context, teamName string, limit int) (*domain.Team, error) {
+	if limit < 0 {
+		return nil, domain.NewValidationError("invalid review limit", domain.FieldError{
+			Field:  "max_open_reviews",
+			Reason: "must not be negative",
+		})
+	}
+
+	var team *domain.Team
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		if err := s.teams.SetDefaultMaxOpenReviews(ctx, teamName, limit); err != nil {
+			return fmt.Errorf("set default max open reviews: %w", err)
+		}
+
+		var err error
+		team, err = s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("reload team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// AddMembers adds or updates members of an existing team. Members of another
+// team are handled as in CreateTeam.
+func (s *TeamService) AddMembers(ctx context.Context, teamName string, members []domain.User, move bool) (*domain.Team, error) {
+	if err := validateRoles(members); err != nil {
+		return nil, err
+	}
+
+	var (
+		team    *domain.Team
+		changes []domain.ReviewerChange
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		var err error
+		if changes, err = s.upsertMembers(ctx, teamName, members, move); err != nil {
+			return err
+		}
+
+		team, err = s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("reload team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, changes)
+	return team, nil
+}
+
+// AddMemberships makes existing users members of the team in addition to
+// their primary team, which stays unchanged. A non-empty role is given to
+// all of them; otherwise new members get RoleMember and users already in the
+// team keep their role.
+func (s *TeamService) AddMemberships(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	role domain.MemberRole,
+) (*domain.Team, error) {
+	if role != "" && !role.IsValid() {
+		return nil, invalidRoleError("role")
+	}
+
+	var team *domain.Team
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		// The repository reports an unknown user as NOT_FOUND.
+		if err := s.users.AddMemberships(ctx, teamName, userIDs, role); err != nil {
+			return fmt.Errorf("add team memberships: %w", err)
+		}
+
+		var err error
+		team, err = s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("reload team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// ProvisionMembers applies an identity provider's group change to the team
+// in one transaction. Users in add join it the way groups expect: users
+// without a primary team get this one, the others keep theirs and join in
+// addition, as in AddMemberships. Users in remove are taken off as in
+// RemoveMembers with reassignment; non-members are skipped. With create the
+// team is created first.
+func (s *TeamService) ProvisionMembers(
+	ctx context.Context,
+	teamName string,
+	add []string,
+	remove []string,
+	create bool,
+) (*domain.TeamMembershipResult, error) {
+	var result *domain.TeamMembershipResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if create {
+			if err := s.teams.Create(ctx, teamName, ""); err != nil {
+				return fmt.Errorf("create team: %w", err)
+			}
+		} else if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		if err := s.provisionMembers(ctx, teamName, add); err != nil {
+			return err
+		}
+
+		team, err := s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("get team: %w", err)
+		}
+		current := make(map[string]struct{}, len(team.Members))
+		for _, m := range team.Members {
+			current[m.ID] = struct{}{}
+		}
+		var members []string
+		for _, id := range remove {
+			if _, ok := current[id]; ok {
+				members = append(members, id)
+			}
+		}
+		result, err = s.removeMembers(ctx, team, members, domain.OpenReviewsReassign)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
+
+// SetMemberRole changes the role of a member of the team.
+func (s *TeamService) SetMemberRole(ctx context.Context, teamName, userID string, role domain.MemberRole) (*domain.Team, error) {
+	if !role.IsValid() {
+		return nil, invalidRoleError("role")
+	}
+
+	var team *domain.Team
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		if err := s.users.SetRole(ctx, teamName, userID, role); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.NewDomainError(domain.ErrorCodeNotFound,
+					fmt.Sprintf("user %s is not a member of team %s", userID, teamName))
+			}
+			return fmt.Errorf("set member role: %w", err)
+		}
+
+		var err error
+		team, err = s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("reload team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// RemoveMembers takes userIDs off the team. Members still assigned to open
+// PRs are reassigned when reassign is set; otherwise the call fails with
+// HAS_OPEN_REVIEWS and nothing changes.
+func (s *TeamService) RemoveMembers(
+	ctx context.Context,
+	teamName string,
+	userIDs []string,
+	reassign bool,
+) (*domain.TeamMembershipResult, error) {
+	var result *domain.TeamMembershipResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		team, err := s.teams.GetWithMembers(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("get team: %w", err)
+		}
+
+		result, err = s.removeMembers(ctx, team, userIDs, reassignPolicy(reassign))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
+
+// UpdateTeam replaces the team's membership with members: listed users are
+// added or updated as in AddMembers and everyone else is removed as in
+// RemoveMembers.
+func (s *TeamService) UpdateTeam(
+	ctx context.Context,
+	teamName string,
+	members []domain.User,
+	reassign bool,
+	move bool,
+) (*domain.TeamMembershipResult, error) {
+	if err := validateRoles(members); err != nil {
+		return nil, err
+	}
 	for i := range members {
 		members[i].TeamName = teamName
 	}
 
-	if err := s.users.UpsertForTeam(ctx, teamName, members); err != nil {
-		return nil, fmt.Errorf("upsert team members: %w", err)
+	var result *domain.TeamMembershipResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		team, err := s.activeTeam(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		keep := make(map[string]struct{}, len(members))
+		for _, m := range members {
+			keep[m.ID] = struct{}{}
+		}
+		var leaving []string
+		for _, m := range team.Members {
+			if _, ok := keep[m.ID]; !ok {
+				leaving = append(leaving, m.ID)
+			}
+		}
+
+		moved, err := s.upsertMembers(ctx, teamName, members, move)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.removeMembers(ctx, team, leaving, reassignPolicy(reassign))
+		if err != nil {
+			return err
+		}
+		// A PR may lose both a moved member and a removed one.
+		result.Changes = mergeChanges(moved, result.Changes)
+		result.UpdatedPullRequests = len(result.Changes)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
 
-	return &domain.Team{
-		Name:    teamName,
-		Members: members,
-	}, nil
+// MoveUser moves the user to teamName. policy decides what happens to the
+// user's open reviews on the old team; empty means OpenReviewsReject.
+// Moving a user to the team they are already in changes nothing.
+func (s *TeamService) MoveUser(
+	ctx context.Context,
+	userID string,
+	teamName string,
+	policy domain.OpenReviewsPolicy,
+) (*domain.UserMoveResult, error) {
+	if policy == "" {
+		policy = domain.OpenReviewsReject
+	}
+	if !validPolicy(policy) {
+		return nil, domain.NewValidationError("unknown open_reviews policy", domain.FieldError{
+			Field:  "open_reviews",
+			Reason: "must be one of reject, reassign, keep",
+		})
+	}
+
+	var result *domain.UserMoveResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		user, err := s.users.GetByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+		result = &domain.UserMoveResult{User: *user, FromTeam: user.TeamName}
+		if user.TeamName == teamName {
+			return nil
+		}
+
+		moved, err := s.users.SetTeam(ctx, userID, teamName)
+		if err != nil {
+			return fmt.Errorf("set user team: %w", err)
+		}
+		result.User = *moved
+
+		result.Changes, err = s.settleOpenReviews(ctx, []string{userID}, policy)
+		result.UpdatedPullRequests = len(result.Changes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
 }
 
-func (s *TeamService) GetTeam(ctx context.Context, teamName string) (*domain.Team, error) {
+// ArchiveTeam retires the team: members are deactivated and their open
+// reviews reassigned as in DeactivateTeamAndReassignOpenPRs, and the team
+// can no longer gain members. Its history is kept.
+func (s *TeamService) ArchiveTeam(ctx context.Context, teamName string) (*domain.TeamArchiveResult, error) {
+	var result *domain.TeamArchiveResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		archivedAt, err := s.teams.Archive(ctx, teamName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				// Archived by a concurrent request.
+				return teamArchivedError(teamName)
+			}
+			return fmt.Errorf("archive team: %w", err)
+		}
+
+		deactivation, err := s.prs.DeactivateTeamAndReassignOpenPRs(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("deactivate team: %w", err)
+		}
+
+		result = &domain.TeamArchiveResult{
+			TeamDeactivationResult: deactivation,
+			ArchivedAt:             archivedAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
+
+// DisbandTeam removes every member from the team, reassigning their open
+// reviews in it, and archives the now empty team. Unlike ArchiveTeam nobody
+// is deactivated: the members keep their other teams.
+func (s *TeamService) DisbandTeam(ctx context.Context, teamName string) (*domain.TeamMembershipResult, error) {
+	var result *domain.TeamMembershipResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		team, err := s.activeTeam(ctx, teamName)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]string, 0, len(team.Members))
+		for _, m := range team.Members {
+			ids = append(ids, m.ID)
+		}
+		result, err = s.removeMembers(ctx, team, ids, domain.OpenReviewsReassign)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.teams.Archive(ctx, teamName); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return teamArchivedError(teamName)
+			}
+			return fmt.Errorf("archive team: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
+
+// ReactivateTeam reverts a /team/deactivate run: only the users that run
+// deactivated are activated again, so users that were inactive before stay
+// inactive. A zero deactivationID picks the team's latest run. With refill,
+// open PRs of the team's authors that lack reviewers are topped up.
+func (s *TeamService) ReactivateTeam(
+	ctx context.Context,
+	teamName string,
+	deactivationID int64,
+	refill bool,
+) (*domain.TeamReactivationResult, error) {
+	if deactivationID < 0 {
+		return nil, domain.NewValidationError("deactivation_id must be positive", domain.FieldError{
+			Field:  "deactivation_id",
+			Reason: "must be positive",
+		})
+	}
+
+	var result *domain.TeamReactivationResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.activeTeam(ctx, teamName); err != nil {
+			return err
+		}
+
+		run, err := s.teams.FindDeactivation(ctx, teamName, deactivationID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.NewDomainError(domain.ErrorCodeNotFound, "deactivation not found")
+			}
+			return fmt.Errorf("find deactivation: %w", err)
+		}
+		if run.IsReactivated() {
+			return domain.NewDomainError(domain.ErrorCodeAlreadyReactivated,
+				fmt.Sprintf("deactivation %d of team %s is already reverted", run.ID, teamName))
+		}
+
+		reactivated, err := s.teams.Reactivate(ctx, run)
+		if err != nil {
+			return fmt.Errorf("reactivate team: %w", err)
+		}
+		result = &domain.TeamReactivationResult{
+			TeamName:         teamName,
+			DeactivationID:   run.ID,
+			ReactivatedUsers: reactivated,
+		}
+
+		if refill {
+			result.Changes, err = s.prs.RefillOpenPRs(ctx, teamName)
+			if err != nil {
+				return fmt.Errorf("refill open pull requests: %w", err)
+			}
+			result.UpdatedPullRequests = len(result.Changes)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.scheduleSync(ctx, result.Changes)
+	return result, nil
+}
+
+// DeleteTeam removes the team and its members for good. Only teams whose
+// members never authored or reviewed a PR can be deleted; others fail with
+// TEAM_HAS_HISTORY and should be archived instead.
+func (s *TeamService) DeleteTeam(ctx context.Context, teamName string) (*domain.TeamDeletionResult, error) {
+	var result *domain.TeamDeletionResult
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.teams.GetWithMembers(ctx, teamName); err != nil {
+			return fmt.Errorf("get team: %w", err)
+		}
+
+		history, err := s.teams.CountHistory(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("count team history: %w", err)
+		}
+		if history > 0 {
+			return domain.NewDomainError(domain.ErrorCodeTeamHasHistory, fmt.Sprintf(
+				"team %s members authored or review %d pull requests; archive the team instead", teamName, history))
+		}
+
+		deleted, err := s.teams.Delete(ctx, teamName)
+		if err != nil {
+			return fmt.Errorf("delete team: %w", err)
+		}
+		result = &domain.TeamDeletionResult{TeamName: teamName, DeletedUsers: deleted}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scheduleSync pushes the reviewer changes of a committed transaction.
+func (s *TeamService) scheduleSync(ctx context.Context, changes []domain.ReviewerChange) {
+	if s.sync == nil || len(changes) == 0 {
+		return
+	}
+	s.sync.ScheduleChanges(ctx, changes)
+}
+
+// activeTeam loads the team and fails with TEAM_ARCHIVED if it is archived.
+func (s *TeamService) activeTeam(ctx context.Context, teamName string) (*domain.Team, error) {
 	team, err := s.teams.GetWithMembers(ctx, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("get team: %w", err)
 	}
+	if team.IsArchived() {
+		return nil, teamArchivedError(teamName)
+	}
 	return team, nil
 }
+
+// upsertMembers must run inside a transaction. Members that belong to
+// another team are moved only when move is set, and their open reviews on
+// the old team are reassigned; it returns the PRs changed.
+func (s *TeamService) upsertMembers(ctx context.Context, teamName string, members []domain.User, move bool) ([]domain.ReviewerChange, error) {
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.ID)
+	}
+	current, err := s.users.CurrentTeams(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get current teams: %w", err)
+	}
+
+	others := make(map[string]string)
+	for id, team := range current {
+		if team != teamName {
+			others[id] = team
+		}
+	}
+	if len(others) > 0 && !move {
+		return nil, userInOtherTeamError(others)
+	}
+
+	if err := s.users.UpsertForTeam(ctx, teamName, members); err != nil {
+		return nil, fmt.Errorf("upsert team members: %w", err)
+	}
+
+	if len(others) == 0 {
+		return nil, nil
+	}
+	return s.settleOpenReviews(ctx, sortedKeys(others), domain.OpenReviewsReassign)
+}
+
+// provisionMembers must run inside a transaction.
+func (s *TeamService) provisionMembers(ctx context.Context, teamName string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	current, err := s.users.CurrentTeams(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("get current teams: %w", err)
+	}
+
+	var others []string
+	for _, id := range userIDs {
+		if _, ok := current[id]; ok {
+			others = append(others, id)
+			continue
+		}
+		if _, err := s.users.SetTeam(ctx, id, teamName); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return domain.NewDomainError(domain.ErrorCodeNotFound, "user "+id+" not found")
+			}
+			return fmt.Errorf("set primary team: %w", err)
+		}
+	}
+	if len(others) > 0 {
+		if err := s.users.AddMemberships(ctx, teamName, others, ""); err != nil {
+			return fmt.Errorf("add team memberships: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeMembers must run inside a transaction; team is the membership
+// before the change.
+func (s *TeamService) removeMembers(
+	ctx context.Context,
+	team *domain.Team,
+	userIDs []string,
+	policy domain.OpenReviewsPolicy,
+) (*domain.TeamMembershipResult, error) {
+	result := &domain.TeamMembershipResult{RemovedMembers: make([]string, 0, len(userIDs))}
+
+	if len(userIDs) > 0 {
+		members := make(map[string]struct{}, len(team.Members))
+		for _, m := range team.Members {
+			members[m.ID] = struct{}{}
+		}
+		for _, id := range userIDs {
+			if _, ok := members[id]; !ok {
+				return nil, domain.NewDomainError(domain.ErrorCodeNotFound,
+					fmt.Sprintf("user %s is not a member of team %s", id, team.Name))
+			}
+		}
+
+		removed, err := s.users.RemoveFromTeam(ctx, team.Name, userIDs)
+		if err != nil {
+			return nil, fmt.Errorf("remove team members: %w", err)
+		}
+		result.RemovedMembers = append(result.RemovedMembers, removed...)
+
+		result.Changes, err = s.settleOpenReviews(ctx, removed, policy)
+		if err != nil {
+			return nil, err
+		}
+		result.UpdatedPullRequests = len(result.Changes)
+	}
+
+	updated, err := s.teams.GetWithMembers(ctx, team.Name)
+	if err != nil {
+		return nil, fmt.Errorf("reload team: %w", err)
+	}
+	result.Team = *updated
+	return result, nil
+}
+
+// settleOpenReviews applies policy to the open reviews userIDs hold outside
+// their (new) team. It must run after the users left their old team and
+// inside the same transaction, so a rejection rolls the change back. It
+// returns the PRs changed.
+func (s *TeamService) settleOpenReviews(
+	ctx context.Context,
+	userIDs []string,
+	policy domain.OpenReviewsPolicy,
+) ([]domain.ReviewerChange, error) {
+	switch policy {
+	case domain.OpenReviewsReassign:
+		changes, err := s.prs.ReassignOpenReviews(ctx, userIDs)
+		if err != nil {
+			return nil, fmt.Errorf("reassign open reviews: %w", err)
+		}
+		return changes, nil
+	case domain.OpenReviewsReject:
+		counts, err := s.prs.OpenReviewCounts(ctx, userIDs)
+		if err != nil {
+			return nil, fmt.Errorf("count open reviews: %w", err)
+		}
+		if len(counts) > 0 {
+			return nil, hasOpenReviewsError(counts)
+		}
+	}
+	return nil, nil
+}
+
+func validateTeamFilter(f domain.TeamFilter) error {
+	if f.MinActiveMembers < 0 {
+		return domain.NewValidationError("invalid team filter", domain.FieldError{
+			Field:  "min_active_members",
+			Reason: "must not be negative",
+		})
+	}
+	if f.MaxActiveMembers == nil {
+		return nil
+	}
+	if *f.MaxActiveMembers < f.MinActiveMembers {
+		return domain.NewValidationError("invalid team filter", domain.FieldError{
+			Field:  "max_active_members",
+			Reason: "must not be less than min_active_members",
+		})
+	}
+	return nil
+}
+
+// validateRoles checks the optional roles of members; an empty role keeps
+// the current one.
+func validateRoles(members []domain.User) error {
+	for i, m := range members {
+		if m.Role != "" && !m.Role.IsValid() {
+			return invalidRoleError(fmt.Sprintf("members[%d].role", i))
+		}
+	}
+	return nil
+}
+
+func invalidRoleError(field string) *domain.DomainError {
+	return domain.NewValidationError("unknown member role", domain.FieldError{
+		Field:  field,
+		Reason: "must be one of member, maintainer, lead",
+	})
+}
+
+func teamArchivedError(teamName string) *domain.DomainError {
+	return domain.NewDomainError(domain.ErrorCodeTeamArchived, "team "+teamName+" is archived")
+}
+
+func reassignPolicy(reassign bool) domain.OpenReviewsPolicy {
+	if reassign {
+		return domain.OpenReviewsReassign
+	}
+	return domain.OpenReviewsReject
+}
+
+func validPolicy(p domain.OpenReviewsPolicy) bool {
+	switch p {
+	case domain.OpenReviewsReject, domain.OpenReviewsReassign, domain.OpenReviewsKeep:
+		return true
+	}
+	return false
+}
+
+func hasOpenReviewsError(counts map[string]int) *domain.DomainError {
+	parts := make([]string, 0, len(counts))
+	for _, id := range sortedKeys(counts) {
+		parts = append(parts, fmt.Sprintf("%s (%d)", id, counts[id]))
+	}
+	return domain.NewDomainError(domain.ErrorCodeHasOpenReviews,
+		"members still review open pull requests: "+strings.Join(parts, ", "))
+}
+
+func userInOtherTeamError(teams map[string]string) *domain.DomainError {
+	parts := make([]string, 0, len(teams))
+	for _, id := range sortedKeys(teams) {
+		parts = append(parts, fmt.Sprintf("%s (%s)", id, teams[id]))
+	}
+	return domain.NewDomainError(domain.ErrorCodeUserInOtherTeam,
+		"users belong to another team: "+strings.Join(parts, ", ")+"; pass move=true to move them")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeChanges combines change sets into one change per PR, in order of
+// first appearance, with the reviewers removed by all of them.
+func mergeChanges(sets ...[]domain.ReviewerChange) []domain.ReviewerChange {
+	var merged []domain.ReviewerChange
+	index := make(map[string]int)
+	for _, changes := range sets {
+		for _, c := range changes {
+			i, ok := index[c.PullRequestID]
+			if !ok {
+				index[c.PullRequestID] = len(merged)
+				merged = append(merged, domain.ReviewerChange{PullRequestID: c.PullRequestID})
+				i = len(merged) - 1
+			}
+			merged[i].Removed = append(merged[i].Removed, c.Removed...)
+		}
+	}
+	return merged
+}
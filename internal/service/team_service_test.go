@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
@@ -14,10 +16,9 @@ func TestTeamService_CreateTeam(t *testing.T) {
 		name           string
 		teamName       string
 		members        []domain.User
-		mockExists     bool
-		mockExistsErr  error
 		mockCreateErr  error
 		mockUpsertErr  error
+		mockTxErr      error
 		wantErr        bool
 		wantErrCode    domain.ErrorCode
 		validateResult func(t *testing.T, team *domain.Team)
@@ -29,8 +30,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 				{ID: "user-1", Username: "user1", IsActive: true},
 				{ID: "user-2", Username: "user2", IsActive: true},
 			},
-			mockExists: false,
-			wantErr:    false,
+			wantErr: false,
 			validateResult: func(t *testing.T, team *domain.Team) {
 				if team.Name != "team-1" {
 					t.Errorf("expected team name team-1, got %s", team.Name)
@@ -51,16 +51,9 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			members: []domain.User{
 				{ID: "user-1", Username: "user1", IsActive: true},
 			},
-			mockExists:  true,
-			wantErr:     true,
-			wantErrCode: domain.ErrorCodeTeamExists,
-		},
-		{
-			name:          "ошибка при проверке существования команды",
-			teamName:      "team-1",
-			members:       []domain.User{},
-			mockExistsErr: errors.New("database error"),
+			mockCreateErr: domain.NewDomainError(domain.ErrorCodeTeamExists, "team already exists"),
 			wantErr:       true,
+			wantErrCode:   domain.ErrorCodeTeamExists,
 		},
 		{
 			name:     "ошибка при создании команды",
@@ -68,7 +61,6 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			members: []domain.User{
 				{ID: "user-1", Username: "user1", IsActive: true},
 			},
-			mockExists:    false,
 			mockCreateErr: errors.New("create error"),
 			wantErr:       true,
 		},
@@ -78,16 +70,23 @@ func TestTeamService_CreateTeam(t *testing.T) {
 			members: []domain.User{
 				{ID: "user-1", Username: "user1", IsActive: true},
 			},
-			mockExists:    false,
 			mockUpsertErr: errors.New("upsert error"),
 			wantErr:       true,
 		},
 		{
-			name:       "создание команды без членов",
-			teamName:   "team-1",
-			members:    []domain.User{},
-			mockExists: false,
-			wantErr:    false,
+			name:     "ошибка начала транзакции",
+			teamName: "team-1",
+			members: []domain.User{
+				{ID: "user-1", Username: "user1", IsActive: true},
+			},
+			mockTxErr: errors.New("begin tx"),
+			wantErr:   true,
+		},
+		{
+			name:     "создание команды без членов",
+			teamName: "team-1",
+			members:  []domain.User{},
+			wantErr:  false,
 			validateResult: func(t *testing.T, team *domain.Team) {
 				if team.Name != "team-1" {
 					t.Errorf("expected team name team-1, got %s", team.Name)
@@ -107,8 +106,7 @@ func TestTeamService_CreateTeam(t *testing.T) {
 				{ID: "user-4", Username: "user4", IsActive: true},
 				{ID: "user-5", Username: "user5", IsActive: true},
 			},
-			mockExists: false,
-			wantErr:    false,
+			wantErr: false,
 			validateResult: func(t *testing.T, team *domain.Team) {
 				if len(team.Members) != 5 {
 					t.Errorf("expected 5 members, got %d", len(team.Members))
@@ -120,18 +118,24 @@ func TestTeamService_CreateTeam(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			mockTeamRepo := &mocks.MockTeamRepository{
-				ExistsResult: tt.mockExists,
-				ExistsErr:    tt.mockExistsErr,
-				CreateErr:    tt.mockCreateErr,
+				CreateErr: tt.mockCreateErr,
 			}
 			mockUserRepo := &mocks.MockTeamUserRepository{
 				UpsertErr: tt.mockUpsertErr,
 			}
+			txManager := &mocks.MockTxManager{Err: tt.mockTxErr}
 
-			service := NewTeamService(mockTeamRepo, mockUserRepo)
+			service := NewTeamService(mockTeamRepo, mockUserRepo, &mocks.MockTeamPRRepository{}, txManager)
 			ctx := context.Background()
 
-			result, err := service.CreateTeam(ctx, tt.teamName, tt.members)
+			result, err := service.CreateTeam(ctx, tt.teamName, "", tt.members, false)
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+			if tt.wantErr && tt.mockTxErr == nil && txManager.FnErr == nil {
+				t.Errorf("expected transaction to be rolled back")
+			}
 
 			if tt.wantErr {
 				if err == nil {
@@ -256,7 +260,7 @@ func TestTeamService_GetTeam(t *testing.T) {
 			}
 			mockUserRepo := &mocks.MockTeamUserRepository{}
 
-			service := NewTeamService(mockTeamRepo, mockUserRepo)
+			service := NewTeamService(mockTeamRepo, mockUserRepo, &mocks.MockTeamPRRepository{}, nil)
 			ctx := context.Background()
 
 			result, err := service.GetTeam(ctx, tt.teamName)
@@ -277,3 +281,1309 @@ func TestTeamService_GetTeam(t *testing.T) {
 		})
 	}
 }
+
+func TestTeamService_ListTeams(t *testing.T) {
+	intPtr := func(n int) *int { return &n }
+
+	tests := []struct {
+		name        string
+		filter      domain.TeamFilter
+		cursor      string
+		limit       int
+		next        string
+		wantErrCode domain.ErrorCode
+		wantField   string
+		wantAfter   string
+		wantLimit   int
+		wantNext    bool
+	}{
+		{
+			name:      "первая страница",
+			filter:    domain.TeamFilter{NamePrefix: "back", MinActiveMembers: 1, MaxActiveMembers: intPtr(3)},
+			next:      "backend",
+			wantLimit: DefaultPageLimit,
+			wantNext:  true,
+		},
+		{
+			name:      "следующая страница",
+			filter:    domain.TeamFilter{MaxActiveMembers: intPtr(0)},
+			cursor:    encodeKeyCursor("backend"),
+			limit:     10,
+			wantAfter: "backend",
+			wantLimit: 10,
+		},
+		{
+			name:        "отрицательный минимум",
+			filter:      domain.TeamFilter{MinActiveMembers: -1},
+			wantErrCode: domain.ErrorCodeValidation,
+			wantField:   "min_active_members",
+		},
+		{
+			name:        "максимум меньше минимума",
+			filter:      domain.TeamFilter{MinActiveMembers: 2, MaxActiveMembers: intPtr(1)},
+			wantErrCode: domain.ErrorCodeValidation,
+			wantField:   "max_active_members",
+		},
+		{
+			name:        "битый курсор",
+			cursor:      "%%%",
+			wantErrCode: domain.ErrorCodeValidation,
+			wantField:   "cursor",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				ListResult: domain.TeamPage{
+					Teams: []domain.TeamSummary{{Name: "backend", MemberCount: 2, ActiveMemberCount: 1}},
+					Next:  tt.next,
+				},
+			}
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+
+			teams, next, err := service.ListTeams(context.Background(), tt.filter, tt.cursor, tt.limit)
+
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				if len(domainErr.Details) != 1 || domainErr.Details[0].Field != tt.wantField {
+					t.Errorf("expected %s field error, got %+v", tt.wantField, domainErr.Details)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(teams) != 1 || teams[0].Name != "backend" {
+				t.Errorf("expected backend listed, got %+v", teams)
+			}
+			if teamRepo.ListFilter.NamePrefix != tt.filter.NamePrefix || teamRepo.ListFilter.MaxActiveMembers != tt.filter.MaxActiveMembers {
+				t.Errorf("expected filter %+v, got %+v", tt.filter, teamRepo.ListFilter)
+			}
+			if teamRepo.ListAfter != tt.wantAfter {
+				t.Errorf("expected to start after %q, got %q", tt.wantAfter, teamRepo.ListAfter)
+			}
+			if teamRepo.ListLimit != tt.wantLimit {
+				t.Errorf("expected limit %d, got %d", tt.wantLimit, teamRepo.ListLimit)
+			}
+			if tt.wantNext != (next != "") {
+				t.Errorf("expected next cursor %v, got %q", tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestTeamService_AddMembers(t *testing.T) {
+	team := &domain.Team{
+		Name: "team-1",
+		Members: []domain.User{
+			{ID: "user-1", Username: "user1", TeamName: "team-1", IsActive: true},
+			{ID: "user-2", Username: "user2", TeamName: "team-1", IsActive: true},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		mockTeam         *domain.Team
+		mockGetErr       error
+		mockCurrentTeams map[string]string
+		mockUpsertErr    error
+		move             bool
+		wantErr          bool
+		wantErrCode      domain.ErrorCode
+		wantUpsert       bool
+		wantReassigned   []string
+	}{
+		{
+			name:       "успешное добавление",
+			mockTeam:   team,
+			wantUpsert: true,
+		},
+		{
+			name:             "участник уже в этой команде",
+			mockTeam:         team,
+			mockCurrentTeams: map[string]string{"user-2": "team-1"},
+			wantUpsert:       true,
+		},
+		{
+			name:             "участник другой команды без move",
+			mockTeam:         team,
+			mockCurrentTeams: map[string]string{"user-2": "team-2"},
+			wantErr:          true,
+			wantErrCode:      domain.ErrorCodeUserInOtherTeam,
+		},
+		{
+			name:        "архивная команда",
+			mockTeam:    &domain.Team{Name: "team-1", ArchivedAt: 1_700_000_000},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:             "перевод участника другой команды",
+			mockTeam:         team,
+			mockCurrentTeams: map[string]string{"user-2": "team-2"},
+			move:             true,
+			wantUpsert:       true,
+			wantReassigned:   []string{"user-2"},
+		},
+		{
+			name:       "команда не найдена",
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    true,
+		},
+		{
+			name:          "ошибка при добавлении",
+			mockTeam:      team,
+			mockUpsertErr: errors.New("upsert error"),
+			wantErr:       true,
+			wantUpsert:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: tt.mockTeam,
+				GetWithMembersErr:    tt.mockGetErr,
+			}
+			userRepo := &mocks.MockTeamUserRepository{
+				CurrentTeamsResult: tt.mockCurrentTeams,
+				UpsertErr:          tt.mockUpsertErr,
+			}
+			prRepo := &mocks.MockTeamPRRepository{}
+			txManager := &mocks.MockTxManager{}
+
+			service := NewTeamService(teamRepo, userRepo, prRepo, txManager)
+			result, err := service.AddMembers(context.Background(), "team-1", []domain.User{
+				{ID: "user-2", Username: "user2", IsActive: true},
+			}, tt.move)
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+			if got := userRepo.UpsertedUsers != nil; got != tt.wantUpsert {
+				t.Errorf("expected upsert %v, got %v", tt.wantUpsert, got)
+			}
+			if !slices.Equal(prRepo.ReassignedIDs, tt.wantReassigned) {
+				t.Errorf("expected reassigned %v, got %v", tt.wantReassigned, prRepo.ReassignedIDs)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if userRepo.UpsertedTeam != "team-1" {
+				t.Errorf("expected upsert into team-1, got %s", userRepo.UpsertedTeam)
+			}
+			if len(result.Members) != 2 {
+				t.Errorf("expected 2 members, got %d", len(result.Members))
+			}
+		})
+	}
+}
+
+func TestTeamService_AddMemberships(t *testing.T) {
+	team := &domain.Team{
+		Name: "guild",
+		Members: []domain.User{
+			{ID: "user-1", Username: "user1", TeamName: "team-1", IsActive: true},
+			{ID: "user-7", Username: "user7", TeamName: "team-2", IsActive: true},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		mockTeam    *domain.Team
+		mockGetErr  error
+		mockAddErr  error
+		wantErrCode domain.ErrorCode
+		wantErr     bool
+		wantAdded   bool
+	}{
+		{
+			name:      "успешное добавление в дополнительную команду",
+			mockTeam:  team,
+			wantAdded: true,
+		},
+		{
+			name:        "архивная команда",
+			mockTeam:    &domain.Team{Name: "guild", ArchivedAt: 1_700_000_000},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:       "команда не найдена",
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    true,
+		},
+		{
+			name:        "пользователь не найден",
+			mockTeam:    team,
+			mockAddErr:  domain.NewDomainError(domain.ErrorCodeNotFound, "user not found"),
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeNotFound,
+			wantAdded:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: tt.mockTeam,
+				GetWithMembersErr:    tt.mockGetErr,
+			}
+			userRepo := &mocks.MockTeamUserRepository{AddMembershipsErr: tt.mockAddErr}
+			txManager := &mocks.MockTxManager{}
+
+			service := NewTeamService(teamRepo, userRepo, &mocks.MockTeamPRRepository{}, txManager)
+			result, err := service.AddMemberships(context.Background(), "guild", []string{"user-1", "user-7"}, domain.RoleMaintainer)
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+			if got := userRepo.MembershipIDs != nil; got != tt.wantAdded {
+				t.Errorf("expected memberships added %v, got %v", tt.wantAdded, got)
+			}
+			if userRepo.UpsertedUsers != nil {
+				t.Errorf("primary team must not change, got upsert of %v", userRepo.UpsertedUsers)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userRepo.MembershipTeam != "guild" {
+				t.Errorf("expected memberships in guild, got %s", userRepo.MembershipTeam)
+			}
+			if userRepo.MembershipRole != domain.RoleMaintainer {
+				t.Errorf("expected maintainer role, got %s", userRepo.MembershipRole)
+			}
+			if len(result.Members) != 2 {
+				t.Errorf("expected 2 members, got %d", len(result.Members))
+			}
+		})
+	}
+}
+
+func TestTeamService_ProvisionMembers(t *testing.T) {
+	team := &domain.Team{
+		Name: "guild",
+		Members: []domain.User{
+			{ID: "user-1", Username: "user1", TeamName: "guild", IsActive: true},
+			{ID: "user-2", Username: "user2", TeamName: "team-1", IsActive: true},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		mockTeam    *domain.Team
+		mockSetErr  error
+		create      bool
+		add         []string
+		remove      []string
+		wantErrCode domain.ErrorCode
+		wantSetTeam int
+		wantMembers []string
+		wantRemoved []string
+	}{
+		{
+			name:        "новый без команды получает основную, остальные членство",
+			mockTeam:    team,
+			add:         []string{"user-3", "user-7"},
+			wantSetTeam: 1,
+			wantMembers: []string{"user-7"},
+		},
+		{
+			name:        "удаление пропускает не участников",
+			mockTeam:    team,
+			remove:      []string{"user-2", "user-9"},
+			wantRemoved: []string{"user-2"},
+		},
+		{
+			name:        "создание команды",
+			mockTeam:    &domain.Team{Name: "guild"},
+			create:      true,
+			add:         []string{"user-3"},
+			wantSetTeam: 1,
+		},
+		{
+			name:        "неизвестный пользователь",
+			mockTeam:    team,
+			mockSetErr:  sql.ErrNoRows,
+			add:         []string{"user-3"},
+			wantErrCode: domain.ErrorCodeNotFound,
+			wantSetTeam: 1,
+		},
+		{
+			name:        "архивная команда",
+			mockTeam:    &domain.Team{Name: "guild", ArchivedAt: 1_700_000_000},
+			add:         []string{"user-3"},
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{GetWithMembersResult: tt.mockTeam}
+			userRepo := &mocks.MockTeamUserRepository{
+				CurrentTeamsResult: map[string]string{"user-7": "team-2"},
+				SetTeamErr:         tt.mockSetErr,
+			}
+			prRepo := &mocks.MockTeamPRRepository{}
+			txManager := &mocks.MockTxManager{}
+
+			service := NewTeamService(teamRepo, userRepo, prRepo, txManager)
+			_, err := service.ProvisionMembers(context.Background(), "guild", tt.add, tt.remove, tt.create)
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userRepo.SetTeamCalls != tt.wantSetTeam {
+				t.Errorf("expected %d primary team changes, got %d", tt.wantSetTeam, userRepo.SetTeamCalls)
+			}
+			if !slices.Equal(userRepo.MembershipIDs, tt.wantMembers) {
+				t.Errorf("expected memberships %v, got %v", tt.wantMembers, userRepo.MembershipIDs)
+			}
+			if !slices.Equal(prRepo.ReassignedIDs, tt.wantRemoved) {
+				t.Errorf("expected reviews of %v reassigned, got %v", tt.wantRemoved, prRepo.ReassignedIDs)
+			}
+		})
+	}
+}
+
+func TestTeamService_SetMemberRole(t *testing.T) {
+	team := &domain.Team{
+		Name: "backend",
+		Members: []domain.User{
+			{ID: "user-1", Username: "user1", TeamName: "backend", IsActive: true, Role: domain.RoleLead},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		role        domain.MemberRole
+		mockTeam    *domain.Team
+		mockSetErr  error
+		wantErrCode domain.ErrorCode
+		wantChanged bool
+	}{
+		{
+			name:        "назначение лида",
+			role:        domain.RoleLead,
+			mockTeam:    team,
+			wantChanged: true,
+		},
+		{
+			name:        "неизвестная роль",
+			role:        "owner",
+			mockTeam:    team,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:        "пользователь не состоит в команде",
+			role:        domain.RoleMaintainer,
+			mockTeam:    team,
+			mockSetErr:  sql.ErrNoRows,
+			wantErrCode: domain.ErrorCodeNotFound,
+		},
+		{
+			name:        "архивная команда",
+			role:        domain.RoleLead,
+			mockTeam:    &domain.Team{Name: "backend", ArchivedAt: 1_700_000_000},
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{GetWithMembersResult: tt.mockTeam}
+			userRepo := &mocks.MockTeamUserRepository{SetRoleErr: tt.mockSetErr}
+			service := NewTeamService(teamRepo, userRepo, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+
+			result, err := service.SetMemberRole(context.Background(), "backend", "user-1", tt.role)
+			if got := userRepo.RoleChanges["user-1"] != ""; got != tt.wantChanged {
+				t.Errorf("expected role changed %v, got %v", tt.wantChanged, got)
+			}
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userRepo.RoleChanges["user-1"] != tt.role {
+				t.Errorf("expected role %s, got %s", tt.role, userRepo.RoleChanges["user-1"])
+			}
+			if result.Members[0].Role != domain.RoleLead {
+				t.Errorf("expected reloaded team with lead, got %+v", result.Members)
+			}
+		})
+	}
+}
+
+func TestTeamService_SetMaxOpenReviews(t *testing.T) {
+	tests := []struct {
+		name        string
+		limit       int
+		mockTeam    *domain.Team
+		mockTeamErr error
+		wantErrCode domain.ErrorCode
+		wantErr     error
+	}{
+		{name: "лимит по умолчанию", limit: 4, mockTeam: &domain.Team{Name: "backend", MaxOpenReviews: 4}},
+		{name: "сброс лимита", limit: 0, mockTeam: &domain.Team{Name: "backend"}},
+		{name: "отрицательный лимит", limit: -1, mockTeam: &domain.Team{Name: "backend"}, wantErrCode: domain.ErrorCodeValidation},
+		{name: "архивная команда", limit: 2, mockTeam: &domain.Team{Name: "backend", ArchivedAt: 1_700_000_000}, wantErrCode: domain.ErrorCodeTeamArchived},
+		{name: "команда не найдена", limit: 2, mockTeamErr: sql.ErrNoRows, wantErr: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: tt.mockTeam,
+				GetWithMembersErr:    tt.mockTeamErr,
+				MaxOpenReviews:       -1,
+			}
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+
+			team, err := service.SetMaxOpenReviews(context.Background(), "backend", tt.limit)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+			case tt.wantErrCode != "":
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+			default:
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if teamRepo.MaxOpenReviews != tt.limit || team.MaxOpenReviews != tt.limit {
+					t.Errorf("expected limit %d stored and returned, got %d and %d", tt.limit, teamRepo.MaxOpenReviews, team.MaxOpenReviews)
+				}
+				return
+			}
+			if teamRepo.MaxOpenReviews != -1 {
+				t.Errorf("limit should not be stored on error")
+			}
+		})
+	}
+}
+
+func TestTeamService_InvalidMemberRole(t *testing.T) {
+	service := NewTeamService(&mocks.MockTeamRepository{}, &mocks.MockTeamUserRepository{},
+		&mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+
+	_, err := service.CreateTeam(context.Background(), "backend", "", []domain.User{
+		{ID: "user-1", Username: "user1", IsActive: true, Role: domain.RoleLead},
+		{ID: "user-2", Username: "user2", IsActive: true, Role: "owner"},
+	}, false)
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+		t.Fatalf("expected VALIDATION_ERROR from CreateTeam, got %v", err)
+	}
+	if len(domainErr.Details) != 1 || domainErr.Details[0].Field != "members[1].role" {
+		t.Errorf("expected members[1].role field error, got %+v", domainErr.Details)
+	}
+
+	_, err = service.AddMemberships(context.Background(), "guild", []string{"user-1"}, "owner")
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+		t.Fatalf("expected VALIDATION_ERROR from AddMemberships, got %v", err)
+	}
+}
+
+func TestTeamService_RemoveMembers(t *testing.T) {
+	team := &domain.Team{
+		Name: "team-1",
+		Members: []domain.User{
+			{ID: "user-1", Username: "user1", TeamName: "team-1", IsActive: true},
+			{ID: "user-2", Username: "user2", TeamName: "team-1", IsActive: true},
+			{ID: "user-3", Username: "user3", TeamName: "team-1", IsActive: false},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		userIDs        []string
+		reassign       bool
+		mockGetErr     error
+		mockCounts     map[string]int
+		mockRemoveErr  error
+		mockReassigned int
+		wantErr        bool
+		wantErrCode    domain.ErrorCode
+		wantRemoved    []string
+		wantReassigned []string
+		wantUpdated    int
+	}{
+		{
+			name:        "удаление участника без открытых ревью",
+			userIDs:     []string{"user-3"},
+			wantRemoved: []string{"user-3"},
+		},
+		{
+			name:        "отказ, если есть открытые ревью",
+			userIDs:     []string{"user-2", "user-3"},
+			mockCounts:  map[string]int{"user-2": 2},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeHasOpenReviews,
+		},
+		{
+			name:           "переназначение открытых ревью",
+			userIDs:        []string{"user-2"},
+			reassign:       true,
+			mockCounts:     map[string]int{"user-2": 2},
+			mockReassigned: 2,
+			wantRemoved:    []string{"user-2"},
+			wantReassigned: []string{"user-2"},
+			wantUpdated:    2,
+		},
+		{
+			name:        "пользователь не в команде",
+			userIDs:     []string{"user-9"},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeNotFound,
+		},
+		{
+			name:       "команда не найдена",
+			userIDs:    []string{"user-1"},
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    true,
+		},
+		{
+			name:          "ошибка при удалении",
+			userIDs:       []string{"user-1"},
+			mockRemoveErr: errors.New("remove error"),
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: team,
+				GetWithMembersErr:    tt.mockGetErr,
+			}
+			userRepo := &mocks.MockTeamUserRepository{RemoveErr: tt.mockRemoveErr}
+			prRepo := &mocks.MockTeamPRRepository{
+				OpenReviewCountsResult: tt.mockCounts,
+				ReassignResult:         mocks.ReviewerChanges(tt.mockReassigned),
+			}
+			txManager := &mocks.MockTxManager{}
+
+			service := NewTeamService(teamRepo, userRepo, prRepo, txManager)
+			result, err := service.RemoveMembers(context.Background(), "team-1", tt.userIDs, tt.reassign)
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				if txManager.FnErr == nil {
+					t.Errorf("expected transaction to be rolled back")
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if !slices.Equal(result.RemovedMembers, tt.wantRemoved) {
+				t.Errorf("expected removed %v, got %v", tt.wantRemoved, result.RemovedMembers)
+			}
+			if !slices.Equal(prRepo.ReassignedIDs, tt.wantReassigned) {
+				t.Errorf("expected reassigned %v, got %v", tt.wantReassigned, prRepo.ReassignedIDs)
+			}
+			if result.UpdatedPullRequests != tt.wantUpdated {
+				t.Errorf("expected %d updated PRs, got %d", tt.wantUpdated, result.UpdatedPullRequests)
+			}
+			if result.Team.Name != "team-1" {
+				t.Errorf("expected team team-1, got %s", result.Team.Name)
+			}
+		})
+	}
+}
+
+func TestTeamService_UpdateTeam(t *testing.T) {
+	teamRepo := &mocks.MockTeamRepository{
+		GetWithMembersResult: &domain.Team{
+			Name: "team-1",
+			Members: []domain.User{
+				{ID: "user-1", Username: "user1", TeamName: "team-1", IsActive: true},
+				{ID: "user-2", Username: "user2", TeamName: "team-1", IsActive: true},
+			},
+		},
+	}
+	userRepo := &mocks.MockTeamUserRepository{}
+	prRepo := &mocks.MockTeamPRRepository{ReassignResult: mocks.ReviewerChanges(1)}
+
+	service := NewTeamService(teamRepo, userRepo, prRepo, &mocks.MockTxManager{})
+	result, err := service.UpdateTeam(context.Background(), "team-1", []domain.User{
+		{ID: "user-1", Username: "user1", IsActive: true},
+		{ID: "user-4", Username: "user4", IsActive: true},
+	}, true, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(userRepo.UpsertedUsers) != 2 {
+		t.Errorf("expected 2 upserted members, got %d", len(userRepo.UpsertedUsers))
+	}
+	for _, u := range userRepo.UpsertedUsers {
+		if u.TeamName != "team-1" {
+			t.Errorf("expected team name team-1 for member %s, got %s", u.ID, u.TeamName)
+		}
+	}
+	if !slices.Equal(userRepo.RemovedIDs, []string{"user-2"}) {
+		t.Errorf("expected user-2 removed, got %v", userRepo.RemovedIDs)
+	}
+	if result.UpdatedPullRequests != 1 {
+		t.Errorf("expected 1 updated PR, got %d", result.UpdatedPullRequests)
+	}
+
+	// user-4 is moved in from another team and user-2 removed, and both
+	// hand over a review of pr-1: it is one updated PR.
+	userRepo.CurrentTeamsResult = map[string]string{"user-4": "team-2"}
+	result, err = service.UpdateTeam(context.Background(), "team-1", []domain.User{
+		{ID: "user-1", Username: "user1", IsActive: true},
+		{ID: "user-4", Username: "user4", IsActive: true},
+	}, true, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.UpdatedPullRequests != 1 || len(result.Changes) != 1 {
+		t.Fatalf("expected pr-1 counted once, got %d and %+v", result.UpdatedPullRequests, result.Changes)
+	}
+	if !slices.Equal(result.Changes[0].Removed, []string{"u1", "u1"}) {
+		t.Errorf("expected the removed reviewers of both changes, got %v", result.Changes[0].Removed)
+	}
+}
+
+func TestTeamService_MoveUser(t *testing.T) {
+	backendUser := &domain.User{ID: "user-1", Username: "user1", TeamName: "backend", IsActive: true}
+
+	tests := []struct {
+		name           string
+		user           *domain.User
+		mockGetUserErr error
+		mockTeamErr    error
+		policy         domain.OpenReviewsPolicy
+		mockCounts     map[string]int
+		mockReassigned int
+		wantErr        bool
+		wantErrCode    domain.ErrorCode
+		wantMoved      bool
+		wantReassigned []string
+		wantUpdated    int
+	}{
+		{
+			name:      "перевод без открытых ревью",
+			user:      backendUser,
+			wantMoved: true,
+		},
+		{
+			name:        "открытые ревью отклоняют перевод по умолчанию",
+			user:        backendUser,
+			mockCounts:  map[string]int{"user-1": 1},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeHasOpenReviews,
+			wantMoved:   true,
+		},
+		{
+			name:           "перевод с переназначением ревью",
+			user:           backendUser,
+			policy:         domain.OpenReviewsReassign,
+			mockReassigned: 2,
+			wantMoved:      true,
+			wantReassigned: []string{"user-1"},
+			wantUpdated:    2,
+		},
+		{
+			name:       "перевод с сохранением ревью",
+			user:       backendUser,
+			policy:     domain.OpenReviewsKeep,
+			mockCounts: map[string]int{"user-1": 1},
+			wantMoved:  true,
+		},
+		{
+			name: "пользователь уже в команде",
+			user: &domain.User{ID: "user-1", Username: "user1", TeamName: "payments", IsActive: true},
+		},
+		{
+			name:        "неизвестная политика",
+			user:        backendUser,
+			policy:      "drop",
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:           "пользователь не найден",
+			mockGetUserErr: sql.ErrNoRows,
+			wantErr:        true,
+		},
+		{
+			name:        "команда не найдена",
+			user:        backendUser,
+			mockTeamErr: sql.ErrNoRows,
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: &domain.Team{Name: "payments"},
+				GetWithMembersErr:    tt.mockTeamErr,
+			}
+			userRepo := &mocks.MockTeamUserRepository{
+				GetByIDResult: tt.user,
+				GetByIDErr:    tt.mockGetUserErr,
+			}
+			prRepo := &mocks.MockTeamPRRepository{
+				OpenReviewCountsResult: tt.mockCounts,
+				ReassignResult:         mocks.ReviewerChanges(tt.mockReassigned),
+			}
+
+			service := NewTeamService(teamRepo, userRepo, prRepo, &mocks.MockTxManager{})
+			result, err := service.MoveUser(context.Background(), "user-1", "payments", tt.policy)
+
+			if got := userRepo.SetTeamCalls > 0; got != tt.wantMoved {
+				t.Errorf("expected moved %v, got %v", tt.wantMoved, got)
+			}
+			if !slices.Equal(prRepo.ReassignedIDs, tt.wantReassigned) {
+				t.Errorf("expected reassigned %v, got %v", tt.wantReassigned, prRepo.ReassignedIDs)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if result.User.TeamName != "payments" {
+				t.Errorf("expected user in payments, got %s", result.User.TeamName)
+			}
+			if result.FromTeam != tt.user.TeamName {
+				t.Errorf("expected from team %s, got %s", tt.user.TeamName, result.FromTeam)
+			}
+			if result.UpdatedPullRequests != tt.wantUpdated {
+				t.Errorf("expected %d updated PRs, got %d", tt.wantUpdated, result.UpdatedPullRequests)
+			}
+		})
+	}
+}
+
+func TestTeamService_SetParentTeam(t *testing.T) {
+	tests := []struct {
+		name          string
+		teamName      string
+		parentTeam    string
+		mockTeam      *domain.Team
+		mockSetErr    error
+		wantErrCode   domain.ErrorCode
+		wantSetCalled bool
+	}{
+		{
+			name:          "перенос в отдел",
+			teamName:      "backend",
+			parentTeam:    "engineering",
+			mockTeam:      &domain.Team{Name: "backend", ParentTeam: "engineering"},
+			wantSetCalled: true,
+		},
+		{
+			name:          "команда становится корневой",
+			teamName:      "backend",
+			mockTeam:      &domain.Team{Name: "backend"},
+			wantSetCalled: true,
+		},
+		{
+			name:        "команда не может быть своим родителем",
+			teamName:    "backend",
+			parentTeam:  "backend",
+			wantErrCode: domain.ErrorCodeTeamCycle,
+		},
+		{
+			name:          "родитель внутри поддерева",
+			teamName:      "backend",
+			parentTeam:    "backend-payments",
+			mockTeam:      &domain.Team{Name: "backend-payments", ParentTeam: "backend"},
+			mockSetErr:    domain.NewDomainError(domain.ErrorCodeTeamCycle, "cycle"),
+			wantErrCode:   domain.ErrorCodeTeamCycle,
+			wantSetCalled: true,
+		},
+		{
+			name:        "архивный родитель",
+			teamName:    "backend",
+			parentTeam:  "legacy",
+			mockTeam:    &domain.Team{Name: "legacy", ArchivedAt: 1_700_000_000},
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: tt.mockTeam,
+				SetParentErr:         tt.mockSetErr,
+			}
+
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+			team, err := service.SetParentTeam(context.Background(), tt.teamName, tt.parentTeam)
+
+			if (teamRepo.SetParentCalls > 0) != tt.wantSetCalled {
+				t.Errorf("expected SetParent called=%v, got %d calls", tt.wantSetCalled, teamRepo.SetParentCalls)
+			}
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if team.ParentTeam != tt.parentTeam {
+				t.Errorf("expected parent %q, got %q", tt.parentTeam, team.ParentTeam)
+			}
+		})
+	}
+}
+
+func TestTeamService_CreateTeamWithParent(t *testing.T) {
+	teamRepo := &mocks.MockTeamRepository{
+		GetWithMembersResult: &domain.Team{Name: "engineering", ArchivedAt: 1_700_000_000},
+	}
+	service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+
+	_, err := service.CreateTeam(context.Background(), "backend", "engineering", nil, false)
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeTeamArchived {
+		t.Errorf("expected TEAM_ARCHIVED for an archived parent, got %v", err)
+	}
+
+	teamRepo.GetWithMembersResult = &domain.Team{Name: "engineering"}
+	team, err := service.CreateTeam(context.Background(), "backend", "engineering", nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if team.ParentTeam != "engineering" || teamRepo.CreatedParent != "engineering" {
+		t.Errorf("expected team created under engineering, got %q (repo %q)", team.ParentTeam, teamRepo.CreatedParent)
+	}
+}
+
+func TestTeamService_ArchiveTeam(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockTeam      *domain.Team
+		mockGetErr    error
+		mockArchErr   error
+		mockDeactErr  error
+		wantErr       bool
+		wantErrCode   domain.ErrorCode
+		wantDeactUser int
+	}{
+		{
+			name:          "успешная архивация",
+			mockTeam:      &domain.Team{Name: "team-1"},
+			wantDeactUser: 3,
+		},
+		{
+			name:        "команда уже архивирована",
+			mockTeam:    &domain.Team{Name: "team-1", ArchivedAt: 1_700_000_000},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:        "архивирована параллельным запросом",
+			mockTeam:    &domain.Team{Name: "team-1"},
+			mockArchErr: sql.ErrNoRows,
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:       "команда не найдена",
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    true,
+		},
+		{
+			name:         "ошибка деактивации",
+			mockTeam:     &domain.Team{Name: "team-1"},
+			mockDeactErr: errors.New("deactivate error"),
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: tt.mockTeam,
+				GetWithMembersErr:    tt.mockGetErr,
+				ArchiveResult:        1_700_000_000,
+				ArchiveErr:           tt.mockArchErr,
+			}
+			prRepo := &mocks.MockTeamPRRepository{
+				DeactivateResult: domain.TeamDeactivationResult{
+					TeamName:            "team-1",
+					DeactivatedUsers:    3,
+					UpdatedPullRequests: 2,
+				},
+				DeactivateErr: tt.mockDeactErr,
+			}
+			txManager := &mocks.MockTxManager{}
+
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, prRepo, txManager)
+			result, err := service.ArchiveTeam(context.Background(), "team-1")
+
+			if txManager.Calls != 1 {
+				t.Errorf("expected 1 transaction, got %d", txManager.Calls)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if result.ArchivedAt != 1_700_000_000 {
+				t.Errorf("expected archived_at 1700000000, got %d", result.ArchivedAt)
+			}
+			if result.DeactivatedUsers != tt.wantDeactUser {
+				t.Errorf("expected %d deactivated users, got %d", tt.wantDeactUser, result.DeactivatedUsers)
+			}
+		})
+	}
+}
+
+func TestTeamService_DisbandTeam(t *testing.T) {
+	teamRepo := &mocks.MockTeamRepository{
+		GetWithMembersResult: &domain.Team{
+			Name: "guild",
+			Members: []domain.User{
+				{ID: "user-1", Username: "user1", TeamName: "guild", IsActive: true},
+				{ID: "user-2", Username: "user2", TeamName: "team-1", IsActive: true},
+			},
+		},
+		ArchiveResult: 1_700_000_000,
+	}
+	userRepo := &mocks.MockTeamUserRepository{}
+	prRepo := &mocks.MockTeamPRRepository{ReassignResult: mocks.ReviewerChanges(3)}
+
+	service := NewTeamService(teamRepo, userRepo, prRepo, &mocks.MockTxManager{})
+	result, err := service.DisbandTeam(context.Background(), "guild")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(userRepo.RemovedIDs, []string{"user-1", "user-2"}) {
+		t.Errorf("expected all members removed, got %v", userRepo.RemovedIDs)
+	}
+	if result.UpdatedPullRequests != 3 {
+		t.Errorf("expected 3 updated PRs, got %d", result.UpdatedPullRequests)
+	}
+
+	teamRepo.ArchiveErr = sql.ErrNoRows
+	_, err = service.DisbandTeam(context.Background(), "guild")
+	var domainErr *domain.DomainError
+	if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeTeamArchived {
+		t.Errorf("expected TEAM_ARCHIVED for a concurrent archive, got %v", err)
+	}
+}
+
+func TestTeamService_SchedulesSyncOfReassignedPRs(t *testing.T) {
+	teamRepo := &mocks.MockTeamRepository{
+		GetWithMembersResult: &domain.Team{
+			Name:    "team-1",
+			Members: []domain.User{{ID: "u1", Username: "user1", TeamName: "team-1", IsActive: true}},
+		},
+	}
+	prRepo := &mocks.MockTeamPRRepository{ReassignResult: mocks.ReviewerChanges(2)}
+	prs := &mocks.MockPRRepository{
+		GetByIDResult:    &domain.PullRequest{ID: "pr-1", Status: domain.PRStatusOpen},
+		GetByIDReviewers: []string{"u5"},
+	}
+	sync := &mocks.MockReviewerSyncScheduler{}
+	prService := NewPRService(prs, &mocks.MockPRUserRepository{}, nil)
+	prService.SetReviewerSync(sync)
+
+	service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, prRepo, &mocks.MockTxManager{})
+	service.SetReviewerSync(prService)
+
+	if _, err := service.RemoveMembers(context.Background(), "team-1", []string{"u1"}, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sync.Scheduled) != 2 {
+		t.Fatalf("expected 2 scheduled syncs, got %d", len(sync.Scheduled))
+	}
+	if !slices.Equal(sync.Scheduled[0].AssignedReviewers, []string{"u5"}) {
+		t.Errorf("expected the current reviewers to be synced, got %v", sync.Scheduled[0].AssignedReviewers)
+	}
+	if !slices.Equal(sync.Removed[1], []string{"u2"}) {
+		t.Errorf("expected removed reviewers of the change, got %v", sync.Removed[1])
+	}
+
+	prRepo.ReassignErr = errors.New("db down")
+	sync.Scheduled = nil
+	if _, err := service.RemoveMembers(context.Background(), "team-1", []string{"u1"}, true); err == nil {
+		t.Fatal("expected error")
+	}
+	if len(sync.Scheduled) != 0 {
+		t.Errorf("expected no sync after a failed change, got %d", len(sync.Scheduled))
+	}
+}
+
+func TestTeamService_ReactivateTeam(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockTeam       *domain.Team
+		mockRun        *domain.TeamDeactivation
+		mockFindErr    error
+		deactivationID int64
+		refill         bool
+		wantErr        bool
+		wantErrCode    domain.ErrorCode
+		wantReact      int
+		wantRefill     int
+		wantUpdated    int
+	}{
+		{
+			name:      "отмена последней деактивации",
+			mockTeam:  &domain.Team{Name: "team-1"},
+			mockRun:   &domain.TeamDeactivation{ID: 7, TeamName: "team-1", UserIDs: []string{"u1", "u2"}},
+			wantReact: 1,
+		},
+		{
+			name:        "отмена с добором ревьюверов",
+			mockTeam:    &domain.Team{Name: "team-1"},
+			mockRun:     &domain.TeamDeactivation{ID: 7, TeamName: "team-1", UserIDs: []string{"u1", "u2"}},
+			refill:      true,
+			wantReact:   1,
+			wantRefill:  1,
+			wantUpdated: 4,
+		},
+		{
+			name:        "запуск уже отменён",
+			mockTeam:    &domain.Team{Name: "team-1"},
+			mockRun:     &domain.TeamDeactivation{ID: 7, TeamName: "team-1", ReactivatedAt: 1_700_000_000},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeAlreadyReactivated,
+		},
+		{
+			name:        "запуск не найден",
+			mockTeam:    &domain.Team{Name: "team-1"},
+			mockFindErr: sql.ErrNoRows,
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeNotFound,
+		},
+		{
+			name:        "команда архивирована",
+			mockTeam:    &domain.Team{Name: "team-1", ArchivedAt: 1_700_000_000},
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamArchived,
+		},
+		{
+			name:           "отрицательный deactivation_id",
+			deactivationID: -1,
+			wantErr:        true,
+			wantErrCode:    domain.ErrorCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult:   tt.mockTeam,
+				FindDeactivationResult: tt.mockRun,
+				FindDeactivationErr:    tt.mockFindErr,
+				ReactivateResult:       []string{"u1"},
+			}
+			prRepo := &mocks.MockTeamPRRepository{RefillResult: mocks.ReviewerChanges(4)}
+
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, prRepo, &mocks.MockTxManager{})
+			result, err := service.ReactivateTeam(context.Background(), "team-1", tt.deactivationID, tt.refill)
+
+			if teamRepo.ReactivateCalls != tt.wantReact {
+				t.Errorf("expected %d reactivate calls, got %d", tt.wantReact, teamRepo.ReactivateCalls)
+			}
+			if prRepo.RefillCalls != tt.wantRefill {
+				t.Errorf("expected %d refill calls, got %d", tt.wantRefill, prRepo.RefillCalls)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if result.DeactivationID != 7 {
+				t.Errorf("expected deactivation 7, got %d", result.DeactivationID)
+			}
+			if len(result.ReactivatedUsers) != 1 || result.ReactivatedUsers[0] != "u1" {
+				t.Errorf("expected u1 reactivated, got %v", result.ReactivatedUsers)
+			}
+			if result.UpdatedPullRequests != tt.wantUpdated {
+				t.Errorf("expected %d updated PRs, got %d", tt.wantUpdated, result.UpdatedPullRequests)
+			}
+		})
+	}
+}
+
+func TestTeamService_DeleteTeam(t *testing.T) {
+	tests := []struct {
+		name        string
+		mockGetErr  error
+		mockHistory int
+		mockDelErr  error
+		wantErr     bool
+		wantErrCode domain.ErrorCode
+		wantDeleted bool
+	}{
+		{
+			name:        "удаление команды без истории",
+			wantDeleted: true,
+		},
+		{
+			name:        "у команды есть история",
+			mockHistory: 4,
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamHasHistory,
+		},
+		{
+			name:       "команда не найдена",
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    true,
+		},
+		{
+			name:        "история появилась во время удаления",
+			mockDelErr:  domain.NewDomainError(domain.ErrorCodeTeamHasHistory, "team members have pull request history"),
+			wantErr:     true,
+			wantErrCode: domain.ErrorCodeTeamHasHistory,
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teamRepo := &mocks.MockTeamRepository{
+				GetWithMembersResult: &domain.Team{Name: "team-1"},
+				GetWithMembersErr:    tt.mockGetErr,
+				CountHistoryResult:   tt.mockHistory,
+				DeleteResult:         2,
+				DeleteErr:            tt.mockDelErr,
+			}
+
+			service := NewTeamService(teamRepo, &mocks.MockTeamUserRepository{}, &mocks.MockTeamPRRepository{}, &mocks.MockTxManager{})
+			result, err := service.DeleteTeam(context.Background(), "team-1")
+
+			if got := teamRepo.DeleteCalls > 0; got != tt.wantDeleted {
+				t.Errorf("expected delete %v, got %v", tt.wantDeleted, got)
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+					return
+				}
+				if tt.wantErrCode != "" {
+					var domainErr *domain.DomainError
+					if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+						t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			if result.DeletedUsers != 2 {
+				t.Errorf("expected 2 deleted users, got %d", result.DeletedUsers)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestReviewCapacity(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	users := service.NewUserService(userRepo, prRepo, postgres.NewTxManager(db))
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+
+	team, err := teams.SetMaxOpenReviews(ctx, "backend", 1)
+	if err != nil {
+		t.Fatalf("SetMaxOpenReviews(backend) returned error: %v", err)
+	}
+	if team.MaxOpenReviews != 1 {
+		t.Errorf("expected team default 1, got %d", team.MaxOpenReviews)
+	}
+	user, err := users.SetMaxOpenReviews(ctx, "u4", 2)
+	if err != nil {
+		t.Fatalf("SetMaxOpenReviews(u4) returned error: %v", err)
+	}
+	if user.MaxOpenReviews != 2 {
+		t.Errorf("expected u4's own limit 2, got %d", user.MaxOpenReviews)
+	}
+
+	// Every PR gets a reviewer while anybody has room, so the limits of u2,
+	// u3 and u4 (1 + 1 + 2) run out after at most four PRs.
+	assigned := 0
+	for i := 1; ; i++ {
+		pr, err := prs.CreatePullRequest(ctx, fmt.Sprintf("pr-%d", i), "Add search", "u1", "", nil, domain.GitHubLink{})
+		if errorCode(err) == domain.ErrorCodeAllAtCapacity {
+			break
+		}
+		if err != nil {
+			t.Fatalf("CreatePullRequest returned error: %v", err)
+		}
+		if i > 4 {
+			t.Fatalf("expected ALL_AT_CAPACITY by pr-5, got reviewers %v", pr.AssignedReviewers)
+		}
+		assigned += len(pr.AssignedReviewers)
+	}
+	if assigned != 4 {
+		t.Errorf("expected 4 assignments in total, got %d", assigned)
+	}
+	for id, limit := range map[string]int{"u2": 1, "u3": 1, "u4": 2} {
+		details, err := users.GetUser(ctx, id)
+		if err != nil {
+			t.Fatalf("GetUser(%s) returned error: %v", id, err)
+		}
+		if details.User.MaxOpenReviews != limit || details.Reviews.Open != limit {
+			t.Errorf("expected %s at its limit %d, got %d of %d", id, limit, details.Reviews.Open, details.User.MaxOpenReviews)
+		}
+	}
+
+	// A newcomer with room takes over u2's review; u3 and u4 are full.
+	if _, err := teams.AddMembers(ctx, "backend", []domain.User{{ID: "u5", Username: "Eve", IsActive: true}}, false); err != nil {
+		t.Fatalf("add u5: %v", err)
+	}
+	handOver := func(userID string) *domain.PullRequest {
+		t.Helper()
+		var prID string
+		if err := db.QueryRowContext(ctx,
+			`SELECT pr_id FROM pull_request_reviewers WHERE reviewer_id = $1`, userID,
+		).Scan(&prID); err != nil {
+			t.Fatalf("find review of %s: %v", userID, err)
+		}
+		// Deactivation hands the user's open reviews over by itself.
+		if _, err := users.SetActive(ctx, userID, false); err != nil {
+			t.Fatalf("deactivate %s: %v", userID, err)
+		}
+		pr, err := prs.GetPullRequest(ctx, prID)
+		if err != nil {
+			t.Fatalf("GetPullRequest returned error: %v", err)
+		}
+		if slices.Contains(pr.AssignedReviewers, userID) {
+			t.Errorf("expected %s off %s, got %v", userID, prID, pr.AssignedReviewers)
+		}
+		return pr
+	}
+
+	pr := handOver("u2")
+	if !slices.Contains(pr.AssignedReviewers, "u5") {
+		t.Errorf("expected u5 to take over u2's review, got %v", pr.AssignedReviewers)
+	}
+
+	// Nobody has room for u3's review any more, so its slot stays empty.
+	handOver("u3")
+	for id, limit := range map[string]int{"u4": 2, "u5": 1} {
+		details, err := users.GetUser(ctx, id)
+		if err != nil {
+			t.Fatalf("GetUser(%s) returned error: %v", id, err)
+		}
+		if details.Reviews.Open != limit {
+			t.Errorf("expected %s to stay at %d open reviews, got %d", id, limit, details.Reviews.Open)
+		}
+	}
+}
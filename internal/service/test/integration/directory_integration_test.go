@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestListTeamsAndUsers(t *testing.T) {
+	db := seedPRList(t)
+	ctx := context.Background()
+
+	extraSQL := `
+INSERT INTO teams(name, parent_team) VALUES ('backend-ops', 'backend');
+INSERT INTO teams(name, archived_at) VALUES ('backend-legacy', now());
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u6', 'Mallory', 'backend',     false),
+  ('u7', 'Alan',    'backend-ops', true);
+
+INSERT INTO team_memberships(team_name, user_id) VALUES ('frontend', 'u1');
+`
+	if _, err := db.ExecContext(ctx, extraSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	users := service.NewUserService(userRepo, prRepo, postgres.NewTxManager(db))
+
+	intPtr := func(n int) *int { return &n }
+	teamNames := func(ts []domain.TeamSummary) []string {
+		names := make([]string, 0, len(ts))
+		for _, t := range ts {
+			names = append(names, t.Name)
+		}
+		return names
+	}
+
+	// backend: u1..u3 active, u6 inactive; frontend: u4, u5 and u1 through a
+	// secondary membership; backend-ops: u7.
+	teamTests := []struct {
+		name   string
+		filter domain.TeamFilter
+		want   []string
+	}{
+		{name: "все неархивные команды", want: []string{"backend", "backend-ops", "frontend"}},
+		{name: "по префиксу имени", filter: domain.TeamFilter{NamePrefix: "backend"}, want: []string{"backend", "backend-ops"}},
+		{name: "не меньше трёх активных", filter: domain.TeamFilter{MinActiveMembers: 3}, want: []string{"backend", "frontend"}},
+		{name: "не больше одного активного", filter: domain.TeamFilter{MaxActiveMembers: intPtr(1)}, want: []string{"backend-ops"}},
+	}
+	for _, tt := range teamTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, next, err := teams.ListTeams(ctx, tt.filter, "", 0)
+			if err != nil {
+				t.Fatalf("ListTeams returned error: %v", err)
+			}
+			if !slices.Equal(teamNames(got), tt.want) || next != "" {
+				t.Errorf("expected %v on one page, got %v (next %q)", tt.want, teamNames(got), next)
+			}
+		})
+	}
+
+	first, next, err := teams.ListTeams(ctx, domain.TeamFilter{}, "", 2)
+	if err != nil {
+		t.Fatalf("ListTeams returned error: %v", err)
+	}
+	if !slices.Equal(teamNames(first), []string{"backend", "backend-ops"}) || next == "" {
+		t.Fatalf("expected first two teams and a cursor, got %v (next %q)", teamNames(first), next)
+	}
+	if first[0].MemberCount != 4 || first[0].ActiveMemberCount != 3 || first[1].ParentTeam != "backend" {
+		t.Errorf("unexpected summaries %+v", first)
+	}
+	second, next, err := teams.ListTeams(ctx, domain.TeamFilter{}, next, 2)
+	if err != nil {
+		t.Fatalf("ListTeams returned error: %v", err)
+	}
+	if !slices.Equal(teamNames(second), []string{"frontend"}) || next != "" {
+		t.Errorf("expected only frontend on the last page, got %v (next %q)", teamNames(second), next)
+	}
+
+	active := true
+	userIDs := func(us []domain.User) []string {
+		ids := make([]string, 0, len(us))
+		for _, u := range us {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+	userTests := []struct {
+		name   string
+		filter domain.UserFilter
+		want   []string
+	}{
+		{name: "все пользователи", want: []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7"}},
+		{name: "участники команды, включая дополнительных", filter: domain.UserFilter{TeamName: "frontend"}, want: []string{"u1", "u4", "u5"}},
+		{name: "только активные", filter: domain.UserFilter{TeamName: "backend", IsActive: &active}, want: []string{"u1", "u2", "u3"}},
+		{name: "подстрока имени без учёта регистра", filter: domain.UserFilter{UsernameContains: "AL"}, want: []string{"u1", "u6", "u7"}},
+	}
+	for _, tt := range userTests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := users.ListUsers(ctx, tt.filter, "", 0)
+			if err != nil {
+				t.Fatalf("ListUsers returned error: %v", err)
+			}
+			if !slices.Equal(userIDs(got), tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, userIDs(got))
+			}
+		})
+	}
+
+	page, next, err := users.ListUsers(ctx, domain.UserFilter{}, "", 4)
+	if err != nil {
+		t.Fatalf("ListUsers returned error: %v", err)
+	}
+	rest, last, err := users.ListUsers(ctx, domain.UserFilter{}, next, 4)
+	if err != nil {
+		t.Fatalf("ListUsers returned error: %v", err)
+	}
+	if got := append(userIDs(page), userIDs(rest)...); !slices.Equal(got, []string{"u1", "u2", "u3", "u4", "u5", "u6", "u7"}) || last != "" {
+		t.Errorf("expected all users over two pages, got %v (next %q)", got, last)
+	}
+
+	// u2 reviews pr-1 (merged) and pr-3 (open).
+	details, err := users.GetUser(ctx, "u2")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if details.User.TeamName != "backend" || details.Reviews != (domain.ReviewSummary{Open: 1, Completed: 1}) {
+		t.Errorf("unexpected details %+v", details)
+	}
+	details, err = users.GetUser(ctx, "u7")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if details.Reviews != (domain.ReviewSummary{}) {
+		t.Errorf("expected no reviews for u7, got %+v", details.Reviews)
+	}
+	if _, err := users.GetUser(ctx, "u9"); err == nil {
+		t.Errorf("expected an error for an unknown user")
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+	now := time.Unix(1_700_000_000, 0)
+	nowFunc := func() time.Time { return now }
+
+	svc := service.NewIdempotencyService(postgres.NewIdempotencyRepo(db), nowFunc, time.Hour)
+	const endpoint = "POST /pullRequest/reassign"
+
+	rec, err := svc.Begin(ctx, "key-1", endpoint, "hash-a")
+	if err != nil || rec != nil {
+		t.Fatalf("expected fresh key to be claimed, got %+v, %v", rec, err)
+	}
+
+	var de *domain.DomainError
+	if _, err := svc.Begin(ctx, "key-1", endpoint, "hash-a"); !errors.As(err, &de) || de.Code != domain.ErrorCodeIdempotencyInProgress {
+		t.Fatalf("expected IDEMPOTENCY_IN_PROGRESS while pending, got %v", err)
+	}
+
+	headers := map[string]string{"Content-Type": "application/json; charset=utf-8", "ETag": `"3"`}
+	if err := svc.Complete(ctx, "key-1", endpoint, 200, headers, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+
+	rec, err = svc.Begin(ctx, "key-1", endpoint, "hash-a")
+	if err != nil {
+		t.Fatalf("Begin on completed key returned error: %v", err)
+	}
+	if rec == nil || rec.StatusCode != 200 || string(rec.ResponseBody) != `{"ok":true}` {
+		t.Fatalf("expected stored response to be replayed, got %+v", rec)
+	}
+	if !maps.Equal(rec.ResponseHeaders, headers) {
+		t.Errorf("expected stored headers %v, got %v", headers, rec.ResponseHeaders)
+	}
+
+	if _, err := svc.Begin(ctx, "key-1", endpoint, "hash-b"); !errors.As(err, &de) || de.Code != domain.ErrorCodeIdempotencyKeyReused {
+		t.Fatalf("expected IDEMPOTENCY_KEY_REUSED for another body, got %v", err)
+	}
+
+	if rec, err := svc.Begin(ctx, "key-1", "POST /pullRequest/create", "hash-b"); err != nil || rec != nil {
+		t.Fatalf("expected the same key on another endpoint to be independent, got %+v, %v", rec, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if rec, err := svc.Begin(ctx, "key-1", endpoint, "hash-b"); err != nil || rec != nil {
+		t.Fatalf("expected expired key to be claimed again, got %+v, %v", rec, err)
+	}
+
+	// The request holding key-2 never completes, e.g. its instance died.
+	svc.SetLockTimeout(time.Minute)
+	if rec, err := svc.Begin(ctx, "key-2", endpoint, "hash-a"); err != nil || rec != nil {
+		t.Fatalf("expected fresh key to be claimed, got %+v, %v", rec, err)
+	}
+	now = now.Add(30 * time.Second)
+	if _, err := svc.Begin(ctx, "key-2", endpoint, "hash-a"); !errors.As(err, &de) || de.Code != domain.ErrorCodeIdempotencyInProgress {
+		t.Fatalf("expected IDEMPOTENCY_IN_PROGRESS within the lock timeout, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if rec, err := svc.Begin(ctx, "key-2", endpoint, "hash-a"); err != nil || rec != nil {
+		t.Fatalf("expected key-2 to be taken over after the lock timeout, got %+v, %v", rec, err)
+	}
+	if err := svc.Complete(ctx, "key-2", endpoint, 200, nil, []byte(`{"ok":true}`)); err != nil {
+		t.Fatalf("Complete returned error: %v", err)
+	}
+	now = now.Add(time.Hour - time.Minute)
+	if rec, err := svc.Begin(ctx, "key-2", endpoint, "hash-a"); err != nil || rec == nil {
+		t.Fatalf("expected a completed key to be replayed past the lock timeout, got %+v, %v", rec, err)
+	}
+
+	now = now.Add(2 * time.Hour)
+	n, err := svc.PurgeExpired(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpired returned error: %v", err)
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 expired keys purged, got %d", n)
+	}
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestOutOfOfficeHandOver(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	txManager := postgres.NewTxManager(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, txManager)
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+	ooo := service.NewOutOfOfficeService(postgres.NewOutOfOfficeRepo(db), prRepo, txManager, time.Now)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+
+	pr, err := prs.CreatePullRequest(ctx, "pr-1", "Add search", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", pr.AssignedReviewers)
+	}
+	away := pr.AssignedReviewers[0]
+
+	now := time.Now()
+	if _, err := ooo.AddPeriod(ctx, domain.OutOfOfficePeriod{
+		UserID:   away,
+		StartsAt: now.Add(48 * time.Hour).Unix(),
+		EndsAt:   now.Add(72 * time.Hour).Unix(),
+		Reason:   "conference",
+	}); err != nil {
+		t.Fatalf("AddPeriod returned error: %v", err)
+	}
+	period, err := ooo.AddPeriod(ctx, domain.OutOfOfficePeriod{
+		UserID:   away,
+		StartsAt: now.Add(-time.Minute).Unix(),
+		EndsAt:   now.Add(24 * time.Hour).Unix(),
+		Reason:   "vacation",
+	})
+	if err != nil {
+		t.Fatalf("AddPeriod returned error: %v", err)
+	}
+	if _, err := ooo.AddPeriod(ctx, domain.OutOfOfficePeriod{
+		UserID:   "ghost",
+		StartsAt: now.Unix(),
+		EndsAt:   now.Add(time.Hour).Unix(),
+	}); errorCode(err) != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for an unknown user, got %v", err)
+	}
+
+	// The user stays active but is away.
+	backend, err := teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	for _, m := range backend.Members {
+		if m.ID == away && (!m.IsActive || !m.OutOfOffice) {
+			t.Errorf("expected %s active and out of office, got %+v", away, m)
+		}
+	}
+
+	started, updated, err := ooo.HandOverStarted(ctx)
+	if err != nil {
+		t.Fatalf("HandOverStarted returned error: %v", err)
+	}
+	if len(started) != 1 || started[0].ID != period.ID || updated != 1 {
+		t.Fatalf("expected the started period handed over with 1 PR, got %+v and %d", started, updated)
+	}
+	_, reviewers, err := prRepo.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if slices.Contains(reviewers, away) || len(reviewers) != 2 {
+		t.Errorf("expected the review of %s handed over, got %v", away, reviewers)
+	}
+
+	// Each period is handed over once.
+	if started, _, err := ooo.HandOverStarted(ctx); err != nil || len(started) != 0 {
+		t.Errorf("expected nothing to hand over, got %+v, %v", started, err)
+	}
+
+	for _, id := range []string{"pr-2", "pr-3", "pr-4"} {
+		pr, err := prs.CreatePullRequest(ctx, id, "Fix search", "u1", "", nil, domain.GitHubLink{})
+		if err != nil {
+			t.Fatalf("CreatePullRequest returned error: %v", err)
+		}
+		if slices.Contains(pr.AssignedReviewers, away) {
+			t.Errorf("%s is out of office but was assigned to %s", away, id)
+		}
+	}
+
+	periods, err := ooo.ListPeriods(ctx, away, false)
+	if err != nil {
+		t.Fatalf("ListPeriods returned error: %v", err)
+	}
+	if len(periods) != 2 || periods[0].ID != period.ID || periods[0].ReassignedAt == 0 || periods[1].ReassignedAt != 0 {
+		t.Errorf("expected the current period handed over and the upcoming one not, got %+v", periods)
+	}
+
+	if _, err := ooo.RemovePeriod(ctx, "u1", period.ID); errorCode(err) != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for a period of another user, got %v", err)
+	}
+	if _, err := ooo.RemovePeriod(ctx, away, period.ID); err != nil {
+		t.Fatalf("RemovePeriod returned error: %v", err)
+	}
+	backend, err = teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	for _, m := range backend.Members {
+		if m.OutOfOffice {
+			t.Errorf("expected nobody out of office once the period is removed, got %s", m.ID)
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func newConcurrencyPRService(t *testing.T) (*service.PRService, func(prID string) (*domain.PullRequest, error)) {
+	t.Helper()
+
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u3', 'Carol', 'backend', true),
+  ('u4', 'Dave',  'backend', true),
+  ('u5', 'Eve',   'backend', true),
+  ('u6', 'Frank', 'backend', true);
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	prRepo := postgres.NewPRRepo(db)
+	svc := service.NewPRService(prRepo, postgres.NewUserRepo(db), time.Now)
+
+	load := func(prID string) (*domain.PullRequest, error) {
+		pr, _, err := prRepo.GetByID(ctx, prID)
+		return pr, err
+	}
+	return svc, load
+}
+
+// runConcurrently starts all fns at once and waits for them.
+func runConcurrently(fns ...func()) {
+	var (
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for _, fn := range fns {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			fn()
+		}()
+	}
+	close(start)
+	wg.Wait()
+}
+
+func errorCode(err error) domain.ErrorCode {
+	var de *domain.DomainError
+	if errors.As(err, &de) {
+		return de.Code
+	}
+	return ""
+}
+
+func TestConcurrentReassignOfSameReviewer(t *testing.T) {
+	svc, load := newConcurrencyPRService(t)
+	ctx := context.Background()
+
+	pr, err := svc.CreatePullRequest(ctx, "pr-1", "Race", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(pr.AssignedReviewers))
+	}
+	oldReviewer := pr.AssignedReviewers[0]
+
+	const workers = 5
+	errs := make([]error, workers)
+	fns := make([]func(), workers)
+	for i := range fns {
+		fns[i] = func() {
+			_, _, errs[i] = svc.ReassignReviewer(ctx, "pr-1", oldReviewer, 0)
+		}
+	}
+	runConcurrently(fns...)
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errorCode(err) == domain.ErrorCodeNotAssigned:
+		default:
+			t.Errorf("unexpected reassign error: %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one reassign of %s to succeed, got %d", oldReviewer, succeeded)
+	}
+
+	final, err := load("pr-1")
+	if err != nil {
+		t.Fatalf("load PR: %v", err)
+	}
+	if final.Version != pr.Version+1 {
+		t.Errorf("expected version %d, got %d", pr.Version+1, final.Version)
+	}
+	if len(final.AssignedReviewers) != 2 {
+		t.Fatalf("expected 2 reviewers after reassign, got %v", final.AssignedReviewers)
+	}
+	for _, id := range final.AssignedReviewers {
+		if id == oldReviewer || id == "u1" {
+			t.Errorf("unexpected reviewer %s after reassign: %v", id, final.AssignedReviewers)
+		}
+	}
+}
+
+func TestConcurrentReassignAndMerge(t *testing.T) {
+	svc, load := newConcurrencyPRService(t)
+	ctx := context.Background()
+
+	for i := 0; i < 10; i++ {
+		prID := fmt.Sprintf("pr-%d", i)
+		pr, err := svc.CreatePullRequest(ctx, prID, "Race", "u1", "", nil, domain.GitHubLink{})
+		if err != nil {
+			t.Fatalf("CreatePullRequest returned error: %v", err)
+		}
+
+		var mergeErr, reassignErr error
+		runConcurrently(
+			func() { _, mergeErr = svc.MergePullRequest(ctx, prID, 0) },
+			func() { _, _, reassignErr = svc.ReassignReviewer(ctx, prID, pr.AssignedReviewers[0], 0) },
+		)
+
+		if mergeErr != nil {
+			t.Fatalf("%s: merge returned error: %v", prID, mergeErr)
+		}
+		if reassignErr != nil && errorCode(reassignErr) != domain.ErrorCodePRMerged {
+			t.Fatalf("%s: expected reassign to succeed or fail with PR_MERGED, got %v", prID, reassignErr)
+		}
+
+		final, err := load(prID)
+		if err != nil {
+			t.Fatalf("%s: load PR: %v", prID, err)
+		}
+		if final.Status != domain.PRStatusMerged {
+			t.Errorf("%s: expected MERGED, got %s", prID, final.Status)
+		}
+
+		wantVersion := pr.Version + 1
+		if reassignErr == nil {
+			wantVersion++
+		}
+		if final.Version != wantVersion {
+			t.Errorf("%s: expected version %d, got %d", prID, wantVersion, final.Version)
+		}
+	}
+}
+
+func TestConcurrentCreateOfSamePR(t *testing.T) {
+	svc, load := newConcurrencyPRService(t)
+	ctx := context.Background()
+
+	const workers = 5
+	errs := make([]error, workers)
+	fns := make([]func(), workers)
+	for i := range fns {
+		fns[i] = func() {
+			_, errs[i] = svc.CreatePullRequest(ctx, "pr-1", "Race", "u1", "", nil, domain.GitHubLink{})
+		}
+	}
+	runConcurrently(fns...)
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errorCode(err) == domain.ErrorCodePRExists:
+		default:
+			t.Errorf("expected PR_EXISTS, got %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one create to succeed, got %d", succeeded)
+	}
+
+	if _, err := load("pr-1"); err != nil {
+		t.Fatalf("load PR: %v", err)
+	}
+}
+
+func TestConcurrentCreateOfSameTeam(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	svc := service.NewTeamService(postgres.NewTeamRepo(db), postgres.NewUserRepo(db), postgres.NewPRRepo(db), postgres.NewTxManager(db))
+	ctx := context.Background()
+
+	const workers = 5
+	errs := make([]error, workers)
+	fns := make([]func(), workers)
+	for i := range fns {
+		fns[i] = func() {
+			members := []domain.User{{ID: fmt.Sprintf("u%d", i), Username: "User", IsActive: true}}
+			_, errs[i] = svc.CreateTeam(ctx, "backend", "", members, false)
+		}
+	}
+	runConcurrently(fns...)
+
+	succeeded := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+		case errorCode(err) == domain.ErrorCodeTeamExists:
+		default:
+			t.Errorf("expected TEAM_EXISTS, got %v", err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one create to succeed, got %d", succeeded)
+	}
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+// seedPRList creates five PRs by two teams for the listing tests.
+func seedPRList(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	// pr-3 and pr-4 share created_at, so the page boundary between them is
+	// decided by the ID tie-breaker.
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend'), ('frontend');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend',  true),
+  ('u2', 'Bob',   'backend',  true),
+  ('u3', 'Carol', 'backend',  true),
+  ('u4', 'Dave',  'frontend', true),
+  ('u5', 'Eve',   'frontend', true);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at, merged_at)
+VALUES
+  ('pr-1', 'One',   'u1', 'backend',  'MERGED', '2025-01-01T10:00:00Z', '2025-01-02T10:00:00Z'),
+  ('pr-2', 'Two',   'u2', 'backend',  'OPEN',   '2025-01-03T10:00:00Z', NULL),
+  ('pr-3', 'Three', 'u1', 'backend',  'OPEN',   '2025-01-05T10:00:00Z', NULL),
+  ('pr-4', 'Four',  'u4', 'frontend', 'MERGED', '2025-01-05T10:00:00Z', '2025-01-06T10:00:00Z'),
+  ('pr-5', 'Five',  'u5', 'frontend', 'OPEN',   '2025-01-07T10:00:00Z', NULL);
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES
+  ('pr-1', 'u2'), ('pr-1', 'u3'),
+  ('pr-2', 'u1'),
+  ('pr-3', 'u2'),
+  ('pr-4', 'u5'),
+  ('pr-5', 'u4');
+`
+	if _, err := db.ExecContext(context.Background(), seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+	return db
+}
+
+func TestListPullRequests(t *testing.T) {
+	db := seedPRList(t)
+	ctx := context.Background()
+
+	svc := service.NewPRService(postgres.NewPRRepo(db), postgres.NewUserRepo(db), time.Now)
+
+	day := func(d int) int64 {
+		return time.Date(2025, 1, d, 0, 0, 0, 0, time.UTC).Unix()
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.PullRequestFilter
+		want   []string
+	}{
+		{
+			name: "без фильтров",
+			want: []string{"pr-5", "pr-4", "pr-3", "pr-2", "pr-1"},
+		},
+		{
+			name:   "по статусу",
+			filter: domain.PullRequestFilter{Status: domain.PRStatusMerged},
+			want:   []string{"pr-4", "pr-1"},
+		},
+		{
+			name:   "по автору",
+			filter: domain.PullRequestFilter{AuthorID: "u1"},
+			want:   []string{"pr-3", "pr-1"},
+		},
+		{
+			name:   "по ревьюверу",
+			filter: domain.PullRequestFilter{ReviewerID: "u2"},
+			want:   []string{"pr-3", "pr-1"},
+		},
+		{
+			name:   "по команде автора",
+			filter: domain.PullRequestFilter{TeamName: "frontend"},
+			want:   []string{"pr-5", "pr-4"},
+		},
+		{
+			name:   "по дате создания",
+			filter: domain.PullRequestFilter{CreatedFrom: day(3), CreatedTo: day(7)},
+			want:   []string{"pr-4", "pr-3", "pr-2"},
+		},
+		{
+			name:   "по дате мержа",
+			filter: domain.PullRequestFilter{MergedFrom: day(2), MergedTo: day(6)},
+			want:   []string{"pr-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A page size of 2 makes every case walk several pages.
+			var (
+				got    []string
+				cursor string
+			)
+			for page := 0; ; page++ {
+				if page > len(tt.want) {
+					t.Fatalf("pagination did not stop after %d pages", page)
+				}
+				prs, next, err := svc.ListPullRequests(ctx, tt.filter, cursor, 2)
+				if err != nil {
+					t.Fatalf("ListPullRequests returned error: %v", err)
+				}
+				for _, pr := range prs {
+					got = append(got, pr.ID)
+					if pr.AssignedReviewers == nil {
+						t.Errorf("%s: reviewers not loaded", pr.ID)
+					}
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+
+	pr, err := svc.GetPullRequest(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetPullRequest returned error: %v", err)
+	}
+	if len(pr.AssignedReviewers) != 2 || pr.MergedAt != time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC).Unix() {
+		t.Errorf("unexpected PR: %+v", pr)
+	}
+}
+
+func TestListAssignedPullRequests(t *testing.T) {
+	db := seedPRList(t)
+	ctx := context.Background()
+
+	svc := service.NewUserService(postgres.NewUserRepo(db), postgres.NewPRRepo(db), postgres.NewTxManager(db))
+
+	tests := []struct {
+		name   string
+		userID string
+		filter domain.ReviewFilter
+		want   []string
+	}{
+		{
+			name:   "все PR ревьювера",
+			userID: "u2",
+			want:   []string{"pr-3", "pr-1"},
+		},
+		{
+			name:   "только открытые",
+			userID: "u2",
+			filter: domain.ReviewFilter{Status: domain.PRStatusOpen},
+			want:   []string{"pr-3"},
+		},
+		{
+			name:   "сначала самые старые",
+			userID: "u2",
+			filter: domain.ReviewFilter{OldestFirst: true},
+			want:   []string{"pr-1", "pr-3"},
+		},
+		{
+			name:   "созданные не раньше since",
+			userID: "u2",
+			filter: domain.ReviewFilter{Since: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC).Unix()},
+			want:   []string{"pr-3"},
+		},
+		{
+			name:   "с соревьюверами",
+			userID: "u3",
+			filter: domain.ReviewFilter{IncludeReviewers: true},
+			want:   []string{"pr-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got    []domain.PullRequest
+				cursor string
+			)
+			for page := 0; ; page++ {
+				if page > len(tt.want) {
+					t.Fatalf("pagination did not stop after %d pages", page)
+				}
+				prs, next, err := svc.ListAssignedPullRequests(ctx, tt.userID, tt.filter, cursor, 1)
+				if err != nil {
+					t.Fatalf("ListAssignedPullRequests returned error: %v", err)
+				}
+				got = append(got, prs...)
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %+v", tt.want, got)
+			}
+			for i, pr := range got {
+				if pr.ID != tt.want[i] {
+					t.Fatalf("expected %v, got %+v", tt.want, got)
+				}
+				if pr.CreatedAt == 0 {
+					t.Errorf("%s: created_at not loaded", pr.ID)
+				}
+				if tt.filter.IncludeReviewers != (pr.AssignedReviewers != nil) {
+					t.Errorf("%s: unexpected reviewers %v", pr.ID, pr.AssignedReviewers)
+				}
+			}
+			if tt.filter.IncludeReviewers && len(got[0].AssignedReviewers) != 2 {
+				t.Errorf("expected both reviewers of pr-1, got %v", got[0].AssignedReviewers)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
@@ -48,6 +49,8 @@ func cleanupTables(t *testing.T, db *sql.DB) {
 
 	ctx := context.Background()
 	queries := []string{
+		`DELETE FROM idempotency_keys`,
+		`DELETE FROM review_assignments`,
 		`DELETE FROM pull_request_reviewers`,
 		`DELETE FROM pull_requests`,
 		`DELETE FROM users`,
@@ -88,7 +91,7 @@ VALUES
 
 	svc := service.NewPRService(prRepo, userRepo, nowFunc)
 
-	pr, err := svc.CreatePullRequest(ctx, "pr-1", "Test PR", "u1")
+	pr, err := svc.CreatePullRequest(ctx, "pr-1", "Test PR", "u1", "", nil, domain.GitHubLink{})
 	if err != nil {
 		t.Fatalf("CreatePullRequest returned error: %v", err)
 	}
@@ -138,10 +141,18 @@ VALUES
 		t.Fatalf("expected merged_at to be NULL for OPEN PR")
 	}
 
-	mergedPR, err := svc.MergePullRequest(ctx, "pr-1")
+	var de *domain.DomainError
+	if _, err := svc.MergePullRequest(ctx, "pr-1", pr.Version+1); !errors.As(err, &de) || de.Code != domain.ErrorCodeVersionConflict {
+		t.Fatalf("expected VERSION_CONFLICT for stale expected version, got %v", err)
+	}
+
+	mergedPR, err := svc.MergePullRequest(ctx, "pr-1", pr.Version)
 	if err != nil {
 		t.Fatalf("MergePullRequest returned error: %v", err)
 	}
+	if mergedPR.Version != pr.Version+1 {
+		t.Fatalf("expected version %d after merge, got %d", pr.Version+1, mergedPR.Version)
+	}
 
 	if mergedPR.Status != domain.PRStatusMerged {
 		t.Fatalf("expected Status MERGED, got %s", mergedPR.Status)
@@ -167,3 +178,53 @@ VALUES
 		t.Fatalf("expected merged_at to be NOT NULL after merge")
 	}
 }
+
+func TestPullRequestGitHubLink(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u3', 'Carol', 'backend', true);
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	svc := service.NewPRService(postgres.NewPRRepo(db), postgres.NewUserRepo(db), time.Now)
+
+	link := domain.GitHubLink{Repo: "acme/api", Number: 12}
+	if _, err := svc.CreatePullRequest(ctx, "pr-1", "Linked", "u1", "", nil, link); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if _, err := svc.CreatePullRequest(ctx, "pr-2", "Not linked", "u1", "", nil, domain.GitHubLink{}); err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+
+	pr, err := svc.GetPullRequest(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetPullRequest returned error: %v", err)
+	}
+	if pr.GitHub != link {
+		t.Fatalf("expected github link %+v, got %+v", link, pr.GitHub)
+	}
+	pr, err = svc.GetPullRequest(ctx, "pr-2")
+	if err != nil {
+		t.Fatalf("GetPullRequest returned error: %v", err)
+	}
+	if !pr.GitHub.IsZero() {
+		t.Fatalf("expected no github link, got %+v", pr.GitHub)
+	}
+
+	_, err = svc.CreatePullRequest(ctx, "pr-3", "Same GitHub PR", "u1", "", nil, link)
+	if code := errorCode(err); code != domain.ErrorCodePRExists {
+		t.Fatalf("expected PR_EXISTS for a GitHub PR linked twice, got %v", err)
+	}
+}
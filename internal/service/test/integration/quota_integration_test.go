@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestWeeklyReviewQuota(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	users := service.NewUserService(userRepo, prRepo, postgres.NewTxManager(db))
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+	prRepo.SetFairness(14*24*time.Hour, time.Now)
+	prs.SetFairness(prRepo)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+
+	user, err := users.SetWeeklyReviewQuota(ctx, "u2", 1)
+	if err != nil {
+		t.Fatalf("SetWeeklyReviewQuota returned error: %v", err)
+	}
+	if user.WeeklyReviewQuota != 1 || user.WeeklyReviews != 0 {
+		t.Errorf("expected quota 1 and no reviews yet, got %d of %d", user.WeeklyReviews, user.WeeklyReviewQuota)
+	}
+
+	pr, err := prs.CreatePullRequest(ctx, "pr-1", "Add search", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("create pr-1: %v", err)
+	}
+	if got := slices.Sorted(slices.Values(pr.AssignedReviewers)); !slices.Equal(got, []string{"u2", "u3"}) {
+		t.Fatalf("expected u2 and u3 on pr-1, got %v", got)
+	}
+
+	// u2 has used up the quota, so only u3 is left.
+	pr, err = prs.CreatePullRequest(ctx, "pr-2", "Fix search", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("create pr-2: %v", err)
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{"u3"}) {
+		t.Errorf("expected only u3 on pr-2, got %v", pr.AssignedReviewers)
+	}
+
+	// Once u2's assignment is older than a week, the quota frees up, and a
+	// reassignment on the same PR does not renew it.
+	if _, err := db.ExecContext(ctx,
+		`UPDATE review_assignments SET assigned_at = now() - interval '10 days' WHERE pr_id = 'pr-1'`,
+	); err != nil {
+		t.Fatalf("age pr-1 assignments: %v", err)
+	}
+	if _, err := teams.AddMembers(ctx, "backend", []domain.User{{ID: "u4", Username: "Dave", IsActive: true}}, false); err != nil {
+		t.Fatalf("add u4: %v", err)
+	}
+	pr, _, err = prs.ReassignReviewer(ctx, "pr-1", "u3", 0)
+	if err != nil {
+		t.Fatalf("ReassignReviewer returned error: %v", err)
+	}
+	if got := slices.Sorted(slices.Values(pr.AssignedReviewers)); !slices.Equal(got, []string{"u2", "u4"}) {
+		t.Errorf("expected u4 to replace u3 on pr-1, got %v", got)
+	}
+	details, err := users.GetUser(ctx, "u2")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if details.User.WeeklyReviews != 0 {
+		t.Errorf("expected u2's assignment to keep its time, got %d reviews this week", details.User.WeeklyReviews)
+	}
+
+	// Assignments counted for fairness: u2's is ten days old, and u3 still
+	// counts pr-1 after losing it.
+	counts, err := prRepo.CountAssignmentsSince(ctx, []string{"u2", "u3", "u4"}, time.Now().Add(-14*24*time.Hour))
+	if err != nil {
+		t.Fatalf("CountAssignmentsSince returned error: %v", err)
+	}
+	if want := map[string]int{"u2": 1, "u3": 2, "u4": 1}; !maps.Equal(counts, want) {
+		t.Errorf("expected counts %v, got %v", want, counts)
+	}
+	counts, err = prRepo.CountAssignmentsSince(ctx, []string{"u2", "u3", "u4"}, time.Now().Add(-7*24*time.Hour))
+	if err != nil {
+		t.Fatalf("CountAssignmentsSince returned error: %v", err)
+	}
+	if _, ok := counts["u2"]; ok {
+		t.Errorf("expected no recent assignments of u2, got %v", counts)
+	}
+
+	if _, err := users.SetWeeklyReviewQuota(ctx, "ghost", 1); err == nil {
+		t.Errorf("expected an error for an unknown user")
+	}
+}
+
+func TestBulkReassignmentFairness(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true},
+		{ID: "u5", Username: "Eve", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+	pr := &domain.PullRequest{ID: "pr-1", Name: "Add search", AuthorID: "u1", TeamName: "backend", Status: domain.PRStatusOpen}
+	if err := prRepo.CreateWithReviewers(ctx, pr, []string{"u2", "u4"}); err != nil {
+		t.Fatalf("CreateWithReviewers returned error: %v", err)
+	}
+
+	// u3 was assigned so much within the window that their weight is next to
+	// nothing; the window is measured from the repo's clock.
+	if _, err := db.ExecContext(ctx,
+		`INSERT INTO review_assignments (pr_id, reviewer_id, assigned_at)
+         SELECT 'pr-1', 'u3', now() - interval '1 day' FROM generate_series(1, 100000)`,
+	); err != nil {
+		t.Fatalf("seed assignments: %v", err)
+	}
+	now := time.Now()
+	prRepo.SetFairness(14*24*time.Hour, func() time.Time { return now })
+
+	if _, err := prRepo.HandOverOpenReviews(ctx, []string{"u2"}); err != nil {
+		t.Fatalf("HandOverOpenReviews returned error: %v", err)
+	}
+	_, reviewers, err := prRepo.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if !slices.Equal(reviewers, []string{"u4", "u5"}) {
+		t.Errorf("expected the less assigned u5 to replace u2, got %v", reviewers)
+	}
+}
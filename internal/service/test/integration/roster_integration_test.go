@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestRosterImportExport(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend');
+INSERT INTO teams(name, archived_at) VALUES ('legacy', now());
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u5', 'Eve',   'backend', true);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at)
+VALUES ('pr-1', 'Open', 'u1', 'backend', 'OPEN', '2025-01-01T10:00:00Z');
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES ('pr-1', 'u2');
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	userRepo := postgres.NewUserRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, postgres.NewPRRepo(db), postgres.NewTxManager(db))
+	roster := service.NewRosterService(postgres.NewRosterRepo(db), postgres.NewPRRepo(db), postgres.NewTxManager(db))
+
+	data := []byte(`team,user_id,username,active,role
+backend,u1,Alice,true,lead
+backend,u2,Bobby,false,
+guild,u3,Carol,true,
+guild,u1,Alice,true,maintainer
+`)
+
+	dry, err := roster.Import(ctx, domain.RosterFormatCSV, data, true)
+	if err != nil {
+		t.Fatalf("dry run returned error: %v", err)
+	}
+	if len(dry.Changes) != 6 {
+		t.Errorf("expected 6 planned changes, got %+v", dry.Changes)
+	}
+	if i := slices.IndexFunc(dry.Changes, func(c domain.RosterChange) bool { return c.UserID == "u2" }); i < 0 ||
+		dry.Changes[i].Detail != "username Bob -> Bobby; active true -> false; open reviews handed over: 1" {
+		t.Errorf("expected the dry run to count u2's open reviews, got %+v", dry.Changes)
+	}
+	if _, err := teams.GetTeam(ctx, "guild"); err == nil {
+		t.Fatalf("expected the dry run to leave guild uncreated")
+	}
+
+	applied, err := roster.Import(ctx, domain.RosterFormatCSV, data, false)
+	if err != nil {
+		t.Fatalf("Import returned error: %v", err)
+	}
+	if !slices.Equal(applied.Changes, dry.Changes) {
+		t.Errorf("expected the dry run to predict the import:\n got %+v\nwant %+v", applied.Changes, dry.Changes)
+	}
+
+	guild, err := teams.GetTeam(ctx, "guild")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	roles := make(map[string]domain.MemberRole)
+	for _, m := range guild.Members {
+		roles[m.ID] = m.Role
+	}
+	if roles["u1"] != domain.RoleMaintainer || roles["u3"] != domain.RoleMember {
+		t.Errorf("unexpected guild roles %v", roles)
+	}
+	u3, err := userRepo.GetByID(ctx, "u3")
+	if err != nil || u3.TeamName != "guild" {
+		t.Errorf("expected u3 created in guild, got %+v (%v)", u3, err)
+	}
+	u2, err := userRepo.GetByID(ctx, "u2")
+	if err != nil || u2.Username != "Bobby" || u2.IsActive {
+		t.Errorf("expected u2 renamed and deactivated, got %+v (%v)", u2, err)
+	}
+	pr, reviewers, err := postgres.NewPRRepo(db).GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if !slices.Equal(reviewers, []string{"u5"}) || pr.Version != 2 {
+		t.Errorf("expected u2's review handed over to u5, got %v (version %d)", reviewers, pr.Version)
+	}
+
+	// An export imported again changes nothing, in either format.
+	for _, format := range []domain.RosterFormat{domain.RosterFormatCSV, domain.RosterFormatYAML} {
+		exported, err := roster.Export(ctx, format)
+		if err != nil {
+			t.Fatalf("Export returned error: %v", err)
+		}
+		again, err := roster.Import(ctx, format, exported, false)
+		if err != nil {
+			t.Fatalf("re-import of %s export returned error: %v", format, err)
+		}
+		if len(again.Changes) != 0 {
+			t.Errorf("expected no changes from the %s export, got %+v", format, again.Changes)
+		}
+	}
+
+	// A failing row rolls back the whole import.
+	_, err = roster.Import(ctx, domain.RosterFormatCSV, []byte("team,user_id,username\nplatform,u4,Dave\nlegacy,u1,Alice\n"), false)
+	if errorCode(err) != domain.ErrorCodeTeamArchived {
+		t.Fatalf("expected TEAM_ARCHIVED, got %v", err)
+	}
+	if _, err := userRepo.GetByID(ctx, "u4"); err == nil {
+		t.Errorf("expected u4 not to be created")
+	}
+}
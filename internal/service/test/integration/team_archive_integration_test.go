@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestArchiveAndDeleteTeam(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend'), ('sandbox');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u3', 'Carol', 'backend', true),
+  ('u9', 'Test',  'sandbox', true);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at)
+VALUES ('pr-1', 'Open', 'u1', 'backend', 'OPEN', '2025-01-01T10:00:00Z');
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES ('pr-1', 'u2');
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	teamRepo := postgres.NewTeamRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	svc := service.NewTeamService(teamRepo, postgres.NewUserRepo(db), prRepo, postgres.NewTxManager(db))
+
+	_, err := svc.DeleteTeam(ctx, "backend")
+	if code := errorCode(err); code != domain.ErrorCodeTeamHasHistory {
+		t.Fatalf("expected TEAM_HAS_HISTORY, got %v", err)
+	}
+
+	deleted, err := svc.DeleteTeam(ctx, "sandbox")
+	if err != nil {
+		t.Fatalf("DeleteTeam returned error: %v", err)
+	}
+	if deleted.DeletedUsers != 1 {
+		t.Errorf("expected 1 deleted user, got %d", deleted.DeletedUsers)
+	}
+	if exists, err := teamRepo.Exists(ctx, "sandbox"); err != nil || exists {
+		t.Errorf("expected sandbox to be deleted, got exists=%v, %v", exists, err)
+	}
+	if _, err := svc.DeleteTeam(ctx, "sandbox"); err == nil {
+		t.Errorf("expected deleting a missing team to fail")
+	}
+
+	archived, err := svc.ArchiveTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("ArchiveTeam returned error: %v", err)
+	}
+	if archived.ArchivedAt == 0 {
+		t.Errorf("expected archived_at to be set")
+	}
+	if archived.DeactivatedUsers != 3 || archived.UpdatedPullRequests != 1 {
+		t.Errorf("expected 3 deactivated users and 1 updated PR, got %+v", archived)
+	}
+
+	team, err := svc.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if team.ArchivedAt != archived.ArchivedAt {
+		t.Errorf("expected archived_at %d, got %d", archived.ArchivedAt, team.ArchivedAt)
+	}
+	for _, m := range team.Members {
+		if m.IsActive {
+			t.Errorf("expected %s to be deactivated", m.ID)
+		}
+	}
+
+	// History stays: the PR is still there, only its reviewer is gone.
+	pr, reviewers, err := prRepo.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("get pr-1: %v", err)
+	}
+	if pr.AuthorID != "u1" || len(reviewers) != 0 {
+		t.Errorf("expected pr-1 by u1 without reviewers, got %+v, %v", pr, reviewers)
+	}
+
+	_, err = svc.ArchiveTeam(ctx, "backend")
+	if code := errorCode(err); code != domain.ErrorCodeTeamArchived {
+		t.Errorf("expected TEAM_ARCHIVED on second archive, got %v", err)
+	}
+	_, err = svc.AddMembers(ctx, "backend", []domain.User{{ID: "u4", Username: "Dave", IsActive: true}}, false)
+	if code := errorCode(err); code != domain.ErrorCodeTeamArchived {
+		t.Errorf("expected TEAM_ARCHIVED when adding members, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestTeamHierarchy(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	teamRepo := postgres.NewTeamRepo(db)
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(teamRepo, userRepo, prRepo, postgres.NewTxManager(db))
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+	prs.SetTeamHierarchy(teamRepo)
+
+	create := func(name, parent string, members ...domain.User) {
+		t.Helper()
+		if _, err := teams.CreateTeam(ctx, name, parent, members, false); err != nil {
+			t.Fatalf("create team %s: %v", name, err)
+		}
+	}
+	create("engineering", "", domain.User{ID: "u1", Username: "Alice", IsActive: true})
+	create("backend", "engineering", domain.User{ID: "u2", Username: "Bob", IsActive: true})
+	create("payments", "backend", domain.User{ID: "u3", Username: "Carol", IsActive: true})
+	create("mobile", "engineering", domain.User{ID: "u4", Username: "Dave", IsActive: true})
+
+	if _, err := teams.CreateTeam(ctx, "orphan", "missing", nil, false); err == nil {
+		t.Errorf("expected creating a team under a missing parent to fail")
+	}
+
+	tree, err := teams.GetTeam(ctx, "engineering")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if len(tree.Subteams) != 2 || tree.Subteams[0].Name != "backend" || tree.Subteams[1].Name != "mobile" {
+		t.Fatalf("expected backend and mobile under engineering, got %+v", tree.Subteams)
+	}
+	backend := tree.Subteams[0]
+	if len(backend.Subteams) != 1 || backend.Subteams[0].Name != "payments" || backend.Subteams[0].Members[0].ID != "u3" {
+		t.Errorf("expected payments with u3 under backend, got %+v", backend.Subteams)
+	}
+
+	members, err := teams.ListSubtreeMembers(ctx, "backend")
+	if err != nil {
+		t.Fatalf("ListSubtreeMembers returned error: %v", err)
+	}
+	ids := make([]string, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.ID)
+	}
+	if !slices.Equal(ids, []string{"u2", "u3"}) {
+		t.Errorf("expected u2 and u3 in backend subtree, got %v", ids)
+	}
+
+	_, err = teams.SetParentTeam(ctx, "engineering", "payments")
+	if code := errorCode(err); code != domain.ErrorCodeTeamCycle {
+		t.Errorf("expected TEAM_CYCLE, got %v", err)
+	}
+	_, err = teams.DeleteTeam(ctx, "backend")
+	if code := errorCode(err); code != domain.ErrorCodeTeamHasSubteams {
+		t.Errorf("expected TEAM_HAS_SUBTEAMS, got %v", err)
+	}
+
+	// payments has nobody besides the author, so selection climbs to the
+	// backend subtree (u2) before engineering.
+	pr, err := prs.CreatePullRequest(ctx, "pr-1", "Fallback", "u3", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{"u2"}) {
+		t.Errorf("expected u2 from the parent team, got %v", pr.AssignedReviewers)
+	}
+
+	moved, err := teams.SetParentTeam(ctx, "payments", "mobile")
+	if err != nil {
+		t.Fatalf("SetParentTeam returned error: %v", err)
+	}
+	if moved.ParentTeam != "mobile" {
+		t.Errorf("expected payments under mobile, got %q", moved.ParentTeam)
+	}
+
+	// Under mobile the nearest candidate is u4; u2 is no longer above.
+	pr, err = prs.CreatePullRequest(ctx, "pr-2", "Fallback after move", "u3", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{"u4"}) {
+		t.Errorf("expected u4 from the new parent team, got %v", pr.AssignedReviewers)
+	}
+
+	// Bulk reassignment climbs the same way: with u4 handed over, mobile has
+	// nobody left, so pr-2 goes to the engineering subtree.
+	changes, err := prRepo.HandOverOpenReviews(ctx, []string{"u4"})
+	if err != nil {
+		t.Fatalf("HandOverOpenReviews returned error: %v", err)
+	}
+	if len(changes) != 1 || changes[0].PullRequestID != "pr-2" {
+		t.Fatalf("expected pr-2 to change, got %+v", changes)
+	}
+	pr, err = prs.GetPullRequest(ctx, "pr-2")
+	if err != nil {
+		t.Fatalf("GetPullRequest returned error: %v", err)
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{"u1", "u2"}) {
+		t.Errorf("expected u1 and u2 from engineering, got %v", pr.AssignedReviewers)
+	}
+}
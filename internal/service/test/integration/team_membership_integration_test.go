@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestTeamMembershipChanges(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u3', 'Carol', 'backend', true),
+  ('u4', 'Dave',  'backend', true);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at, merged_at)
+VALUES
+  ('pr-1', 'Open',   'u1', 'backend', 'OPEN',   '2025-01-01T10:00:00Z', NULL),
+  ('pr-2', 'Merged', 'u1', 'backend', 'MERGED', '2025-01-01T10:00:00Z', '2025-01-02T10:00:00Z');
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES
+  ('pr-1', 'u2'), ('pr-1', 'u3'),
+  ('pr-2', 'u2');
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	svc := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+
+	reviewersOf := func(prID string) []string {
+		t.Helper()
+		_, reviewers, err := prRepo.GetByID(ctx, prID)
+		if err != nil {
+			t.Fatalf("get %s: %v", prID, err)
+		}
+		slices.Sort(reviewers)
+		return reviewers
+	}
+	memberIDs := func(team domain.Team) []string {
+		ids := make([]string, 0, len(team.Members))
+		for _, m := range team.Members {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+
+	// Without reassign_reviews a reviewer of an open PR cannot leave.
+	_, err := svc.RemoveMembers(ctx, "backend", []string{"u2"}, false)
+	if code := errorCode(err); code != domain.ErrorCodeHasOpenReviews {
+		t.Fatalf("expected HAS_OPEN_REVIEWS, got %v", err)
+	}
+	if u, err := userRepo.GetByID(ctx, "u2"); err != nil || u.TeamName != "backend" {
+		t.Fatalf("expected u2 to stay in backend, got %+v, %v", u, err)
+	}
+
+	res, err := svc.RemoveMembers(ctx, "backend", []string{"u2"}, true)
+	if err != nil {
+		t.Fatalf("RemoveMembers returned error: %v", err)
+	}
+	if !slices.Equal(res.RemovedMembers, []string{"u2"}) {
+		t.Errorf("expected u2 removed, got %v", res.RemovedMembers)
+	}
+	if res.UpdatedPullRequests != 1 {
+		t.Errorf("expected 1 updated PR, got %d", res.UpdatedPullRequests)
+	}
+	if got := memberIDs(res.Team); !slices.Equal(got, []string{"u1", "u3", "u4"}) {
+		t.Errorf("expected members [u1 u3 u4], got %v", got)
+	}
+	if got := reviewersOf("pr-1"); !slices.Equal(got, []string{"u3", "u4"}) {
+		t.Errorf("expected pr-1 reviewers [u3 u4], got %v", got)
+	}
+	if got := reviewersOf("pr-2"); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("expected merged pr-2 to keep u2, got %v", got)
+	}
+	if u, err := userRepo.GetByID(ctx, "u2"); err != nil || u.TeamName != "" {
+		t.Errorf("expected u2 without a team, got %+v, %v", u, err)
+	}
+
+	// u4 reviews pr-1 now, so dropping it from the list is rejected as a whole.
+	_, err = svc.UpdateTeam(ctx, "backend", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u5", Username: "Eve", IsActive: true},
+	}, false, false)
+	if code := errorCode(err); code != domain.ErrorCodeHasOpenReviews {
+		t.Fatalf("expected HAS_OPEN_REVIEWS, got %v", err)
+	}
+	if _, err := userRepo.GetByID(ctx, "u5"); err == nil {
+		t.Errorf("expected u5 upsert to be rolled back")
+	}
+
+	res, err = svc.UpdateTeam(ctx, "backend", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u5", Username: "Eve", IsActive: true},
+	}, true, false)
+	if err != nil {
+		t.Fatalf("UpdateTeam returned error: %v", err)
+	}
+	if !slices.Equal(res.RemovedMembers, []string{"u4"}) {
+		t.Errorf("expected u4 removed, got %v", res.RemovedMembers)
+	}
+	if got := memberIDs(res.Team); !slices.Equal(got, []string{"u1", "u3", "u5"}) {
+		t.Errorf("expected members [u1 u3 u5], got %v", got)
+	}
+	if got := reviewersOf("pr-1"); !slices.Equal(got, []string{"u3", "u5"}) {
+		t.Errorf("expected pr-1 reviewers [u3 u5], got %v", got)
+	}
+
+	if _, err := svc.AddMembers(ctx, "frontend", []domain.User{{ID: "u6", Username: "Frank", IsActive: true}}, false); err == nil {
+		t.Errorf("expected AddMembers to fail for a missing team")
+	}
+	team, err := svc.AddMembers(ctx, "backend", []domain.User{{ID: "u2", Username: "Bob", IsActive: true}}, false)
+	if err != nil {
+		t.Fatalf("AddMembers returned error: %v", err)
+	}
+	if got := memberIDs(*team); !slices.Equal(got, []string{"u1", "u2", "u3", "u5"}) {
+		t.Errorf("expected members [u1 u2 u3 u5], got %v", got)
+	}
+}
+
+func TestMoveUsersBetweenTeams(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend'), ('payments');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend',  true),
+  ('u2', 'Bob',   'backend',  true),
+  ('u3', 'Carol', 'backend',  true),
+  ('u4', 'Dave',  'backend',  true),
+  ('u5', 'Eve',   'payments', true);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at)
+VALUES ('pr-1', 'Open', 'u1', 'backend', 'OPEN', '2025-01-01T10:00:00Z');
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES ('pr-1', 'u2'), ('pr-1', 'u3');
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	teamRepo := postgres.NewTeamRepo(db)
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	svc := service.NewTeamService(teamRepo, userRepo, prRepo, postgres.NewTxManager(db))
+
+	reviewersOf := func(prID string) []string {
+		t.Helper()
+		_, reviewers, err := prRepo.GetByID(ctx, prID)
+		if err != nil {
+			t.Fatalf("get %s: %v", prID, err)
+		}
+		slices.Sort(reviewers)
+		return reviewers
+	}
+	teamOf := func(userID string) string {
+		t.Helper()
+		u, err := userRepo.GetByID(ctx, userID)
+		if err != nil {
+			t.Fatalf("get %s: %v", userID, err)
+		}
+		return u.TeamName
+	}
+
+	// /team/add no longer takes users away from their team silently.
+	_, err := svc.CreateTeam(ctx, "frontend", "", []domain.User{{ID: "u2", Username: "Bob", IsActive: true}}, false)
+	if code := errorCode(err); code != domain.ErrorCodeUserInOtherTeam {
+		t.Fatalf("expected USER_IN_OTHER_TEAM, got %v", err)
+	}
+	if exists, err := teamRepo.Exists(ctx, "frontend"); err != nil || exists {
+		t.Errorf("expected frontend to be rolled back, got exists=%v, %v", exists, err)
+	}
+	if got := teamOf("u2"); got != "backend" {
+		t.Errorf("expected u2 to stay in backend, got %s", got)
+	}
+
+	_, err = svc.MoveUser(ctx, "u2", "payments", "")
+	if code := errorCode(err); code != domain.ErrorCodeHasOpenReviews {
+		t.Fatalf("expected HAS_OPEN_REVIEWS by default, got %v", err)
+	}
+	if got := teamOf("u2"); got != "backend" {
+		t.Errorf("expected u2 to stay in backend, got %s", got)
+	}
+
+	res, err := svc.MoveUser(ctx, "u2", "payments", domain.OpenReviewsKeep)
+	if err != nil {
+		t.Fatalf("MoveUser keep returned error: %v", err)
+	}
+	if res.FromTeam != "backend" || res.User.TeamName != "payments" || res.UpdatedPullRequests != 0 {
+		t.Errorf("unexpected keep result: %+v", res)
+	}
+	if got := reviewersOf("pr-1"); !slices.Equal(got, []string{"u2", "u3"}) {
+		t.Errorf("expected pr-1 reviewers [u2 u3], got %v", got)
+	}
+
+	res, err = svc.MoveUser(ctx, "u3", "payments", domain.OpenReviewsReassign)
+	if err != nil {
+		t.Fatalf("MoveUser reassign returned error: %v", err)
+	}
+	if res.UpdatedPullRequests != 1 {
+		t.Errorf("expected 1 updated PR, got %d", res.UpdatedPullRequests)
+	}
+	// Only u3's review is handed over; u2 kept theirs on purpose.
+	if got := reviewersOf("pr-1"); !slices.Equal(got, []string{"u2", "u4"}) {
+		t.Errorf("expected pr-1 reviewers [u2 u4], got %v", got)
+	}
+
+	team, err := svc.CreateTeam(ctx, "frontend", "", []domain.User{{ID: "u4", Username: "Dave", IsActive: true}}, true)
+	if err != nil {
+		t.Fatalf("CreateTeam with move returned error: %v", err)
+	}
+	if len(team.Members) != 1 || teamOf("u4") != "frontend" {
+		t.Errorf("expected u4 moved to frontend, got %+v", team)
+	}
+	// backend has no one left to take over u4's review.
+	if got := reviewersOf("pr-1"); !slices.Equal(got, []string{"u2"}) {
+		t.Errorf("expected pr-1 reviewers [u2], got %v", got)
+	}
+}
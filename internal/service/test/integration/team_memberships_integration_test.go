@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestTeamMemberships(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+	if _, err := teams.CreateTeam(ctx, "guild", "", []domain.User{
+		{ID: "u3", Username: "Carol", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create guild: %v", err)
+	}
+
+	memberIDs := func(team *domain.Team) []string {
+		ids := make([]string, 0, len(team.Members))
+		for _, m := range team.Members {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+
+	guild, err := teams.AddMemberships(ctx, "guild", []string{"u1"}, "")
+	if err != nil {
+		t.Fatalf("AddMemberships returned error: %v", err)
+	}
+	if !slices.Equal(memberIDs(guild), []string{"u1", "u3"}) {
+		t.Errorf("expected u1 and u3 in guild, got %v", memberIDs(guild))
+	}
+	if guild.Members[0].TeamName != "backend" {
+		t.Errorf("expected u1 to keep backend as primary team, got %q", guild.Members[0].TeamName)
+	}
+	backend, err := teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if !slices.Equal(memberIDs(backend), []string{"u1", "u2"}) {
+		t.Errorf("expected u1 and u2 still in backend, got %v", memberIDs(backend))
+	}
+	if _, err := teams.AddMemberships(ctx, "guild", []string{"u9"}, ""); errorCode(err) != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for an unknown user, got %v", err)
+	}
+
+	pr, err := prs.CreatePullRequest(ctx, "pr-1", "Guild change", "u1", "guild", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if pr.TeamName != "guild" || !slices.Equal(pr.AssignedReviewers, []string{"u3"}) {
+		t.Errorf("expected guild PR reviewed by u3, got team %q reviewers %v", pr.TeamName, pr.AssignedReviewers)
+	}
+	pr, err = prs.CreatePullRequest(ctx, "pr-2", "Backend change", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if pr.TeamName != "backend" || !slices.Equal(pr.AssignedReviewers, []string{"u2"}) {
+		t.Errorf("expected backend PR reviewed by u2, got team %q reviewers %v", pr.TeamName, pr.AssignedReviewers)
+	}
+	_, err = prs.CreatePullRequest(ctx, "pr-3", "Not a member", "u2", "guild", nil, domain.GitHubLink{})
+	if code := errorCode(err); code != domain.ErrorCodeValidation {
+		t.Errorf("expected VALIDATION_ERROR for a team the author is not in, got %v", err)
+	}
+
+	listed, _, err := prs.ListPullRequests(ctx, domain.PullRequestFilter{TeamName: "guild"}, "", 10)
+	if err != nil {
+		t.Fatalf("ListPullRequests returned error: %v", err)
+	}
+	if len(listed) != 1 || listed[0].ID != "pr-1" || listed[0].TeamName != "guild" {
+		t.Errorf("expected only pr-1 under guild, got %+v", listed)
+	}
+
+	// u3 reviews pr-1 only through the guild membership.
+	_, err = teams.RemoveMembers(ctx, "guild", []string{"u3"}, false)
+	if code := errorCode(err); code != domain.ErrorCodeHasOpenReviews {
+		t.Errorf("expected HAS_OPEN_REVIEWS, got %v", err)
+	}
+
+	// Leaving a secondary team keeps the primary one.
+	if _, err := teams.RemoveMembers(ctx, "guild", []string{"u1"}, false); err != nil {
+		t.Fatalf("RemoveMembers returned error: %v", err)
+	}
+	u1, err := userRepo.GetByID(ctx, "u1")
+	if err != nil {
+		t.Fatalf("GetByID returned error: %v", err)
+	}
+	if u1.TeamName != "backend" {
+		t.Errorf("expected u1 to stay in backend, got %q", u1.TeamName)
+	}
+	guild, err = teams.GetTeam(ctx, "guild")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if !slices.Equal(memberIDs(guild), []string{"u3"}) {
+		t.Errorf("expected only u3 left in guild, got %v", memberIDs(guild))
+	}
+}
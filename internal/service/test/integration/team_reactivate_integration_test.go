@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestReactivateTeam(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	seedSQL := `
+INSERT INTO teams(name) VALUES ('backend');
+
+INSERT INTO users(id, username, team_name, is_active)
+VALUES
+  ('u1', 'Alice', 'backend', true),
+  ('u2', 'Bob',   'backend', true),
+  ('u3', 'Carol', 'backend', true),
+  ('u4', 'Dave',  'backend', false);
+
+INSERT INTO pull_requests(id, name, author_id, team_name, status, created_at)
+VALUES ('pr-1', 'Open', 'u1', 'backend', 'OPEN', '2025-01-01T10:00:00Z');
+
+INSERT INTO pull_request_reviewers(pr_id, reviewer_id)
+VALUES ('pr-1', 'u2');
+`
+	if _, err := db.ExecContext(ctx, seedSQL); err != nil {
+		t.Fatalf("seed data failed: %v", err)
+	}
+
+	prRepo := postgres.NewPRRepo(db)
+	svc := service.NewTeamService(postgres.NewTeamRepo(db), postgres.NewUserRepo(db), prRepo, postgres.NewTxManager(db))
+
+	_, err := svc.ReactivateTeam(ctx, "backend", 0, false)
+	if code := errorCode(err); code != domain.ErrorCodeNotFound {
+		t.Fatalf("expected NOT_FOUND before any deactivation, got %v", err)
+	}
+
+	deactivation, err := prRepo.DeactivateTeamAndReassignOpenPRs(ctx, "backend")
+	if err != nil {
+		t.Fatalf("deactivate team: %v", err)
+	}
+	if deactivation.DeactivatedUsers != 3 || deactivation.DeactivationID == 0 {
+		t.Fatalf("expected a recorded run with 3 users, got %+v", deactivation)
+	}
+
+	_, err = svc.ReactivateTeam(ctx, "backend", deactivation.DeactivationID+1, false)
+	if code := errorCode(err); code != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for an unknown run, got %v", err)
+	}
+
+	res, err := svc.ReactivateTeam(ctx, "backend", deactivation.DeactivationID, true)
+	if err != nil {
+		t.Fatalf("ReactivateTeam returned error: %v", err)
+	}
+	if !slices.Equal(res.ReactivatedUsers, []string{"u1", "u2", "u3"}) {
+		t.Errorf("expected u1, u2, u3 reactivated, got %v", res.ReactivatedUsers)
+	}
+	if res.UpdatedPullRequests != 1 {
+		t.Errorf("expected 1 refilled PR, got %d", res.UpdatedPullRequests)
+	}
+
+	team, err := svc.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	for _, m := range team.Members {
+		// u4 was inactive before the deactivation and stays so.
+		if m.IsActive != (m.ID != "u4") {
+			t.Errorf("unexpected is_active=%v for %s", m.IsActive, m.ID)
+		}
+	}
+
+	_, reviewers, err := prRepo.GetByID(ctx, "pr-1")
+	if err != nil {
+		t.Fatalf("get pr-1: %v", err)
+	}
+	slices.Sort(reviewers)
+	if !slices.Equal(reviewers, []string{"u2", "u3"}) {
+		t.Errorf("expected pr-1 refilled with u2 and u3, got %v", reviewers)
+	}
+
+	_, err = svc.ReactivateTeam(ctx, "backend", 0, false)
+	if code := errorCode(err); code != domain.ErrorCodeAlreadyReactivated {
+		t.Errorf("expected ALREADY_REACTIVATED, got %v", err)
+	}
+}
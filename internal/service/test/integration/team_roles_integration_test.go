@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestTeamRoles(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	prs := service.NewPRService(prRepo, userRepo, time.Now)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true, Role: domain.RoleLead},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+	if _, err := teams.CreateTeam(ctx, "guild", "", []domain.User{
+		{ID: "u5", Username: "Eve", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create guild: %v", err)
+	}
+
+	roles := func(team *domain.Team) map[string]domain.MemberRole {
+		out := make(map[string]domain.MemberRole, len(team.Members))
+		for _, m := range team.Members {
+			out[m.ID] = m.Role
+		}
+		return out
+	}
+
+	backend, err := teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if got := roles(backend); got["u1"] != domain.RoleMember || got["u4"] != domain.RoleLead {
+		t.Errorf("expected u1 member and u4 lead, got %v", got)
+	}
+
+	// Roles are per membership: u4 leads backend but only maintains guild.
+	guild, err := teams.AddMemberships(ctx, "guild", []string{"u4"}, domain.RoleMaintainer)
+	if err != nil {
+		t.Fatalf("AddMemberships returned error: %v", err)
+	}
+	if got := roles(guild)["u4"]; got != domain.RoleMaintainer {
+		t.Errorf("expected u4 maintainer in guild, got %q", got)
+	}
+	// Adding again without a role keeps it.
+	guild, err = teams.AddMemberships(ctx, "guild", []string{"u4"}, "")
+	if err != nil {
+		t.Fatalf("AddMemberships returned error: %v", err)
+	}
+	if got := roles(guild)["u4"]; got != domain.RoleMaintainer {
+		t.Errorf("expected u4 to stay maintainer in guild, got %q", got)
+	}
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5"} {
+		pr, err := prs.CreatePullRequest(ctx, id, "Risky change", "u1", "", []string{domain.LabelRisky}, domain.GitHubLink{})
+		if err != nil {
+			t.Fatalf("CreatePullRequest returned error: %v", err)
+		}
+		if !slices.Contains(pr.AssignedReviewers, "u4") {
+			t.Fatalf("expected lead u4 among reviewers of a risky PR, got %v", pr.AssignedReviewers)
+		}
+		if !slices.Equal(pr.Labels, []string{domain.LabelRisky}) {
+			t.Errorf("expected risky label, got %v", pr.Labels)
+		}
+	}
+
+	listed, _, err := prs.ListPullRequests(ctx, domain.PullRequestFilter{TeamName: "backend"}, "", 10)
+	if err != nil {
+		t.Fatalf("ListPullRequests returned error: %v", err)
+	}
+	for _, pr := range listed {
+		if !pr.IsRisky() {
+			t.Errorf("expected %s to keep its labels, got %v", pr.ID, pr.Labels)
+		}
+	}
+
+	// Replacing the only lead falls back to any member.
+	pr, _, err := prs.ReassignReviewer(ctx, "pr-1", "u4", 0)
+	if err != nil {
+		t.Fatalf("ReassignReviewer returned error: %v", err)
+	}
+	if slices.Contains(pr.AssignedReviewers, "u4") {
+		t.Errorf("expected u4 replaced, got %v", pr.AssignedReviewers)
+	}
+
+	backend, err = teams.SetMemberRole(ctx, "backend", "u2", domain.RoleLead)
+	if err != nil {
+		t.Fatalf("SetMemberRole returned error: %v", err)
+	}
+	if got := roles(backend)["u2"]; got != domain.RoleLead {
+		t.Errorf("expected u2 lead, got %q", got)
+	}
+	if _, err := teams.SetMemberRole(ctx, "guild", "u1", domain.RoleLead); errorCode(err) != domain.ErrorCodeNotFound {
+		t.Errorf("expected NOT_FOUND for a non-member, got %v", err)
+	}
+	if _, err := teams.SetMemberRole(ctx, "backend", "u2", "owner"); errorCode(err) != domain.ErrorCodeValidation {
+		t.Errorf("expected VALIDATION_ERROR for an unknown role, got %v", err)
+	}
+
+	// Members listed without a role keep the one they have.
+	members := slices.Clone(backend.Members)
+	for i := range members {
+		members[i].Role = ""
+	}
+	if _, err := teams.UpdateTeam(ctx, "backend", members, false, false); err != nil {
+		t.Fatalf("UpdateTeam returned error: %v", err)
+	}
+	backend, err = teams.GetTeam(ctx, "backend")
+	if err != nil {
+		t.Fatalf("GetTeam returned error: %v", err)
+	}
+	if got := roles(backend); got["u2"] != domain.RoleLead || got["u4"] != domain.RoleLead {
+		t.Errorf("expected u2 and u4 to stay leads, got %v", got)
+	}
+
+	// Bulk reassignment keeps a lead on risky PRs too: with u2 a member
+	// again, handing u4 over gives pr-6 the lead u6 rather than one of the
+	// members.
+	if _, err := teams.SetMemberRole(ctx, "backend", "u2", domain.RoleMember); err != nil {
+		t.Fatalf("SetMemberRole returned error: %v", err)
+	}
+	if _, err := teams.AddMembers(ctx, "backend", []domain.User{
+		{ID: "u6", Username: "Frank", IsActive: true, Role: domain.RoleLead},
+	}, false); err != nil {
+		t.Fatalf("AddMembers returned error: %v", err)
+	}
+	risky := &domain.PullRequest{
+		ID:       "pr-6",
+		Name:     "Risky change",
+		AuthorID: "u1",
+		TeamName: "backend",
+		Labels:   []string{domain.LabelRisky},
+		Status:   domain.PRStatusOpen,
+	}
+	if err := prRepo.CreateWithReviewers(ctx, risky, []string{"u3", "u4"}); err != nil {
+		t.Fatalf("CreateWithReviewers returned error: %v", err)
+	}
+	if _, err := prRepo.HandOverOpenReviews(ctx, []string{"u4"}); err != nil {
+		t.Fatalf("HandOverOpenReviews returned error: %v", err)
+	}
+	pr, err = prs.GetPullRequest(ctx, "pr-6")
+	if err != nil {
+		t.Fatalf("GetPullRequest returned error: %v", err)
+	}
+	if !slices.Equal(pr.AssignedReviewers, []string{"u3", "u6"}) {
+		t.Errorf("expected lead u6 to replace u4, got %v", pr.AssignedReviewers)
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestCreateTeamRollsBackOnMemberFailure(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	teamRepo := postgres.NewTeamRepo(db)
+	svc := service.NewTeamService(teamRepo, postgres.NewUserRepo(db), postgres.NewPRRepo(db), postgres.NewTxManager(db))
+	ctx := context.Background()
+
+	members := []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		// Not valid UTF-8, so Postgres rejects the second upsert.
+		{ID: "u2", Username: "\xff", IsActive: true},
+	}
+	if _, err := svc.CreateTeam(ctx, "backend", "", members, false); err == nil {
+		t.Fatalf("expected CreateTeam to fail")
+	}
+
+	exists, err := teamRepo.Exists(ctx, "backend")
+	if err != nil {
+		t.Fatalf("check team exists: %v", err)
+	}
+	if exists {
+		t.Errorf("expected team to be rolled back together with its members")
+	}
+
+	var users int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM users`).Scan(&users); err != nil {
+		t.Fatalf("count users: %v", err)
+	}
+	if users != 0 {
+		t.Errorf("expected no users after rollback, got %d", users)
+	}
+
+	members[1].Username = "Bob"
+	team, err := svc.CreateTeam(ctx, "backend", "", members, false)
+	if err != nil {
+		t.Fatalf("CreateTeam retry returned error: %v", err)
+	}
+	if len(team.Members) != 2 {
+		t.Errorf("expected 2 members, got %d", len(team.Members))
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/repo/postgres"
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/service"
+)
+
+func TestWorkingHoursPreference(t *testing.T) {
+	db := openTestDB(t)
+	cleanupTables(t, db)
+
+	ctx := context.Background()
+
+	userRepo := postgres.NewUserRepo(db)
+	prRepo := postgres.NewPRRepo(db)
+	teams := service.NewTeamService(postgres.NewTeamRepo(db), userRepo, prRepo, postgres.NewTxManager(db))
+	users := service.NewUserService(userRepo, prRepo, postgres.NewTxManager(db))
+	// 10:30 in Moscow, 02:30 in New York.
+	now := time.Date(2025, time.January, 15, 7, 30, 0, 0, time.UTC)
+	prs := service.NewPRService(prRepo, userRepo, func() time.Time { return now })
+	prs.SetReviewerPreference(service.PreferWorkingNow)
+
+	if _, err := teams.CreateTeam(ctx, "backend", "", []domain.User{
+		{ID: "u1", Username: "Alice", IsActive: true},
+		{ID: "u2", Username: "Bob", IsActive: true},
+		{ID: "u3", Username: "Carol", IsActive: true},
+		{ID: "u4", Username: "Dave", IsActive: true},
+	}, false); err != nil {
+		t.Fatalf("create backend: %v", err)
+	}
+
+	moscow := &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60}
+	newYork := &domain.WorkingHours{TimeZone: "America/New_York", Start: 9 * 60, End: 18 * 60}
+	for id, hours := range map[string]*domain.WorkingHours{"u2": moscow, "u3": newYork, "u4": newYork} {
+		if _, err := users.SetWorkingHours(ctx, id, hours); err != nil {
+			t.Fatalf("SetWorkingHours(%s) returned error: %v", id, err)
+		}
+	}
+	if _, err := users.SetWorkingHours(ctx, "ghost", moscow); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows for an unknown user, got %v", err)
+	}
+
+	details, err := users.GetUser(ctx, "u2")
+	if err != nil {
+		t.Fatalf("GetUser returned error: %v", err)
+	}
+	if details.User.WorkingHours == nil || *details.User.WorkingHours != *moscow {
+		t.Errorf("expected Moscow working hours, got %+v", details.User.WorkingHours)
+	}
+
+	// Only u2 is working now, so the second reviewer is either New Yorker.
+	for _, id := range []string{"pr-1", "pr-2", "pr-3"} {
+		pr, err := prs.CreatePullRequest(ctx, id, "Add search", "u1", "", nil, domain.GitHubLink{})
+		if err != nil {
+			t.Fatalf("CreatePullRequest returned error: %v", err)
+		}
+		if len(pr.AssignedReviewers) != 2 || !slices.Contains(pr.AssignedReviewers, "u2") {
+			t.Errorf("expected u2 among 2 reviewers of %s, got %v", id, pr.AssignedReviewers)
+		}
+	}
+
+	// Clearing makes u3 always working, so it is preferred too.
+	user, err := users.SetWorkingHours(ctx, "u3", nil)
+	if err != nil {
+		t.Fatalf("SetWorkingHours returned error: %v", err)
+	}
+	if user.WorkingHours != nil {
+		t.Errorf("expected working hours cleared, got %+v", user.WorkingHours)
+	}
+	pr, err := prs.CreatePullRequest(ctx, "pr-4", "Fix search", "u1", "", nil, domain.GitHubLink{})
+	if err != nil {
+		t.Fatalf("CreatePullRequest returned error: %v", err)
+	}
+	if got := slices.Sorted(slices.Values(pr.AssignedReviewers)); !slices.Equal(got, []string{"u2", "u3"}) {
+		t.Errorf("expected u2 and u3, got %v", got)
+	}
+}
@@ -0,0 +1,18 @@
+package service
+
+import "context"
+
+// TxManager runs fn in a single transaction. Repository calls made with the
+// ctx passed to fn take part in it, so they are committed or rolled back
+// together.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// noTx runs fn without a transaction; services fall back to it when no
+// TxManager is given.
+type noTx struct{}
+
+func (noTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
@@ -8,38 +8,264 @@ import (
 )
 
 type UserRepository interface {
+	Create(ctx context.Context, u domain.User) error
 	GetByID(ctx context.Context, id string) (*domain.User, error)
+	SetUsername(ctx context.Context, id, username string) (*domain.User, error)
 	SetIsActive(ctx context.Context, id string, active bool) (*domain.User, error)
+	List(ctx context.Context, filter domain.UserFilter, after string, limit int) (domain.UserPage, error)
+	SetWorkingHours(ctx context.Context, userID string, h *domain.WorkingHours) error
+	SetMaxOpenReviews(ctx context.Context, userID string, limit int) error
+	SetWeeklyReviewQuota(ctx context.Context, userID string, quota int) error
 }
 
 type UserPRRepository interface {
-	ListByReviewer(ctx context.Context, userID string) ([]domain.PullRequest, error)
+	ListByReviewer(
+		ctx context.Context,
+		userID string,
+		filter domain.ReviewFilter,
+		after *domain.PullRequestCursor,
+		limit int,
+	) (domain.PullRequestPage, error)
+	ReviewSummary(ctx context.Context, userID string) (domain.ReviewSummary, error)
+	HandOverOpenReviews(ctx context.Context, userIDs []string) ([]domain.ReviewerChange, error)
 }
 
 type UserService struct {
 	users UserRepository
 	prs   UserPRRepository
+	tx    TxManager
+	sync  ReviewerChangeScheduler
 }
 
-func NewUserService(users UserRepository, prs UserPRRepository) *UserService {
+func NewUserService(users UserRepository, prs UserPRRepository, tx TxManager) *UserService {
+	if tx == nil {
+		tx = noTx{}
+	}
 	return &UserService{
 		users: users,
 		prs:   prs,
+		tx:    tx,
+	}
+}
+
+// SetReviewerSync enables pushing the reviewers of PRs changed by
+// deactivations to the Git hosting API.
+func (s *UserService) SetReviewerSync(sync ReviewerChangeScheduler) {
+	s.sync = sync
+}
+
+// CreateUser adds a user without a team; teams are joined through
+// TeamService. An existing ID yields USER_EXISTS.
+func (s *UserService) CreateUser(ctx context.Context, userID, username string, active bool) (*domain.User, error) {
+	var fields []domain.FieldError
+	if userID == "" {
+		fields = append(fields, domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	if username == "" {
+		fields = append(fields, domain.FieldError{Field: "username", Reason: "required"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid user", fields...)
+	}
+
+	user := domain.User{ID: userID, Username: username, IsActive: active}
+	if err := s.users.Create(ctx, user); err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
 	}
+	return &user, nil
 }
 
+func (s *UserService) RenameUser(ctx context.Context, userID, username string) (*domain.User, error) {
+	if username == "" {
+		return nil, domain.NewValidationError("invalid user", domain.FieldError{Field: "username", Reason: "required"})
+	}
+	user, err := s.users.SetUsername(ctx, userID, username)
+	if err != nil {
+		return nil, fmt.Errorf("rename user: %w", err)
+	}
+	return user, nil
+}
+
+// SetActive sets the user's active flag; deactivating goes through
+// Deactivate.
 func (s *UserService) SetActive(ctx context.Context, userID string, active bool) (*domain.User, error) {
-	user, err := s.users.SetIsActive(ctx, userID, active)
+	if !active {
+		return s.Deactivate(ctx, userID)
+	}
+	user, err := s.users.SetIsActive(ctx, userID, true)
 	if err != nil {
 		return nil, fmt.Errorf("set user active: %w", err)
 	}
 	return user, nil
 }
 
-func (s *UserService) ListAssignedPullRequests(ctx context.Context, userID string) ([]domain.PullRequest, error) {
-	prs, err := s.prs.ListByReviewer(ctx, userID)
+// Deactivate makes the user inactive and hands their open reviews over to
+// other members in the same transaction. The update locks the user row, so
+// a concurrent activation waits for the hand-over to commit.
+func (s *UserService) Deactivate(ctx context.Context, userID string) (*domain.User, error) {
+	var (
+		user    *domain.User
+		changes []domain.ReviewerChange
+	)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.users.SetIsActive(ctx, userID, false)
+		if err != nil {
+			return fmt.Errorf("set user active: %w", err)
+		}
+		changes, err = s.prs.HandOverOpenReviews(ctx, []string{userID})
+		if err != nil {
+			return fmt.Errorf("hand over open reviews: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if s.sync != nil && len(changes) > 0 {
+		s.sync.ScheduleChanges(ctx, changes)
+	}
+	return user, nil
+}
+
+// GetUser returns the user with a summary of the reviews assigned to them.
+func (s *UserService) GetUser(ctx context.Context, userID string) (*domain.UserDetails, error) {
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	reviews, err := s.prs.ReviewSummary(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("summarize reviews: %w", err)
+	}
+	return &domain.UserDetails{User: *user, Reviews: reviews}, nil
+}
+
+// SetWorkingHours replaces the user's working hours, or clears them when h
+// is nil, and returns the user.
+func (s *UserService) SetWorkingHours(ctx context.Context, userID string, h *domain.WorkingHours) (*domain.User, error) {
+	var fields []domain.FieldError
+	if userID == "" {
+		fields = append(fields, domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	if h != nil {
+		fields = append(fields, validateWorkingHours(h)...)
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid working hours", fields...)
+	}
+
+	if _, err := s.users.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if err := s.users.SetWorkingHours(ctx, userID, h); err != nil {
+		return nil, fmt.Errorf("set working hours: %w", err)
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+// SetMaxOpenReviews sets how many open PRs the user reviews at most; zero
+// clears the user's own limit, leaving the default of their team. It
+// returns the user with the limit now in effect.
+func (s *UserService) SetMaxOpenReviews(ctx context.Context, userID string, limit int) (*domain.User, error) {
+	var fields []domain.FieldError
+	if userID == "" {
+		fields = append(fields, domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	if limit < 0 {
+		fields = append(fields, domain.FieldError{Field: "max_open_reviews", Reason: "must not be negative"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid review limit", fields...)
+	}
+
+	if err := s.users.SetMaxOpenReviews(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("set max open reviews: %w", err)
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+// SetWeeklyReviewQuota sets how many reviews the user may be assigned over
+// domain.QuotaWindow; zero clears the quota. It returns the user with their
+// reviews of that window.
+func (s *UserService) SetWeeklyReviewQuota(ctx context.Context, userID string, quota int) (*domain.User, error) {
+	var fields []domain.FieldError
+	if userID == "" {
+		fields = append(fields, domain.FieldError{Field: "user_id", Reason: "required"})
+	}
+	if quota < 0 {
+		fields = append(fields, domain.FieldError{Field: "weekly_review_quota", Reason: "must not be negative"})
+	}
+	if len(fields) > 0 {
+		return nil, domain.NewValidationError("invalid weekly review quota", fields...)
+	}
+
+	if err := s.users.SetWeeklyReviewQuota(ctx, userID, quota); err != nil {
+		return nil, fmt.Errorf("set weekly review quota: %w", err)
+	}
+	user, err := s.users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers returns one page of the users matching filter, ordered by ID.
+// Paging works as in PRService.ListPullRequests.
+func (s *UserService) ListUsers(
+	ctx context.Context,
+	filter domain.UserFilter,
+	cursor string,
+	limit int,
+) (users []domain.User, nextCursor string, err error) {
+	limit, err = pageLimit(limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	after, err := decodeKeyCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, err := s.users.List(ctx, filter, after, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("list users: %w", err)
+	}
+	return page.Users, encodeKeyCursor(page.Next), nil
+}
+
+// ListAssignedPullRequests returns one page of the PRs userID reviews.
+// Paging works as in PRService.ListPullRequests.
+func (s *UserService) ListAssignedPullRequests(
+	ctx context.Context,
+	userID string,
+	filter domain.ReviewFilter,
+	cursor string,
+	limit int,
+) (prs []domain.PullRequest, nextCursor string, err error) {
+	limit, err = pageLimit(limit)
+	if err != nil {
+		return nil, "", err
+	}
+
+	after, err := decodePRCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, err := s.prs.ListByReviewer(ctx, userID, filter, after, limit)
 	if err != nil {
-		return nil, fmt.Errorf("list pull_requests by reviewer: %w", err)
+		return nil, "", fmt.Errorf("list pull_requests by reviewer: %w", err)
 	}
-	return prs, nil
+	return page.PullRequests, encodePRCursor(page.Next), nil
 }
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
@@ -80,7 +82,7 @@ func TestUserService_SetActive(t *testing.T) {
 			}
 			mockPRRepo := &mocks.MockUserPRRepository{}
 
-			service := NewUserService(mockUserRepo, mockPRRepo)
+			service := NewUserService(mockUserRepo, mockPRRepo, &mocks.MockTxManager{})
 			ctx := context.Background()
 
 			result, err := service.SetActive(ctx, tt.userID, tt.active)
@@ -102,6 +104,322 @@ func TestUserService_SetActive(t *testing.T) {
 	}
 }
 
+func TestUserService_DeactivateHandsOverReviews(t *testing.T) {
+	userRepo := &mocks.MockUserRepository{
+		SetIsActiveResult: &domain.User{ID: "u1", Username: "alice", TeamName: "team-1", IsActive: false},
+	}
+	prRepo := &mocks.MockUserPRRepository{HandOverResult: mocks.ReviewerChanges(2)}
+	tx := &mocks.MockTxManager{}
+	sync := &mocks.MockReviewerSyncScheduler{}
+	prService := NewPRService(&mocks.MockPRRepository{
+		GetByIDResult: &domain.PullRequest{ID: "pr-1", Status: domain.PRStatusOpen},
+	}, &mocks.MockPRUserRepository{}, nil)
+	prService.SetReviewerSync(sync)
+
+	service := NewUserService(userRepo, prRepo, tx)
+	service.SetReviewerSync(prService)
+
+	if _, err := service.SetActive(context.Background(), "u1", false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tx.Calls != 1 {
+		t.Errorf("expected deactivation in one transaction, got %d", tx.Calls)
+	}
+	if len(prRepo.HandedOver) != 1 || !slices.Equal(prRepo.HandedOver[0], []string{"u1"}) {
+		t.Errorf("expected open reviews of u1 handed over, got %v", prRepo.HandedOver)
+	}
+	if len(sync.Scheduled) != 2 {
+		t.Errorf("expected 2 scheduled syncs, got %d", len(sync.Scheduled))
+	}
+
+	prRepo.HandedOver = nil
+	if _, err := service.SetActive(context.Background(), "u1", true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(prRepo.HandedOver) != 0 {
+		t.Errorf("expected no hand-over on activation, got %v", prRepo.HandedOver)
+	}
+
+	prRepo.HandOverErr = errors.New("db down")
+	sync.Scheduled = nil
+	if _, err := service.SetActive(context.Background(), "u1", false); err == nil {
+		t.Fatal("expected error")
+	}
+	if !errors.Is(tx.FnErr, prRepo.HandOverErr) || len(sync.Scheduled) != 0 {
+		t.Errorf("expected the deactivation rolled back without sync, got %v and %d syncs", tx.FnErr, len(sync.Scheduled))
+	}
+}
+
+func TestUserService_CreateUser(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      string
+		username    string
+		mockErr     error
+		wantErrCode domain.ErrorCode
+	}{
+		{name: "успешное создание", userID: "user-1", username: "alice"},
+		{name: "без идентификатора", username: "alice", wantErrCode: domain.ErrorCodeValidation},
+		{name: "без имени", userID: "user-1", wantErrCode: domain.ErrorCodeValidation},
+		{
+			name:        "пользователь уже есть",
+			userID:      "user-1",
+			username:    "alice",
+			mockErr:     domain.NewDomainError(domain.ErrorCodeUserExists, "user already exists"),
+			wantErrCode: domain.ErrorCodeUserExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &mocks.MockUserRepository{CreateErr: tt.mockErr}
+			service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+			user, err := service.CreateUser(context.Background(), tt.userID, tt.username, false)
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Errorf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(userRepo.Created) != 1 || userRepo.Created[0] != *user || user.IsActive || user.TeamName != "" {
+				t.Errorf("expected an inactive user without a team, got %+v", userRepo.Created)
+			}
+		})
+	}
+}
+
+func TestUserService_RenameUser(t *testing.T) {
+	userRepo := &mocks.MockUserRepository{
+		GetByIDResult: &domain.User{ID: "user-1", Username: "alice", TeamName: "team-1", IsActive: true},
+	}
+	service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+	user, err := service.RenameUser(context.Background(), "user-1", "alice.smith")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if user.Username != "alice.smith" || user.TeamName != "team-1" {
+		t.Errorf("expected the user renamed, got %+v", user)
+	}
+
+	if _, err := service.RenameUser(context.Background(), "user-1", ""); err == nil {
+		t.Errorf("expected an error for an empty username")
+	}
+}
+
+func TestUserService_SetWorkingHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		hours      *domain.WorkingHours
+		wantFields []string
+	}{
+		{
+			name:  "рабочие часы",
+			hours: &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60},
+		},
+		{
+			name:  "ночная смена",
+			hours: &domain.WorkingHours{TimeZone: "Asia/Tokyo", Start: 22 * 60, End: 6 * 60},
+		},
+		{
+			name:  "сброс рабочих часов",
+			hours: nil,
+		},
+		{
+			name:       "неизвестный часовой пояс",
+			hours:      &domain.WorkingHours{TimeZone: "Mars/Olympus", Start: 9 * 60, End: 18 * 60},
+			wantFields: []string{"working_hours.time_zone"},
+		},
+		{
+			name:       "часовой пояс сервера",
+			hours:      &domain.WorkingHours{TimeZone: "Local", Start: 9 * 60, End: 18 * 60},
+			wantFields: []string{"working_hours.time_zone"},
+		},
+		{
+			name:       "пустое окно без часового пояса",
+			hours:      &domain.WorkingHours{Start: 9 * 60, End: 9 * 60},
+			wantFields: []string{"working_hours.time_zone", "working_hours.end"},
+		},
+		{
+			name:       "время за пределами суток",
+			hours:      &domain.WorkingHours{TimeZone: "UTC", Start: 24 * 60, End: -1},
+			wantFields: []string{"working_hours.start", "working_hours.end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &mocks.MockUserRepository{
+				GetByIDResult: &domain.User{ID: "user-1", Username: "alice", IsActive: true},
+			}
+			service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+			user, err := service.SetWorkingHours(context.Background(), "user-1", tt.hours)
+			if len(tt.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !userRepo.WorkingHoursSet || user.WorkingHours != tt.hours {
+					t.Errorf("expected working hours %+v stored and returned, got %+v", tt.hours, user.WorkingHours)
+				}
+				return
+			}
+
+			var domainErr *domain.DomainError
+			if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			fields := make([]string, 0, len(domainErr.Details))
+			for _, d := range domainErr.Details {
+				fields = append(fields, d.Field)
+			}
+			if !slices.Equal(fields, tt.wantFields) {
+				t.Errorf("expected fields %v, got %v", tt.wantFields, fields)
+			}
+			if userRepo.WorkingHoursSet {
+				t.Errorf("invalid working hours should not be stored")
+			}
+		})
+	}
+
+	t.Run("пользователь не найден", func(t *testing.T) {
+		userRepo := &mocks.MockUserRepository{GetByIDErr: sql.ErrNoRows}
+		service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+		if _, err := service.SetWorkingHours(context.Background(), "ghost", nil); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("expected sql.ErrNoRows, got %v", err)
+		}
+	})
+}
+
+func TestUserService_SetMaxOpenReviews(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		limit      int
+		mockSetErr error
+		wantFields []string
+		wantErr    error
+	}{
+		{name: "лимит", userID: "user-1", limit: 3},
+		{name: "сброс лимита", userID: "user-1", limit: 0},
+		{name: "отрицательный лимит", userID: "user-1", limit: -1, wantFields: []string{"max_open_reviews"}},
+		{name: "без пользователя", limit: -2, wantFields: []string{"user_id", "max_open_reviews"}},
+		{name: "пользователь не найден", userID: "ghost", limit: 2, mockSetErr: sql.ErrNoRows, wantErr: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &mocks.MockUserRepository{
+				GetByIDResult:        &domain.User{ID: "user-1", IsActive: true, MaxOpenReviews: 3},
+				MaxOpenReviews:       -1,
+				SetMaxOpenReviewsErr: tt.mockSetErr,
+			}
+			service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+			user, err := service.SetMaxOpenReviews(context.Background(), tt.userID, tt.limit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if len(tt.wantFields) > 0 {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				fields := make([]string, 0, len(domainErr.Details))
+				for _, d := range domainErr.Details {
+					fields = append(fields, d.Field)
+				}
+				if !slices.Equal(fields, tt.wantFields) {
+					t.Errorf("expected fields %v, got %v", tt.wantFields, fields)
+				}
+				if userRepo.MaxOpenReviews != -1 {
+					t.Errorf("invalid limit should not be stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userRepo.MaxOpenReviews != tt.limit {
+				t.Errorf("expected limit %d stored, got %d", tt.limit, userRepo.MaxOpenReviews)
+			}
+			if user.ID != "user-1" {
+				t.Errorf("expected the reloaded user, got %+v", user)
+			}
+		})
+	}
+}
+
+func TestUserService_SetWeeklyReviewQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		quota      int
+		mockSetErr error
+		wantFields []string
+		wantErr    error
+	}{
+		{name: "квота", userID: "user-1", quota: 10},
+		{name: "сброс квоты", userID: "user-1", quota: 0},
+		{name: "отрицательная квота", userID: "user-1", quota: -1, wantFields: []string{"weekly_review_quota"}},
+		{name: "без пользователя", quota: -2, wantFields: []string{"user_id", "weekly_review_quota"}},
+		{name: "пользователь не найден", userID: "ghost", quota: 2, mockSetErr: sql.ErrNoRows, wantErr: sql.ErrNoRows},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userRepo := &mocks.MockUserRepository{
+				GetByIDResult:           &domain.User{ID: "user-1", IsActive: true, WeeklyReviewQuota: 10},
+				WeeklyReviewQuota:       -1,
+				SetWeeklyReviewQuotaErr: tt.mockSetErr,
+			}
+			service := NewUserService(userRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+			user, err := service.SetWeeklyReviewQuota(context.Background(), tt.userID, tt.quota)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if len(tt.wantFields) > 0 {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != domain.ErrorCodeValidation {
+					t.Fatalf("expected validation error, got %v", err)
+				}
+				fields := make([]string, 0, len(domainErr.Details))
+				for _, d := range domainErr.Details {
+					fields = append(fields, d.Field)
+				}
+				if !slices.Equal(fields, tt.wantFields) {
+					t.Errorf("expected fields %v, got %v", tt.wantFields, fields)
+				}
+				if userRepo.WeeklyReviewQuota != -1 {
+					t.Errorf("invalid quota should not be stored")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if userRepo.WeeklyReviewQuota != tt.quota {
+				t.Errorf("expected quota %d stored, got %d", tt.quota, userRepo.WeeklyReviewQuota)
+			}
+			if user.ID != "user-1" {
+				t.Errorf("expected the reloaded user, got %+v", user)
+			}
+		})
+	}
+}
+
 func TestUserService_ListAssignedPullRequests(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -215,10 +533,10 @@ func TestUserService_ListAssignedPullRequests(t *testing.T) {
 				ListByReviewerErr:    tt.mockListErr,
 			}
 
-			service := NewUserService(mockUserRepo, mockPRRepo)
+			service := NewUserService(mockUserRepo, mockPRRepo, &mocks.MockTxManager{})
 			ctx := context.Background()
 
-			result, err := service.ListAssignedPullRequests(ctx, tt.userID)
+			result, _, err := service.ListAssignedPullRequests(ctx, tt.userID, domain.ReviewFilter{}, "", 0)
 
 			if tt.wantErr {
 				if err == nil {
@@ -236,3 +554,212 @@ func TestUserService_ListAssignedPullRequests(t *testing.T) {
 		})
 	}
 }
+
+func TestUserService_ListAssignedPullRequests_Paging(t *testing.T) {
+	filter := domain.ReviewFilter{Status: domain.PRStatusOpen, Since: 1_700_000_000, OldestFirst: true, IncludeReviewers: true}
+	cursor := &domain.PullRequestCursor{CreatedAtMicro: 1_700_000_000_000_001, ID: "pr-1"}
+
+	tests := []struct {
+		name        string
+		cursor      string
+		limit       int
+		next        *domain.PullRequestCursor
+		wantErrCode domain.ErrorCode
+		wantLimit   int
+		wantAfter   *domain.PullRequestCursor
+		wantNext    bool
+	}{
+		{
+			name:      "первая страница",
+			next:      cursor,
+			wantLimit: DefaultPageLimit,
+			wantNext:  true,
+		},
+		{
+			name:      "последняя страница по курсору",
+			cursor:    encodePRCursor(cursor),
+			limit:     5,
+			wantLimit: 5,
+			wantAfter: cursor,
+		},
+		{
+			name:        "отрицательный лимит",
+			limit:       -1,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:        "битый курсор",
+			cursor:      "%%%",
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockPRRepo := &mocks.MockUserPRRepository{
+				ListByReviewerResult: []domain.PullRequest{{ID: "pr-2"}},
+				ListByReviewerNext:   tt.next,
+			}
+			service := NewUserService(&mocks.MockUserRepository{}, mockPRRepo, &mocks.MockTxManager{})
+
+			_, next, err := service.ListAssignedPullRequests(context.Background(), "user-1", filter, tt.cursor, tt.limit)
+
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if mockPRRepo.ListByReviewerFilter != filter {
+				t.Errorf("expected filter %+v, got %+v", filter, mockPRRepo.ListByReviewerFilter)
+			}
+			if mockPRRepo.ListByReviewerLimit != tt.wantLimit {
+				t.Errorf("expected limit %d, got %d", tt.wantLimit, mockPRRepo.ListByReviewerLimit)
+			}
+			if (tt.wantAfter == nil) != (mockPRRepo.ListByReviewerAfter == nil) ||
+				(tt.wantAfter != nil && *tt.wantAfter != *mockPRRepo.ListByReviewerAfter) {
+				t.Errorf("expected cursor %+v, got %+v", tt.wantAfter, mockPRRepo.ListByReviewerAfter)
+			}
+			if tt.wantNext != (next != "") {
+				t.Errorf("expected next cursor %v, got %q", tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestUserService_ListUsers(t *testing.T) {
+	active := true
+	filter := domain.UserFilter{TeamName: "team-1", IsActive: &active, UsernameContains: "al"}
+
+	tests := []struct {
+		name        string
+		cursor      string
+		limit       int
+		next        string
+		wantErrCode domain.ErrorCode
+		wantLimit   int
+		wantAfter   string
+		wantNext    bool
+	}{
+		{
+			name:      "первая страница",
+			next:      "user-2",
+			wantLimit: DefaultPageLimit,
+			wantNext:  true,
+		},
+		{
+			name:      "последняя страница по курсору",
+			cursor:    encodeKeyCursor("user-2"),
+			limit:     2,
+			wantLimit: 2,
+			wantAfter: "user-2",
+		},
+		{
+			name:        "лимит больше максимального",
+			limit:       MaxPageLimit + 1,
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+		{
+			name:        "битый курсор",
+			cursor:      "%%%",
+			wantErrCode: domain.ErrorCodeValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockUserRepository{
+				ListResult: domain.UserPage{Users: []domain.User{{ID: "user-1"}, {ID: "user-2"}}, Next: tt.next},
+			}
+			service := NewUserService(mockUserRepo, &mocks.MockUserPRRepository{}, &mocks.MockTxManager{})
+
+			users, next, err := service.ListUsers(context.Background(), filter, tt.cursor, tt.limit)
+
+			if tt.wantErrCode != "" {
+				var domainErr *domain.DomainError
+				if !errors.As(err, &domainErr) || domainErr.Code != tt.wantErrCode {
+					t.Fatalf("expected error code %s, got %v", tt.wantErrCode, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(users) != 2 {
+				t.Errorf("expected 2 users, got %d", len(users))
+			}
+			if mockUserRepo.ListFilter != filter {
+				t.Errorf("expected filter %+v, got %+v", filter, mockUserRepo.ListFilter)
+			}
+			if mockUserRepo.ListLimit != tt.wantLimit {
+				t.Errorf("expected limit %d, got %d", tt.wantLimit, mockUserRepo.ListLimit)
+			}
+			if mockUserRepo.ListAfter != tt.wantAfter {
+				t.Errorf("expected to start after %q, got %q", tt.wantAfter, mockUserRepo.ListAfter)
+			}
+			if tt.wantNext != (next != "") {
+				t.Errorf("expected next cursor %v, got %q", tt.wantNext, next)
+			}
+		})
+	}
+}
+
+func TestUserService_GetUser(t *testing.T) {
+	tests := []struct {
+		name       string
+		mockUser   *domain.User
+		mockGetErr error
+		summary    domain.ReviewSummary
+		summaryErr error
+		wantErr    error
+	}{
+		{
+			name:     "пользователь со сводкой ревью",
+			mockUser: &domain.User{ID: "user-1", Username: "user1", TeamName: "team-1", IsActive: true},
+			summary:  domain.ReviewSummary{Open: 2, Completed: 5},
+		},
+		{
+			name:       "пользователь не найден",
+			mockGetErr: sql.ErrNoRows,
+			wantErr:    sql.ErrNoRows,
+		},
+		{
+			name:       "ошибка подсчёта ревью",
+			mockUser:   &domain.User{ID: "user-1", TeamName: "team-1"},
+			summaryErr: errors.New("db down"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUserRepo := &mocks.MockUserRepository{GetByIDResult: tt.mockUser, GetByIDErr: tt.mockGetErr}
+			mockPRRepo := &mocks.MockUserPRRepository{ReviewSummaryResult: tt.summary, ReviewSummaryErr: tt.summaryErr}
+			service := NewUserService(mockUserRepo, mockPRRepo, &mocks.MockTxManager{})
+
+			details, err := service.GetUser(context.Background(), "user-1")
+
+			if tt.mockGetErr != nil || tt.summaryErr != nil {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if details.User != *tt.mockUser {
+				t.Errorf("expected user %+v, got %+v", *tt.mockUser, details.User)
+			}
+			if details.Reviews != tt.summary {
+				t.Errorf("expected reviews %+v, got %+v", tt.summary, details.Reviews)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+// ReviewerPreference decides which available candidates reviewer selection
+// tries first. The others are picked only when the preferred ones do not
+// fill the slots.
+type ReviewerPreference string
+
+const (
+	// PreferNone picks among all candidates at random.
+	PreferNone ReviewerPreference = ""
+	// PreferWorkingNow prefers candidates inside their working hours.
+	PreferWorkingNow ReviewerPreference = "working_now"
+	// PreferHoursOverlap prefers candidates whose working hours overlap the
+	// author's the most.
+	PreferHoursOverlap ReviewerPreference = "hours_overlap"
+)
+
+// reviewerRank scores a candidate; higher scores are picked first. A nil
+// rank picks at random.
+type reviewerRank func(u domain.User) int
+
+const minutesPerDay = 24 * 60
+
+// validateWorkingHours checks h as a user may set it.
+func validateWorkingHours(h *domain.WorkingHours) []domain.FieldError {
+	var fields []domain.FieldError
+	if h.TimeZone == "" {
+		fields = append(fields, domain.FieldError{Field: "working_hours.time_zone", Reason: "required"})
+	} else if _, err := loadTimeZone(h.TimeZone); err != nil {
+		fields = append(fields, domain.FieldError{Field: "working_hours.time_zone", Reason: "unknown time zone"})
+	}
+	if h.Start < 0 || h.Start >= minutesPerDay {
+		fields = append(fields, domain.FieldError{Field: "working_hours.start", Reason: "must be within a day"})
+	}
+	if h.End < 0 || h.End >= minutesPerDay {
+		fields = append(fields, domain.FieldError{Field: "working_hours.end", Reason: "must be within a day"})
+	} else if h.End == h.Start {
+		fields = append(fields, domain.FieldError{Field: "working_hours.end", Reason: "must differ from start"})
+	}
+	return fields
+}
+
+// loadTimeZone loads an IANA zone. "Local" is rejected: it means the
+// server's zone, not the user's.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %s", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// workingAt reports whether t falls into the working hours. A user without
+// working hours counts as always working.
+func workingAt(h *domain.WorkingHours, t time.Time) bool {
+	if h == nil {
+		return true
+	}
+	local := t.In(zoneOf(h))
+	minute := local.Hour()*60 + local.Minute()
+	if h.Start < h.End {
+		return minute >= h.Start && minute < h.End
+	}
+	return minute >= h.Start || minute < h.End
+}
+
+// hoursOverlap returns how many minutes of the author's working day around
+// t are also working time of other. A user without working hours overlaps
+// the whole day.
+func hoursOverlap(author, other *domain.WorkingHours, t time.Time) int {
+	from, to := workingDay(author, t, 0)
+	if other == nil {
+		return int(to.Sub(from) / time.Minute)
+	}
+
+	var overlap time.Duration
+	// The other's days before and after cover every zone offset.
+	for _, offset := range []int{-1, 0, 1} {
+		oFrom, oTo := workingDay(other, t, offset)
+		if start, end := later(from, oFrom), earlier(to, oTo); end.After(start) {
+			overlap += end.Sub(start)
+		}
+	}
+	return int(overlap / time.Minute)
+}
+
+// workingDay returns the working hours on the local date of t shifted by
+// offset days.
+func workingDay(h *domain.WorkingHours, t time.Time, offset int) (from, to time.Time) {
+	local := t.In(zoneOf(h))
+	y, m, d := local.Date()
+	d += offset
+	from = time.Date(y, m, d, 0, h.Start, 0, 0, local.Location())
+	if h.End <= h.Start {
+		d++
+	}
+	to = time.Date(y, m, d, 0, h.End, 0, 0, local.Location())
+	return from, to
+}
+
+func zoneOf(h *domain.WorkingHours) *time.Location {
+	loc, err := loadTimeZone(h.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// pickRanked picks up to max candidates, best rank first and with
+// domain.PickFair among equal ranks.
+func pickRanked(candidates []domain.User, max int, rank reviewerRank) []string {
+	if rank == nil {
+		return domain.PickFair(candidates, max)
+	}
+
+	byScore := make(map[int][]domain.User)
+	for _, c := range candidates {
+		score := rank(c)
+		byScore[score] = append(byScore[score], c)
+	}
+	scores := make([]int, 0, len(byScore))
+	for score := range byScore {
+		scores = append(scores, score)
+	}
+	slices.Sort(scores)
+	slices.Reverse(scores)
+
+	var out []string
+	for _, score := range scores {
+		if len(out) == max {
+			break
+		}
+		out = append(out, domain.PickFair(byScore[score], max-len(out))...)
+	}
+	return out
+}
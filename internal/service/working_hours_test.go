@@ -0,0 +1,86 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/forsitet/Service-for-assigning-reviewers-for-Pull-Requests/internal/domain"
+)
+
+func TestWorkingAt(t *testing.T) {
+	// 2025-01-15 07:30 UTC: 10:30 in Moscow, 14:30 in Novosibirsk, 02:30 in New York.
+	now := time.Date(2025, time.January, 15, 7, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		hours *domain.WorkingHours
+		want  bool
+	}{
+		{name: "без рабочих часов", hours: nil, want: true},
+		{name: "внутри рабочего дня", hours: &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60}, want: true},
+		{name: "до начала рабочего дня", hours: &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 11 * 60, End: 20 * 60}, want: false},
+		{name: "конец не включительно", hours: &domain.WorkingHours{TimeZone: "Asia/Novosibirsk", Start: 6 * 60, End: 14*60 + 30}, want: false},
+		{name: "ночная смена через полночь", hours: &domain.WorkingHours{TimeZone: "America/New_York", Start: 22 * 60, End: 6 * 60}, want: true},
+		{name: "вне ночной смены", hours: &domain.WorkingHours{TimeZone: "Asia/Novosibirsk", Start: 22 * 60, End: 6 * 60}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := workingAt(tt.hours, now); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestHoursOverlap(t *testing.T) {
+	now := time.Date(2025, time.January, 15, 7, 30, 0, 0, time.UTC)
+	moscow := &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 9 * 60, End: 18 * 60}
+
+	tests := []struct {
+		name  string
+		other *domain.WorkingHours
+		want  int
+	}{
+		{name: "тот же часовой пояс", other: &domain.WorkingHours{TimeZone: "Europe/Moscow", Start: 10 * 60, End: 19 * 60}, want: 8 * 60},
+		{name: "без рабочих часов", other: nil, want: 9 * 60},
+		// 09:00-18:00 in Novosibirsk is 05:00-14:00 in Moscow.
+		{name: "на четыре часа восточнее", other: &domain.WorkingHours{TimeZone: "Asia/Novosibirsk", Start: 9 * 60, End: 18 * 60}, want: 5 * 60},
+		// 09:00-18:00 in New York is 17:00-02:00 in Moscow.
+		{name: "другой континент", other: &domain.WorkingHours{TimeZone: "America/New_York", Start: 9 * 60, End: 18 * 60}, want: 60},
+		// 20:00-08:00 in Tokyo is 14:00-02:00 in Moscow.
+		{name: "ночная смена через полночь", other: &domain.WorkingHours{TimeZone: "Asia/Tokyo", Start: 20 * 60, End: 8 * 60}, want: 4 * 60},
+		{name: "без пересечения", other: &domain.WorkingHours{TimeZone: "Pacific/Auckland", Start: 9 * 60, End: 12 * 60}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hoursOverlap(moscow, tt.other, now); got != tt.want {
+				t.Errorf("expected %d minutes, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPickRanked(t *testing.T) {
+	candidates := []domain.User{{ID: "u1"}, {ID: "u2"}, {ID: "u3"}, {ID: "u4"}}
+	scores := map[string]int{"u1": 1, "u2": 3, "u3": 0, "u4": 3}
+	rank := func(u domain.User) int { return scores[u.ID] }
+
+	// Equal ranks are picked at random, so repeat.
+	for range 20 {
+		got := pickRanked(candidates, 2, rank)
+		slices.Sort(got)
+		if !slices.Equal(got, []string{"u2", "u4"}) {
+			t.Fatalf("expected the two best ranked, got %v", got)
+		}
+		got = pickRanked(candidates, 3, rank)
+		if len(got) != 3 || got[2] != "u1" {
+			t.Fatalf("expected u1 to fill the third slot, got %v", got)
+		}
+	}
+	if got := pickRanked(candidates, 4, nil); len(got) != 4 {
+		t.Errorf("expected all candidates without a rank, got %v", got)
+	}
+}
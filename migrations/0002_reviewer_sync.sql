@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS pull_request_reviewer_sync (
+  pr_id TEXT PRIMARY KEY REFERENCES pull_requests(id) ON DELETE CASCADE,
+  status TEXT NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  last_error TEXT NOT NULL DEFAULT '',
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+-- +goose Down
+DROP TABLE IF EXISTS pull_request_reviewer_sync;
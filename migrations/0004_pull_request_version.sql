@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE pull_requests ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
+-- +goose Down
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS version;
@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS idx_pull_requests_created_at_id ON pull_requests (created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_status_created_at ON pull_requests (status, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_author_created_at ON pull_requests (author_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_pull_requests_merged_at ON pull_requests (merged_at) WHERE merged_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pull_request_reviewers_reviewer ON pull_request_reviewers (reviewer_id, pr_id);
+CREATE INDEX IF NOT EXISTS idx_users_team_name ON users (team_name);
+-- +goose Down
+DROP INDEX IF EXISTS idx_users_team_name;
+DROP INDEX IF EXISTS idx_pull_request_reviewers_reviewer;
+DROP INDEX IF EXISTS idx_pull_requests_merged_at;
+DROP INDEX IF EXISTS idx_pull_requests_author_created_at;
+DROP INDEX IF EXISTS idx_pull_requests_status_created_at;
+DROP INDEX IF EXISTS idx_pull_requests_created_at_id;
@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE users ALTER COLUMN team_name DROP NOT NULL;
+-- +goose Down
+ALTER TABLE users ALTER COLUMN team_name SET NOT NULL;
@@ -0,0 +1,4 @@
+-- +goose Up
+ALTER TABLE teams ADD COLUMN archived_at TIMESTAMPTZ;
+-- +goose Down
+ALTER TABLE teams DROP COLUMN archived_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS team_deactivations (
+  id BIGSERIAL PRIMARY KEY,
+  team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+  deactivated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  reactivated_at TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS idx_team_deactivations_team_name ON team_deactivations (team_name, id DESC);
+
+CREATE TABLE IF NOT EXISTS team_deactivation_users (
+  deactivation_id BIGINT NOT NULL REFERENCES team_deactivations(id) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  PRIMARY KEY (deactivation_id, user_id)
+);
+-- +goose Down
+DROP TABLE IF EXISTS team_deactivation_users;
+DROP TABLE IF EXISTS team_deactivations;
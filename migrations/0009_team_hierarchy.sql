@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE teams ADD COLUMN parent_team TEXT REFERENCES teams(name);
+ALTER TABLE teams ADD CONSTRAINT teams_parent_team_not_self CHECK (parent_team <> name);
+CREATE INDEX IF NOT EXISTS idx_teams_parent_team ON teams (parent_team);
+-- +goose Down
+DROP INDEX IF EXISTS idx_teams_parent_team;
+ALTER TABLE teams DROP COLUMN parent_team;
@@ -0,0 +1,49 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS team_memberships (
+  team_name TEXT NOT NULL REFERENCES teams(name) ON DELETE CASCADE,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (team_name, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_team_memberships_user_id ON team_memberships (user_id);
+
+INSERT INTO team_memberships (team_name, user_id)
+SELECT team_name, id FROM users WHERE team_name IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- users.team_name stays the primary team; keep its membership row in step.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION sync_primary_team_membership() RETURNS trigger AS $$
+BEGIN
+  IF TG_OP = 'UPDATE' AND OLD.team_name IS NOT DISTINCT FROM NEW.team_name THEN
+    RETURN NEW;
+  END IF;
+  IF TG_OP = 'UPDATE' AND OLD.team_name IS NOT NULL THEN
+    DELETE FROM team_memberships WHERE team_name = OLD.team_name AND user_id = OLD.id;
+  END IF;
+  IF NEW.team_name IS NOT NULL THEN
+    INSERT INTO team_memberships (team_name, user_id)
+    VALUES (NEW.team_name, NEW.id)
+    ON CONFLICT DO NOTHING;
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER users_primary_team_membership
+AFTER INSERT OR UPDATE OF team_name ON users
+FOR EACH ROW EXECUTE FUNCTION sync_primary_team_membership();
+
+ALTER TABLE pull_requests ADD COLUMN team_name TEXT REFERENCES teams(name);
+UPDATE pull_requests p
+SET team_name = u.team_name
+FROM users u
+WHERE u.id = p.author_id;
+CREATE INDEX IF NOT EXISTS idx_pull_requests_team_name ON pull_requests (team_name, created_at DESC);
+-- +goose Down
+DROP INDEX IF EXISTS idx_pull_requests_team_name;
+ALTER TABLE pull_requests DROP COLUMN team_name;
+DROP TRIGGER IF EXISTS users_primary_team_membership ON users;
+DROP FUNCTION IF EXISTS sync_primary_team_membership();
+DROP TABLE IF EXISTS team_memberships;
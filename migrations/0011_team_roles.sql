@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE team_memberships ADD COLUMN role TEXT NOT NULL DEFAULT 'member';
+ALTER TABLE team_memberships ADD CONSTRAINT team_memberships_role_check
+  CHECK (role IN ('member', 'maintainer', 'lead'));
+
+ALTER TABLE pull_requests ADD COLUMN labels TEXT[] NOT NULL DEFAULT '{}';
+-- +goose Down
+ALTER TABLE pull_requests DROP COLUMN labels;
+ALTER TABLE team_memberships DROP CONSTRAINT team_memberships_role_check;
+ALTER TABLE team_memberships DROP COLUMN role;
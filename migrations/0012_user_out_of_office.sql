@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_out_of_office (
+  id BIGSERIAL PRIMARY KEY,
+  user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  starts_at TIMESTAMPTZ NOT NULL,
+  ends_at TIMESTAMPTZ NOT NULL,
+  reason TEXT NOT NULL DEFAULT '',
+  reassigned_at TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT user_out_of_office_window_check CHECK (ends_at > starts_at)
+);
+CREATE INDEX IF NOT EXISTS idx_user_out_of_office_user ON user_out_of_office (user_id, ends_at);
+CREATE INDEX IF NOT EXISTS idx_user_out_of_office_pending ON user_out_of_office (starts_at) WHERE reassigned_at IS NULL;
+-- +goose Down
+DROP TABLE IF EXISTS user_out_of_office;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_working_hours (
+  user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+  time_zone TEXT NOT NULL,
+  start_minute SMALLINT NOT NULL,
+  end_minute SMALLINT NOT NULL,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  CONSTRAINT user_working_hours_minutes_check CHECK (
+    start_minute BETWEEN 0 AND 1439 AND end_minute BETWEEN 0 AND 1439 AND start_minute <> end_minute)
+);
+-- +goose Down
+DROP TABLE IF EXISTS user_working_hours;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE users ADD COLUMN max_open_reviews INTEGER CONSTRAINT users_max_open_reviews_check CHECK (max_open_reviews > 0);
+ALTER TABLE teams ADD COLUMN default_max_open_reviews INTEGER CONSTRAINT teams_default_max_open_reviews_check CHECK (default_max_open_reviews > 0);
+-- +goose Down
+ALTER TABLE teams DROP COLUMN default_max_open_reviews;
+ALTER TABLE users DROP COLUMN max_open_reviews;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS review_assignments (
+  id BIGSERIAL PRIMARY KEY,
+  pr_id TEXT NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+  reviewer_id TEXT NOT NULL REFERENCES users(id) ON DELETE RESTRICT,
+  assigned_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+INSERT INTO review_assignments (pr_id, reviewer_id, assigned_at)
+SELECT r.pr_id, r.reviewer_id, p.created_at
+FROM pull_request_reviewers r
+JOIN pull_requests p ON p.id = r.pr_id
+ORDER BY p.created_at;
+CREATE INDEX IF NOT EXISTS idx_review_assignments_reviewer ON review_assignments (reviewer_id, assigned_at);
+ALTER TABLE users ADD COLUMN weekly_review_quota INTEGER CONSTRAINT users_weekly_review_quota_check CHECK (weekly_review_quota > 0);
+-- +goose Down
+ALTER TABLE users DROP COLUMN weekly_review_quota;
+DROP TABLE IF EXISTS review_assignments;
@@ -0,0 +1,10 @@
+-- +goose Up
+ALTER TABLE pull_requests ADD COLUMN github_repo TEXT;
+ALTER TABLE pull_requests ADD COLUMN github_number INTEGER CONSTRAINT pull_requests_github_number_check CHECK (github_number > 0);
+ALTER TABLE pull_requests ADD CONSTRAINT pull_requests_github_link_check CHECK ((github_repo IS NULL) = (github_number IS NULL));
+CREATE UNIQUE INDEX pull_requests_github_link_key ON pull_requests (github_repo, github_number) WHERE github_repo IS NOT NULL;
+-- +goose Down
+DROP INDEX IF EXISTS pull_requests_github_link_key;
+ALTER TABLE pull_requests DROP CONSTRAINT pull_requests_github_link_check;
+ALTER TABLE pull_requests DROP COLUMN github_number;
+ALTER TABLE pull_requests DROP COLUMN github_repo;